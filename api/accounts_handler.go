@@ -1,13 +1,18 @@
 package api
 
 import (
+	"Simple-Bank/auth"
+	"Simple-Bank/db/models"
 	"Simple-Bank/db/services"
 	"Simple-Bank/requests"
 	"Simple-Bank/responses"
+	"Simple-Bank/stepup"
 	"Simple-Bank/token"
+	"Simple-Bank/verification"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 	"net/http"
 	"time"
@@ -49,7 +54,7 @@ func (handler *Handler) GetAccount(context *gin.Context) {
 	}
 
 	auhPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
-	if auhPayload.Username != account.Owner {
+	if !auth.CanAccess(auhPayload, account.Owner, auth.PermissionReadAccounts) {
 		err := fmt.Errorf("users cannot create account for other users")
 		context.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -123,26 +128,231 @@ func (handler *Handler) Transfer(context *gin.Context) {
 		return
 	}
 
+	// large transfers require a recent re-authentication, even before they wait for approval
+	if handler.stepUp.TransferRequiresStepUp(req.Amount) && !handler.checkStepUp(context, stepup.OperationTransfer) {
+		return
+	}
+
 	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	if err := handler.emails.CheckVerified(authPayload.Username); err != nil {
+		if errors.Is(err, verification.ErrEmailNotVerified) {
+			context.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
 
-	transfer, err := handler.services.Transfer(services.TransferRequest{
+	transferRequest := services.TransferRequest{
 		Owner:         authPayload.Username,
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
-	})
+	}
+
+	// large transfers wait until another user approves them
+	if handler.approvals.TransferRequiresApproval(req.Amount) {
+		approval, err := handler.approvals.RequestTransfer(transferRequest)
+		if err != nil {
+			approvalErrorResponse(context, err)
+			return
+		}
+		context.JSON(http.StatusAccepted, newApprovalResponse(approval))
+		return
+	}
+
+	transfer, err := handler.services.Transfer(transferRequest)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTransferFailed):
+			// the failed transfer has been persisted; return it so the client can see why it failed
+			context.JSON(http.StatusUnprocessableEntity, newTransferResponse(transfer))
+		case errors.Is(err, services.ErrNotAccountOwner):
+			context.JSON(http.StatusUnauthorized, errorResponse(err))
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			context.JSON(http.StatusNotFound, errorResponse(err))
+		default:
+			var pgError *pgconn.PgError
+			if errors.As(err, &pgError) && pgError.Code == foreignKeyViolationCode {
+				context.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+			context.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	context.JSON(http.StatusOK, newTransferResponse(transfer))
+}
+
+// GetTransfer returns a transfer and the history of its status. Only the owners of its source and destination
+// accounts, and users allowed to read any transfer, can see a transfer.
+func (handler *Handler) GetTransfer(context *gin.Context) {
+	var req requests.GetTransferRequest
+	if err := context.ShouldBindUri(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := handler.services.GetTransfer(req.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			context.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !auth.HasPermission(authPayload, auth.PermissionReadTransfers) {
+		isParty, err := handler.ownsAnyAccount(authPayload.Username, transfer.FromAccountID, transfer.ToAccountID)
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if !isParty {
+			err := fmt.Errorf("users cannot see transfers of other users")
+			context.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+	}
+
+	history, err := handler.services.ListTransferStatusHistory(transfer.ID)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	context.JSON(http.StatusOK, responses.TransferResponse{
+	res := responses.GetTransferResponse{
+		TransferResponse: newTransferResponse(transfer),
+		History:          make([]responses.TransferStatusChangeResponse, len(history)),
+	}
+	for i, change := range history {
+		res.History[i] = responses.TransferStatusChangeResponse{
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			Reason:     change.Reason,
+			Actor:      change.Actor,
+			CreatedAt:  change.CreatedAt.Local(),
+		}
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// ownsAnyAccount reports whether the user owns any of the accounts with the given ids.
+func (handler *Handler) ownsAnyAccount(username string, accountIDs ...int64) (bool, error) {
+	for _, id := range accountIDs {
+		account, err := handler.services.GetAccount(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return false, err
+		}
+		if account.Owner == username {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func newTransferResponse(transfer models.Transfer) responses.TransferResponse {
+	return responses.TransferResponse{
 		TransferID:      transfer.ID,
 		SrcAccountID:    transfer.FromAccountID,
 		DstAccountID:    transfer.ToAccountID,
 		Amount:          transfer.Amount,
+		Status:          transfer.Status,
+		StatusReason:    transfer.StatusReason,
 		CreatedAt:       transfer.CreatedAt.Local(),
+		UpdatedAt:       transfer.UpdatedAt.Local(),
 		IncomingEntryID: transfer.IncomingEntryID,
 		OutgoingEntryID: transfer.OutgoingEntryID,
+	}
+}
+
+// GetAccountBalance returns the balance of an account as of the "at" query parameter,
+// or as of now if it is not provided.
+func (handler *Handler) GetAccountBalance(context *gin.Context) {
+	var uriReq requests.GetAccountRequest
+	if err := context.ShouldBindUri(&uriReq); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req requests.GetAccountBalanceRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.At.IsZero() {
+		req.At = time.Now()
+	}
+
+	account, err := handler.services.GetAccount(uriReq.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			context.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !auth.CanAccess(authPayload, account.Owner, auth.PermissionReadAccounts) {
+		err := fmt.Errorf("users cannot see balance of other users` accounts")
+		context.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	balance, err := handler.services.GetBalanceAt(account.ID, req.At)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, responses.AccountBalanceResponse{
+		AccountID: account.ID,
+		Balance:   balance,
+		At:        req.At.Local(),
+	})
+}
+
+// Deposit deposits money into any account. Only users allowed to deposit, e.g. tellers, can deposit.
+func (handler *Handler) Deposit(context *gin.Context) {
+	var req requests.DepositRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	entry, err := handler.services.DepositMoney(req)
+	if err != nil {
+		if errors.Is(err, services.ErrAccountFrozen) {
+			context.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			context.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == foreignKeyViolationCode {
+			context.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, responses.EntryResponse{
+		EntryID:   entry.ID,
+		AccountID: entry.AccountID,
+		CreatedAt: entry.CreatedAt.Local(),
+		Amount:    entry.Amount,
 	})
 }
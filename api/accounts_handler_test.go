@@ -12,6 +12,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"gorm.io/gorm"
@@ -383,7 +384,7 @@ func TestTransfer(t *testing.T) {
 	account1 := createAccount(user1.Username)
 	account2 := createAccount(user2.Username)
 
-	amount := int32(util.RandomInt(1, math.MaxInt32))
+	amount := int32(util.RandomInt(1, largeTransferAmount-1))
 
 	transfer := models.Transfer{
 		ID:              util.RandomID(),
@@ -392,9 +393,17 @@ func TestTransfer(t *testing.T) {
 		Amount:          amount,
 		OutgoingEntryID: util.RandomID(),
 		IncomingEntryID: util.RandomID(),
+		Status:          models.TransferStatusCompleted,
+		StatusReason:    "transfer completed",
 		CreatedAt:       time.Now().UTC(),
 	}
 
+	failedTransfer := transfer
+	failedTransfer.OutgoingEntryID = 0
+	failedTransfer.IncomingEntryID = 0
+	failedTransfer.Status = models.TransferStatusFailed
+	failedTransfer.StatusReason = "insufficient funds"
+
 	testCases := []struct {
 		name          string
 		req           requests.TransferRequest
@@ -425,6 +434,31 @@ func TestTransfer(t *testing.T) {
 				requireBodyMatchTransfer(t, recorder.Body, transfer)
 			},
 		},
+		{
+			name: "LargeTransferRequiresApproval",
+			req: requests.TransferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        largeTransferAmount,
+			},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices, req requests.TransferRequest) {
+				services.EXPECT().GetAccount(account1.ID).Times(1).Return(account1, nil)
+				services.EXPECT().
+					CreateApproval(gomock.Cond(func(x any) bool {
+						approval := x.(servicesPackage.CreateApprovalRequest)
+						return approval.Operation == models.OperationTransfer && approval.RequestedBy == user1.Username
+					})).
+					Times(1).
+					Return(models.Approval{ID: 1, Operation: models.OperationTransfer, Status: models.ApprovalStatusPending}, nil)
+				services.EXPECT().Transfer(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
 		{
 			name: "UnAuthorized",
 			req: requests.TransferRequest{
@@ -458,6 +492,61 @@ func TestTransfer(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "TransferFailed",
+			req: requests.TransferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+			},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices, req requests.TransferRequest) {
+				services.EXPECT().Transfer(gomock.Any()).Times(1).
+					Return(failedTransfer, servicesPackage.ErrTransferFailed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchTransfer(t, recorder.Body, failedTransfer)
+			},
+		},
+		{
+			name: "NotAccountOwner",
+			req: requests.TransferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+			},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices, req requests.TransferRequest) {
+				services.EXPECT().Transfer(gomock.Any()).Times(1).
+					Return(models.Transfer{}, servicesPackage.ErrNotAccountOwner)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			req: requests.TransferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+			},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices, req requests.TransferRequest) {
+				services.EXPECT().Transfer(gomock.Any()).Times(1).
+					Return(models.Transfer{}, gorm.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
 		{
 			name: "InternalServerError",
 			req: requests.TransferRequest{
@@ -567,5 +656,484 @@ func requireBodyMatchTransfer(t *testing.T, body *bytes.Buffer, transfer models.
 	require.Equal(t, transfer.Amount, response.Amount)
 	require.Equal(t, transfer.IncomingEntryID, response.IncomingEntryID)
 	require.Equal(t, transfer.OutgoingEntryID, response.OutgoingEntryID)
+	require.Equal(t, transfer.Status, response.Status)
+	require.Equal(t, transfer.StatusReason, response.StatusReason)
 	require.Equal(t, transfer.CreatedAt.Local().Truncate(time.Second), response.CreatedAt.Local().Truncate(time.Second))
 }
+
+func TestGetAccountBalance(t *testing.T) {
+	randomUser, _ := randomUser(t)
+	account := createAccount(randomUser.Username)
+	balance := util.RandomBalance()
+	at := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		query         string
+		setupAuth     func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker)
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			query:     "?at=" + at.Format(time.RFC3339),
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, randomUser.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				services.EXPECT().
+					GetBalanceAt(gomock.Eq(account.ID), timeMatcher{at}).
+					Times(1).
+					Return(balance, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccountBalance(t, recorder.Body, account.ID, balance, at)
+			},
+		},
+		{
+			name:      "DefaultsToNow",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, randomUser.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				services.EXPECT().
+					GetBalanceAt(gomock.Eq(account.ID), gomock.Any()).
+					Times(1).
+					Return(balance, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccountBalance(t, recorder.Body, account.ID, balance, time.Now())
+			},
+		},
+		{
+			name:      "UnAuthorized",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(gomock.Any()).Times(0)
+				services.EXPECT().GetBalanceAt(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "OtherUsersAccount",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, util.RandomUsername(), time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				services.EXPECT().GetBalanceAt(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "AuditorReadsOtherUsersAccount",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, util.RandomUsername(), models.RoleAuditor, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				services.EXPECT().GetBalanceAt(gomock.Eq(account.ID), gomock.Any()).Times(1).Return(balance, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "BadRequest",
+			accountID: account.ID,
+			query:     "?at=yesterday",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, randomUser.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(gomock.Any()).Times(0)
+				services.EXPECT().GetBalanceAt(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, randomUser.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(gomock.Eq(account.ID)).Times(1).Return(models.Account{}, gorm.ErrRecordNotFound)
+				services.EXPECT().GetBalanceAt(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalServerError",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, randomUser.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				services.EXPECT().
+					GetBalanceAt(gomock.Eq(account.ID), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			require.NotEmpty(t, tokenMaker)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/balance%s", testCase.accountID, testCase.query)
+			httpReq, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			testCase.setupAuth(t, httpReq, server.handlers.tokenMaker)
+
+			server.RouterServeHTTP(recorder, httpReq)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+// timeMatcher matches a time.Time argument representing the same instant as the expected time.
+type timeMatcher struct {
+	expected time.Time
+}
+
+func (m timeMatcher) Matches(x any) bool {
+	actual, ok := x.(time.Time)
+	return ok && actual.Equal(m.expected)
+}
+
+func (m timeMatcher) String() string {
+	return fmt.Sprintf("is equal to %v", m.expected)
+}
+
+func requireBodyMatchAccountBalance(t *testing.T, body *bytes.Buffer, accountID int64, balance int64, at time.Time) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var response responses.AccountBalanceResponse
+	err = json.Unmarshal(data, &response)
+	require.NoError(t, err)
+	require.Equal(t, accountID, response.AccountID)
+	require.Equal(t, balance, response.Balance)
+	require.WithinDuration(t, at, response.At, time.Second)
+}
+
+func TestGetTransfer(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := createAccount(user1.Username)
+	account2 := createAccount(user2.Username)
+
+	transfer := models.Transfer{
+		ID:              util.RandomID(),
+		FromAccountID:   account1.ID,
+		ToAccountID:     account2.ID,
+		Amount:          int32(util.RandomInt(1, math.MaxInt32)),
+		OutgoingEntryID: util.RandomID(),
+		IncomingEntryID: util.RandomID(),
+		Status:          models.TransferStatusCompleted,
+		StatusReason:    "transfer completed",
+		CreatedAt:       time.Now().UTC(),
+		UpdatedAt:       time.Now().UTC(),
+	}
+	history := []models.TransferStatusHistory{
+		{ID: 1, TransferID: transfer.ID, ToStatus: models.TransferStatusPending, Actor: user1.Username},
+		{
+			ID:         2,
+			TransferID: transfer.ID,
+			FromStatus: models.TransferStatusPending,
+			ToStatus:   models.TransferStatusCompleted,
+			Reason:     "transfer completed",
+			Actor:      user1.Username,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		transferID    int64
+		setupAuth     func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker)
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			transferID: transfer.ID,
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetTransfer(gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				services.EXPECT().GetAccount(gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				services.EXPECT().GetAccount(gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				services.EXPECT().ListTransferStatusHistory(gomock.Eq(transfer.ID)).Times(1).Return(history, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.GetTransferResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, response.TransferID)
+				require.Equal(t, transfer.Status, response.Status)
+				require.Len(t, response.History, len(history))
+				for i, change := range history {
+					require.Equal(t, change.FromStatus, response.History[i].FromStatus)
+					require.Equal(t, change.ToStatus, response.History[i].ToStatus)
+					require.Equal(t, change.Actor, response.History[i].Actor)
+				}
+			},
+		},
+		{
+			name:       "UnAuthorized",
+			transferID: transfer.ID,
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetTransfer(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "OtherUsersTransfer",
+			transferID: transfer.ID,
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, util.RandomUsername(), time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetTransfer(gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				services.EXPECT().GetAccount(gomock.Any()).Times(2).Return(account1, nil)
+				services.EXPECT().ListTransferStatusHistory(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "NotFound",
+			transferID: transfer.ID,
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetTransfer(gomock.Eq(transfer.ID)).Times(1).Return(models.Transfer{}, gorm.ErrRecordNotFound)
+				services.EXPECT().ListTransferStatusHistory(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "BadRequest",
+			transferID: 0,
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetTransfer(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			require.NotEmpty(t, tokenMaker)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/%d", testCase.transferID)
+			httpReq, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			testCase.setupAuth(t, httpReq, server.handlers.tokenMaker)
+
+			server.RouterServeHTTP(recorder, httpReq)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeposit(t *testing.T) {
+	randomUser, _ := randomUser(t)
+	account := createAccount(randomUser.Username)
+	amount := int32(util.RandomInt(1, 1000))
+
+	entry := models.Entry{
+		ID:        util.RandomInt(1, 1000),
+		AccountID: account.ID,
+		Amount:    amount,
+		CreatedAt: time.Now().Truncate(time.Second),
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker)
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Teller",
+			body: gin.H{"account_id": account.ID, "amount": amount},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, util.RandomUsername(), models.RoleTeller, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().
+					DepositMoney(gomock.Eq(requests.DepositRequest{AccountID: account.ID, Amount: amount})).
+					Times(1).
+					Return(entry, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.EntryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, entry.ID, response.EntryID)
+				require.Equal(t, account.ID, response.AccountID)
+				require.Equal(t, amount, response.Amount)
+			},
+		},
+		{
+			name: "Customer",
+			body: gin.H{"account_id": account.ID, "amount": amount},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, randomUser.Username, models.RoleCustomer, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().DepositMoney(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Auditor",
+			body: gin.H{"account_id": account.ID, "amount": amount},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, util.RandomUsername(), models.RoleAuditor, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().DepositMoney(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UnAuthorized",
+			body: gin.H{"account_id": account.ID, "amount": amount},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().DepositMoney(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest",
+			body: gin.H{"account_id": account.ID, "amount": -amount},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, util.RandomUsername(), models.RoleTeller, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().DepositMoney(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			body: gin.H{"account_id": account.ID, "amount": amount},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, util.RandomUsername(), models.RoleAdmin, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().DepositMoney(gomock.Any()).Times(1).Return(models.Entry{}, gorm.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			httpReq, err := http.NewRequest(http.MethodPost, "/accounts/deposit", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			testCase.setupAuth(t, httpReq, server.handlers.tokenMaker)
+
+			server.RouterServeHTTP(recorder, httpReq)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package api
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+// adminAction returns the operator of the back office and the reason of their action, written to the audit trail.
+func adminAction(context *gin.Context, reason string) services.AdminAction {
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	return services.AdminAction{
+		Operator: authPayload.Username,
+		Reason:   reason,
+	}
+}
+
+// adminErrorResponse writes the response of a failed action of the back office.
+func adminErrorResponse(context *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMissingReason), errors.Is(err, services.ErrInvalidAdjustment):
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		context.JSON(http.StatusNotFound, errorResponse(err))
+	default:
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
+
+func newAdminUserResponse(user models.User) responses.AdminUserResponse {
+	res := responses.AdminUserResponse{
+		UserInformationResponse: responses.UserInformationResponse{
+			Username:      user.Username,
+			Email:         user.Email,
+			FullName:      user.FullName,
+			Role:          user.Role,
+			EmailVerified: user.EmailVerified,
+			CreatedAt:     user.CreatedAt.Local().Truncate(time.Second),
+			UpdatedAt:     user.UpdatedAt.Local().Truncate(time.Second),
+			DeletedAt:     user.DeletedAt.Time.Truncate(time.Second),
+		},
+		PasswordResetRequired: user.PasswordResetRequired,
+	}
+	if user.FrozenAt != nil {
+		frozenAt := user.FrozenAt.Local()
+		res.FrozenAt = &frozenAt
+	}
+
+	return res
+}
+
+func newAdminAccountResponse(account models.Account) responses.AdminAccountResponse {
+	res := responses.AdminAccountResponse{
+		GetAccountResponse: responses.GetAccountResponse{
+			AccountID: account.ID,
+			Owner:     account.Owner,
+			Balance:   account.Balance,
+			CreatedAt: account.CreatedAt.Truncate(time.Second).Local(),
+			UpdatedAt: account.UpdatedAt.Truncate(time.Second).Local(),
+			DeletedAt: account.DeletedAt.Time.Truncate(time.Second),
+		},
+	}
+	if account.FrozenAt != nil {
+		frozenAt := account.FrozenAt.Local()
+		res.FrozenAt = &frozenAt
+	}
+
+	return res
+}
+
+// SearchUsers returns the users whose username or email contains the query.
+func (handler *Handler) SearchUsers(context *gin.Context) {
+	var req requests.AdminSearchUsersRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	users, err := handler.services.SearchUsers(adminAction(context, req.Reason), services.SearchUsersRequest{
+		Query:      req.Query,
+		PageSize:   int(req.PageSize),
+		PageNumber: int(req.PageID),
+	})
+	if err != nil {
+		adminErrorResponse(context, err)
+		return
+	}
+
+	res := responses.AdminListUsersResponse{Users: make([]responses.AdminUserResponse, len(users))}
+	for i, user := range users {
+		res.Users[i] = newAdminUserResponse(user)
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// ListUserAccounts returns every account of any user.
+func (handler *Handler) ListUserAccounts(context *gin.Context) {
+	var uri requests.AdminUserRequest
+	if err := context.ShouldBindUri(&uri); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req requests.AdminReasonQuery
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	accounts, err := handler.services.ListUserAccounts(adminAction(context, req.Reason), uri.Username)
+	if err != nil {
+		adminErrorResponse(context, err)
+		return
+	}
+
+	res := responses.AdminListAccountsResponse{Accounts: make([]responses.AdminAccountResponse, len(accounts))}
+	for i, account := range accounts {
+		res.Accounts[i] = newAdminAccountResponse(account)
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// ListUserSessions returns every session of any user, including blocked and expired ones.
+func (handler *Handler) ListUserSessions(context *gin.Context) {
+	var uri requests.AdminUserRequest
+	if err := context.ShouldBindUri(&uri); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req requests.AdminReasonQuery
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	sessions, err := handler.services.ListUserSessions(adminAction(context, req.Reason), uri.Username)
+	if err != nil {
+		adminErrorResponse(context, err)
+		return
+	}
+
+	res := responses.AdminListSessionsResponse{Sessions: make([]responses.AdminSessionResponse, len(sessions))}
+	for i, session := range sessions {
+		res.Sessions[i] = responses.AdminSessionResponse{
+			SessionID:  session.ID,
+			FamilyID:   session.FamilyID,
+			UserAgent:  session.UserAgent,
+			ClientIP:   session.ClientIP,
+			IsBlocked:  session.IsBlocked,
+			CreatedAt:  session.CreatedAt.Local(),
+			ExpiresAt:  session.ExpiresAt.Local(),
+			ConsumedAt: session.ConsumedAt,
+		}
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// ListAccountEntries returns the entries of any account.
+func (handler *Handler) ListAccountEntries(context *gin.Context) {
+	var uri requests.GetAccountRequest
+	if err := context.ShouldBindUri(&uri); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req requests.AdminListEntriesRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	entries, err := handler.services.ListAccountEntries(adminAction(context, req.Reason), services.ListEntriesRequest{
+		AccountID:  uri.ID,
+		PageSize:   int(req.PageSize),
+		PageNumber: int(req.PageID),
+	})
+	if err != nil {
+		adminErrorResponse(context, err)
+		return
+	}
+
+	res := responses.ListEntriesResponse{Entries: make([]responses.EntryResponse, len(entries))}
+	for i, entry := range entries {
+		res.Entries[i] = responses.EntryResponse{
+			EntryID:   entry.ID,
+			AccountID: entry.AccountID,
+			CreatedAt: entry.CreatedAt.Local(),
+			Amount:    entry.Amount,
+		}
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// FreezeUser freezes a user, who cannot log in anymore and whose sessions are blocked.
+func (handler *Handler) FreezeUser(context *gin.Context) {
+	handler.setUserFrozen(context, true)
+}
+
+// UnfreezeUser unfreezes a user, who can log in again.
+func (handler *Handler) UnfreezeUser(context *gin.Context) {
+	handler.setUserFrozen(context, false)
+}
+
+func (handler *Handler) setUserFrozen(context *gin.Context, frozen bool) {
+	var uri requests.AdminUserRequest
+	if err := context.ShouldBindUri(&uri); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req requests.AdminReasonRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := handler.services.SetUserFrozen(adminAction(context, req.Reason), uri.Username, frozen)
+	if err != nil {
+		adminErrorResponse(context, err)
+		return
+	}
+	if frozen {
+		handler.revocations.Reset()
+	}
+
+	context.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// ForcePasswordReset requires a user to change their password and logs them out of every session.
+func (handler *Handler) ForcePasswordReset(context *gin.Context) {
+	var uri requests.AdminUserRequest
+	if err := context.ShouldBindUri(&uri); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req requests.AdminReasonRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := handler.services.ForcePasswordReset(adminAction(context, req.Reason), uri.Username)
+	if err != nil {
+		adminErrorResponse(context, err)
+		return
+	}
+	handler.revocations.Reset()
+
+	context.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// UnlockUser forgets the failed logins of a user locked out by them, who can log in again right away.
+func (handler *Handler) UnlockUser(context *gin.Context) {
+	var uri requests.AdminUserRequest
+	if err := context.ShouldBindUri(&uri); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req requests.AdminReasonRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := handler.services.UnlockUser(adminAction(context, req.Reason), uri.Username)
+	if err != nil {
+		adminErrorResponse(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// FreezeAccount freezes a customer account, which cannot send or receive money anymore.
+func (handler *Handler) FreezeAccount(context *gin.Context) {
+	handler.setAccountFrozen(context, true)
+}
+
+// UnfreezeAccount unfreezes a customer account.
+func (handler *Handler) UnfreezeAccount(context *gin.Context) {
+	handler.setAccountFrozen(context, false)
+}
+
+func (handler *Handler) setAccountFrozen(context *gin.Context, frozen bool) {
+	var uri requests.GetAccountRequest
+	if err := context.ShouldBindUri(&uri); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req requests.AdminReasonRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := handler.services.SetAccountFrozen(adminAction(context, req.Reason), uri.ID, frozen)
+	if err != nil {
+		adminErrorResponse(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, newAdminAccountResponse(account))
+}
+
+// AdjustBalance requests a manual adjustment to a customer account, which is posted once another admin approves it.
+func (handler *Handler) AdjustBalance(context *gin.Context) {
+	var uri requests.GetAccountRequest
+	if err := context.ShouldBindUri(&uri); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req requests.AdminAdjustBalanceRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	action := adminAction(context, req.Reason)
+	approval, err := handler.approvals.RequestAdjustment(action.Operator, action.Reason, uri.ID, req.Amount)
+	if err != nil {
+		approvalErrorResponse(context, err)
+		return
+	}
+
+	context.JSON(http.StatusAccepted, newApprovalResponse(approval))
+}
+
+// AdminRevokeToken denies any token of any user, e.g. because it was stolen.
+func (handler *Handler) AdminRevokeToken(context *gin.Context) {
+	var req requests.AdminRevokeTokenRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the token cannot be decoded, so it is denied for as long as any token can live
+	lifetime := max(handler.config.TokenAccessTokenDuration, handler.config.TokenRefreshTokenDuration)
+	if err := handler.services.AdminRevokeToken(adminAction(context, req.Reason), services.RevokeTokenRequest{
+		TokenID:   uuid.MustParse(req.TokenID),
+		Username:  req.Username,
+		ExpiresAt: time.Now().Add(lifetime),
+	}); err != nil {
+		adminErrorResponse(context, err)
+		return
+	}
+	handler.revocations.Reset()
+
+	context.Status(http.StatusNoContent)
+}
+
+// ListAuditEvents returns the audit trail of the back office.
+func (handler *Handler) ListAuditEvents(context *gin.Context) {
+	var req requests.AdminListAuditEventsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	events, err := handler.services.ListAuditEvents(adminAction(context, req.Reason), services.ListAuditEventsRequest{
+		Operator:   req.Operator,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		PageSize:   int(req.PageSize),
+		PageNumber: int(req.PageID),
+	})
+	if err != nil {
+		adminErrorResponse(context, err)
+		return
+	}
+
+	res := responses.ListAuditEventsResponse{Events: make([]responses.AuditEventResponse, len(events))}
+	for i, event := range events {
+		res.Events[i] = responses.AuditEventResponse{
+			ID:         event.ID,
+			Operator:   event.Operator,
+			Action:     event.Action,
+			TargetType: event.TargetType,
+			TargetID:   event.TargetID,
+			Reason:     event.Reason,
+			Details:    json.RawMessage(event.Details),
+			CreatedAt:  event.CreatedAt.Local(),
+		}
+	}
+
+	context.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,431 @@
+package api
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	servicesPackage "Simple-Bank/db/services"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFreezeUser(t *testing.T) {
+	user, _ := randomUser(t)
+	operator := util.RandomUsername()
+	reason := "account takeover"
+
+	frozenUser := user
+	frozenAt := time.Now()
+	frozenUser.FrozenAt = &frozenAt
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker)
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"reason": reason},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, operator, models.RoleAdmin, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				action := servicesPackage.AdminAction{Operator: operator, Reason: reason}
+				services.EXPECT().SetUserFrozen(action, user.Username, true).Times(1).Return(frozenUser, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.AdminUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, user.Username, response.Username)
+				require.NotNil(t, response.FrozenAt)
+			},
+		},
+		{
+			name: "MissingReason",
+			body: gin.H{},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, operator, models.RoleAdmin, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().SetUserFrozen(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			body: gin.H{"reason": reason},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, operator, models.RoleAdmin, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().
+					SetUserFrozen(gomock.Any(), user.Username, true).
+					Times(1).
+					Return(models.User{}, gorm.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InternalServerError",
+			body: gin.H{"reason": reason},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, operator, models.RoleAdmin, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().
+					SetUserFrozen(gomock.Any(), user.Username, true).
+					Times(1).
+					Return(models.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "Auditor",
+			body: gin.H{"reason": reason},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, operator, models.RoleAuditor, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().SetUserFrozen(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			body: gin.H{"reason": reason},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().SetUserFrozen(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UnAuthorized",
+			body: gin.H{"reason": reason},
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().SetUserFrozen(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/users/%s/freeze", user.Username)
+			httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			testCase.setupAuth(t, httpReq, server.handlers.tokenMaker)
+
+			server.RouterServeHTTP(recorder, httpReq)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUnlockUser(t *testing.T) {
+	user, _ := randomUser(t)
+	operator := util.RandomUsername()
+	reason := "user called the support"
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: models.RoleAdmin,
+			buildStubs: func(services *mockdb.MockServices) {
+				action := servicesPackage.AdminAction{Operator: operator, Reason: reason}
+				services.EXPECT().UnlockUser(action, user.Username).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			role: models.RoleAdmin,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().UnlockUser(gomock.Any(), user.Username).Times(1).Return(models.User{}, gorm.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			role: models.RoleCustomer,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().UnlockUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(gin.H{"reason": reason})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/users/%s/unlock", user.Username)
+			httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+			addRoleAuthorization(t, tokenMaker, operator, testCase.role, time.Minute, httpReq)
+
+			server.RouterServeHTTP(recorder, httpReq)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdjustBalance(t *testing.T) {
+	operator := util.RandomUsername()
+	account := createAccount(util.RandomUsername())
+	reason := "refund of a fee"
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"amount": -25, "reason": reason},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(account.ID).Times(1).Return(account, nil)
+				services.EXPECT().
+					CreateApproval(gomock.Cond(func(x any) bool {
+						req := x.(servicesPackage.CreateApprovalRequest)
+						return req.Operation == models.OperationAdjustBalance &&
+							req.RequestedBy == operator &&
+							req.Reason == reason &&
+							req.Payload == fmt.Sprintf(`{"account_id":%d,"amount":-25}`, account.ID)
+					})).
+					Times(1).
+					Return(models.Approval{ID: 1, Operation: models.OperationAdjustBalance, Status: models.ApprovalStatusPending}, nil)
+				// the adjustment is only posted once another admin approves it
+				services.EXPECT().AdjustBalance(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var response responses.ApprovalResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, models.ApprovalStatusPending, response.Status)
+			},
+		},
+		{
+			name: "ZeroAmount",
+			body: gin.H{"amount": 0, "reason": reason},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().CreateApproval(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BlankReason",
+			body: gin.H{"amount": 25, "reason": " "},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(account.ID).Times(1).Return(account, nil)
+				services.EXPECT().
+					CreateApproval(gomock.Any()).
+					Times(1).
+					Return(models.Approval{}, servicesPackage.ErrMissingReason)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			body: gin.H{"amount": 25, "reason": reason},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(account.ID).Times(1).Return(models.Account{}, gorm.ErrRecordNotFound)
+				services.EXPECT().CreateApproval(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/accounts/%d/adjustments", account.ID)
+			httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			addRoleAuthorization(t, server.handlers.tokenMaker, operator, models.RoleAdmin, time.Minute, httpReq)
+
+			server.RouterServeHTTP(recorder, httpReq)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminRevokeToken(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	operator := util.RandomUsername()
+	username := util.RandomUsername()
+	tokenID := uuid.New()
+
+	services := mockdb.NewMockServices(controller)
+	services.EXPECT().
+		AdminRevokeToken(
+			servicesPackage.AdminAction{Operator: operator, Reason: "stolen token"},
+			gomock.Cond(func(x any) bool {
+				req := x.(servicesPackage.RevokeTokenRequest)
+				return req.TokenID == tokenID && req.Username == username && req.ExpiresAt.After(time.Now())
+			}),
+		).
+		Times(1).
+		Return(nil)
+
+	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	server := NewTestServer(t, services, tokenMaker)
+	recorder := httptest.NewRecorder()
+
+	body, err := json.Marshal(gin.H{"token_id": tokenID, "username": username, "reason": "stolen token"})
+	require.NoError(t, err)
+
+	httpReq, err := http.NewRequest(http.MethodPost, "/admin/tokens/revoke", bytes.NewReader(body))
+	require.NoError(t, err)
+	addRoleAuthorization(t, server.handlers.tokenMaker, operator, models.RoleAdmin, time.Minute, httpReq)
+
+	server.RouterServeHTTP(recorder, httpReq)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+}
+
+func TestDebugVars(t *testing.T) {
+	testCases := []struct {
+		name         string
+		setupAuth    func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		expectedCode int
+	}{
+		{
+			name: "Admin",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, util.RandomUsername(), models.RoleAdmin, time.Minute, request)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Customer",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, util.RandomUsername(), models.RoleCustomer, time.Minute, request)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "NoAuthorization",
+			setupAuth:    func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			server := NewTestServer(t, mockdb.NewMockServices(ctrl), tokenMaker)
+
+			request, err := http.NewRequest(http.MethodGet, "/debug/vars", nil)
+			require.NoError(t, err)
+			testCase.setupAuth(t, request, tokenMaker)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			require.Equal(t, testCase.expectedCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/db/models"
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+func newAPIKeyResponse(key models.APIKey) responses.APIKeyResponse {
+	return responses.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		AllowedIPs: key.AllowedIPList(),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		LastUsedIP: key.LastUsedIP,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt.Local(),
+	}
+}
+
+// CreateAPIKey creates an api key for the user. The key is only returned in this response.
+func (handler *Handler) CreateAPIKey(context *gin.Context) {
+	var req requests.CreateAPIKeyRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		err := fmt.Errorf("expires_at must be in the future")
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	rawKey, key, err := auth.NewAPIKey(authPayload.Username, req.Name, req.Scopes, req.AllowedIPs, req.ExpiresAt)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	key, err = handler.services.CreateAPIKey(key)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	context.JSON(http.StatusCreated, responses.CreateAPIKeyResponse{
+		APIKeyResponse: newAPIKeyResponse(key),
+		Key:            rawKey,
+	})
+}
+
+// ListAPIKeys returns the api keys of the user that have not been revoked.
+func (handler *Handler) ListAPIKeys(context *gin.Context) {
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	keys, err := handler.services.ListAPIKeys(authPayload.Username)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := responses.ListAPIKeysResponse{APIKeys: make([]responses.APIKeyResponse, len(keys))}
+	for i, key := range keys {
+		res.APIKeys[i] = newAPIKeyResponse(key)
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// RevokeAPIKey revokes an api key of the user, which cannot be used anymore.
+func (handler *Handler) RevokeAPIKey(context *gin.Context) {
+	var req requests.RevokeAPIKeyRequest
+	if err := context.ShouldBindUri(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	key, err := handler.services.RevokeAPIKey(authPayload.Username, uuid.MustParse(req.ID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			context.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("api key not found")))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, newAPIKeyResponse(key))
+}
@@ -0,0 +1,328 @@
+package api
+
+import (
+	"Simple-Bank/auth"
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// addAPIKeyAuthorization adds a new api key of user with the given scopes to the request
+// and expects it to be looked up.
+func addAPIKeyAuthorization(
+	t *testing.T,
+	services *mockdb.MockServices,
+	user models.User,
+	scopes []auth.Scope,
+	request *http.Request,
+) models.APIKey {
+	return addAllowlistedAPIKeyAuthorization(t, services, user, scopes, nil, request)
+}
+
+// addAllowlistedAPIKeyAuthorization authorizes request with an api key only usable from allowedIPs.
+func addAllowlistedAPIKeyAuthorization(
+	t *testing.T,
+	services *mockdb.MockServices,
+	user models.User,
+	scopes []auth.Scope,
+	allowedIPs []string,
+	request *http.Request,
+) models.APIKey {
+	scopeNames := make([]string, len(scopes))
+	for i, scope := range scopes {
+		scopeNames[i] = string(scope)
+	}
+
+	rawKey, key, err := auth.NewAPIKey(user.Username, "integration", scopeNames, allowedIPs, nil)
+	require.NoError(t, err)
+
+	services.EXPECT().GetAPIKeyByPrefix(key.Prefix).AnyTimes().Return(key, nil)
+	services.EXPECT().GetUser(user.Username).AnyTimes().Return(user, nil)
+	services.EXPECT().TouchAPIKey(key.ID, gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeAPIKey, rawKey))
+
+	return key
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, services *mockdb.MockServices, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":        "accounting",
+				"scopes":      []string{"accounts:read", "transfers:read"},
+				"allowed_ips": []string{"10.0.0.0/8"},
+				"expires_at":  time.Now().Add(time.Hour),
+			},
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, request)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().
+					CreateAPIKey(gomock.Cond(func(x any) bool {
+						key := x.(models.APIKey)
+						return key.Owner == user.Username && key.Scopes == "accounts:read transfers:read" &&
+							key.AllowedIPs == "10.0.0.0/8" && key.ExpiresAt != nil
+					})).
+					Times(1).
+					DoAndReturn(func(key models.APIKey) (models.APIKey, error) {
+						return key, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var response responses.CreateAPIKeyResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Contains(t, response.Key, response.Prefix)
+				require.Equal(t, []string{"accounts:read", "transfers:read"}, response.Scopes)
+			},
+		},
+		{
+			name: "UnknownScope",
+			body: gin.H{"name": "accounting", "scopes": []string{"accounts:delete"}},
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, request)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().CreateAPIKey(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAllowedIP",
+			body: gin.H{"name": "accounting", "scopes": []string{"accounts:read"}, "allowed_ips": []string{"example.com"}},
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, request)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().CreateAPIKey(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiresInThePast",
+			body: gin.H{"name": "accounting", "scopes": []string{"accounts:read"}, "expires_at": time.Now().Add(-time.Hour)},
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, request)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().CreateAPIKey(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "APIKeysCannotCreateAPIKeys",
+			body: gin.H{"name": "accounting", "scopes": []string{"accounts:read"}},
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request, tokenMaker token.Maker) {
+				addAPIKeyAuthorization(t, services, user, []auth.Scope{auth.ScopeAccountsRead, auth.ScopeAccountsWrite}, request)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().CreateAPIKey(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api_keys", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			testCase.setupAuth(t, services, request, server.handlers.tokenMaker)
+
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	user, _ := randomUser(t)
+	user.Role = models.RoleCustomer
+	account := createAccount(user.Username)
+	admin, _ := randomUser(t)
+	admin.Role = models.RoleAdmin
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		setupAuth     func(t *testing.T, services *mockdb.MockServices, request *http.Request)
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request) {
+				addAPIKeyAuthorization(t, services, user, []auth.Scope{auth.ScopeAccountsRead}, request)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(account.ID).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "MissingScope",
+			method: http.MethodPost,
+			url:    "/accounts/transfer",
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request) {
+				addAPIKeyAuthorization(t, services, user, []auth.Scope{auth.ScopeAccountsRead}, request)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().Transfer(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "UnscopedRoute",
+			method: http.MethodGet,
+			url:    "/sessions",
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request) {
+				addAPIKeyAuthorization(t, services, user, []auth.Scope{auth.ScopeAccountsRead}, request)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().ListActiveSessions(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			// the key of an admin only acts on the resources of the admin
+			name:   "AccountOfAnotherUserWithKeyOfAdmin",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request) {
+				addAPIKeyAuthorization(t, services, admin, []auth.Scope{auth.ScopeAccountsRead}, request)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(account.ID).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "AllowedAddress",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request) {
+				addAllowlistedAPIKeyAuthorization(t, services, user, []auth.Scope{auth.ScopeAccountsRead}, []string{"203.0.113.7"}, request)
+				request.RemoteAddr = "203.0.113.7:40000"
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(account.ID).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			// only trusted proxies are believed about the address of the client
+			name:   "ForgedForwardedAddress",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request) {
+				addAllowlistedAPIKeyAuthorization(t, services, user, []auth.Scope{auth.ScopeAccountsRead}, []string{"203.0.113.7"}, request)
+				request.RemoteAddr = "198.51.100.20:40000"
+				request.Header.Set("X-Forwarded-For", "203.0.113.7")
+				request.Header.Set("X-Real-IP", "203.0.113.7")
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidKey",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			setupAuth: func(t *testing.T, services *mockdb.MockServices, request *http.Request) {
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeAPIKey, util.RandomString(32, util.ALPHANUMERIC)))
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(testCase.method, testCase.url, nil)
+			require.NoError(t, err)
+
+			testCase.setupAuth(t, services, request)
+
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"Simple-Bank/approvals"
+	"Simple-Bank/auth"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"Simple-Bank/stepup"
+	"Simple-Bank/token"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+func newApprovalResponse(approval models.Approval) responses.ApprovalResponse {
+	res := responses.ApprovalResponse{
+		ID:             approval.ID,
+		Operation:      approval.Operation,
+		Status:         approval.Status,
+		RequestedBy:    approval.RequestedBy,
+		Reason:         approval.Reason,
+		DecisionReason: approval.DecisionReason,
+		Error:          approval.Error,
+		CreatedAt:      approval.CreatedAt.Local(),
+		ExpiresAt:      approval.ExpiresAt.Local(),
+		DecidedAt:      approval.DecidedAt,
+		ExecutedAt:     approval.ExecutedAt,
+	}
+	if approval.Payload != "" {
+		res.Payload = json.RawMessage(approval.Payload)
+	}
+	if approval.DecidedBy != nil {
+		res.DecidedBy = *approval.DecidedBy
+	}
+	if approval.Result != nil {
+		res.Result = json.RawMessage(*approval.Result)
+	}
+
+	return res
+}
+
+// approvalErrorResponse writes the response of a failed request or decision of an operation.
+func approvalErrorResponse(context *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrPermissionDenied), errors.Is(err, services.ErrSelfApproval):
+		context.JSON(http.StatusForbidden, errorResponse(err))
+	case errors.Is(err, services.ErrNotAccountOwner):
+		context.JSON(http.StatusUnauthorized, errorResponse(err))
+	case errors.Is(err, services.ErrApprovalNotPending), errors.Is(err, services.ErrApprovalExpired):
+		context.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, services.ErrMissingReason), errors.Is(err, services.ErrInvalidAdjustment):
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		context.JSON(http.StatusNotFound, errorResponse(err))
+	default:
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
+
+// ListApprovals lists the operations of every user to users allowed to approve operations,
+// and the operations requested by the user to other users.
+func (handler *Handler) ListApprovals(context *gin.Context) {
+	var req requests.ListApprovalsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	listRequest := services.ListApprovalsRequest{
+		Status:     req.Status,
+		PageSize:   int(req.PageSize),
+		PageNumber: int(req.PageID),
+	}
+	if !auth.HasPermission(authPayload, auth.PermissionApproveOperations) && !auth.HasPermission(authPayload, auth.PermissionBackOffice) {
+		listRequest.RequestedBy = authPayload.Username
+	}
+
+	list, err := handler.services.ListApprovals(listRequest)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := responses.ListApprovalsResponse{Approvals: make([]responses.ApprovalResponse, len(list))}
+	for i, approval := range list {
+		res.Approvals[i] = newApprovalResponse(approval)
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// GetApproval returns an operation to its requester and to the users allowed to decide on it.
+func (handler *Handler) GetApproval(context *gin.Context) {
+	var req requests.GetApprovalRequest
+	if err := context.ShouldBindUri(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	approval, err := handler.services.GetApproval(req.ID)
+	if err != nil {
+		approvalErrorResponse(context, err)
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	if approval.RequestedBy != authPayload.Username && !approvals.CanDecide(authPayload, approval) {
+		err := fmt.Errorf("users cannot see the operations of other users")
+		context.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, newApprovalResponse(approval))
+}
+
+// ApproveOperation approves an operation requested by another user and executes it.
+func (handler *Handler) ApproveOperation(context *gin.Context) {
+	handler.decideOperation(context, true)
+}
+
+// RejectOperation rejects an operation requested by another user.
+func (handler *Handler) RejectOperation(context *gin.Context) {
+	handler.decideOperation(context, false)
+}
+
+func (handler *Handler) decideOperation(context *gin.Context, approve bool) {
+	var uri requests.GetApprovalRequest
+	if err := context.ShouldBindUri(&uri); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req requests.DecideApprovalRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	decide := handler.approvals.Reject
+	if approve {
+		decide = handler.approvals.Approve
+	}
+
+	approval, err := decide(authPayload, uri.ID, req.Reason)
+	if err != nil {
+		if errors.Is(err, approvals.ErrExecutionFailed) {
+			// the failed approval has been persisted; return it so the client can see why it failed
+			context.JSON(http.StatusUnprocessableEntity, newApprovalResponse(approval))
+			return
+		}
+		approvalErrorResponse(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, newApprovalResponse(approval))
+}
+
+// CloseAccount requests the closure of an account of the user, which is closed once another user approves it.
+func (handler *Handler) CloseAccount(context *gin.Context) {
+	var uri requests.GetAccountRequest
+	if err := context.ShouldBindUri(&uri); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req requests.CloseAccountRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if handler.stepUp.RequiresStepUp(stepup.OperationCloseAccount) && !handler.checkStepUp(context, stepup.OperationCloseAccount) {
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	approval, err := handler.approvals.RequestAccountClosure(authPayload.Username, req.Reason, uri.ID)
+	if err != nil {
+		approvalErrorResponse(context, err)
+		return
+	}
+
+	context.JSON(http.StatusAccepted, newApprovalResponse(approval))
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	servicesPackage "Simple-Bank/db/services"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestApproveOperation(t *testing.T) {
+	requester := util.RandomUsername()
+	approver := util.RandomUsername()
+	reason := "verified with the customer"
+
+	pendingTransfer := models.Approval{
+		ID:          util.RandomID(),
+		Operation:   models.OperationTransfer,
+		Payload:     fmt.Sprintf(`{"owner":%q,"from_account_id":1,"to_account_id":2,"amount":%d}`, requester, largeTransferAmount),
+		Status:      models.ApprovalStatusPending,
+		RequestedBy: requester,
+		Reason:      "large transfer",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	approvedTransfer := pendingTransfer
+	approvedTransfer.Status = models.ApprovalStatusApproved
+	approvedTransfer.DecidedBy = &approver
+
+	executedTransfer := approvedTransfer
+	executedTransfer.Status = models.ApprovalStatusExecuted
+	result := `{"transfer_id":7}`
+	executedTransfer.Result = &result
+
+	failedTransfer := approvedTransfer
+	failedTransfer.Status = models.ApprovalStatusFailed
+	failedTransfer.Error = "insufficient funds"
+
+	pendingAdjustment := pendingTransfer
+	pendingAdjustment.Operation = models.OperationAdjustBalance
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker)
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, approver, models.RoleTeller, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetApproval(pendingTransfer.ID).Times(1).Return(pendingTransfer, nil)
+				services.EXPECT().
+					DecideApproval(servicesPackage.DecideApprovalRequest{
+						ID:        pendingTransfer.ID,
+						Approve:   true,
+						DecidedBy: approver,
+						Reason:    reason,
+					}).
+					Times(1).
+					Return(approvedTransfer, nil)
+				services.EXPECT().
+					Transfer(servicesPackage.TransferRequest{
+						Owner:         requester,
+						FromAccountID: 1,
+						ToAccountID:   2,
+						Amount:        largeTransferAmount,
+					}).
+					Times(1).
+					Return(models.Transfer{ID: 7, Status: models.TransferStatusCompleted}, nil)
+				services.EXPECT().
+					CompleteApproval(pendingTransfer.ID, result, "").
+					Times(1).
+					Return(executedTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.ApprovalResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, models.ApprovalStatusExecuted, response.Status)
+				require.Equal(t, approver, response.DecidedBy)
+				require.JSONEq(t, result, string(response.Result))
+			},
+		},
+		{
+			name: "ExecutionFailed",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, approver, models.RoleTeller, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetApproval(pendingTransfer.ID).Times(1).Return(pendingTransfer, nil)
+				services.EXPECT().DecideApproval(gomock.Any()).Times(1).Return(approvedTransfer, nil)
+				services.EXPECT().
+					Transfer(gomock.Any()).
+					Times(1).
+					Return(models.Transfer{ID: 7, Status: models.TransferStatusFailed}, servicesPackage.ErrTransferFailed)
+				services.EXPECT().
+					CompleteApproval(pendingTransfer.ID, "", gomock.Not("")).
+					Times(1).
+					Return(failedTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var response responses.ApprovalResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, models.ApprovalStatusFailed, response.Status)
+			},
+		},
+		{
+			name: "SelfApproval",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, requester, models.RoleTeller, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetApproval(pendingTransfer.ID).Times(1).Return(pendingTransfer, nil)
+				services.EXPECT().
+					DecideApproval(gomock.Any()).
+					Times(1).
+					Return(models.Approval{}, servicesPackage.ErrSelfApproval)
+				services.EXPECT().Transfer(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Expired",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, approver, models.RoleTeller, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetApproval(pendingTransfer.ID).Times(1).Return(pendingTransfer, nil)
+				services.EXPECT().
+					DecideApproval(gomock.Any()).
+					Times(1).
+					Return(models.Approval{}, servicesPackage.ErrApprovalExpired)
+				services.EXPECT().Transfer(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "TellerCannotApproveAdjustment",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, approver, models.RoleTeller, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetApproval(pendingTransfer.ID).Times(1).Return(pendingAdjustment, nil)
+				services.EXPECT().DecideApproval(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, approver, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetApproval(pendingTransfer.ID).Times(1).Return(pendingTransfer, nil)
+				services.EXPECT().DecideApproval(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, approver, models.RoleTeller, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetApproval(pendingTransfer.ID).Times(1).Return(models.Approval{}, gorm.ErrRecordNotFound)
+				services.EXPECT().DecideApproval(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(gin.H{"reason": reason})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/approvals/%d/approve", pendingTransfer.ID)
+			httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			testCase.setupAuth(t, httpReq, server.handlers.tokenMaker)
+
+			server.RouterServeHTTP(recorder, httpReq)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCloseAccount(t *testing.T) {
+	user, _ := randomUser(t)
+	account := createAccount(user.Username)
+	reason := "moving to another bank"
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(account.ID).Times(1).Return(account, nil)
+				services.EXPECT().
+					CreateApproval(gomock.Cond(func(x any) bool {
+						req := x.(servicesPackage.CreateApprovalRequest)
+						return req.Operation == models.OperationCloseAccount && req.RequestedBy == user.Username && req.Reason == reason
+					})).
+					Times(1).
+					Return(models.Approval{ID: 1, Operation: models.OperationCloseAccount, Status: models.ApprovalStatusPending}, nil)
+				// the account is only closed once another user approves it
+				services.EXPECT().DeleteAccount(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: util.RandomUsername(),
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAccount(account.ID).Times(1).Return(account, nil)
+				services.EXPECT().CreateApproval(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(gin.H{"reason": reason})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d", account.ID)
+			httpReq, err := http.NewRequest(http.MethodDelete, url, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			addAuthorization(t, server.handlers.tokenMaker, authorizationTypeBearer, testCase.username, time.Minute, httpReq)
+
+			server.RouterServeHTTP(recorder, httpReq)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+func newTrustedDeviceResponse(device models.TrustedDevice) responses.TrustedDeviceResponse {
+	return responses.TrustedDeviceResponse{
+		ID:         device.ID,
+		UserAgent:  device.UserAgent,
+		IPRange:    device.IPRange,
+		LastIP:     device.LastIP,
+		CreatedAt:  device.CreatedAt.Local(),
+		LastSeenAt: device.LastSeenAt.Local(),
+	}
+}
+
+// ListTrustedDevices returns the devices the user logged in from, the last seen first.
+func (handler *Handler) ListTrustedDevices(context *gin.Context) {
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	devices, err := handler.services.ListTrustedDevices(authPayload.Username)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := responses.ListTrustedDevicesResponse{Devices: make([]responses.TrustedDeviceResponse, len(devices))}
+	for i, device := range devices {
+		res.Devices[i] = newTrustedDeviceResponse(device)
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// RemoveTrustedDevice forgets a device of the user, the next login from it alerts the user again.
+func (handler *Handler) RemoveTrustedDevice(context *gin.Context) {
+	var req requests.RemoveTrustedDeviceRequest
+	if err := context.ShouldBindUri(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	if err := handler.services.DeleteTrustedDevice(authPayload.Username, req.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			context.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("trusted device not found")))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	context.Status(http.StatusNoContent)
+}
@@ -0,0 +1,338 @@
+package api
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/devices"
+	"Simple-Bank/login"
+	"Simple-Bank/passwordless"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	deviceUserAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"
+	deviceAddress   = "192.168.1.17:52000"
+)
+
+// recordingNotifier records the new-device alerts of a test server.
+type recordingNotifier struct {
+	alerts []devices.Alert
+}
+
+func (notifier *recordingNotifier) Notify(alert devices.Alert) error {
+	notifier.alerts = append(notifier.alerts, alert)
+	return nil
+}
+
+// trustedDevice returns a trusted device of user matching the logins of TestLoginDevices.
+func trustedDevice(user models.User) models.TrustedDevice {
+	return models.TrustedDevice{
+		ID:          1,
+		Username:    user.Username,
+		Fingerprint: devices.Fingerprint(deviceUserAgent),
+		UserAgent:   deviceUserAgent,
+		IPRange:     devices.IPRange(deviceAddress),
+		LastIP:      "192.168.1.17",
+		CreatedAt:   time.Now().Add(-time.Hour),
+		LastSeenAt:  time.Now(),
+	}
+}
+
+func TestTrustedDevicesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	device := trustedDevice(user)
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "List",
+			method: http.MethodGet,
+			url:    "/users/devices",
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().ListTrustedDevices(user.Username).Times(1).Return([]models.TrustedDevice{device}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.ListTrustedDevicesResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Len(t, response.Devices, 1)
+				require.Equal(t, device.ID, response.Devices[0].ID)
+				require.Equal(t, deviceUserAgent, response.Devices[0].UserAgent)
+				require.Equal(t, "192.168.1.0/24", response.Devices[0].IPRange)
+				require.Equal(t, device.LastIP, response.Devices[0].LastIP)
+			},
+		},
+		{
+			name:   "ListInternalError",
+			method: http.MethodGet,
+			url:    "/users/devices",
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().ListTrustedDevices(user.Username).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "Remove",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/users/devices/%d", device.ID),
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().DeleteTrustedDevice(user.Username, device.ID).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "RemoveNotFound",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/users/devices/%d", device.ID),
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().DeleteTrustedDevice(user.Username, device.ID).Times(1).Return(gorm.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "RemoveBadRequest",
+			method: http.MethodDelete,
+			url:    "/users/devices/0",
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().DeleteTrustedDevice(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			server := NewTestServer(t, services, tokenMaker)
+
+			request, err := http.NewRequest(testCase.method, testCase.url, nil)
+			require.NoError(t, err)
+			addRoleAuthorization(t, tokenMaker, user.Username, models.RoleCustomer, time.Minute, request)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLoginDevices(t *testing.T) {
+	user, password := randomUser(t)
+	otherDevice := trustedDevice(user)
+	otherDevice.Fingerprint = devices.Fingerprint("curl/8.4.0")
+	deviceOnOtherNetwork := trustedDevice(user)
+	deviceOnOtherNetwork.IPRange = devices.IPRange("203.0.113.7")
+
+	testCases := []struct {
+		name                  string
+		devices               []models.TrustedDevice
+		forwardedFor          string
+		mfaEnabled            bool
+		newDeviceVerification bool
+		sessionStarted        bool
+		checkResponse         func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier, mailer *testMailer)
+	}{
+		{
+			name:           "TrustedDevice",
+			devices:        []models.TrustedDevice{trustedDevice(user)},
+			sessionStarted: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier, mailer *testMailer) {
+				requireSessionStarted(t, recorder)
+				require.Empty(t, notifier.alerts)
+			},
+		},
+		{
+			name:           "NewDevice",
+			devices:        []models.TrustedDevice{otherDevice},
+			sessionStarted: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier, mailer *testMailer) {
+				requireSessionStarted(t, recorder)
+				require.Len(t, notifier.alerts, 1)
+				require.Equal(t, user.Username, notifier.alerts[0].User.Username)
+				require.Equal(t, deviceUserAgent, notifier.alerts[0].UserAgent)
+				require.Equal(t, "192.168.1.17", notifier.alerts[0].ClientIP)
+				require.True(t, notifier.alerts[0].NewDevice)
+			},
+		},
+		{
+			name:       "TrustedDeviceRequiresMFA",
+			devices:    []models.TrustedDevice{trustedDevice(user)},
+			mfaEnabled: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier, mailer *testMailer) {
+				requireMFARequired(t, recorder)
+			},
+		},
+		{
+			name:                  "NewDeviceRequiresMFA",
+			devices:               []models.TrustedDevice{otherDevice},
+			mfaEnabled:            true,
+			newDeviceVerification: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier, mailer *testMailer) {
+				requireMFARequired(t, recorder)
+				require.Len(t, notifier.alerts, 1)
+				// the second factor already verifies the device
+				require.Empty(t, mailer.messages)
+			},
+		},
+		{
+			name:                  "NewDeviceRequiresLoginCode",
+			devices:               []models.TrustedDevice{otherDevice},
+			newDeviceVerification: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier, mailer *testMailer) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.LoginResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.True(t, response.DeviceVerificationRequired)
+				require.Empty(t, response.AccessToken)
+				require.Len(t, notifier.alerts, 1)
+				require.Len(t, mailer.messages, 1)
+				require.Equal(t, user.Email, mailer.messages[0].To)
+			},
+		},
+		{
+			// the device cannot claim the network it is trusted on, only trusted proxies are believed
+			name:                  "ForgedForwardedAddressOfTrustedDevice",
+			devices:               []models.TrustedDevice{deviceOnOtherNetwork},
+			forwardedFor:          "203.0.113.7",
+			newDeviceVerification: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier, mailer *testMailer) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.LoginResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.True(t, response.DeviceVerificationRequired)
+				require.Empty(t, response.AccessToken)
+				require.Len(t, notifier.alerts, 1)
+				require.Equal(t, "192.168.1.17", notifier.alerts[0].ClientIP)
+				require.False(t, notifier.alerts[0].NewDevice)
+			},
+		},
+		{
+			name:                  "TrustedDeviceWithoutLoginCode",
+			devices:               []models.TrustedDevice{trustedDevice(user)},
+			newDeviceVerification: true,
+			sessionStarted:        true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier, mailer *testMailer) {
+				requireSessionStarted(t, recorder)
+				require.Empty(t, mailer.messages)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			services.EXPECT().GetUser(user.Username).MinTimes(1).Return(user, nil)
+			services.EXPECT().ListTrustedDevices(user.Username).Times(1).Return(testCase.devices, nil)
+			if testCase.mfaEnabled {
+				services.EXPECT().GetTOTPCredential(user.Username).AnyTimes().Return(confirmedTOTPCredential(user), nil)
+				services.EXPECT().CreateMFAChallenge(gomock.Any()).AnyTimes().DoAndReturn(func(challenge models.MFAChallenge) (models.MFAChallenge, error) {
+					return challenge, nil
+				})
+			}
+			services.EXPECT().CreateLoginCode(gomock.Any()).AnyTimes().DoAndReturn(func(loginCode models.LoginCode) (models.LoginCode, error) {
+				return loginCode, nil
+			})
+			// the device is trusted when the session starts, not before the second factor
+			sessions := 0
+			if testCase.sessionStarted {
+				sessions = 1
+			}
+			services.EXPECT().CreateSession(gomock.Any()).Times(sessions).DoAndReturn(func(session models.Session) (models.Session, error) {
+				return session, nil
+			})
+			services.EXPECT().
+				TrustDevice(gomock.Any()).
+				Times(sessions).
+				DoAndReturn(func(device models.TrustedDevice) (models.TrustedDevice, error) {
+					require.Equal(t, user.Username, device.Username)
+					require.Equal(t, devices.Fingerprint(deviceUserAgent), device.Fingerprint)
+					require.Equal(t, "192.168.1.17", device.LastIP)
+					return device, nil
+				})
+
+			config := getTestConfig()
+			config.NewDeviceVerification = testCase.newDeviceVerification
+			tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+			require.NoError(t, err)
+			server := NewTestServerWithConfig(t, config, services, tokenMaker)
+			notifier := &recordingNotifier{}
+			mailer := &testMailer{}
+			server.handlers.devices = devices.NewRegistry(services, notifier)
+			server.handlers.passwordless = passwordless.NewManager(services, passwordless.NewEmailDelivery(mailer), 0, "", 0, 0)
+			server.handlers.login = login.NewFlow(services, tokenMaker, server.handlers.mfa, server.handlers.devices,
+				server.handlers.passwordless, config.TokenAccessTokenDuration, config.TokenRefreshTokenDuration, config.NewDeviceVerification)
+
+			body, err := json.Marshal(gin.H{"username": user.Username, "password": password})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(body))
+			require.NoError(t, err)
+			request.Header.Set("User-Agent", deviceUserAgent)
+			request.RemoteAddr = deviceAddress
+			if testCase.forwardedFor != "" {
+				request.Header.Set("X-Forwarded-For", testCase.forwardedFor)
+				request.Header.Set("X-Real-IP", testCase.forwardedFor)
+			}
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder, notifier, mailer)
+		})
+	}
+}
+
+func requireSessionStarted(t *testing.T, recorder *httptest.ResponseRecorder) {
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response responses.LoginResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.False(t, response.MFARequired)
+	require.NotEmpty(t, response.AccessToken)
+}
+
+func requireMFARequired(t *testing.T, recorder *httptest.ResponseRecorder) {
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response responses.LoginResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.True(t, response.MFARequired)
+	require.Empty(t, response.AccessToken)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"Simple-Bank/auth"
 	"Simple-Bank/util"
 	"github.com/go-playground/validator/v10"
 )
@@ -15,9 +16,9 @@ var ValidUsername validator.Func = func(fl validator.FieldLevel) bool {
 	return false
 }
 
-var ValidPassword validator.Func = func(fl validator.FieldLevel) bool {
-	if password, ok := fl.Field().Interface().(string); ok {
-		if err := util.ValidatePassword(password); err != nil {
+var ValidFullname validator.Func = func(fl validator.FieldLevel) bool {
+	if fullname, ok := fl.Field().Interface().(string); ok {
+		if err := util.ValidateFullname(fullname); err != nil {
 			return false
 		}
 		return true
@@ -25,12 +26,9 @@ var ValidPassword validator.Func = func(fl validator.FieldLevel) bool {
 	return false
 }
 
-var ValidFullname validator.Func = func(fl validator.FieldLevel) bool {
-	if fullname, ok := fl.Field().Interface().(string); ok {
-		if err := util.ValidateFullname(fullname); err != nil {
-			return false
-		}
-		return true
+var ValidScope validator.Func = func(fl validator.FieldLevel) bool {
+	if scope, ok := fl.Field().Interface().(string); ok {
+		return auth.IsValidScope(scope)
 	}
 	return false
 }
@@ -2,11 +2,16 @@ package api
 
 import (
 	"Simple-Bank/config"
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
 	"Simple-Bank/db/services"
+	"Simple-Bank/mail"
 	"Simple-Bank/token"
 	"Simple-Bank/util"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
 	"os"
 	"testing"
 	"time"
@@ -14,19 +19,53 @@ import (
 
 var configs *config.Config
 
+// largeTransferAmount is the amount from which transfers require approval in tests
+const largeTransferAmount = 100_000
+
 func NewTestServer(t *testing.T, services services.Services, tokenMaker token.Maker) *Server {
-	server, err := NewServer(getTestConfig(), services, tokenMaker)
+	return NewTestServerWithConfig(t, getTestConfig(), services, tokenMaker)
+}
+
+// NewTestServerWithConfig creates a test server with a config other than the default test config.
+func NewTestServerWithConfig(t *testing.T, config *config.Config, services services.Services, tokenMaker token.Maker) *Server {
+	if mockServices, ok := services.(*mockdb.MockServices); ok {
+		// only the tests of revoked tokens expect a token to be denied
+		mockServices.EXPECT().IsTokenRevoked(gomock.Any()).AnyTimes().Return(false, nil)
+		// only the tests of two-factor authentication enroll users
+		mockServices.EXPECT().GetTOTPCredential(gomock.Any()).AnyTimes().Return(models.TOTPCredential{}, gorm.ErrRecordNotFound)
+		// only the tests of lockouts have failed logins before the request
+		mockServices.EXPECT().GetLoginFailure(gomock.Any(), gomock.Any()).AnyTimes().Return(models.LoginFailure{}, gorm.ErrRecordNotFound)
+		mockServices.EXPECT().RecordLoginFailure(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(models.LoginFailure{Failures: 1}, nil)
+		mockServices.EXPECT().ClearLoginFailures(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+		// only the tests of trusted devices have devices before the request
+		mockServices.EXPECT().ListTrustedDevices(gomock.Any()).AnyTimes().Return([]models.TrustedDevice{}, nil)
+		mockServices.EXPECT().TrustDevice(gomock.Any()).AnyTimes().Return(models.TrustedDevice{}, nil)
+	}
+
+	server, err := NewServer(config, services, tokenMaker, &testMailer{})
 	require.NoError(t, err)
 	require.NotEmpty(t, server)
 
 	return server
 }
 
+// testMailer records the emails sent by a test server.
+type testMailer struct {
+	messages []mail.Message
+}
+
+func (mailer *testMailer) Send(message mail.Message) error {
+	mailer.messages = append(mailer.messages, message)
+	return nil
+}
+
 func getTestConfig() *config.Config {
 	return &config.Config{
 		TokenAccessTokenDuration:  15 * time.Minute,
 		TokenRefreshTokenDuration: 24 * time.Hour,
 		TokenSymmetricKey:         util.RandomString(32, util.ALL),
+		MFAEncryptionKey:          util.RandomString(32, util.ALL),
+		ApprovalTransferThreshold: largeTransferAmount,
 	}
 }
 
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"Simple-Bank/db/services"
+	"Simple-Bank/mfa"
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// VerifyLoginMFA completes the login of a user with two-factor authentication, exchanging the challenge returned
+// by Login and a code of the authenticator app of the user, or a recovery code, for tokens and a session.
+func (handler *Handler) VerifyLoginMFA(context *gin.Context) {
+	var req requests.VerifyLoginMFARequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	username, err := handler.mfa.CompleteChallenge(req.ChallengeToken, req.Code)
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidChallenge) || errors.Is(err, mfa.ErrInvalidCode) || errors.Is(err, mfa.ErrTooManyAttempts) {
+			context.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := handler.services.GetUser(username)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	// the user may have been frozen since the password was checked
+	if user.FrozenAt != nil {
+		context.JSON(http.StatusForbidden, errorResponse(errUserFrozen))
+		return
+	}
+
+	tokens, err := handler.login.StartSession(user, loginClient(context))
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	context.JSON(http.StatusOK, newLoginResponse(user, tokens))
+}
+
+// EnrollTOTP generates a new secret for the authenticator app of the user. Two-factor authentication is only
+// enabled once the user confirms the enrollment with a code.
+func (handler *Handler) EnrollTOTP(context *gin.Context) {
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	enrollment, err := handler.mfa.Enroll(authPayload.Username)
+	if err != nil {
+		if errors.Is(err, services.ErrTOTPAlreadyEnabled) {
+			context.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, responses.EnrollTOTPResponse{Secret: enrollment.Secret, OTPAuthURI: enrollment.URI})
+}
+
+// ConfirmTOTP enables the two-factor authentication of the user with a first code of their authenticator app,
+// and returns their recovery codes.
+func (handler *Handler) ConfirmTOTP(context *gin.Context) {
+	var req requests.TOTPCodeRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	recoveryCodes, err := handler.mfa.Confirm(authPayload.Username, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, mfa.ErrNotEnabled):
+			context.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, services.ErrTOTPAlreadyEnabled):
+			context.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, mfa.ErrInvalidCode):
+			context.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			context.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	context.JSON(http.StatusOK, responses.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes})
+}
+
+// DisableTOTP disables the two-factor authentication of the user, who must prove it with a code of their
+// authenticator app or a recovery code.
+func (handler *Handler) DisableTOTP(context *gin.Context) {
+	var req requests.TOTPCodeRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	if err := handler.mfa.Disable(authPayload.Username, req.Code); err != nil {
+		switch {
+		case errors.Is(err, mfa.ErrNotEnabled):
+			context.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, mfa.ErrInvalidCode):
+			context.JSON(http.StatusUnauthorized, errorResponse(err))
+		default:
+			context.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	context.Status(http.StatusNoContent)
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// confirmedTOTPCredential returns a confirmed credential of user, enough for logins to require a second factor.
+func confirmedTOTPCredential(user models.User) models.TOTPCredential {
+	confirmedAt := time.Now().UTC()
+	return models.TOTPCredential{Username: user.Username, EncryptedSecret: "secret", ConfirmedAt: &confirmedAt}
+}
+
+func TestLoginWithMFA(t *testing.T) {
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	services := mockdb.NewMockServices(ctrl)
+	services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+	services.EXPECT().GetTOTPCredential(user.Username).Times(1).Return(confirmedTOTPCredential(user), nil)
+	services.EXPECT().
+		CreateMFAChallenge(gomock.Cond(func(x any) bool {
+			challenge := x.(models.MFAChallenge)
+			return challenge.Username == user.Username && challenge.HashedToken != ""
+		})).
+		Times(1).
+		DoAndReturn(func(challenge models.MFAChallenge) (models.MFAChallenge, error) {
+			return challenge, nil
+		})
+	// no session is started before the second factor is checked
+	services.EXPECT().CreateSession(gomock.Any()).Times(0)
+
+	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	require.NoError(t, err)
+	server := NewTestServer(t, services, tokenMaker)
+
+	body, err := json.Marshal(gin.H{"username": user.Username, "password": password})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	server.RouterServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response responses.LoginResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.True(t, response.MFARequired)
+	require.NotEmpty(t, response.MFAChallengeToken)
+	require.NotNil(t, response.MFAChallengeExpiresAt)
+	require.Empty(t, response.AccessToken)
+	require.Empty(t, response.RefreshToken)
+}
+
+func TestVerifyLoginMFA(t *testing.T) {
+	user, _ := randomUser(t)
+	challenge := models.MFAChallenge{ID: 1, Username: user.Username, ExpiresAt: time.Now().Add(time.Minute)}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OKWithRecoveryCode",
+			body: gin.H{"mfa_challenge_token": "challenge", "code": "abcde-12345"},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetMFAChallenge(gomock.Any()).Times(1).Return(challenge, nil)
+				services.EXPECT().GetTOTPCredential(user.Username).Times(1).Return(confirmedTOTPCredential(user), nil)
+				services.EXPECT().UseRecoveryCode(user.Username, gomock.Any()).Times(1).Return(nil)
+				services.EXPECT().ConsumeMFAChallenge(challenge.ID).Times(1).Return(nil)
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				services.EXPECT().
+					CreateSession(gomock.Any()).
+					Times(1).
+					DoAndReturn(func(session models.Session) (models.Session, error) {
+						return session, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.LoginResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.False(t, response.MFARequired)
+				require.NotEmpty(t, response.AccessToken)
+				require.NotEmpty(t, response.RefreshToken)
+			},
+		},
+		{
+			name: "UnknownChallenge",
+			body: gin.H{"mfa_challenge_token": "challenge", "code": "123456"},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetMFAChallenge(gomock.Any()).Times(1).Return(models.MFAChallenge{}, gorm.ErrRecordNotFound)
+				services.EXPECT().CreateSession(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ConsumedChallenge",
+			body: gin.H{"mfa_challenge_token": "challenge", "code": "123456"},
+			buildStubs: func(services *mockdb.MockServices) {
+				consumed := challenge
+				consumedAt := time.Now()
+				consumed.ConsumedAt = &consumedAt
+				services.EXPECT().GetMFAChallenge(gomock.Any()).Times(1).Return(consumed, nil)
+				services.EXPECT().CreateSession(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "WrongRecoveryCode",
+			body: gin.H{"mfa_challenge_token": "challenge", "code": "abcde-12345"},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetMFAChallenge(gomock.Any()).Times(1).Return(challenge, nil)
+				services.EXPECT().GetTOTPCredential(user.Username).Times(1).Return(confirmedTOTPCredential(user), nil)
+				services.EXPECT().UseRecoveryCode(user.Username, gomock.Any()).Times(1).Return(gorm.ErrRecordNotFound)
+				failed := challenge
+				failed.Attempts = 1
+				services.EXPECT().FailMFAChallenge(challenge.ID).Times(1).Return(failed, nil)
+				services.EXPECT().CreateSession(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest",
+			body: gin.H{"code": "123456"},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetMFAChallenge(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			server := NewTestServer(t, services, tokenMaker)
+
+			body, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/users/login/mfa", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().
+					SaveTOTPCredential(gomock.Cond(func(x any) bool {
+						credential := x.(models.TOTPCredential)
+						return credential.Username == user.Username && credential.EncryptedSecret != ""
+					})).
+					Times(1).
+					DoAndReturn(func(credential models.TOTPCredential) (models.TOTPCredential, error) {
+						return credential, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.EnrollTOTPResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.NotEmpty(t, response.Secret)
+				require.Contains(t, response.OTPAuthURI, "secret="+response.Secret)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().
+					SaveTOTPCredential(gomock.Any()).
+					Times(1).
+					Return(models.TOTPCredential{}, services.ErrTOTPAlreadyEnabled)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockServices := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(mockServices)
+
+			tokenMaker, err := token.NewPasetoMaker(util.RandomString(32, util.ALL))
+			require.NoError(t, err)
+			server := NewTestServer(t, mockServices, tokenMaker)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/mfa/totp", nil)
+			require.NoError(t, err)
+			addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, request)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
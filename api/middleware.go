@@ -1,10 +1,17 @@
 package api
 
 import (
+	"Simple-Bank/auth"
+	"Simple-Bank/config"
+	"Simple-Bank/ratelimit"
 	"Simple-Bank/token"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -12,9 +19,24 @@ const (
 	authorizationHeaderKey  string = "authorization"
 	authorizationPayloadKey string = "authorization_payload"
 	authorizationTypeBearer string = "bearer"
+	authorizationTypeAPIKey string = "apikey"
 )
 
-func authMiddleWare(tokenMaker token.Maker) gin.HandlerFunc {
+// routeScopes declares the scope a scoped credential, e.g. an api key, needs to use a route, keyed by
+// the method and the path of the route. Routes missing from the map cannot be used with scoped credentials.
+var routeScopes = map[string]auth.Scope{
+	"POST /accounts":            auth.ScopeAccountsWrite,
+	"GET /accounts/:id":         auth.ScopeAccountsRead,
+	"GET /accounts/:id/balance": auth.ScopeAccountsRead,
+	"GET /accounts":             auth.ScopeAccountsRead,
+	"POST /accounts/transfer":   auth.ScopeTransfersWrite,
+	"GET /transfers/:id":        auth.ScopeTransfersRead,
+	"GET /users/:username":      auth.ScopeUsersRead,
+}
+
+// authMiddleWare authenticates requests with either a bearer access token or an api key,
+// and only lets scoped credentials through on the routes of their scopes.
+func authMiddleWare(tokenMaker token.Maker, revocations *auth.RevocationChecker, apiKeys *auth.APIKeyAuthenticator) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		authorizationHeader := context.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
@@ -30,17 +52,42 @@ func authMiddleWare(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
-		authorizationType := fields[0]
-		if strings.ToLower(authorizationType) != authorizationTypeBearer {
+		var payload *token.Payload
+		var err error
+
+		switch strings.ToLower(fields[0]) {
+		case authorizationTypeBearer:
+			payload, err = tokenMaker.VerifyToken(fields[1])
+			if err != nil {
+				context.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+			// refresh tokens are only exchanged for new tokens, they do not authenticate requests
+			if err := payload.CheckType(token.TokenTypeAccess); err != nil {
+				context.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+
+			err = revocations.Check(payload)
+		case authorizationTypeAPIKey:
+			payload, err = apiKeys.Authenticate(fields[1], context.ClientIP())
+		default:
 			err := fmt.Errorf("invalid authorization header format")
 			context.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
-
-		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
 		if err != nil {
-			context.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			if errors.Is(err, auth.ErrInactiveSession) || errors.Is(err, auth.ErrRevokedToken) ||
+				errors.Is(err, auth.ErrInvalidAPIKey) || errors.Is(err, auth.ErrAddressNotAllowed) {
+				context.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+			context.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if err := auth.AuthorizeScope(payload, routeScopes[context.Request.Method+" "+context.FullPath()]); err != nil {
+			context.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
 			return
 		}
 
@@ -48,3 +95,65 @@ func authMiddleWare(tokenMaker token.Maker) gin.HandlerFunc {
 		context.Next()
 	}
 }
+
+// requirePermission only lets requests through if the role of the user has the given permission.
+// It must run after authMiddleWare.
+func requirePermission(permission auth.Permission) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+		if err := auth.Authorize(authPayload, permission); err != nil {
+			context.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		context.Next()
+	}
+}
+
+// rateLimitMiddleware limits the requests of each client address to each route. It runs before authMiddleWare, so
+// the requests with invalid credentials are limited too.
+func rateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return rateLimitSubjectMiddleware(limiter, func(context *gin.Context) string {
+		return ratelimit.IPSubject(context.ClientIP())
+	})
+}
+
+// userRateLimitMiddleware limits the requests of each authenticated user to each route, whatever their address.
+// It must run after authMiddleWare.
+func userRateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return rateLimitSubjectMiddleware(limiter, func(context *gin.Context) string {
+		return ratelimit.UserSubject(context.MustGet(authorizationPayloadKey).(*token.Payload).Username)
+	})
+}
+
+// rateLimitSubjectMiddleware limits the requests to each route per subject of the requests. Requests are let
+// through if the limiter cannot reach its store, so an outage of the store does not take the api down.
+func rateLimitSubjectMiddleware(limiter *ratelimit.Limiter, subject func(context *gin.Context) string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		route := context.Request.Method + " " + context.FullPath()
+		if err := limiter.Allow(route, subject(context)); err != nil {
+			var limited *ratelimit.LimitedError
+			if errors.As(err, &limited) {
+				context.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+				context.AbortWithStatusJSON(http.StatusTooManyRequests, errorResponse(err))
+				return
+			}
+			log.Error().Err(err).Str("route", route).Msg("cannot rate limit the request")
+		}
+
+		context.Next()
+	}
+}
+
+// rateLimitRules returns the limits of the routes configured in rules.
+func rateLimitRules(rules []config.RateLimitRule) []ratelimit.Rule {
+	limits := make([]ratelimit.Rule, 0, len(rules))
+	for _, rule := range rules {
+		limits = append(limits, ratelimit.Rule{
+			Route: rule.Route,
+			Limit: ratelimit.Limit{Requests: rule.Requests, Period: rule.Period, Burst: rule.Burst},
+		})
+	}
+
+	return limits
+}
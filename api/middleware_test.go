@@ -1,15 +1,21 @@
 package api
 
 import (
+	"Simple-Bank/config"
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
 	"Simple-Bank/token"
 	mocktokenmaker "Simple-Bank/token/mock"
 	"Simple-Bank/util"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -33,6 +39,51 @@ func addAuthorization(
 	return accessToken, payload
 }
 
+// addSessionAuthorization adds an access token bound to the session with the given id to the request.
+func addSessionAuthorization(
+	t *testing.T,
+	tokenMaker token.Maker,
+	sessionID uuid.UUID,
+	duration time.Duration,
+	request *http.Request,
+) (string, *token.Payload) {
+	accessToken, payload, err := tokenMaker.CreateSessionToken(util.RandomUsername(), models.RoleCustomer, sessionID, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, accessToken)
+
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+
+	return accessToken, payload
+}
+
+// addRoleAuthorization adds an access token of a user with the given role to the request.
+func addRoleAuthorization(
+	t *testing.T,
+	tokenMaker token.Maker,
+	username string,
+	role string,
+	duration time.Duration,
+	request *http.Request,
+) (string, *token.Payload) {
+	accessToken, payload, err := tokenMaker.CreateSessionToken(username, role, uuid.Nil, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, accessToken)
+
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+
+	return accessToken, payload
+}
+
+// activeSession returns the active session an access token was issued for.
+func activeSession(payload *token.Payload) models.Session {
+	return models.Session{
+		ID:        payload.SessionID,
+		Username:  payload.Username,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	configs := getTestConfig()
 
@@ -42,7 +93,7 @@ func TestAuthMiddleware(t *testing.T) {
 	testCases := []struct {
 		name          string
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
-		buildStubs    func(tokenMaker *mocktokenmaker.MockMaker)
+		buildStubs    func(tokenMaker *mocktokenmaker.MockMaker, services *mockdb.MockServices)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
@@ -50,7 +101,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				accessToken, accessTokenPayload = addAuthorization(t, tokenMaker, authorizationTypeBearer, util.RandomUsername(), time.Minute, request)
 			},
-			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker) {
+			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker, services *mockdb.MockServices) {
 				tokenMaker.EXPECT().VerifyToken(accessToken).Times(1).Return(accessTokenPayload, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -61,7 +112,7 @@ func TestAuthMiddleware(t *testing.T) {
 			name: "NoAuthorization",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
-			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker) {
+			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker, services *mockdb.MockServices) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -72,7 +123,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, tokenMaker, "unsupported", util.RandomUsername(), time.Minute, request)
 			},
-			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker) {
+			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker, services *mockdb.MockServices) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -83,7 +134,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, tokenMaker, "", util.RandomUsername(), time.Minute, request)
 			},
-			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker) {
+			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker, services *mockdb.MockServices) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -94,13 +145,90 @@ func TestAuthMiddleware(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				accessToken, accessTokenPayload = addAuthorization(t, tokenMaker, authorizationTypeBearer, util.RandomUsername(), -time.Minute, request)
 			},
-			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker) {
+			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker, services *mockdb.MockServices) {
 				tokenMaker.EXPECT().VerifyToken(accessToken).Times(1).Return(nil, token.ErrExpiredToken)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				var err error
+				accessToken, accessTokenPayload, err = tokenMaker.CreateRefreshToken(util.RandomUsername(), models.RoleCustomer, uuid.New(), time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			},
+			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker, services *mockdb.MockServices) {
+				tokenMaker.EXPECT().VerifyToken(accessToken).Times(1).Return(accessTokenPayload, nil)
+				services.EXPECT().GetSession(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ActiveSession",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, accessTokenPayload = addSessionAuthorization(t, tokenMaker, uuid.New(), time.Minute, request)
+			},
+			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker, services *mockdb.MockServices) {
+				tokenMaker.EXPECT().VerifyToken(accessToken).Times(1).Return(accessTokenPayload, nil)
+				services.EXPECT().
+					GetSession(gomock.Eq(accessTokenPayload.SessionID)).
+					Times(1).
+					Return(activeSession(accessTokenPayload), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BlockedSession",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, accessTokenPayload = addSessionAuthorization(t, tokenMaker, uuid.New(), time.Minute, request)
+			},
+			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker, services *mockdb.MockServices) {
+				session := activeSession(accessTokenPayload)
+				session.IsBlocked = true
+
+				tokenMaker.EXPECT().VerifyToken(accessToken).Times(1).Return(accessTokenPayload, nil)
+				services.EXPECT().GetSession(gomock.Eq(accessTokenPayload.SessionID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredSession",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, accessTokenPayload = addSessionAuthorization(t, tokenMaker, uuid.New(), time.Minute, request)
+			},
+			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker, services *mockdb.MockServices) {
+				session := activeSession(accessTokenPayload)
+				session.ExpiresAt = time.Now().Add(-time.Minute)
+
+				tokenMaker.EXPECT().VerifyToken(accessToken).Times(1).Return(accessTokenPayload, nil)
+				services.EXPECT().GetSession(gomock.Eq(accessTokenPayload.SessionID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MissingSession",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, accessTokenPayload = addSessionAuthorization(t, tokenMaker, uuid.New(), time.Minute, request)
+			},
+			buildStubs: func(tokenMaker *mocktokenmaker.MockMaker, services *mockdb.MockServices) {
+				tokenMaker.EXPECT().VerifyToken(accessToken).Times(1).Return(accessTokenPayload, nil)
+				services.EXPECT().GetSession(gomock.Eq(accessTokenPayload.SessionID)).Times(1).Return(models.Session{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -109,9 +237,10 @@ func TestAuthMiddleware(t *testing.T) {
 			defer ctrl.Finish()
 
 			mockTokenMaker := mocktokenmaker.NewMockMaker(ctrl)
+			services := mockdb.NewMockServices(ctrl)
 
-			server := NewTestServer(t, nil, mockTokenMaker)
-			authRoutes := server.router.Group("/").Use(authMiddleWare(server.handlers.tokenMaker))
+			server := NewTestServer(t, services, mockTokenMaker)
+			authRoutes := server.router.Group("/").Use(authMiddleWare(server.handlers.tokenMaker, server.handlers.revocations, server.handlers.apiKeys))
 			authRoutes.GET("/auth",
 				func(context *gin.Context) {
 					context.JSON(http.StatusOK, gin.H{})
@@ -129,7 +258,7 @@ func TestAuthMiddleware(t *testing.T) {
 			// in this test buildStubs must be called after the
 			// setupAuth method so the accessToken and accessTokenPayload
 			// variables are initialized
-			testCase.buildStubs(mockTokenMaker)
+			testCase.buildStubs(mockTokenMaker, services)
 
 			server.router.ServeHTTP(recorder, request)
 			testCase.checkResponse(t, recorder)
@@ -137,3 +266,108 @@ func TestAuthMiddleware(t *testing.T) {
 	}
 
 }
+
+func TestAuthMiddlewareRevokedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	services := mockdb.NewMockServices(ctrl)
+	server, err := NewServer(configs, services, tokenMaker, &testMailer{})
+	require.NoError(t, err)
+
+	authRoutes := server.router.Group("/").Use(authMiddleWare(server.handlers.tokenMaker, server.handlers.revocations, server.handlers.apiKeys))
+	authRoutes.GET("/auth", func(context *gin.Context) {
+		context.JSON(http.StatusOK, gin.H{})
+	})
+
+	request, err := http.NewRequest(http.MethodGet, "/auth", nil)
+	require.NoError(t, err)
+	_, payload := addSessionAuthorization(t, tokenMaker, uuid.New(), time.Minute, request)
+
+	services.EXPECT().IsTokenRevoked(gomock.Eq(payload.ID)).Times(1).Return(false, nil)
+	services.EXPECT().GetSession(gomock.Eq(payload.SessionID)).Times(1).Return(activeSession(payload), nil)
+
+	// the second request is answered from the cache
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	// revoking resets the cache, so the token is refused right away
+	server.handlers.revocations.Reset()
+	services.EXPECT().IsTokenRevoked(gomock.Eq(payload.ID)).Times(1).Return(true, nil)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	services := mockdb.NewMockServices(ctrl)
+	services.EXPECT().GetUser(gomock.Any()).AnyTimes().Return(models.User{}, gorm.ErrRecordNotFound)
+	services.EXPECT().GetSession(gomock.Any()).AnyTimes().Return(models.Session{}, gorm.ErrRecordNotFound)
+
+	testConfig := getTestConfig()
+	testConfig.RateLimits = []config.RateLimitRule{
+		{Route: "POST /users/login", Requests: 1, Period: time.Minute},
+		{Route: "GET /users/devices", Requests: 1, Period: time.Minute},
+	}
+	tokenMaker, err := token.NewPasetoMaker(testConfig.TokenSymmetricKey)
+	require.NoError(t, err)
+	server := NewTestServerWithConfig(t, testConfig, services, tokenMaker)
+
+	serve := func(request *http.Request, address string) *httptest.ResponseRecorder {
+		request.RemoteAddr = address
+		recorder := httptest.NewRecorder()
+		server.RouterServeHTTP(recorder, request)
+		return recorder
+	}
+	login := func(address string) *httptest.ResponseRecorder {
+		body := gin.H{"username": util.RandomUsername(), "password": util.RandomString(12, util.ALL)}
+		return serve(newPasswordlessRequest(t, "/users/login", body), address)
+	}
+	listDevices := func(username, address string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(http.MethodGet, "/users/devices", nil)
+		require.NoError(t, err)
+		addRoleAuthorization(t, tokenMaker, username, models.RoleCustomer, time.Minute, request)
+		return serve(request, address)
+	}
+
+	// public routes are limited per client address
+	require.Equal(t, http.StatusUnauthorized, login("10.0.0.1:40000").Code)
+	recorder := login("10.0.0.1:40001")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	retryAfter, err := strconv.Atoi(recorder.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	require.Positive(t, retryAfter)
+	require.LessOrEqual(t, retryAfter, 60)
+	require.Equal(t, http.StatusUnauthorized, login("10.0.0.2:40000").Code)
+
+	// routes of authenticated users are limited per user, whatever their address, and per address, whatever
+	// their user
+	username := util.RandomUsername()
+	require.Equal(t, http.StatusOK, listDevices(username, "10.0.0.3:40000").Code)
+	require.Equal(t, http.StatusTooManyRequests, listDevices(username, "10.0.0.4:40000").Code)
+	require.Equal(t, http.StatusTooManyRequests, listDevices(util.RandomUsername(), "10.0.0.3:40000").Code)
+	require.Equal(t, http.StatusOK, listDevices(util.RandomUsername(), "10.0.0.5:40000").Code)
+
+	// requests with invalid credentials are limited before they are authenticated
+	invalidRequest := func() *http.Request {
+		request, err := http.NewRequest(http.MethodGet, "/users/devices", nil)
+		require.NoError(t, err)
+		request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, "invalid"))
+		return request
+	}
+	require.Equal(t, http.StatusUnauthorized, serve(invalidRequest(), "10.0.0.6:40000").Code)
+	require.Equal(t, http.StatusTooManyRequests, serve(invalidRequest(), "10.0.0.6:40000").Code)
+
+	// other routes have the default limit
+	require.Equal(t, http.StatusOK, serve(httptest.NewRequest(http.MethodGet, "/password_policy", nil), "10.0.0.1:40000").Code)
+}
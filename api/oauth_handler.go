@@ -0,0 +1,192 @@
+package api
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/oauth"
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"net/http"
+	"strings"
+)
+
+func newOAuthClientResponse(client models.OAuthClient) responses.OAuthClientResponse {
+	return responses.OAuthClientResponse{
+		ClientID:     client.ID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIList(),
+		Scopes:       client.ScopeList(),
+		Confidential: client.IsConfidential(),
+		CreatedAt:    client.CreatedAt.Local(),
+	}
+}
+
+// oauthErrorResponse writes the response of a failed request to an endpoint of the OAuth2 protocol,
+// in the format of RFC 6749 rather than errorResponse.
+func oauthErrorResponse(context *gin.Context, err error) {
+	var oauthErr *oauth.Error
+	if !errors.As(err, &oauthErr) {
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	if oauthErr.Code == oauth.ErrorInvalidClient {
+		context.JSON(http.StatusUnauthorized, oauthErr)
+		return
+	}
+	context.JSON(http.StatusBadRequest, oauthErr)
+}
+
+// clientCredentials returns the credentials of the client of a request, taken from basic authentication
+// if present and from the form otherwise.
+func clientCredentials(context *gin.Context, formID, formSecret string) (string, string) {
+	if id, secret, ok := context.Request.BasicAuth(); ok {
+		return id, secret
+	}
+
+	return formID, formSecret
+}
+
+// RegisterOAuthClient registers a third-party app of the user. The secret of a confidential client is only
+// returned in this response.
+func (handler *Handler) RegisterOAuthClient(context *gin.Context) {
+	var req requests.RegisterOAuthClientRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	client, secret, err := handler.oauth.RegisterClient(authPayload.Username, oauth.RegisterClientRequest{
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       req.Scopes,
+		Confidential: req.Confidential,
+	})
+	if err != nil {
+		var oauthErr *oauth.Error
+		if errors.As(err, &oauthErr) {
+			context.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	context.JSON(http.StatusCreated, responses.RegisterOAuthClientResponse{
+		OAuthClientResponse: newOAuthClientResponse(client),
+		ClientSecret:        secret,
+	})
+}
+
+// ListOAuthClients returns the third-party apps registered by the user.
+func (handler *Handler) ListOAuthClients(context *gin.Context) {
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	clients, err := handler.services.ListOAuthClients(authPayload.Username)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := responses.ListOAuthClientsResponse{Clients: make([]responses.OAuthClientResponse, len(clients))}
+	for i, client := range clients {
+		res.Clients[i] = newOAuthClientResponse(client)
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// AuthorizeOAuthClient lets the user grant scopes to a third-party app. It returns the redirect uri of the app
+// carrying an authorization code, which the app exchanges for an access token at the token endpoint.
+func (handler *Handler) AuthorizeOAuthClient(context *gin.Context) {
+	var req requests.AuthorizeOAuthClientRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	redirectURI, err := handler.oauth.Authorize(authPayload, oauth.AuthorizeRequest{
+		ResponseType:        req.ResponseType,
+		ClientID:            req.ClientID,
+		RedirectURI:         req.RedirectURI,
+		Scopes:              strings.Fields(req.Scope),
+		State:               req.State,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+	})
+	if err != nil {
+		oauthErrorResponse(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, responses.AuthorizeOAuthClientResponse{RedirectURI: redirectURI})
+}
+
+// OAuthToken is the token endpoint of the OAuth2 server, issuing access tokens for the authorization code
+// and the client credentials grants.
+func (handler *Handler) OAuthToken(context *gin.Context) {
+	var req requests.OAuthTokenRequest
+	if err := context.ShouldBindWith(&req, binding.FormPost); err != nil {
+		context.JSON(http.StatusBadRequest, &oauth.Error{Code: oauth.ErrorInvalidRequest, Description: err.Error()})
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(context, req.ClientID, req.ClientSecret)
+	res, err := handler.oauth.Token(oauth.TokenRequest{
+		GrantType:    req.GrantType,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Code:         req.Code,
+		RedirectURI:  req.RedirectURI,
+		CodeVerifier: req.CodeVerifier,
+		Scopes:       strings.Fields(req.Scope),
+	})
+	// responses carrying tokens must not be cached
+	context.Header("Cache-Control", "no-store")
+	if err != nil {
+		oauthErrorResponse(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// IntrospectOAuthToken describes an access token to the client it was issued to.
+func (handler *Handler) IntrospectOAuthToken(context *gin.Context) {
+	var req requests.OAuthTokenActionRequest
+	if err := context.ShouldBindWith(&req, binding.FormPost); err != nil {
+		context.JSON(http.StatusBadRequest, &oauth.Error{Code: oauth.ErrorInvalidRequest, Description: err.Error()})
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(context, req.ClientID, req.ClientSecret)
+	res, err := handler.oauth.Introspect(clientID, clientSecret, req.Token)
+	if err != nil {
+		oauthErrorResponse(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// RevokeOAuthToken revokes an access token issued to the client of the request.
+func (handler *Handler) RevokeOAuthToken(context *gin.Context) {
+	var req requests.OAuthTokenActionRequest
+	if err := context.ShouldBindWith(&req, binding.FormPost); err != nil {
+		context.JSON(http.StatusBadRequest, &oauth.Error{Code: oauth.ErrorInvalidRequest, Description: err.Error()})
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(context, req.ClientID, req.ClientSecret)
+	if err := handler.oauth.Revoke(clientID, clientSecret, req.Token); err != nil {
+		oauthErrorResponse(context, err)
+		return
+	}
+
+	context.Status(http.StatusOK)
+}
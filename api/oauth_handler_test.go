@@ -0,0 +1,184 @@
+package api
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/oauth"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterOAuthClient(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":          "budgeting app",
+				"redirect_uris": []string{"https://app.example.com/callback"},
+				"scopes":        []string{"accounts:read"},
+				"confidential":  true,
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().
+					CreateOAuthClient(gomock.Cond(func(x any) bool {
+						client := x.(models.OAuthClient)
+						return client.Owner == user.Username && client.Scopes == "accounts:read" && client.HashedSecret != ""
+					})).
+					Times(1).
+					DoAndReturn(func(client models.OAuthClient) (models.OAuthClient, error) {
+						return client, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var response responses.RegisterOAuthClientResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.NotEmpty(t, response.ClientID)
+				require.NotEmpty(t, response.ClientSecret)
+				require.True(t, response.Confidential)
+			},
+		},
+		{
+			name:       "UnknownScope",
+			body:       gin.H{"name": "budgeting app", "scopes": []string{"accounts:delete"}},
+			buildStubs: func(services *mockdb.MockServices) { services.EXPECT().CreateOAuthClient(gomock.Any()).Times(0) },
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "InvalidRedirectURI",
+			body:       gin.H{"name": "budgeting app", "scopes": []string{"accounts:read"}, "redirect_uris": []string{"callback"}},
+			buildStubs: func(services *mockdb.MockServices) { services.EXPECT().CreateOAuthClient(gomock.Any()).Times(0) },
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/clients", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, request)
+
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	user, _ := randomUser(t)
+	user.Role = models.RoleCustomer
+	account := createAccount(user.Username)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	services := mockdb.NewMockServices(controller)
+	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	require.NoError(t, err)
+	server := NewTestServer(t, services, tokenMaker)
+
+	// register a confidential client able to read accounts
+	var client models.OAuthClient
+	services.EXPECT().
+		CreateOAuthClient(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(created models.OAuthClient) (models.OAuthClient, error) {
+			client = created
+			return created, nil
+		})
+
+	body, err := json.Marshal(gin.H{"name": "reporting", "scopes": []string{"accounts:read"}, "confidential": true})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/oauth/clients", bytes.NewReader(body))
+	require.NoError(t, err)
+	addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, request)
+	recorder := httptest.NewRecorder()
+	server.RouterServeHTTP(recorder, request)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	var registered responses.RegisterOAuthClientResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &registered))
+
+	services.EXPECT().GetOAuthClient(client.ID).AnyTimes().Return(client, nil)
+	services.EXPECT().GetUser(user.Username).AnyTimes().Return(user, nil)
+
+	requestToken := func(secret string) *httptest.ResponseRecorder {
+		form := url.Values{"grant_type": {oauth.GrantTypeClientCredentials}}
+		request, err := http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.SetBasicAuth(client.ID, secret)
+
+		recorder := httptest.NewRecorder()
+		server.RouterServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder = requestToken("wrong")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.Contains(t, recorder.Body.String(), oauth.ErrorInvalidClient)
+
+	recorder = requestToken(registered.ClientSecret)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+	var tokenResponse oauth.TokenResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &tokenResponse))
+	require.Equal(t, "accounts:read", tokenResponse.Scope)
+
+	// the token can read accounts but cannot transfer
+	services.EXPECT().GetAccount(account.ID).Times(1).Return(account, nil)
+	request, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d", account.ID), nil)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, tokenResponse.AccessToken))
+	recorder = httptest.NewRecorder()
+	server.RouterServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	services.EXPECT().Transfer(gomock.Any()).Times(0)
+	request, err = http.NewRequest(http.MethodPost, "/accounts/transfer", nil)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, tokenResponse.AccessToken))
+	recorder = httptest.NewRecorder()
+	server.RouterServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/login"
+	"Simple-Bank/passwordless"
+	"Simple-Bank/requests"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// RequestPasswordlessLogin delivers a login code and a magic link to the user with the given username or email.
+// It answers alike whether or not the user exists, so it does not reveal which users are registered.
+func (handler *Handler) RequestPasswordlessLogin(context *gin.Context) {
+	var req requests.PasswordlessLoginRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := handler.passwordless.Request(req.Identifier, context.ClientIP()); err != nil {
+		var limited *passwordless.LimitedError
+		if errors.As(err, &limited) {
+			context.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+			context.JSON(http.StatusTooManyRequests, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	context.Status(http.StatusAccepted)
+}
+
+// VerifyPasswordlessLogin logs in a user with a login code or a magic link delivered by RequestPasswordlessLogin,
+// like Login does with a password.
+func (handler *Handler) VerifyPasswordlessLogin(context *gin.Context) {
+	var req requests.VerifyPasswordlessLoginRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var user models.User
+	var err error
+	if req.Token != "" {
+		user, err = handler.passwordless.VerifyLink(req.Token)
+	} else {
+		user, err = handler.passwordless.VerifyCode(req.Identifier, req.Code)
+	}
+	if err != nil {
+		if errors.Is(err, passwordless.ErrInvalidCode) {
+			context.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	handler.completeLogin(context, user, login.MethodLoginCode)
+}
@@ -0,0 +1,281 @@
+package api
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/passwordless"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRequestPasswordlessLogin(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *testMailer)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"identifier": user.Email},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUserByEmail(user.Email).Times(1).Return(user, nil)
+				services.EXPECT().
+					CreateLoginCode(gomock.Any()).
+					Times(1).
+					DoAndReturn(func(loginCode models.LoginCode) (models.LoginCode, error) {
+						require.Equal(t, user.Username, loginCode.Username)
+						return loginCode, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *testMailer) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Len(t, mailer.messages, 1)
+				require.Equal(t, user.Email, mailer.messages[0].To)
+			},
+		},
+		{
+			name: "UnknownUser",
+			body: gin.H{"identifier": util.RandomUsername()},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(gomock.Any()).Times(1).Return(models.User{}, gorm.ErrRecordNotFound)
+				services.EXPECT().CreateLoginCode(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *testMailer) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Empty(t, mailer.messages)
+			},
+		},
+		{
+			name: "NoIdentifier",
+			body: gin.H{},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *testMailer) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			mailer := &testMailer{}
+			server, err := NewServer(configs, services, tokenMaker, mailer)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, newPasswordlessRequest(t, "/users/login/passwordless", testCase.body))
+			testCase.checkResponse(t, recorder, mailer)
+		})
+	}
+}
+
+func TestRequestPasswordlessLoginLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	services := mockdb.NewMockServices(ctrl)
+	services.EXPECT().GetUser(gomock.Any()).Times(passwordless.DefaultMaxRequests).Return(models.User{}, gorm.ErrRecordNotFound)
+
+	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	require.NoError(t, err)
+	server, err := NewServer(configs, services, tokenMaker, &testMailer{})
+	require.NoError(t, err)
+
+	body := gin.H{"identifier": util.RandomUsername()}
+	for i := 0; i < passwordless.DefaultMaxRequests; i++ {
+		recorder := httptest.NewRecorder()
+		server.RouterServeHTTP(recorder, newPasswordlessRequest(t, "/users/login/passwordless", body))
+		require.Equal(t, http.StatusAccepted, recorder.Code)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.RouterServeHTTP(recorder, newPasswordlessRequest(t, "/users/login/passwordless", body))
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+	retryAfter, err := strconv.Atoi(recorder.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	require.Positive(t, retryAfter)
+}
+
+func TestVerifyPasswordlessLogin(t *testing.T) {
+	user, _ := randomUser(t)
+	frozenAt := time.Now()
+	frozenUser := user
+	frozenUser.FrozenAt = &frozenAt
+
+	code := "123456"
+	loginToken := util.RandomString(64, util.LOWERCASE)
+	loginCode := models.LoginCode{
+		ID:          1,
+		Username:    user.Username,
+		HashedCode:  hashLoginSecret(code),
+		HashedToken: hashLoginSecret(loginToken),
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OKWithCode",
+			body: gin.H{"identifier": user.Username, "code": code},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				services.EXPECT().GetLatestLoginCode(user.Username).Times(1).Return(loginCode, nil)
+				services.EXPECT().UseLoginCode(loginCode).Times(1).Return(nil)
+				expectSession(t, services, user)
+			},
+			checkResponse: requirePasswordlessLogin,
+		},
+		{
+			name: "OKWithLink",
+			body: gin.H{"token": loginToken},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetLoginCodeByToken(loginCode.HashedToken).Times(1).Return(loginCode, nil)
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				services.EXPECT().UseLoginCode(loginCode).Times(1).Return(nil)
+				expectSession(t, services, user)
+			},
+			checkResponse: requirePasswordlessLogin,
+		},
+		{
+			name: "WrongCode",
+			body: gin.H{"identifier": user.Username, "code": "654321"},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				services.EXPECT().GetLatestLoginCode(user.Username).Times(1).Return(loginCode, nil)
+				services.EXPECT().RecordLoginCodeAttempt(loginCode.ID).Times(1).Return(loginCode, nil)
+				services.EXPECT().CreateSession(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownToken",
+			body: gin.H{"token": util.RandomString(64, util.LOWERCASE)},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetLoginCodeByToken(gomock.Any()).Times(1).Return(models.LoginCode{}, gorm.ErrRecordNotFound)
+				services.EXPECT().CreateSession(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "FrozenUser",
+			body: gin.H{"identifier": user.Username, "code": code},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(user.Username).Times(1).Return(frozenUser, nil)
+				services.EXPECT().GetLatestLoginCode(user.Username).Times(1).Return(loginCode, nil)
+				services.EXPECT().UseLoginCode(loginCode).Times(1).Return(nil)
+				services.EXPECT().CreateSession(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "CodeWithoutIdentifier",
+			body: gin.H{"code": code},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetLatestLoginCode(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "IdentifierWithoutCode",
+			body: gin.H{"identifier": user.Username},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetLatestLoginCode(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			server := NewTestServer(t, services, tokenMaker)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, newPasswordlessRequest(t, "/users/login/passwordless/verify", testCase.body))
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func newPasswordlessRequest(t *testing.T, url string, body gin.H) *http.Request {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	return request
+}
+
+// hashLoginSecret hashes login codes and magic link tokens like they are stored.
+func hashLoginSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func expectSession(t *testing.T, services *mockdb.MockServices, user models.User) {
+	services.EXPECT().
+		CreateSession(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(session models.Session) (models.Session, error) {
+			require.Equal(t, user.Username, session.Username)
+			return session, nil
+		})
+}
+
+func requirePasswordlessLogin(t *testing.T, recorder *httptest.ResponseRecorder) {
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response responses.LoginResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.False(t, response.MFARequired)
+	require.NotEmpty(t, response.AccessToken)
+	require.NotEmpty(t, response.RefreshToken)
+}
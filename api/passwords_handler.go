@@ -0,0 +1,92 @@
+package api
+
+import (
+	"Simple-Bank/passwords"
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ForgotPassword emails a password reset token to the user with the given email. It answers alike whether or not
+// a user has the email, so it does not reveal which emails are registered.
+func (handler *Handler) ForgotPassword(context *gin.Context) {
+	var req requests.ForgotPasswordRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := handler.passwords.RequestReset(req.Email); err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	context.Status(http.StatusAccepted)
+}
+
+// ResetPassword sets a new password with a token emailed by ForgotPassword, and logs the user out of every session.
+func (handler *Handler) ResetPassword(context *gin.Context) {
+	var req requests.ResetPasswordRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, err := handler.passwords.Reset(req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, passwords.ErrInvalidToken) || errors.Is(err, passwords.ErrPasswordRejected) {
+			context.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	handler.revocations.Reset()
+
+	context.Status(http.StatusNoContent)
+}
+
+// ChangePassword sets a new password for a user who proves they know the current one, and logs the user out of
+// every other session.
+func (handler *Handler) ChangePassword(context *gin.Context) {
+	var req requests.ChangePasswordRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	revoked, err := handler.passwords.Change(authPayload.Username, req.CurrentPassword, req.NewPassword, authPayload.SessionID)
+	if err != nil {
+		if errors.Is(err, passwords.ErrInvalidCredentials) {
+			context.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		if errors.Is(err, passwords.ErrPasswordRejected) {
+			context.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	handler.revocations.Reset()
+
+	context.JSON(http.StatusOK, responses.ChangePasswordResponse{RevokedSessions: revoked})
+}
+
+// GetPasswordPolicy returns the rules new passwords must satisfy, so clients can show them to users.
+func (handler *Handler) GetPasswordPolicy(context *gin.Context) {
+	rules := handler.passwordPolicy.Rules()
+	res := responses.PasswordPolicyResponse{
+		MinLength: handler.passwordPolicy.MinLength(),
+		MaxLength: handler.passwordPolicy.MaxLength(),
+		Rules:     make([]responses.PasswordRule, len(rules)),
+	}
+	for i, rule := range rules {
+		res.Rules[i] = responses.PasswordRule{Name: rule.Name, Description: rule.Description}
+	}
+
+	context.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,347 @@
+package api
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	servicesPackage "Simple-Bank/db/services"
+	"Simple-Bank/passwords"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestForgotPassword(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		email         string
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *testMailer)
+	}{
+		{
+			name:  "OK",
+			email: user.Email,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUserByEmail(user.Email).Times(1).Return(user, nil)
+				services.EXPECT().
+					CreatePasswordReset(gomock.Any()).
+					Times(1).
+					DoAndReturn(func(reset models.PasswordReset) (models.PasswordReset, error) {
+						require.Equal(t, user.Username, reset.Username)
+						return reset, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *testMailer) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Len(t, mailer.messages, 1)
+				require.Equal(t, user.Email, mailer.messages[0].To)
+			},
+		},
+		{
+			name:  "UnknownEmail",
+			email: util.RandomEmail(),
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUserByEmail(gomock.Any()).Times(1).Return(models.User{}, gorm.ErrRecordNotFound)
+				services.EXPECT().CreatePasswordReset(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *testMailer) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Empty(t, mailer.messages)
+			},
+		},
+		{
+			name:  "InvalidEmail",
+			email: "not an email",
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUserByEmail(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *testMailer) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			mailer := &testMailer{}
+			server, err := NewServer(configs, services, tokenMaker, mailer)
+			require.NoError(t, err)
+
+			body, err := json.Marshal(gin.H{"email": testCase.email})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/users/forgot_password", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder, mailer)
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	user, password := randomUser(t)
+	history := []models.PasswordHistory{{Username: user.Username, HashedPassword: user.HashedPassword}}
+	resetToken := util.RandomString(64, util.LOWERCASE)
+	hashedToken := sha256.Sum256([]byte(resetToken))
+	reset := models.PasswordReset{
+		ID:          1,
+		HashedToken: hex.EncodeToString(hashedToken[:]),
+		Username:    user.Username,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	newPassword := util.RandomPassword()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"token": resetToken, "new_password": newPassword},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetPasswordReset(reset.HashedToken).Times(1).Return(reset, nil)
+				services.EXPECT().ListPasswordHistory(user.Username, passwords.DefaultHistorySize).Times(1).Return(history, nil)
+				services.EXPECT().ResetPassword(reset, newPassword).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "UsedToken",
+			body: gin.H{"token": resetToken, "new_password": newPassword},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetPasswordReset(reset.HashedToken).Times(1).Return(reset, nil)
+				services.EXPECT().ListPasswordHistory(user.Username, passwords.DefaultHistorySize).Times(1).Return(history, nil)
+				services.EXPECT().ResetPassword(reset, newPassword).Times(1).Return(models.User{}, servicesPackage.ErrPasswordResetUsed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownToken",
+			body: gin.H{"token": resetToken, "new_password": newPassword},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetPasswordReset(gomock.Any()).Times(1).Return(models.PasswordReset{}, gorm.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidPassword",
+			body: gin.H{"token": resetToken, "new_password": "short"},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetPasswordReset(reset.HashedToken).Times(1).Return(reset, nil)
+				services.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "password must be at least 8 characters long")
+			},
+		},
+		{
+			name: "ReusedPassword",
+			body: gin.H{"token": resetToken, "new_password": password},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetPasswordReset(reset.HashedToken).Times(1).Return(reset, nil)
+				services.EXPECT().ListPasswordHistory(user.Username, passwords.DefaultHistorySize).Times(1).Return(history, nil)
+				services.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "password must not be one of the last 5 passwords")
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			server := NewTestServer(t, services, tokenMaker)
+
+			body, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/users/reset_password", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	user, password := randomUser(t)
+	history := []models.PasswordHistory{{Username: user.Username, HashedPassword: user.HashedPassword}}
+	newPassword := util.RandomPassword()
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"current_password": password, "new_password": newPassword},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				services.EXPECT().ListPasswordHistory(user.Username, passwords.DefaultHistorySize).Times(1).Return(history, nil)
+				services.EXPECT().
+					UpdateUser(servicesPackage.UpdateUserRequest{Username: user.Username, Password: &newPassword}).
+					Times(1).
+					Return(user, nil)
+				services.EXPECT().RevokeOtherSessions(user.Username, sessionID).Times(1).Return(int64(3), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.ChangePasswordResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, int64(3), response.RevokedSessions)
+			},
+		},
+		{
+			name: "WrongCurrentPassword",
+			body: gin.H{"current_password": password + "x", "new_password": newPassword},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				services.EXPECT().UpdateUser(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ReusedPassword",
+			body: gin.H{"current_password": password, "new_password": password},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				services.EXPECT().ListPasswordHistory(user.Username, passwords.DefaultHistorySize).Times(1).Return(history, nil)
+				services.EXPECT().UpdateUser(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "WeakPassword",
+			body: gin.H{"current_password": password, "new_password": "alllowercase"},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				services.EXPECT().ListPasswordHistory(gomock.Any(), gomock.Any()).Times(0)
+				services.EXPECT().UpdateUser(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "password must contain an uppercase letter")
+			},
+		},
+		{
+			name: "NoCurrentPassword",
+			body: gin.H{"new_password": newPassword},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().UpdateUser(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			server := NewTestServer(t, services, tokenMaker)
+
+			body, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/users/change_password", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			accessToken, payload, err := tokenMaker.CreateSessionToken(user.Username, models.RoleCustomer, sessionID, time.Minute)
+			require.NoError(t, err)
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			services.EXPECT().GetSession(sessionID).AnyTimes().Return(activeSession(payload), nil)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetPasswordPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	require.NoError(t, err)
+	config := getTestConfig()
+	config.PasswordMinLength = 12
+	config.PasswordRequiredClasses = []string{passwords.RuleDigit}
+	config.PasswordHistorySize = -1
+	server := NewTestServerWithConfig(t, config, mockdb.NewMockServices(ctrl), tokenMaker)
+
+	request, err := http.NewRequest(http.MethodGet, "/password_policy", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	server.RouterServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response responses.PasswordPolicyResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Equal(t, 12, response.MinLength)
+	require.Equal(t, passwords.DefaultMaxLength, response.MaxLength)
+	require.Equal(t, []responses.PasswordRule{
+		{Name: passwords.RuleMinLength, Description: "must be at least 12 characters long"},
+		{Name: passwords.RuleMaxLength, Description: "must be at most 64 characters long"},
+		{Name: passwords.RuleDigit, Description: "must contain a digit"},
+		{Name: passwords.RuleAllowedCharacters, Description: "must not contain control characters"},
+	}, response.Rules)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// GetTrialBalance returns the trial balance as of the "at" query parameter, or as of now if it is not provided.
+func (handler *Handler) GetTrialBalance(context *gin.Context) {
+	var req requests.GetTrialBalanceRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.At.IsZero() {
+		req.At = time.Now()
+	}
+
+	trialBalance, err := handler.services.GetTrialBalance(req.At)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := responses.TrialBalanceResponse{
+		At:                    trialBalance.At.Local(),
+		GLAccounts:            make([]responses.TrialBalanceLineResponse, len(trialBalance.GLAccounts)),
+		CustomerAccounts:      trialBalance.CustomerAccounts,
+		CustomerAccountsTotal: trialBalance.CustomerAccountsTotal,
+		Total:                 trialBalance.Total,
+		Balanced:              trialBalance.Balanced(),
+	}
+	for i, line := range trialBalance.GLAccounts {
+		res.GLAccounts[i] = responses.TrialBalanceLineResponse{
+			Code:     line.Code,
+			Name:     line.Name,
+			Category: line.Category,
+			Balance:  line.Balance,
+		}
+	}
+
+	context.JSON(http.StatusOK, res)
+}
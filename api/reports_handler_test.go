@@ -0,0 +1,127 @@
+package api
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	servicesPackage "Simple-Bank/db/services"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetTrialBalance(t *testing.T) {
+	trialBalance := servicesPackage.TrialBalance{
+		At: time.Now().Truncate(time.Second),
+		GLAccounts: []servicesPackage.TrialBalanceLine{
+			{Code: models.GLCodeCash, Name: "Cash", Category: "asset", Balance: -100},
+		},
+		CustomerAccounts:      1,
+		CustomerAccountsTotal: 100,
+		Total:                 0,
+	}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker)
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Auditor",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, util.RandomUsername(), models.RoleAuditor, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetTrialBalance(gomock.Any()).Times(1).Return(trialBalance, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.TrialBalanceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.True(t, response.Balanced)
+				require.Len(t, response.GLAccounts, 1)
+				require.Equal(t, models.GLCodeCash, response.GLAccounts[0].Code)
+				require.Equal(t, int64(100), response.CustomerAccountsTotal)
+			},
+		},
+		{
+			name: "Admin",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, util.RandomUsername(), models.RoleAdmin, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetTrialBalance(gomock.Any()).Times(1).Return(trialBalance, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Teller",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, tokenMaker, util.RandomUsername(), models.RoleTeller, time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetTrialBalance(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, tokenMaker, authorizationTypeBearer, util.RandomUsername(), time.Minute, httpReq)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetTrialBalance(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UnAuthorized",
+			setupAuth: func(t *testing.T, httpReq *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetTrialBalance(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			httpReq, err := http.NewRequest(http.MethodGet, "/reports/trial_balance", nil)
+			require.NoError(t, err)
+
+			testCase.setupAuth(t, httpReq, server.handlers.tokenMaker)
+
+			server.RouterServeHTTP(recorder, httpReq)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
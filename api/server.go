@@ -1,9 +1,12 @@
 package api
 
 import (
+	"Simple-Bank/auth"
 	"Simple-Bank/config"
 	"Simple-Bank/db/services"
+	"Simple-Bank/mail"
 	"Simple-Bank/token"
+	"expvar"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
@@ -17,10 +20,20 @@ type Server struct {
 	handlers *Handler
 }
 
-func NewServer(config *config.Config, services services.Services, tokenMaker token.Maker) (*Server, error) {
+func NewServer(config *config.Config, services services.Services, tokenMaker token.Maker, mailer mail.Mailer) (*Server, error) {
+	handlers, err := New(services, tokenMaker, mailer, config)
+	if err != nil {
+		return nil, err
+	}
+	router := gin.Default()
+	// only the proxies in front of the server are believed about the address of clients, which api key
+	// allowlists, lockouts and rate limits rely on
+	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, err
+	}
 	server := &Server{
-		router:   gin.Default(),
-		handlers: New(services, tokenMaker, config),
+		router:   router,
+		handlers: handlers,
 	}
 
 	registerCustomValidators()
@@ -30,19 +43,87 @@ func NewServer(config *config.Config, services services.Services, tokenMaker tok
 }
 
 func (server *Server) setupRouter() {
-	server.router.GET("/", func(context *gin.Context) {
+	authenticate := authMiddleWare(server.handlers.tokenMaker, server.handlers.revocations, server.handlers.apiKeys)
+	// every request is limited per client address before its credentials are checked, and the requests of
+	// authenticated users per user as well
+	rateLimit := rateLimitMiddleware(server.handlers.rateLimiter)
+	rateLimitUser := userRateLimitMiddleware(server.handlers.rateLimiter)
+
+	publicRoutes := server.router.Group("/").Use(rateLimit)
+	publicRoutes.GET("/", func(context *gin.Context) {
 		context.JSON(http.StatusOK, gin.H{"message": "Welcome to our bank"})
 	})
 
-	authRoutes := server.router.Group("/").Use(authMiddleWare(server.handlers.tokenMaker))
+	authRoutes := server.router.Group("/").Use(rateLimit, authenticate, rateLimitUser)
 	authRoutes.POST("/accounts", server.handlers.CreateAccount)
 	authRoutes.GET("/accounts/:id", server.handlers.GetAccount)
+	authRoutes.GET("/accounts/:id/balance", server.handlers.GetAccountBalance)
 	authRoutes.GET("/accounts", server.handlers.GetAccountsList)
 	authRoutes.POST("/accounts/transfer", server.handlers.Transfer)
-	server.router.POST("/users", server.handlers.CreateUser)
+	authRoutes.GET("/transfers/:id", server.handlers.GetTransfer)
+	publicRoutes.POST("/users", server.handlers.CreateUser)
 	authRoutes.GET("/users/:username", server.handlers.GetUser)
-	server.router.POST("/users/login", server.handlers.Login)
-	server.router.POST("/tokens/renew_access_token", server.handlers.RenewAccessToken)
+	publicRoutes.POST("/users/login", server.handlers.Login)
+	publicRoutes.POST("/users/login/mfa", server.handlers.VerifyLoginMFA)
+	publicRoutes.POST("/users/login/passwordless", server.handlers.RequestPasswordlessLogin)
+	publicRoutes.POST("/users/login/passwordless/verify", server.handlers.VerifyPasswordlessLogin)
+	authRoutes.POST("/users/mfa/totp", server.handlers.EnrollTOTP)
+	authRoutes.POST("/users/mfa/totp/confirm", server.handlers.ConfirmTOTP)
+	authRoutes.POST("/users/mfa/totp/disable", server.handlers.DisableTOTP)
+	authRoutes.POST("/users/step_up", server.handlers.StepUp)
+	publicRoutes.POST("/users/verify_email", server.handlers.VerifyEmail)
+	authRoutes.POST("/users/verify_email/resend", server.handlers.ResendVerificationEmail)
+	publicRoutes.GET("/password_policy", server.handlers.GetPasswordPolicy)
+	publicRoutes.POST("/users/forgot_password", server.handlers.ForgotPassword)
+	publicRoutes.POST("/users/reset_password", server.handlers.ResetPassword)
+	authRoutes.POST("/users/change_password", server.handlers.ChangePassword)
+	publicRoutes.POST("/tokens/renew_access_token", server.handlers.RenewAccessToken)
+	publicRoutes.GET("/.well-known/jwks.json", server.handlers.PublicKeys)
+	authRoutes.GET("/users/devices", server.handlers.ListTrustedDevices)
+	authRoutes.DELETE("/users/devices/:id", server.handlers.RemoveTrustedDevice)
+	authRoutes.GET("/sessions", server.handlers.ListSessions)
+	authRoutes.DELETE("/sessions/:id", server.handlers.RevokeSession)
+	authRoutes.POST("/sessions/logout", server.handlers.Logout)
+	authRoutes.POST("/sessions/logout_others", server.handlers.LogoutOtherSessions)
+	authRoutes.DELETE("/accounts/:id", server.handlers.CloseAccount)
+	authRoutes.GET("/approvals", server.handlers.ListApprovals)
+	authRoutes.GET("/approvals/:id", server.handlers.GetApproval)
+	authRoutes.POST("/approvals/:id/approve", server.handlers.ApproveOperation)
+	authRoutes.POST("/approvals/:id/reject", server.handlers.RejectOperation)
+	authRoutes.POST("/api_keys", server.handlers.CreateAPIKey)
+	authRoutes.GET("/api_keys", server.handlers.ListAPIKeys)
+	authRoutes.DELETE("/api_keys/:id", server.handlers.RevokeAPIKey)
+	authRoutes.POST("/oauth/clients", server.handlers.RegisterOAuthClient)
+	authRoutes.GET("/oauth/clients", server.handlers.ListOAuthClients)
+	authRoutes.POST("/oauth/authorize", server.handlers.AuthorizeOAuthClient)
+	publicRoutes.POST("/oauth/token", server.handlers.OAuthToken)
+	publicRoutes.POST("/oauth/introspect", server.handlers.IntrospectOAuthToken)
+	publicRoutes.POST("/oauth/revoke", server.handlers.RevokeOAuthToken)
+
+	depositRoutes := server.router.Group("/").Use(rateLimit, authenticate, rateLimitUser, requirePermission(auth.PermissionDeposit))
+	depositRoutes.POST("/accounts/deposit", server.handlers.Deposit)
+
+	reportRoutes := server.router.Group("/reports").Use(rateLimit, authenticate, rateLimitUser, requirePermission(auth.PermissionReadReports))
+	reportRoutes.GET("/trial_balance", server.handlers.GetTrialBalance)
+
+	// the metrics of the services, e.g. the retries of transactions, are only exposed to the back office
+	debugRoutes := server.router.Group("/debug").Use(rateLimit, authenticate, rateLimitUser, requirePermission(auth.PermissionBackOffice))
+	debugRoutes.GET("/vars", gin.WrapH(expvar.Handler()))
+
+	adminRoutes := server.router.Group("/admin").Use(rateLimit, authenticate, rateLimitUser, requirePermission(auth.PermissionBackOffice))
+	adminRoutes.GET("/users", server.handlers.SearchUsers)
+	adminRoutes.GET("/users/:username/accounts", server.handlers.ListUserAccounts)
+	adminRoutes.GET("/users/:username/sessions", server.handlers.ListUserSessions)
+	adminRoutes.POST("/users/:username/freeze", server.handlers.FreezeUser)
+	adminRoutes.POST("/users/:username/unfreeze", server.handlers.UnfreezeUser)
+	adminRoutes.POST("/users/:username/force_password_reset", server.handlers.ForcePasswordReset)
+	adminRoutes.POST("/users/:username/unlock", server.handlers.UnlockUser)
+	adminRoutes.GET("/accounts/:id/entries", server.handlers.ListAccountEntries)
+	adminRoutes.POST("/accounts/:id/freeze", server.handlers.FreezeAccount)
+	adminRoutes.POST("/accounts/:id/unfreeze", server.handlers.UnfreezeAccount)
+	adminRoutes.POST("/accounts/:id/adjustments", server.handlers.AdjustBalance)
+	adminRoutes.POST("/tokens/revoke", server.handlers.AdminRevokeToken)
+	adminRoutes.GET("/audit_events", server.handlers.ListAuditEvents)
 }
 
 func registerCustomValidators() {
@@ -50,12 +131,12 @@ func registerCustomValidators() {
 		if err := v.RegisterValidation("validUsername", ValidUsername); err != nil {
 			log.Fatal("could not register validUsername validator")
 		}
-		if err := v.RegisterValidation("validPassword", ValidPassword); err != nil {
-			log.Fatal("could not register validPassword validator")
-		}
 		if err := v.RegisterValidation("validFullname", ValidFullname); err != nil {
 			log.Fatal("could not register validFullname validator")
 		}
+		if err := v.RegisterValidation("validScope", ValidScope); err != nil {
+			log.Fatal("could not register validScope validator")
+		}
 	}
 }
 
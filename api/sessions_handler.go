@@ -0,0 +1,110 @@
+package api
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// errNoSession is returned when an access token is not bound to a session
+var errNoSession = errors.New("access token is not bound to a session")
+
+// ListSessions returns the active sessions of the user.
+func (handler *Handler) ListSessions(context *gin.Context) {
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	sessions, err := handler.services.ListActiveSessions(authPayload.Username)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := responses.ListSessionsResponse{
+		Sessions: make([]responses.SessionResponse, len(sessions)),
+	}
+	for i, session := range sessions {
+		res.Sessions[i] = newSessionResponse(session, authPayload.SessionID)
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// RevokeSession blocks a session of the user, so that its tokens cannot be used anymore.
+func (handler *Handler) RevokeSession(context *gin.Context) {
+	var req requests.RevokeSessionRequest
+	if err := context.ShouldBindUri(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	session, err := handler.services.RevokeSession(authPayload.Username, uuid.MustParse(req.ID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			context.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("session not found")))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	handler.revocations.Reset()
+
+	context.JSON(http.StatusOK, newSessionResponse(session, authPayload.SessionID))
+}
+
+// Logout blocks the session of the request.
+func (handler *Handler) Logout(context *gin.Context) {
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.SessionID == uuid.Nil {
+		context.JSON(http.StatusBadRequest, errorResponse(errNoSession))
+		return
+	}
+
+	if _, err := handler.services.RevokeSession(authPayload.Username, authPayload.SessionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			context.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("session not found")))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	handler.revocations.Reset()
+
+	context.Status(http.StatusNoContent)
+}
+
+// LogoutOtherSessions blocks every session of the user except the one of the request.
+func (handler *Handler) LogoutOtherSessions(context *gin.Context) {
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.SessionID == uuid.Nil {
+		context.JSON(http.StatusBadRequest, errorResponse(errNoSession))
+		return
+	}
+
+	revoked, err := handler.services.RevokeOtherSessions(authPayload.Username, authPayload.SessionID)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	handler.revocations.Reset()
+
+	context.JSON(http.StatusOK, responses.LogoutOtherSessionsResponse{RevokedSessions: revoked})
+}
+
+func newSessionResponse(session models.Session, currentSessionID uuid.UUID) responses.SessionResponse {
+	return responses.SessionResponse{
+		SessionID: session.ID,
+		UserAgent: session.UserAgent,
+		ClientIP:  session.ClientIP,
+		CreatedAt: session.CreatedAt.Local(),
+		ExpiresAt: session.ExpiresAt.Local(),
+		Current:   session.ID == currentSessionID,
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"database/sql"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSessionsAPI(t *testing.T) {
+	sessionID := uuid.New()
+	otherSessionID := uuid.New()
+
+	var payload *token.Payload
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		withSession   bool
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "ListSessions",
+			method:      http.MethodGet,
+			url:         "/sessions",
+			withSession: true,
+			buildStubs: func(services *mockdb.MockServices) {
+				other := activeSession(payload)
+				other.ID = otherSessionID
+
+				services.EXPECT().
+					ListActiveSessions(gomock.Eq(payload.Username)).
+					Times(1).
+					Return([]models.Session{activeSession(payload), other}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.ListSessionsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Len(t, response.Sessions, 2)
+				require.Equal(t, sessionID, response.Sessions[0].SessionID)
+				require.True(t, response.Sessions[0].Current)
+				require.Equal(t, otherSessionID, response.Sessions[1].SessionID)
+				require.False(t, response.Sessions[1].Current)
+			},
+		},
+		{
+			name:        "ListSessionsInternalError",
+			method:      http.MethodGet,
+			url:         "/sessions",
+			withSession: true,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().ListActiveSessions(gomock.Any()).Times(1).Return([]models.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:        "RevokeSession",
+			method:      http.MethodDelete,
+			url:         "/sessions/" + otherSessionID.String(),
+			withSession: true,
+			buildStubs: func(services *mockdb.MockServices) {
+				revoked := activeSession(payload)
+				revoked.ID = otherSessionID
+				revoked.IsBlocked = true
+
+				services.EXPECT().
+					RevokeSession(gomock.Eq(payload.Username), gomock.Eq(otherSessionID)).
+					Times(1).
+					Return(revoked, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.SessionResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, otherSessionID, response.SessionID)
+				require.False(t, response.Current)
+			},
+		},
+		{
+			name:        "RevokeSessionNotFound",
+			method:      http.MethodDelete,
+			url:         "/sessions/" + otherSessionID.String(),
+			withSession: true,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Times(1).Return(models.Session{}, gorm.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:        "RevokeSessionBadRequest",
+			method:      http.MethodDelete,
+			url:         "/sessions/not-a-uuid",
+			withSession: true,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "Logout",
+			method:      http.MethodPost,
+			url:         "/sessions/logout",
+			withSession: true,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().
+					RevokeSession(gomock.Eq(payload.Username), gomock.Eq(sessionID)).
+					Times(1).
+					Return(activeSession(payload), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "LogoutWithoutSession",
+			method: http.MethodPost,
+			url:    "/sessions/logout",
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "LogoutOtherSessions",
+			method:      http.MethodPost,
+			url:         "/sessions/logout_others",
+			withSession: true,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().
+					RevokeOtherSessions(gomock.Eq(payload.Username), gomock.Eq(sessionID)).
+					Times(1).
+					Return(int64(3), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.LogoutOtherSessionsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, int64(3), response.RevokedSessions)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			httpReq, err := http.NewRequest(testCase.method, testCase.url, nil)
+			require.NoError(t, err)
+
+			if testCase.withSession {
+				_, payload = addSessionAuthorization(t, tokenMaker, sessionID, time.Minute, httpReq)
+				services.EXPECT().GetSession(gomock.Eq(sessionID)).Times(1).Return(activeSession(payload), nil)
+			} else {
+				_, payload = addAuthorization(t, tokenMaker, authorizationTypeBearer, util.RandomUsername(), time.Minute, httpReq)
+			}
+			testCase.buildStubs(services)
+
+			server.RouterServeHTTP(recorder, httpReq)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"Simple-Bank/stepup"
+	"Simple-Bank/token"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// stepUpTokenHeaderKey is the header carrying the elevated token of a recent re-authentication
+const stepUpTokenHeaderKey = "X-Step-Up-Token"
+
+// StepUp re-authenticates the user with their password, or a code if they enabled two-factor authentication,
+// and returns a short-lived elevated token accepted for the requested operation.
+func (handler *Handler) StepUp(context *gin.Context) {
+	var req requests.StepUpRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+	stepUpToken, payload, err := handler.stepUp.Elevate(
+		authPayload.Username, stepup.Operation(req.Operation), req.Password, req.Code, context.ClientIP())
+	if err != nil {
+		var locked *auth.LockedError
+		switch {
+		case errors.As(err, &locked):
+			context.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			context.JSON(http.StatusTooManyRequests, errorResponse(err))
+		case errors.Is(err, stepup.ErrInvalidCredentials):
+			context.JSON(http.StatusUnauthorized, errorResponse(err))
+		default:
+			context.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	context.JSON(http.StatusOK, responses.StepUpResponse{
+		StepUpToken:          stepUpToken,
+		StepUpTokenExpiresAt: payload.ExpiredAt,
+		Operation:            req.Operation,
+	})
+}
+
+// checkStepUp checks the elevated token of the request for operation, and responds that a re-authentication
+// is required if it is missing or invalid. It reports whether the request can go on.
+func (handler *Handler) checkStepUp(context *gin.Context, operation stepup.Operation) bool {
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	err := handler.stepUp.Check(authPayload, operation, context.GetHeader(stepUpTokenHeaderKey))
+	if err != nil {
+		// the challenge of RFC 9470, asking clients to authenticate again
+		context.Header("WWW-Authenticate", `Bearer error="insufficient_user_authentication"`)
+		context.JSON(http.StatusUnauthorized, responses.StepUpRequiredResponse{
+			Error:     err.Error(),
+			Operation: string(operation),
+		})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,285 @@
+package api
+
+import (
+	"Simple-Bank/auth"
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	servicesPackage "Simple-Bank/db/services"
+	"Simple-Bank/responses"
+	"Simple-Bank/stepup"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stepUpTransferAmount is the amount from which transfers require a re-authentication in the tests of step-up
+const stepUpTransferAmount = 1000
+
+// createStepUpToken creates an elevated token of username for operation.
+func createStepUpToken(t *testing.T, tokenMaker token.Maker, username string, operation stepup.Operation) string {
+	stepUpToken, _, err := tokenMaker.CreateScopedToken(
+		username, models.RoleCustomer, []string{"step_up:" + string(operation)}, stepup.Audience, time.Minute)
+	require.NoError(t, err)
+
+	return stepUpToken
+}
+
+func TestStepUp(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"operation": "transfer", "password": password},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.StepUpResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, "transfer", response.Operation)
+
+				payload, err := tokenMaker.VerifyToken(response.StepUpToken)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+				require.Equal(t, stepup.Audience, payload.Audience)
+				require.Equal(t, []string{"step_up:transfer"}, payload.Scopes)
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{"operation": "transfer", "password": util.RandomPassword()},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Locked",
+			body: gin.H{"operation": "transfer", "password": password},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().
+					GetLoginFailure(models.LoginFailureKindUsername, user.Username).
+					Times(1).
+					Return(models.LoginFailure{Failures: auth.DefaultMaxLoginFailures, LastFailedAt: time.Now()}, nil)
+				services.EXPECT().GetUser(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "UnknownOperation",
+			body: gin.H{"operation": "withdraw", "password": password},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetUser(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			server := NewTestServer(t, services, tokenMaker)
+
+			body, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/users/step_up", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, request)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder, tokenMaker)
+		})
+	}
+}
+
+func TestTransferRequiresStepUp(t *testing.T) {
+	user, _ := randomUser(t)
+	account1 := createAccount(user.Username)
+	account2 := createAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		amount        int32
+		setupStepUp   func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			amount: stepUpTransferAmount,
+			setupStepUp: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				request.Header.Set(stepUpTokenHeaderKey, createStepUpToken(t, tokenMaker, user.Username, stepup.OperationTransfer))
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().
+					Transfer(gomock.Eq(servicesPackage.TransferRequest{
+						Owner:         user.Username,
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        stepUpTransferAmount,
+					})).
+					Times(1).
+					Return(models.Transfer{ID: 1, Status: models.TransferStatusCompleted}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "SmallTransfer",
+			amount:      stepUpTransferAmount - 1,
+			setupStepUp: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().Transfer(gomock.Any()).Times(1).Return(models.Transfer{ID: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "NoStepUpToken",
+			amount:      stepUpTransferAmount,
+			setupStepUp: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().Transfer(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Header().Get("WWW-Authenticate"), "insufficient_user_authentication")
+
+				var response responses.StepUpRequiredResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, "transfer", response.Operation)
+			},
+		},
+		{
+			name:   "TokenOfOtherOperation",
+			amount: stepUpTransferAmount,
+			setupStepUp: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				request.Header.Set(stepUpTokenHeaderKey, createStepUpToken(t, tokenMaker, user.Username, stepup.OperationCloseAccount))
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().Transfer(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "TokenOfOtherUser",
+			amount: stepUpTransferAmount,
+			setupStepUp: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				other, _ := randomUser(t)
+				request.Header.Set(stepUpTokenHeaderKey, createStepUpToken(t, tokenMaker, other.Username, stepup.OperationTransfer))
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().Transfer(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "AccessTokenAsStepUpToken",
+			amount: stepUpTransferAmount,
+			setupStepUp: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, _, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(stepUpTokenHeaderKey, accessToken)
+			},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().Transfer(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(services)
+
+			config := getTestConfig()
+			config.StepUpTransferThreshold = stepUpTransferAmount
+			tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+			require.NoError(t, err)
+			server := NewTestServerWithConfig(t, config, services, tokenMaker)
+
+			body, err := json.Marshal(gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          testCase.amount,
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/accounts/transfer", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, request)
+			testCase.setupStepUp(t, request, tokenMaker)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestStepUpTokenIsNotAnAccessToken(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	services := mockdb.NewMockServices(ctrl)
+	services.EXPECT().Transfer(gomock.Any()).Times(0)
+
+	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	require.NoError(t, err)
+	server := NewTestServer(t, services, tokenMaker)
+
+	body, err := json.Marshal(gin.H{"from_account_id": 1, "to_account_id": 2, "amount": 10})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/accounts/transfer", bytes.NewReader(body))
+	require.NoError(t, err)
+	stepUpToken := createStepUpToken(t, tokenMaker, user.Username, stepup.OperationTransfer)
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, stepUpToken))
+
+	recorder := httptest.NewRecorder()
+	server.RouterServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
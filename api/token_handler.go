@@ -2,17 +2,22 @@ package api
 
 import (
 	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
 	"Simple-Bank/requests"
 	"Simple-Bank/responses"
 	"Simple-Bank/token"
-	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 	"net/http"
 	"time"
 )
 
+// RenewAccessToken exchanges a refresh token for a new access token and a new refresh token.
+// Every refresh token can be used once: using it again revokes every session renewed from it.
 func (handler *Handler) RenewAccessToken(context *gin.Context) {
 	var req requests.RenewAccessTokenRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
@@ -21,15 +26,26 @@ func (handler *Handler) RenewAccessToken(context *gin.Context) {
 	}
 
 	refreshTokenPayload, err := handler.tokenMaker.VerifyToken(req.RefreshToken)
-	if err := context.ShouldBindJSON(&req); err != nil {
+	if err != nil {
 		context.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
+	// refresh tokens issued before tokens had a type have none, they are still checked against their session
+	if refreshTokenPayload.Type == token.TokenTypeAccess {
+		context.JSON(http.StatusUnauthorized, errorResponse(token.ErrInvalidTokenType))
+		return
+	}
+
+	// refresh tokens issued before tokens were bound to sessions have the id of their session
+	sessionID := refreshTokenPayload.SessionID
+	if sessionID == uuid.Nil {
+		sessionID = refreshTokenPayload.ID
+	}
 
-	session, err := handler.services.GetSession(refreshTokenPayload.ID)
+	session, err := handler.services.GetSession(sessionID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			context.JSON(http.StatusNotFound, errorResponse(err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			context.JSON(http.StatusUnauthorized, errorResponse(fmt.Errorf("session not found")))
 			return
 		}
 		context.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -41,22 +57,63 @@ func (handler *Handler) RenewAccessToken(context *gin.Context) {
 		return
 	}
 
-	accessToken, accessTokenPayload, err := handler.tokenMaker.CreateToken(
-		session.Username,
-		handler.config.TokenAccessTokenDuration,
-	)
+	// the new tokens carry the current role of the user, so role changes apply at the latest on renewal
+	user, err := handler.services.GetUser(session.Username)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			context.JSON(http.StatusUnauthorized, errorResponse(fmt.Errorf("user not found")))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if user.FrozenAt != nil {
+		context.JSON(http.StatusForbidden, errorResponse(errUserFrozen))
+		return
+	}
+
+	tokens, err := handler.login.RotateSession(session, user, loginClient(context))
+	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			handler.revocations.Reset()
+			log.Warn().
+				Str("event", "refresh_token_reuse").
+				Str("username", session.Username).
+				Str("session_id", session.ID.String()).
+				Str("family_id", session.FamilyID.String()).
+				Str("client_ip", context.ClientIP()).
+				Str("user_agent", context.Request.UserAgent()).
+				Msg("refresh token reused, revoked the session family")
+			context.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
 		context.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
 	response := responses.RenewAccessTokenResponse{
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: accessTokenPayload.ExpiredAt,
+		AccessToken:           tokens.AccessToken,
+		AccessTokenExpiresAt:  tokens.AccessTokenPayload.ExpiredAt,
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: tokens.RefreshTokenPayload.ExpiredAt,
+		SessionID:             tokens.Session.ID,
 	}
 	context.JSON(http.StatusOK, response)
 }
 
+// PublicKeys returns the public keys verifying access tokens, so other services can verify them without
+// holding a secret. There is no key when tokens are signed with a symmetric key.
+func (handler *Handler) PublicKeys(context *gin.Context) {
+	keys := handler.tokenMaker.PublicKeys()
+	if keys == nil {
+		keys = []token.JSONWebKey{}
+	}
+
+	// verifiers may cache the keys for a while, and fetch them again when a token names an unknown key
+	context.Header("Cache-Control", "public, max-age=300")
+	context.JSON(http.StatusOK, responses.PublicKeysResponse{Keys: keys})
+}
+
 func checkSession(session models.Session, refreshToken string, refreshTokenPayload *token.Payload) error {
 	if session.IsBlocked {
 		return fmt.Errorf("session is blocked")
@@ -0,0 +1,246 @@
+package api
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	servicesPackage "Simple-Bank/db/services"
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRenewAccessToken(t *testing.T) {
+	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	username := util.RandomUsername()
+	sessionID := uuid.New()
+	refreshToken, refreshTokenPayload, err := tokenMaker.CreateRefreshToken(username, models.RoleCustomer, sessionID, time.Hour)
+	require.NoError(t, err)
+	accessToken, _, err := tokenMaker.CreateSessionToken(username, models.RoleCustomer, sessionID, time.Hour)
+	require.NoError(t, err)
+
+	session := models.Session{
+		ID:           sessionID,
+		Username:     username,
+		RefreshToken: refreshToken,
+		FamilyID:     sessionID,
+		CreatedAt:    refreshTokenPayload.IssuedAt,
+		ExpiresAt:    refreshTokenPayload.ExpiredAt,
+	}
+	// the role of the user changed since the refresh token was issued
+	user := models.User{Username: username, Role: models.RoleTeller}
+
+	testCases := []struct {
+		name          string
+		refreshToken  string
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:         "OK",
+			refreshToken: refreshToken,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetSession(gomock.Eq(sessionID)).Times(1).Return(session, nil)
+				services.EXPECT().GetUser(gomock.Eq(username)).Times(1).Return(user, nil)
+				services.EXPECT().
+					RotateSession(gomock.Eq(sessionID), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(consumedID uuid.UUID, next models.Session) (models.Session, error) {
+						require.Equal(t, username, next.Username)
+						require.NotEqual(t, sessionID, next.ID)
+						require.NotEqual(t, refreshToken, next.RefreshToken)
+						next.FamilyID = session.FamilyID
+						return next, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.RenewAccessTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.NotEmpty(t, response.AccessToken)
+				require.NotEmpty(t, response.RefreshToken)
+				require.NotEqual(t, refreshToken, response.RefreshToken)
+				require.NotEqual(t, sessionID, response.SessionID)
+
+				// both new tokens are bound to the new session
+				accessTokenPayload, err := tokenMaker.VerifyToken(response.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, response.SessionID, accessTokenPayload.SessionID)
+				require.Equal(t, models.RoleTeller, accessTokenPayload.Role)
+				require.Equal(t, token.TokenTypeAccess, accessTokenPayload.Type)
+
+				newRefreshTokenPayload, err := tokenMaker.VerifyToken(response.RefreshToken)
+				require.NoError(t, err)
+				require.Equal(t, response.SessionID, newRefreshTokenPayload.SessionID)
+				require.Equal(t, token.TokenTypeRefresh, newRefreshTokenPayload.Type)
+			},
+		},
+		{
+			name:         "Reused",
+			refreshToken: refreshToken,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetSession(gomock.Eq(sessionID)).Times(1).Return(session, nil)
+				services.EXPECT().GetUser(gomock.Eq(username)).Times(1).Return(user, nil)
+				services.EXPECT().
+					RotateSession(gomock.Eq(sessionID), gomock.Any()).
+					Times(1).
+					Return(session, servicesPackage.ErrRefreshTokenReused)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "BlockedSession",
+			refreshToken: refreshToken,
+			buildStubs: func(services *mockdb.MockServices) {
+				blocked := session
+				blocked.IsBlocked = true
+
+				services.EXPECT().GetSession(gomock.Eq(sessionID)).Times(1).Return(blocked, nil)
+				services.EXPECT().RotateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "SessionNotFound",
+			refreshToken: refreshToken,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetSession(gomock.Eq(sessionID)).Times(1).Return(models.Session{}, gorm.ErrRecordNotFound)
+				services.EXPECT().RotateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "AccessToken",
+			refreshToken: accessToken,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetSession(gomock.Any()).Times(0)
+				services.EXPECT().RotateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "InvalidToken",
+			refreshToken: "invalid",
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetSession(gomock.Any()).Times(0)
+				services.EXPECT().RotateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "InternalError",
+			refreshToken: refreshToken,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetSession(gomock.Eq(sessionID)).Times(1).Return(session, nil)
+				services.EXPECT().GetUser(gomock.Eq(username)).Times(1).Return(user, nil)
+				services.EXPECT().
+					RotateSession(gomock.Eq(sessionID), gomock.Any()).
+					Times(1).
+					Return(models.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			services := mockdb.NewMockServices(controller)
+			testCase.buildStubs(services)
+
+			server := NewTestServer(t, services, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			jsonReq, err := json.Marshal(requests.RenewAccessTokenRequest{RefreshToken: testCase.refreshToken})
+			require.NoError(t, err)
+
+			httpReq, err := http.NewRequest(http.MethodPost, "/tokens/renew_access_token", bytes.NewBuffer(jsonReq))
+			require.NoError(t, err)
+
+			server.RouterServeHTTP(recorder, httpReq)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPublicKeys(t *testing.T) {
+	key, err := token.GenerateSigningKey("2024-01")
+	require.NoError(t, err)
+	keyRing, err := token.NewKeyRing(key)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		newTokenMaker func() (token.Maker, error)
+		checkResponse func(t *testing.T, response responses.PublicKeysResponse)
+	}{
+		{
+			name:          "AsymmetricKeys",
+			newTokenMaker: func() (token.Maker, error) { return token.NewEdDSAJWTMaker(keyRing) },
+			checkResponse: func(t *testing.T, response responses.PublicKeysResponse) {
+				require.Len(t, response.Keys, 1)
+				require.Equal(t, key.ID, response.Keys[0].KeyID)
+				require.Equal(t, "OKP", response.Keys[0].KeyType)
+			},
+		},
+		{
+			name:          "SymmetricKey",
+			newTokenMaker: func() (token.Maker, error) { return token.NewPasetoMaker(configs.TokenSymmetricKey) },
+			checkResponse: func(t *testing.T, response responses.PublicKeysResponse) {
+				require.NotNil(t, response.Keys)
+				require.Empty(t, response.Keys)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			tokenMaker, err := testCase.newTokenMaker()
+			require.NoError(t, err)
+
+			server := NewTestServer(t, mockdb.NewMockServices(controller), tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			require.NoError(t, err)
+
+			server.RouterServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var response responses.PublicKeysResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			testCase.checkResponse(t, response)
+		})
+	}
+}
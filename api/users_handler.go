@@ -1,20 +1,29 @@
 package api
 
 import (
+	"Simple-Bank/auth"
 	"Simple-Bank/db/models"
+	"Simple-Bank/login"
+	"Simple-Bank/passwordless"
+	"Simple-Bank/passwords"
 	"Simple-Bank/requests"
 	"Simple-Bank/responses"
 	"Simple-Bank/token"
-	"Simple-Bank/util"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 )
 
+// errUserFrozen is returned when a frozen user logs in or renews their tokens
+var errUserFrozen = errors.New("user is frozen")
+
 func (handler *Handler) CreateUser(context *gin.Context) {
 	var req requests.CreateUserRequest
 
@@ -23,6 +32,15 @@ func (handler *Handler) CreateUser(context *gin.Context) {
 		return
 	}
 
+	if err := handler.passwordPolicy.Validate(req.Password); err != nil {
+		if errors.Is(err, passwords.ErrPasswordRejected) {
+			context.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	newUser, err := handler.services.CreateUser(req)
 	if err != nil {
 		var pgError *pgconn.PgError
@@ -37,54 +55,35 @@ func (handler *Handler) CreateUser(context *gin.Context) {
 		return
 	}
 
-	userInformation := responses.UserInformationResponse{
-		Username:  newUser.Username,
-		Email:     newUser.Email,
-		FullName:  newUser.FullName,
-		CreatedAt: newUser.CreatedAt.Local().Truncate(time.Second),
-		UpdatedAt: newUser.UpdatedAt.Local().Truncate(time.Second),
-		DeletedAt: newUser.DeletedAt.Time.Truncate(time.Second),
-	}
-
-	accessToken, accessTokenPayload, err := handler.tokenMaker.CreateToken(
-		userInformation.Username,
-		handler.config.TokenAccessTokenDuration,
-	)
-	if err != nil {
-		context.JSON(http.StatusInternalServerError, errorResponse(err))
+	// the user is created even if the email cannot be sent, they can ask for another one
+	if err := handler.emails.Send(newUser); err != nil {
+		log.Error().Err(err).Str("username", newUser.Username).Msg("cannot send the email verification")
 	}
 
-	refreshToken, refreshTokenPayload, err := handler.tokenMaker.CreateToken(
-		req.Username,
-		handler.config.TokenRefreshTokenDuration)
-	if err != nil {
-		context.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	session := models.Session{
-		ID:           refreshTokenPayload.ID,
-		Username:     req.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    context.Request.UserAgent(),
-		ClientIP:     context.ClientIP(),
-		IsBlocked:    false,
-		CreatedAt:    time.Now().UTC(),
-		ExpiresAt:    time.Now().UTC(),
-		DeletedAt:    gorm.DeletedAt{},
-	}
-	session, err = handler.services.CreateSession(session)
+	userInformation := responses.UserInformationResponse{
+		Username:      newUser.Username,
+		Email:         newUser.Email,
+		FullName:      newUser.FullName,
+		Role:          newUser.Role,
+		EmailVerified: newUser.EmailVerified,
+		CreatedAt:     newUser.CreatedAt.Local().Truncate(time.Second),
+		UpdatedAt:     newUser.UpdatedAt.Local().Truncate(time.Second),
+		DeletedAt:     newUser.DeletedAt.Time.Truncate(time.Second),
+	}
+
+	// the user signs up from the device they will log in from
+	tokens, err := handler.login.StartSession(newUser, loginClient(context))
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
 	res := responses.LoginResponse{
-		AccessToken:           accessToken,
-		AccessTokenExpiresAt:  accessTokenPayload.ExpiredAt,
-		RefreshToken:          refreshToken,
-		RefreshTokenExpiresAt: refreshTokenPayload.ExpiredAt,
-		SessionID:             session.ID,
+		AccessToken:           tokens.AccessToken,
+		AccessTokenExpiresAt:  tokens.AccessTokenPayload.ExpiredAt,
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: tokens.RefreshTokenPayload.ExpiredAt,
+		SessionID:             tokens.Session.ID,
 		UserInformation:       userInformation,
 	}
 	context.JSON(http.StatusOK, res)
@@ -98,7 +97,7 @@ func (handler *Handler) GetUser(context *gin.Context) {
 	}
 
 	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
-	if authPayload.Username != req.Username {
+	if !auth.CanAccess(authPayload, req.Username, auth.PermissionReadUsers) {
 		err := fmt.Errorf("users cannot see other user`s information")
 		context.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -115,11 +114,13 @@ func (handler *Handler) GetUser(context *gin.Context) {
 	}
 
 	res := responses.UserInformationResponse{
-		Username:  user.Username,
-		Email:     user.Email,
-		FullName:  user.FullName,
-		CreatedAt: user.CreatedAt.Local().Truncate(time.Second),
-		UpdatedAt: user.CreatedAt.Local().Truncate(time.Second),
+		Username:      user.Username,
+		Email:         user.Email,
+		FullName:      user.FullName,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		CreatedAt:     user.CreatedAt.Local().Truncate(time.Second),
+		UpdatedAt:     user.CreatedAt.Local().Truncate(time.Second),
 	}
 
 	if user.DeletedAt.Time.IsZero() {
@@ -138,70 +139,81 @@ func (handler *Handler) Login(context *gin.Context) {
 		return
 	}
 
-	user, err := handler.services.GetUser(req.Username)
+	user, err := handler.logins.Authenticate(req.Username, req.Password, context.ClientIP())
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			context.JSON(http.StatusNotFound, errorResponse(err))
-			return
+		var locked *auth.LockedError
+		switch {
+		case errors.As(err, &locked):
+			context.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			context.JSON(http.StatusTooManyRequests, errorResponse(err))
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			context.JSON(http.StatusUnauthorized, errorResponse(err))
+		default:
+			context.JSON(http.StatusInternalServerError, errorResponse(err))
 		}
-		context.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	if err := util.CheckPassword(req.Password, user.HashedPassword); err != nil {
-		context.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
-	userInformation := responses.UserInformationResponse{
-		Username:  user.Username,
-		Email:     user.Email,
-		FullName:  user.FullName,
-		CreatedAt: user.CreatedAt.Local().Truncate(time.Second),
-		UpdatedAt: user.CreatedAt.Local().Truncate(time.Second),
-	}
+	handler.completeLogin(context, user, login.MethodPassword)
+}
 
-	accessToken, accessTokenPayload, err := handler.tokenMaker.CreateToken(
-		req.Username,
-		handler.config.TokenAccessTokenDuration,
-	)
+// completeLogin logs in a user who proved who they are with method: it starts a two-factor challenge if they
+// enabled it, delivers a login code if their device must be verified, or starts a session otherwise.
+func (handler *Handler) completeLogin(context *gin.Context, user models.User, method login.Method) {
+	result, err := handler.login.Complete(user, loginClient(context), method)
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		var limited *passwordless.LimitedError
+		switch {
+		case errors.Is(err, login.ErrUserFrozen):
+			context.JSON(http.StatusForbidden, errorResponse(err))
+		case errors.As(err, &limited):
+			context.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+			context.JSON(http.StatusTooManyRequests, errorResponse(err))
+		default:
+			context.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
 		return
 	}
 
-	refreshToken, refreshTokenPayload, err := handler.tokenMaker.CreateToken(
-		req.Username,
-		handler.config.TokenRefreshTokenDuration)
-	if err != nil {
-		context.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+	switch {
+	case result.MFAChallengeToken != "":
+		context.JSON(http.StatusOK, responses.LoginResponse{
+			MFARequired:           true,
+			MFAChallengeToken:     result.MFAChallengeToken,
+			MFAChallengeExpiresAt: &result.MFAChallengeExpiresAt,
+		})
+	case result.DeviceVerificationRequired:
+		context.JSON(http.StatusOK, responses.LoginResponse{DeviceVerificationRequired: true})
+	default:
+		context.JSON(http.StatusOK, newLoginResponse(user, *result.Tokens))
 	}
+}
 
-	session := models.Session{
-		ID:           refreshTokenPayload.ID,
-		Username:     req.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    context.Request.UserAgent(),
-		ClientIP:     context.ClientIP(),
-		IsBlocked:    false,
-		CreatedAt:    time.Now().UTC(),
-		ExpiresAt:    time.Now().UTC(),
-		DeletedAt:    gorm.DeletedAt{},
-	}
-	session, err = handler.services.CreateSession(session)
-	if err != nil {
-		context.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+// loginClient returns the device a request is made from. Its address is only taken from the X-Forwarded-For
+// headers of the trusted proxies of the router.
+func loginClient(context *gin.Context) login.Client {
+	return login.Client{UserAgent: context.Request.UserAgent(), ClientIP: context.ClientIP()}
+}
+
+// newLoginResponse returns the tokens of the session a user who fully logged in started.
+func newLoginResponse(user models.User, tokens login.Tokens) responses.LoginResponse {
+	userInformation := responses.UserInformationResponse{
+		Username:      user.Username,
+		Email:         user.Email,
+		FullName:      user.FullName,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		CreatedAt:     user.CreatedAt.Local().Truncate(time.Second),
+		UpdatedAt:     user.CreatedAt.Local().Truncate(time.Second),
 	}
 
-	response := responses.LoginResponse{
+	return responses.LoginResponse{
 		UserInformation:       userInformation,
-		AccessToken:           accessToken,
-		AccessTokenExpiresAt:  accessTokenPayload.ExpiredAt,
-		RefreshToken:          refreshToken,
-		RefreshTokenExpiresAt: refreshTokenPayload.ExpiredAt,
-		SessionID:             session.ID,
+		AccessToken:           tokens.AccessToken,
+		AccessTokenExpiresAt:  tokens.AccessTokenPayload.ExpiredAt,
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: tokens.RefreshTokenPayload.ExpiredAt,
+		SessionID:             tokens.Session.ID,
+		PasswordResetRequired: user.PasswordResetRequired,
 	}
-	context.JSON(http.StatusOK, response)
 }
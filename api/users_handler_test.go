@@ -1,6 +1,7 @@
 package api
 
 import (
+	"Simple-Bank/auth"
 	mockdb "Simple-Bank/db/mock"
 	"Simple-Bank/db/models"
 	"Simple-Bank/requests"
@@ -21,6 +22,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -70,12 +72,20 @@ func TestCreateUserAPI(t *testing.T) {
 					CreateUser(gomock.Eq(req)).
 					Times(1).
 					Return(createdUser, nil)
+				services.EXPECT().
+					CreateEmailVerification(gomock.Any()).
+					Times(1).
+					DoAndReturn(func(verification models.EmailVerification) (models.EmailVerification, error) {
+						require.Equal(t, createdUser.Username, verification.Username)
+						require.Equal(t, createdUser.Email, verification.Email)
+						return verification, nil
+					})
 				tokenMaker.EXPECT().
-					CreateToken(req.Username, configs.TokenAccessTokenDuration).
+					CreateSessionToken(req.Username, models.RoleCustomer, gomock.Any(), configs.TokenAccessTokenDuration).
 					Times(1).
 					Return(accessToken, accessTokenPayload, nil)
 				tokenMaker.EXPECT().
-					CreateToken(req.Username, configs.TokenRefreshTokenDuration).
+					CreateRefreshToken(req.Username, models.RoleCustomer, gomock.Any(), configs.TokenRefreshTokenDuration).
 					Times(1).
 					Return(refreshToken, refreshTokenPayload, nil)
 				services.EXPECT().
@@ -451,11 +461,11 @@ func TestLogin(t *testing.T) {
 					Times(1).
 					Return(randomUser, nil)
 				tokenMaker.EXPECT().
-					CreateToken(req.Username, configs.TokenAccessTokenDuration).
+					CreateSessionToken(req.Username, models.RoleCustomer, gomock.Any(), configs.TokenAccessTokenDuration).
 					Times(1).
 					Return(accessToken, accessTokenPayload, nil)
 				tokenMaker.EXPECT().
-					CreateToken(req.Username, configs.TokenRefreshTokenDuration).
+					CreateRefreshToken(req.Username, models.RoleCustomer, gomock.Any(), configs.TokenRefreshTokenDuration).
 					Times(1).
 					Return(refreshToken, refreshTokenPayload, nil)
 				services.EXPECT().CreateSession(newSessionMatcher(session)).Return(session, nil)
@@ -478,7 +488,7 @@ func TestLogin(t *testing.T) {
 			},
 		},
 		{
-			name: "NotFound",
+			name: "UnknownUsernameUnAuthorized",
 			req: requests.LoginRequest{
 				Username: randomUser.Username,
 				Password: password,
@@ -490,7 +500,9 @@ func TestLogin(t *testing.T) {
 					Return(models.User{}, gorm.ErrRecordNotFound)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				// the response must not reveal that the username does not exist
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.JSONEq(t, `{"error":"incorrect username or password"}`, recorder.Body.String())
 			},
 		},
 		{
@@ -527,6 +539,32 @@ func TestLogin(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.JSONEq(t, `{"error":"incorrect username or password"}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "FrozenUserForbidden",
+			req: requests.LoginRequest{
+				Username: randomUser.Username,
+				Password: password,
+			},
+			buildStubs: func(
+				services *mockdb.MockServices,
+				tokenMaker *mocktokenmaker.MockMaker,
+				req requests.LoginRequest,
+			) {
+				frozenUser := randomUser
+				frozenAt := time.Now()
+				frozenUser.FrozenAt = &frozenAt
+				services.EXPECT().
+					GetUser(gomock.Eq(req.Username)).
+					Times(1).
+					Return(frozenUser, nil)
+				tokenMaker.EXPECT().CreateSessionToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				services.EXPECT().CreateSession(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 		{
@@ -545,7 +583,7 @@ func TestLogin(t *testing.T) {
 					Times(1).
 					Return(randomUser, nil)
 				tokenMaker.EXPECT().
-					CreateToken(req.Username, configs.TokenAccessTokenDuration).
+					CreateSessionToken(req.Username, models.RoleCustomer, gomock.Any(), configs.TokenAccessTokenDuration).
 					Times(1).
 					Return("", &token.Payload{}, errors.New("failed to encode payload to []byte"))
 			},
@@ -569,11 +607,11 @@ func TestLogin(t *testing.T) {
 					Times(1).
 					Return(randomUser, nil)
 				tokenMaker.EXPECT().
-					CreateToken(req.Username, configs.TokenAccessTokenDuration).
+					CreateSessionToken(req.Username, models.RoleCustomer, gomock.Any(), configs.TokenAccessTokenDuration).
 					Times(1).
 					Return(accessToken, accessTokenPayload, nil)
 				tokenMaker.EXPECT().
-					CreateToken(req.Username, configs.TokenRefreshTokenDuration).
+					CreateRefreshToken(req.Username, models.RoleCustomer, gomock.Any(), configs.TokenRefreshTokenDuration).
 					Times(1).
 					Return("", &token.Payload{}, errors.New("failed to encode payload to []byte"))
 			},
@@ -597,11 +635,11 @@ func TestLogin(t *testing.T) {
 					Times(1).
 					Return(randomUser, nil)
 				tokenMaker.EXPECT().
-					CreateToken(req.Username, configs.TokenAccessTokenDuration).
+					CreateSessionToken(req.Username, models.RoleCustomer, gomock.Any(), configs.TokenAccessTokenDuration).
 					Times(1).
 					Return(accessToken, accessTokenPayload, nil)
 				tokenMaker.EXPECT().
-					CreateToken(req.Username, configs.TokenRefreshTokenDuration).
+					CreateRefreshToken(req.Username, models.RoleCustomer, gomock.Any(), configs.TokenRefreshTokenDuration).
 					Times(1).
 					Return(refreshToken, refreshTokenPayload, nil)
 				services.EXPECT().CreateSession(newSessionMatcher(session)).Return(models.Session{}, sql.ErrConnDone)
@@ -659,6 +697,7 @@ func randomUser(t *testing.T) (models.User, string) {
 		CreatedAt:      time.Now().Truncate(time.Second).UTC(),
 		UpdatedAt:      time.Now().Truncate(time.Second).UTC(),
 		DeletedAt:      gorm.DeletedAt{},
+		Role:           models.RoleCustomer,
 	}, password
 }
 
@@ -753,3 +792,78 @@ func requireBodyMatchLogin(
 	require.NotEmpty(t, loginResponse.SessionID)
 	require.Equal(t, refreshTokenPayload.ID, loginResponse.SessionID)
 }
+
+func TestLoginLockedOut(t *testing.T) {
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	services := mockdb.NewMockServices(ctrl)
+	services.EXPECT().
+		GetLoginFailure(models.LoginFailureKindUsername, user.Username).
+		Times(1).
+		Return(models.LoginFailure{Failures: auth.DefaultMaxLoginFailures, LastFailedAt: time.Now()}, nil)
+	// the password is not even checked while the username is locked out
+	services.EXPECT().GetUser(gomock.Any()).Times(0)
+
+	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	require.NoError(t, err)
+	server := NewTestServer(t, services, tokenMaker)
+
+	body, err := json.Marshal(requests.LoginRequest{Username: user.Username, Password: password})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	server.RouterServeHTTP(recorder, request)
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+	retryAfter, err := strconv.Atoi(recorder.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	require.InDelta(t, auth.DefaultLockoutDuration.Seconds(), retryAfter, 2)
+}
+
+func TestLoginForgedForwardedAddress(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the failures count against the address the request came from, not the one it claims to come from
+	services := mockdb.NewMockServices(ctrl)
+	services.EXPECT().
+		GetLoginFailure(models.LoginFailureKindUsername, user.Username).
+		Times(1).
+		Return(models.LoginFailure{}, gorm.ErrRecordNotFound)
+	services.EXPECT().
+		GetLoginFailure(models.LoginFailureKindIP, "203.0.113.7").
+		Times(1).
+		Return(models.LoginFailure{}, gorm.ErrRecordNotFound)
+	services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+	services.EXPECT().
+		RecordLoginFailure(models.LoginFailureKindUsername, user.Username, gomock.Any()).
+		Times(1).
+		Return(models.LoginFailure{Failures: 1}, nil)
+	services.EXPECT().
+		RecordLoginFailure(models.LoginFailureKindIP, "203.0.113.7", gomock.Any()).
+		Times(1).
+		Return(models.LoginFailure{Failures: 1}, nil)
+
+	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	require.NoError(t, err)
+	server := NewTestServer(t, services, tokenMaker)
+
+	body, err := json.Marshal(requests.LoginRequest{Username: user.Username, Password: "wrong password"})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(body))
+	require.NoError(t, err)
+	request.RemoteAddr = "203.0.113.7:40000"
+	request.Header.Set("X-Forwarded-For", "198.51.100.1")
+	request.Header.Set("X-Real-IP", "198.51.100.1")
+
+	recorder := httptest.NewRecorder()
+	server.RouterServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
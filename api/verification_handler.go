@@ -0,0 +1,61 @@
+package api
+
+import (
+	"Simple-Bank/requests"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"Simple-Bank/verification"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+// VerifyEmail verifies the email of the user a token was emailed to.
+func (handler *Handler) VerifyEmail(context *gin.Context) {
+	var req requests.VerifyEmailRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := handler.emails.Verify(req.Token)
+	if err != nil {
+		if errors.Is(err, verification.ErrInvalidToken) {
+			context.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		context.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, responses.UserInformationResponse{
+		Username:      user.Username,
+		Email:         user.Email,
+		FullName:      user.FullName,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		CreatedAt:     user.CreatedAt.Local().Truncate(time.Second),
+		UpdatedAt:     user.UpdatedAt.Local().Truncate(time.Second),
+	})
+}
+
+// ResendVerificationEmail emails a new verification token to the user, e.g. when the previous one expired.
+func (handler *Handler) ResendVerificationEmail(context *gin.Context) {
+	authPayload := context.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if err := handler.emails.Resend(authPayload.Username); err != nil {
+		switch {
+		case errors.Is(err, verification.ErrAlreadyVerified):
+			context.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			context.JSON(http.StatusNotFound, errorResponse(err))
+		default:
+			context.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	context.Status(http.StatusNoContent)
+}
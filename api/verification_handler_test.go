@@ -0,0 +1,220 @@
+package api
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	servicesPackage "Simple-Bank/db/services"
+	"Simple-Bank/responses"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	user, _ := randomUser(t)
+	verificationToken := util.RandomString(64, util.LOWERCASE)
+	hashedToken := sha256.Sum256([]byte(verificationToken))
+	verification := models.EmailVerification{
+		ID:          1,
+		HashedToken: hex.EncodeToString(hashedToken[:]),
+		Username:    user.Username,
+		Email:       user.Email,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"token": verificationToken},
+			buildStubs: func(services *mockdb.MockServices) {
+				verifiedUser := user
+				verifiedUser.EmailVerified = true
+				services.EXPECT().GetEmailVerification(verification.HashedToken).Times(1).Return(verification, nil)
+				services.EXPECT().VerifyEmail(verification).Times(1).Return(verifiedUser, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response responses.UserInformationResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, user.Username, response.Username)
+				require.True(t, response.EmailVerified)
+			},
+		},
+		{
+			name: "UnknownToken",
+			body: gin.H{"token": verificationToken},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetEmailVerification(gomock.Any()).Times(1).Return(models.EmailVerification{}, gorm.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "EmailChanged",
+			body: gin.H{"token": verificationToken},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetEmailVerification(gomock.Any()).Times(1).Return(verification, nil)
+				services.EXPECT().VerifyEmail(gomock.Any()).Times(1).Return(models.User{}, servicesPackage.ErrEmailChanged)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoToken",
+			body: gin.H{},
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetEmailVerification(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(services)
+
+			tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+			require.NoError(t, err)
+			server := NewTestServer(t, services, tokenMaker)
+
+			body, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/users/verify_email", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestResendVerificationEmail(t *testing.T) {
+	user, _ := randomUser(t)
+	verifiedUser, _ := randomUser(t)
+	verifiedUser.EmailVerified = true
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	services := mockdb.NewMockServices(ctrl)
+	services.EXPECT().IsTokenRevoked(gomock.Any()).AnyTimes().Return(false, nil)
+	services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+	services.EXPECT().GetUser(verifiedUser.Username).Times(1).Return(verifiedUser, nil)
+	services.EXPECT().CreateEmailVerification(gomock.Any()).Times(1).Return(models.EmailVerification{}, nil)
+
+	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	require.NoError(t, err)
+	mailer := &testMailer{}
+	server, err := NewServer(configs, services, tokenMaker, mailer)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/users/verify_email/resend", nil)
+	require.NoError(t, err)
+	addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, request)
+	recorder := httptest.NewRecorder()
+	server.RouterServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	require.Len(t, mailer.messages, 1)
+	require.Equal(t, user.Email, mailer.messages[0].To)
+
+	request, err = http.NewRequest(http.MethodPost, "/users/verify_email/resend", nil)
+	require.NoError(t, err)
+	addAuthorization(t, tokenMaker, authorizationTypeBearer, verifiedUser.Username, time.Minute, request)
+	recorder = httptest.NewRecorder()
+	server.RouterServeHTTP(recorder, request)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	require.Len(t, mailer.messages, 1)
+}
+
+func TestTransferRequiresVerifiedEmail(t *testing.T) {
+	user, _ := randomUser(t)
+	account1 := createAccount(user.Username)
+	account2 := createAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		emailVerified bool
+		buildStubs    func(services *mockdb.MockServices)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:          "Verified",
+			emailVerified: true,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().Transfer(gomock.Any()).Times(1).Return(models.Transfer{ID: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:          "NotVerified",
+			emailVerified: false,
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().Transfer(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			services := mockdb.NewMockServices(ctrl)
+			verifiedUser := user
+			verifiedUser.EmailVerified = testCase.emailVerified
+			services.EXPECT().GetUser(user.Username).Times(1).Return(verifiedUser, nil)
+			testCase.buildStubs(services)
+
+			config := getTestConfig()
+			config.RequireEmailVerification = true
+			tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+			require.NoError(t, err)
+			server := NewTestServerWithConfig(t, config, services, tokenMaker)
+
+			body, err := json.Marshal(gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/accounts/transfer", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, tokenMaker, authorizationTypeBearer, user.Username, time.Minute, request)
+
+			recorder := httptest.NewRecorder()
+			server.RouterServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
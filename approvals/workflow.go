@@ -0,0 +1,261 @@
+package approvals
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/token"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultTTL is how long an operation waits for a decision when no ttl is configured.
+const DefaultTTL = 24 * time.Hour
+
+var (
+	// ErrUnknownOperation is returned when an approval holds an operation the workflow cannot execute
+	ErrUnknownOperation = errors.New("unknown operation")
+	// ErrExecutionFailed is returned when an approved operation could not be executed
+	ErrExecutionFailed = errors.New("approved operation failed")
+)
+
+// operationPermissions declares the permission required to approve or reject every operation.
+var operationPermissions = map[string]auth.Permission{
+	models.OperationAdjustBalance: auth.PermissionBackOffice,
+	models.OperationTransfer:      auth.PermissionApproveOperations,
+	models.OperationCloseAccount:  auth.PermissionApproveOperations,
+}
+
+// AdjustBalancePayload holds the parameters of a manual adjustment waiting for approval.
+type AdjustBalancePayload struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int32 `json:"amount"`
+}
+
+// TransferPayload holds the parameters of a transfer waiting for approval.
+type TransferPayload struct {
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int32  `json:"amount"`
+}
+
+// CloseAccountPayload holds the parameters of an account closure waiting for approval.
+type CloseAccountPayload struct {
+	Owner     string `json:"owner"`
+	AccountID int64  `json:"account_id"`
+}
+
+// Workflow holds sensitive operations until a user other than their requester approves them,
+// and only then executes them through services.Services.
+type Workflow struct {
+	services services.Services
+	ttl      time.Duration
+	// transferThreshold is the amount from which transfers require approval, zero if they never do
+	transferThreshold int32
+}
+
+// NewWorkflow creates a Workflow whose operations expire after ttl and where transfers of at least
+// transferThreshold require approval. A zero ttl uses DefaultTTL and a zero threshold disables the approval
+// of transfers.
+func NewWorkflow(services services.Services, ttl time.Duration, transferThreshold int32) *Workflow {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &Workflow{
+		services:          services,
+		ttl:               ttl,
+		transferThreshold: transferThreshold,
+	}
+}
+
+// TransferRequiresApproval reports whether a transfer of amount must be approved before it is executed.
+func (workflow *Workflow) TransferRequiresApproval(amount int32) bool {
+	return workflow.transferThreshold > 0 && amount >= workflow.transferThreshold
+}
+
+// RequestAdjustment stores a manual adjustment of the balance of a customer account until it is approved.
+func (workflow *Workflow) RequestAdjustment(requester, reason string, accountID int64, amount int32) (models.Approval, error) {
+	if amount == 0 {
+		return models.Approval{}, services.ErrInvalidAdjustment
+	}
+	account, err := workflow.services.GetAccount(accountID)
+	if err != nil {
+		return models.Approval{}, err
+	}
+	if account.IsSystem {
+		return models.Approval{}, fmt.Errorf("cannot adjust system account %d: %w", accountID, services.ErrInvalidAdjustment)
+	}
+
+	return workflow.request(models.OperationAdjustBalance, requester, reason, AdjustBalancePayload{
+		AccountID: accountID,
+		Amount:    amount,
+	})
+}
+
+// RequestTransfer stores a transfer until it is approved. The requester must own the source account.
+func (workflow *Workflow) RequestTransfer(req services.TransferRequest) (models.Approval, error) {
+	account, err := workflow.services.GetAccount(req.FromAccountID)
+	if err != nil {
+		return models.Approval{}, err
+	}
+	if account.Owner != req.Owner {
+		return models.Approval{}, services.ErrNotAccountOwner
+	}
+
+	reason := fmt.Sprintf("transfer of %d reaches the approval threshold of %d", req.Amount, workflow.transferThreshold)
+	return workflow.request(models.OperationTransfer, req.Owner, reason, TransferPayload{
+		Owner:         req.Owner,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+	})
+}
+
+// RequestAccountClosure stores the closure of an account until it is approved. The requester must own the account.
+func (workflow *Workflow) RequestAccountClosure(owner, reason string, accountID int64) (models.Approval, error) {
+	account, err := workflow.services.GetAccount(accountID)
+	if err != nil {
+		return models.Approval{}, err
+	}
+	if account.Owner != owner || account.IsSystem {
+		return models.Approval{}, services.ErrNotAccountOwner
+	}
+
+	return workflow.request(models.OperationCloseAccount, owner, reason, CloseAccountPayload{
+		Owner:     owner,
+		AccountID: accountID,
+	})
+}
+
+func (workflow *Workflow) request(operation, requester, reason string, payload any) (models.Approval, error) {
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return models.Approval{}, err
+	}
+
+	return workflow.services.CreateApproval(services.CreateApprovalRequest{
+		Operation:   operation,
+		Payload:     string(encodedPayload),
+		RequestedBy: requester,
+		Reason:      reason,
+		ExpiresAt:   time.Now().Add(workflow.ttl),
+	})
+}
+
+// CanDecide reports whether the user of a token is allowed to approve or reject the operation of an approval.
+func CanDecide(payload *token.Payload, approval models.Approval) bool {
+	permission, ok := operationPermissions[approval.Operation]
+	return ok && auth.HasPermission(payload, permission)
+}
+
+// Approve approves a pending operation on behalf of the user of a token and executes it.
+// If the execution fails, the failed approval is returned along with an error wrapping ErrExecutionFailed.
+func (workflow *Workflow) Approve(payload *token.Payload, id int64, reason string) (models.Approval, error) {
+	approval, err := workflow.decide(payload, id, reason, true)
+	if err != nil {
+		return approval, err
+	}
+
+	result, execErr := workflow.execute(approval)
+	failure := ""
+	if execErr != nil {
+		failure = execErr.Error()
+	}
+
+	approval, err = workflow.services.CompleteApproval(approval.ID, result, failure)
+	if err != nil {
+		return models.Approval{}, err
+	}
+	if execErr != nil {
+		return approval, fmt.Errorf("%w: %w", ErrExecutionFailed, execErr)
+	}
+
+	return approval, nil
+}
+
+// Reject rejects a pending operation on behalf of the user of a token.
+func (workflow *Workflow) Reject(payload *token.Payload, id int64, reason string) (models.Approval, error) {
+	return workflow.decide(payload, id, reason, false)
+}
+
+func (workflow *Workflow) decide(payload *token.Payload, id int64, reason string, approve bool) (models.Approval, error) {
+	approval, err := workflow.services.GetApproval(id)
+	if err != nil {
+		return models.Approval{}, err
+	}
+	if !CanDecide(payload, approval) {
+		return models.Approval{}, fmt.Errorf("%w: role %s cannot decide on %s", auth.ErrPermissionDenied, auth.RoleOf(payload), approval.Operation)
+	}
+
+	return workflow.services.DecideApproval(services.DecideApprovalRequest{
+		ID:        id,
+		Approve:   approve,
+		DecidedBy: payload.Username,
+		Reason:    reason,
+	})
+}
+
+// execute executes an approved operation and returns its JSON result.
+func (workflow *Workflow) execute(approval models.Approval) (string, error) {
+	var result any
+
+	switch approval.Operation {
+	case models.OperationAdjustBalance:
+		var payload AdjustBalancePayload
+		if err := json.Unmarshal([]byte(approval.Payload), &payload); err != nil {
+			return "", err
+		}
+
+		action := services.AdminAction{
+			Operator: approval.RequestedBy,
+			Reason:   fmt.Sprintf("%s (approval %d)", approval.Reason, approval.ID),
+		}
+		entry, err := workflow.services.AdjustBalance(action, payload.AccountID, payload.Amount)
+		if err != nil {
+			return "", err
+		}
+		result = map[string]any{"entry_id": entry.ID}
+
+	case models.OperationTransfer:
+		var payload TransferPayload
+		if err := json.Unmarshal([]byte(approval.Payload), &payload); err != nil {
+			return "", err
+		}
+
+		transfer, err := workflow.services.Transfer(services.TransferRequest{
+			Owner:         payload.Owner,
+			FromAccountID: payload.FromAccountID,
+			ToAccountID:   payload.ToAccountID,
+			Amount:        payload.Amount,
+		})
+		if err != nil {
+			if errors.Is(err, services.ErrTransferFailed) {
+				return "", fmt.Errorf("transfer %d failed: %s", transfer.ID, transfer.StatusReason)
+			}
+			return "", err
+		}
+		result = map[string]any{"transfer_id": transfer.ID}
+
+	case models.OperationCloseAccount:
+		var payload CloseAccountPayload
+		if err := json.Unmarshal([]byte(approval.Payload), &payload); err != nil {
+			return "", err
+		}
+
+		account, err := workflow.services.DeleteAccount(payload.AccountID)
+		if err != nil {
+			return "", err
+		}
+		result = map[string]any{"account_id": account.ID}
+
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownOperation, approval.Operation)
+	}
+
+	encodedResult, err := json.Marshal(result)
+	return string(encodedResult), err
+}
@@ -0,0 +1,132 @@
+package approvals
+
+import (
+	"Simple-Bank/auth"
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestTransferRequiresApproval(t *testing.T) {
+	workflow := NewWorkflow(nil, 0, 1000)
+	require.Equal(t, DefaultTTL, workflow.ttl)
+	require.False(t, workflow.TransferRequiresApproval(999))
+	require.True(t, workflow.TransferRequiresApproval(1000))
+
+	// a zero threshold disables the approval of transfers
+	require.False(t, NewWorkflow(nil, time.Hour, 0).TransferRequiresApproval(1<<30))
+}
+
+func TestRequestTransferNotAccountOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	workflow := NewWorkflow(mockServices, time.Hour, 1000)
+
+	mockServices.EXPECT().GetAccount(int64(1)).Times(1).Return(models.Account{ID: 1, Owner: "owner"}, nil)
+	mockServices.EXPECT().CreateApproval(gomock.Any()).Times(0)
+
+	_, err := workflow.RequestTransfer(services.TransferRequest{
+		Owner:         "someone_else",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        1000,
+	})
+	require.ErrorIs(t, err, services.ErrNotAccountOwner)
+}
+
+func TestApproveAdjustment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	workflow := NewWorkflow(mockServices, time.Hour, 0)
+
+	approver := &token.Payload{Username: util.RandomUsername(), Role: models.RoleAdmin}
+	approval := models.Approval{
+		ID:          3,
+		Operation:   models.OperationAdjustBalance,
+		Payload:     `{"account_id":5,"amount":-20}`,
+		Status:      models.ApprovalStatusPending,
+		RequestedBy: util.RandomUsername(),
+		Reason:      "duplicate fee",
+	}
+	approved := approval
+	approved.Status = models.ApprovalStatusApproved
+
+	mockServices.EXPECT().GetApproval(approval.ID).Times(1).Return(approval, nil)
+	mockServices.EXPECT().
+		DecideApproval(services.DecideApprovalRequest{ID: approval.ID, Approve: true, DecidedBy: approver.Username, Reason: "ok"}).
+		Times(1).
+		Return(approved, nil)
+	mockServices.EXPECT().
+		AdjustBalance(services.AdminAction{Operator: approval.RequestedBy, Reason: "duplicate fee (approval 3)"}, int64(5), int32(-20)).
+		Times(1).
+		Return(models.Entry{ID: 9}, nil)
+	mockServices.EXPECT().
+		CompleteApproval(approval.ID, `{"entry_id":9}`, "").
+		Times(1).
+		Return(models.Approval{Status: models.ApprovalStatusExecuted}, nil)
+
+	executed, err := workflow.Approve(approver, approval.ID, "ok")
+	require.NoError(t, err)
+	require.Equal(t, models.ApprovalStatusExecuted, executed.Status)
+}
+
+func TestApproveExecutionFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	workflow := NewWorkflow(mockServices, time.Hour, 0)
+
+	approver := &token.Payload{Username: util.RandomUsername(), Role: models.RoleTeller}
+	approval := models.Approval{
+		ID:          4,
+		Operation:   models.OperationCloseAccount,
+		Payload:     `{"owner":"owner","account_id":5}`,
+		Status:      models.ApprovalStatusApproved,
+		RequestedBy: "owner",
+	}
+	closeErr := errors.New("connection lost")
+
+	mockServices.EXPECT().GetApproval(approval.ID).Times(1).Return(approval, nil)
+	mockServices.EXPECT().DecideApproval(gomock.Any()).Times(1).Return(approval, nil)
+	mockServices.EXPECT().DeleteAccount(int64(5)).Times(1).Return(models.Account{}, closeErr)
+	mockServices.EXPECT().
+		CompleteApproval(approval.ID, "", closeErr.Error()).
+		Times(1).
+		Return(models.Approval{Status: models.ApprovalStatusFailed}, nil)
+
+	failed, err := workflow.Approve(approver, approval.ID, "ok")
+	require.ErrorIs(t, err, ErrExecutionFailed)
+	require.ErrorIs(t, err, closeErr)
+	require.Equal(t, models.ApprovalStatusFailed, failed.Status)
+}
+
+func TestCanDecide(t *testing.T) {
+	customer := &token.Payload{Username: util.RandomUsername(), Role: models.RoleCustomer}
+	teller := &token.Payload{Username: util.RandomUsername(), Role: models.RoleTeller}
+	admin := &token.Payload{Username: util.RandomUsername(), Role: models.RoleAdmin}
+
+	for operation := range operationPermissions {
+		approval := models.Approval{Operation: operation}
+		require.False(t, CanDecide(customer, approval), operation)
+		require.True(t, CanDecide(admin, approval), operation)
+	}
+
+	require.True(t, CanDecide(teller, models.Approval{Operation: models.OperationTransfer}))
+	require.False(t, CanDecide(teller, models.Approval{Operation: models.OperationAdjustBalance}))
+	require.False(t, CanDecide(admin, models.Approval{Operation: "unknown"}))
+
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	mockServices.EXPECT().GetApproval(int64(1)).Times(1).Return(models.Approval{ID: 1, Operation: models.OperationAdjustBalance}, nil)
+	mockServices.EXPECT().DecideApproval(gomock.Any()).Times(0)
+
+	_, err := NewWorkflow(mockServices, time.Hour, 0).Reject(teller, 1, fmt.Sprintf("rejected by %s", teller.Username))
+	require.ErrorIs(t, err, auth.ErrPermissionDenied)
+}
@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/token"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// apiKeyMarker starts every api key, so leaked keys are easy to recognize
+	apiKeyMarker = "sbk"
+	// apiKeyPrefixBytes and apiKeySecretBytes are the number of random bytes of the prefix and the secret of a key
+	apiKeyPrefixBytes = 6
+	apiKeySecretBytes = 32
+	// lastUsedInterval is how often the last use of an api key is recorded, so busy keys do not write on every request
+	lastUsedInterval = time.Minute
+)
+
+var (
+	// ErrInvalidAPIKey is returned when an api key is malformed, unknown, revoked or expired
+	ErrInvalidAPIKey = errors.New("api key is invalid")
+	// ErrAddressNotAllowed is returned when an api key is used from an address missing from its allowlist
+	ErrAddressNotAllowed = errors.New("api key cannot be used from this address")
+)
+
+// NewAPIKey generates an api key for a user. It returns the key, which must be shown to the user once since only
+// its hash is stored, and the model to store. Scopes and allowed addresses must have been validated.
+func NewAPIKey(owner, name string, scopes, allowedIPs []string, expiresAt *time.Time) (string, models.APIKey, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", models.APIKey{}, err
+	}
+	prefix, err := randomHex(apiKeyPrefixBytes)
+	if err != nil {
+		return "", models.APIKey{}, err
+	}
+	secret, err := randomHex(apiKeySecretBytes)
+	if err != nil {
+		return "", models.APIKey{}, err
+	}
+
+	key := models.APIKey{
+		ID:           id,
+		Owner:        owner,
+		Name:         name,
+		Prefix:       prefix,
+		HashedSecret: hashAPIKeySecret(secret),
+		Scopes:       strings.Join(scopes, " "),
+		AllowedIPs:   strings.Join(allowedIPs, " "),
+		ExpiresAt:    expiresAt,
+	}
+
+	return fmt.Sprintf("%s_%s_%s", apiKeyMarker, prefix, secret), key, nil
+}
+
+// ValidateScopes checks the scopes requested for a credential.
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !IsValidScope(scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+
+	return nil
+}
+
+// ValidateAllowedIPs checks that every entry of an allowlist is an address or a CIDR range.
+func ValidateAllowedIPs(allowedIPs []string) error {
+	for _, allowed := range allowedIPs {
+		if _, _, err := net.ParseCIDR(allowed); err == nil {
+			continue
+		}
+		if net.ParseIP(allowed) == nil {
+			return fmt.Errorf("%q is neither an address nor a CIDR range", allowed)
+		}
+	}
+
+	return nil
+}
+
+// APIKeyAuthenticator authenticates the requests made with api keys.
+type APIKeyAuthenticator struct {
+	services services.Services
+}
+
+// NewAPIKeyAuthenticator creates an APIKeyAuthenticator looking keys up through services.
+func NewAPIKeyAuthenticator(services services.Services) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{services: services}
+}
+
+// Authenticate checks an api key used from clientIP and returns the payload of its owner, restricted to its scopes.
+// It returns an error wrapping ErrInvalidAPIKey or ErrAddressNotAllowed if the key cannot be used.
+func (authenticator *APIKeyAuthenticator) Authenticate(rawKey, clientIP string) (*token.Payload, error) {
+	parts := strings.SplitN(rawKey, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyMarker {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := authenticator.services.GetAPIKeyByPrefix(parts[1])
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("cannot check api key: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKeySecret(parts[2])), []byte(key.HashedSecret)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+	if key.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: api key has been revoked", ErrInvalidAPIKey)
+	}
+	now := time.Now()
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, fmt.Errorf("%w: api key has expired", ErrInvalidAPIKey)
+	}
+	if !isAddressAllowed(key.AllowedIPList(), clientIP) {
+		return nil, ErrAddressNotAllowed
+	}
+
+	user, err := authenticator.services.GetUser(key.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("cannot check api key: %w", err)
+	}
+	if user.FrozenAt != nil {
+		return nil, fmt.Errorf("%w: user is frozen", ErrInvalidAPIKey)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval {
+		// failing to record the use must not fail the request
+		if err := authenticator.services.TouchAPIKey(key.ID, clientIP, now.UTC()); err != nil {
+			log.Error().Err(err).Str("api_key_id", key.ID.String()).Msg("cannot record the use of an api key")
+		}
+	}
+
+	// the key only acts on the resources of its owner, whatever the role of the owner
+	payload := &token.Payload{
+		ID:       key.ID,
+		Username: user.Username,
+		Role:     models.RoleCustomer,
+		Scopes:   key.ScopeList(),
+		APIKeyID: key.ID,
+		IssuedAt: key.CreatedAt,
+	}
+	if key.ExpiresAt != nil {
+		payload.ExpiredAt = *key.ExpiresAt
+	}
+
+	return payload, nil
+}
+
+// hashAPIKeySecret hashes the secret of an api key. Unlike passwords, secrets are long random strings,
+// so a fast hash is enough and keeps the authentication of every request cheap.
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// isAddressAllowed reports whether clientIP, with or without a port, matches an entry of the allowlist.
+// An empty allowlist allows every address.
+func isAddressAllowed(allowedIPs []string, clientIP string) bool {
+	if len(allowedIPs) == 0 {
+		return true
+	}
+
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		clientIP = host
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+
+	for _, allowed := range allowedIPs {
+		if _, network, err := net.ParseCIDR(allowed); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if ip.Equal(net.ParseIP(allowed)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func randomHex(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	user := models.User{Username: util.RandomUsername(), Role: models.RoleAdmin}
+	rawKey, key, err := NewAPIKey(user.Username, "partner", []string{string(ScopeAccountsRead)}, []string{"10.0.0.0/8", "192.168.1.7"}, nil)
+	require.NoError(t, err)
+	require.NotContains(t, key.HashedSecret, rawKey)
+
+	revokedAt := time.Now().Add(-time.Minute)
+	expiresAt := time.Now().Add(-time.Second)
+	lastUsedAt := time.Now().Add(-time.Second)
+	frozenAt := time.Now()
+
+	testCases := []struct {
+		name       string
+		rawKey     string
+		clientIP   string
+		buildStubs func(services *mockdb.MockServices)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name:     "OK",
+			rawKey:   rawKey,
+			clientIP: "10.1.2.3:4567",
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAPIKeyByPrefix(key.Prefix).Times(1).Return(key, nil)
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				services.EXPECT().TouchAPIKey(key.ID, "10.1.2.3:4567", gomock.Any()).Times(1).Return(nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "RecentlyUsed",
+			rawKey:   rawKey,
+			clientIP: "192.168.1.7",
+			buildStubs: func(services *mockdb.MockServices) {
+				used := key
+				used.LastUsedAt = &lastUsedAt
+				services.EXPECT().GetAPIKeyByPrefix(key.Prefix).Times(1).Return(used, nil)
+				services.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				services.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "Malformed",
+			rawKey:   "not-a-key",
+			clientIP: "10.1.2.3",
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAPIKeyByPrefix(gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidAPIKey)
+			},
+		},
+		{
+			name:     "UnknownPrefix",
+			rawKey:   rawKey,
+			clientIP: "10.1.2.3",
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAPIKeyByPrefix(key.Prefix).Times(1).Return(models.APIKey{}, gorm.ErrRecordNotFound)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidAPIKey)
+			},
+		},
+		{
+			name:     "WrongSecret",
+			rawKey:   rawKey[:len(rawKey)-1] + "x",
+			clientIP: "10.1.2.3",
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAPIKeyByPrefix(key.Prefix).Times(1).Return(key, nil)
+				services.EXPECT().GetUser(gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidAPIKey)
+			},
+		},
+		{
+			name:     "Revoked",
+			rawKey:   rawKey,
+			clientIP: "10.1.2.3",
+			buildStubs: func(services *mockdb.MockServices) {
+				revoked := key
+				revoked.RevokedAt = &revokedAt
+				services.EXPECT().GetAPIKeyByPrefix(key.Prefix).Times(1).Return(revoked, nil)
+				services.EXPECT().GetUser(gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidAPIKey)
+			},
+		},
+		{
+			name:     "Expired",
+			rawKey:   rawKey,
+			clientIP: "10.1.2.3",
+			buildStubs: func(services *mockdb.MockServices) {
+				expired := key
+				expired.ExpiresAt = &expiresAt
+				services.EXPECT().GetAPIKeyByPrefix(key.Prefix).Times(1).Return(expired, nil)
+				services.EXPECT().GetUser(gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidAPIKey)
+			},
+		},
+		{
+			name:     "AddressNotAllowed",
+			rawKey:   rawKey,
+			clientIP: "192.168.1.8",
+			buildStubs: func(services *mockdb.MockServices) {
+				services.EXPECT().GetAPIKeyByPrefix(key.Prefix).Times(1).Return(key, nil)
+				services.EXPECT().GetUser(gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrAddressNotAllowed)
+			},
+		},
+		{
+			name:     "FrozenUser",
+			rawKey:   rawKey,
+			clientIP: "10.1.2.3",
+			buildStubs: func(services *mockdb.MockServices) {
+				frozen := user
+				frozen.FrozenAt = &frozenAt
+				services.EXPECT().GetAPIKeyByPrefix(key.Prefix).Times(1).Return(key, nil)
+				services.EXPECT().GetUser(user.Username).Times(1).Return(frozen, nil)
+				services.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidAPIKey)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			services := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(services)
+
+			payload, err := NewAPIKeyAuthenticator(services).Authenticate(testCase.rawKey, testCase.clientIP)
+			testCase.checkError(t, err)
+			if err == nil {
+				require.Equal(t, user.Username, payload.Username)
+				require.Equal(t, key.ID, payload.APIKeyID)
+				require.Equal(t, []string{string(ScopeAccountsRead)}, payload.Scopes)
+				// the key of an admin only acts on the resources of the admin
+				require.Equal(t, models.RoleCustomer, payload.Role)
+			}
+		})
+	}
+}
+
+func TestValidateAllowedIPs(t *testing.T) {
+	require.NoError(t, ValidateAllowedIPs([]string{"10.0.0.0/8", "::1", "192.168.1.7"}))
+	require.Error(t, ValidateAllowedIPs([]string{"10.0.0.0/33"}))
+	require.Error(t, ValidateAllowedIPs([]string{"example.com"}))
+}
+
+func TestAuthorizeScope(t *testing.T) {
+	payload := newPayload(t)
+
+	// payloads without scopes, e.g. of an interactive login, are not restricted
+	require.NoError(t, AuthorizeScope(payload, ScopeTransfersWrite))
+	require.NoError(t, AuthorizeScope(payload, ""))
+
+	payload.Scopes = []string{string(ScopeAccountsRead)}
+	require.NoError(t, AuthorizeScope(payload, ScopeAccountsRead))
+	require.ErrorIs(t, AuthorizeScope(payload, ScopeTransfersWrite), ErrInsufficientScope)
+	require.ErrorIs(t, AuthorizeScope(payload, ""), ErrInsufficientScope)
+
+	require.NoError(t, ValidateScopes([]string{"accounts:read", "transfers:write"}))
+	require.Error(t, ValidateScopes([]string{"accounts:delete"}))
+	require.Error(t, ValidateScopes([]string{"reports:read"}))
+	require.Error(t, ValidateScopes(nil))
+}
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/util"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxLoginFailures is the number of failed logins of a username locking it out when none is configured
+	DefaultMaxLoginFailures = 5
+	// DefaultMaxIPLoginFailures is the number of failed logins from an address locking it out when none is configured
+	DefaultMaxIPLoginFailures = 50
+	// DefaultLockoutDuration is how long lockouts last when no duration is configured
+	DefaultLockoutDuration = 15 * time.Minute
+	// loginFailureWindow is how long failed logins are remembered after the last one, so a user locked out once
+	// is locked out again after a single failure until they log in successfully or the failures are forgotten
+	loginFailureWindow = 24 * time.Hour
+	// firstLoginDelay is the delay after the first failure counted towards a lockout, doubling with every failure
+	firstLoginDelay = time.Second
+)
+
+var (
+	// ErrInvalidCredentials is returned for a wrong password and for a username that does not exist alike,
+	// so responses do not reveal which usernames exist
+	ErrInvalidCredentials = errors.New("incorrect username or password")
+	// ErrLoginLocked is returned when a username or an address has failed to log in too many times
+	ErrLoginLocked = errors.New("too many failed logins")
+)
+
+// LockedError is the error of a login refused because of previous failures. It wraps ErrLoginLocked.
+type LockedError struct {
+	// RetryAfter is how long to wait before trying again
+	RetryAfter time.Duration
+}
+
+func (err *LockedError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrLoginLocked, err.RetryAfter.Round(time.Second))
+}
+
+func (err *LockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// dummyPasswordHash is checked when a username does not exist, so such logins take as long as wrong passwords
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hashedPassword, _ := util.HashPassword("password of a user that does not exist")
+	return hashedPassword
+})
+
+// LoginAuthenticator checks the passwords of users logging in, and slows down password guessing by counting
+// failed logins per username and per client address. Once half of the allowed failures are reached, every
+// failure delays the next attempt twice as long as the previous one, up to a lockout once all are reached.
+// A successful login forgets the failures of the username, not those of the address.
+type LoginAuthenticator struct {
+	services services.Services
+	// maxFailures and maxIPFailures are the failures locking out a username and an address
+	maxFailures   int32
+	maxIPFailures int32
+	lockout       time.Duration
+}
+
+// NewLoginAuthenticator creates a LoginAuthenticator locking out usernames after maxFailures failed logins and
+// addresses after maxIPFailures, for lockout. Zero values use the defaults.
+func NewLoginAuthenticator(services services.Services, maxFailures, maxIPFailures int32, lockout time.Duration) *LoginAuthenticator {
+	if maxFailures <= 0 {
+		maxFailures = DefaultMaxLoginFailures
+	}
+	if maxIPFailures <= 0 {
+		maxIPFailures = DefaultMaxIPLoginFailures
+	}
+	if lockout <= 0 {
+		lockout = DefaultLockoutDuration
+	}
+
+	return &LoginAuthenticator{
+		services:      services,
+		maxFailures:   maxFailures,
+		maxIPFailures: maxIPFailures,
+		lockout:       lockout,
+	}
+}
+
+// Authenticate returns the user with username if password is theirs. It returns ErrInvalidCredentials if the
+// username does not exist or the password is wrong, and a *LockedError without checking the password if the
+// username or clientIP failed too many times. Passwords hashed with outdated parameters are rehashed.
+// clientIP must be the address the request came from, or the one reported by a trusted proxy, never an address
+// taken from headers the client controls, which would let it spread its failures over made up addresses.
+func (authenticator *LoginAuthenticator) Authenticate(username, password, clientIP string) (models.User, error) {
+	clientIP = normalizeIP(clientIP)
+
+	if err := authenticator.checkLocked(models.LoginFailureKindUsername, username, authenticator.maxFailures); err != nil {
+		return models.User{}, err
+	}
+	if clientIP != "" {
+		if err := authenticator.checkLocked(models.LoginFailureKindIP, clientIP, authenticator.maxIPFailures); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	user, err := authenticator.services.GetUser(username)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, err
+		}
+		_ = util.CheckPassword(password, dummyPasswordHash())
+		return models.User{}, authenticator.fail(username, clientIP)
+	}
+
+	if err := util.CheckPassword(password, user.HashedPassword); err != nil {
+		return models.User{}, authenticator.fail(username, clientIP)
+	}
+
+	if err := authenticator.services.ClearLoginFailures(models.LoginFailureKindUsername, username); err != nil {
+		return models.User{}, err
+	}
+
+	if util.PasswordNeedsRehash(user.HashedPassword) {
+		authenticator.rehash(user.Username, password, user.HashedPassword)
+	}
+
+	return user, nil
+}
+
+// rehash replaces an outdated password hash with one using the current hashing algorithm and parameters.
+// Failures only keep the outdated hash until the next login, so they are logged rather than failing the login.
+func (authenticator *LoginAuthenticator) rehash(username, password, oldHash string) {
+	newHash, err := util.HashPassword(password)
+	if err == nil {
+		err = authenticator.services.RehashPassword(username, oldHash, newHash)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("cannot rehash password")
+	}
+}
+
+// checkLocked returns a *LockedError if the subject must wait before trying again.
+func (authenticator *LoginAuthenticator) checkLocked(kind, subject string, maxFailures int32) error {
+	failure, err := authenticator.services.GetLoginFailure(kind, subject)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if time.Since(failure.LastFailedAt) > loginFailureWindow {
+		return nil
+	}
+
+	lockedUntil := failure.LastFailedAt.Add(authenticator.delay(failure.Failures, maxFailures))
+	if retryAfter := time.Until(lockedUntil); retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// fail records a failed login and returns ErrInvalidCredentials, or the error recording it.
+func (authenticator *LoginAuthenticator) fail(username, clientIP string) error {
+	forgetBefore := time.Now().Add(-loginFailureWindow)
+
+	if _, err := authenticator.services.RecordLoginFailure(models.LoginFailureKindUsername, username, forgetBefore); err != nil {
+		return err
+	}
+	if clientIP != "" {
+		if _, err := authenticator.services.RecordLoginFailure(models.LoginFailureKindIP, clientIP, forgetBefore); err != nil {
+			return err
+		}
+	}
+
+	return ErrInvalidCredentials
+}
+
+// delay returns how long to wait after the last of failures before trying again.
+func (authenticator *LoginAuthenticator) delay(failures, maxFailures int32) time.Duration {
+	if failures >= maxFailures {
+		return authenticator.lockout
+	}
+
+	progressiveFrom := maxFailures / 2
+	if failures < progressiveFrom {
+		return 0
+	}
+
+	// shifting further would overflow, and the delay would be years long anyway
+	shift := failures - progressiveFrom
+	if shift > 30 {
+		return authenticator.lockout
+	}
+
+	delay := firstLoginDelay << shift
+	if delay > authenticator.lockout {
+		return authenticator.lockout
+	}
+
+	return delay
+}
+
+// normalizeIP removes the port of addresses, e.g. the peer addresses of grpc requests, and returns them in their
+// canonical form, so that every spelling of an address shares its failures. Anything else than an address, e.g. a
+// list of forwarded addresses, returns an empty string and is not tracked.
+func normalizeIP(clientIP string) string {
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		clientIP = host
+	}
+
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return ""
+	}
+
+	return ip.String()
+}
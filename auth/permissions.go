@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/token"
+	"errors"
+	"fmt"
+)
+
+// ErrPermissionDenied is returned when the role of a user does not have a required permission
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Permission allows a user to act on resources of other users.
+// Every user can act on their own resources without any permission.
+type Permission string
+
+// permissions granted to roles
+const (
+	// PermissionReadAccounts allows reading any account and its balance
+	PermissionReadAccounts Permission = "accounts:read"
+	// PermissionDeposit allows depositing money into any account
+	PermissionDeposit Permission = "accounts:deposit"
+	// PermissionReadTransfers allows reading any transfer
+	PermissionReadTransfers Permission = "transfers:read"
+	// PermissionReadUsers allows reading the information of any user
+	PermissionReadUsers Permission = "users:read"
+	// PermissionReadReports allows reading the reports of the bank, e.g. the trial balance
+	PermissionReadReports Permission = "reports:read"
+	// PermissionBackOffice allows using the back office, e.g. freezing users and adjusting balances
+	PermissionBackOffice Permission = "backoffice"
+	// PermissionApproveOperations allows approving or rejecting the operations of other users,
+	// e.g. large transfers and account closures
+	PermissionApproveOperations Permission = "operations:approve"
+)
+
+// rolePermissions declares the permissions of every role.
+var rolePermissions = map[string][]Permission{
+	models.RoleCustomer: {},
+	models.RoleTeller: {
+		PermissionReadAccounts,
+		PermissionDeposit,
+		PermissionReadUsers,
+		PermissionApproveOperations,
+	},
+	models.RoleAuditor: {
+		PermissionReadAccounts,
+		PermissionReadTransfers,
+		PermissionReadUsers,
+		PermissionReadReports,
+	},
+	models.RoleAdmin: {
+		PermissionReadAccounts,
+		PermissionDeposit,
+		PermissionReadTransfers,
+		PermissionReadUsers,
+		PermissionReadReports,
+		PermissionBackOffice,
+		PermissionApproveOperations,
+	},
+}
+
+// RoleOf returns the role of the user of a token. Tokens issued before roles existed belong to customers, and so do
+// scoped credentials, e.g. api keys and oauth tokens: they are delegated to other applications, which only act on
+// the resources of their owner, whatever the role of the owner.
+func RoleOf(payload *token.Payload) string {
+	if payload.Role == "" || IsScoped(payload) {
+		return models.RoleCustomer
+	}
+
+	return payload.Role
+}
+
+// HasPermission reports whether the role of the user of a token has the given permission.
+func HasPermission(payload *token.Payload, permission Permission) bool {
+	for _, granted := range rolePermissions[RoleOf(payload)] {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authorize returns an error wrapping ErrPermissionDenied if the role of the user of a token
+// does not have the given permission.
+func Authorize(payload *token.Payload, permission Permission) error {
+	if !HasPermission(payload, permission) {
+		return fmt.Errorf("%w: role %s does not have permission %s", ErrPermissionDenied, RoleOf(payload), permission)
+	}
+
+	return nil
+}
+
+// CanAccess reports whether the user of a token can access a resource of the given owner:
+// users can access their own resources, and the resources of other users with the given permission.
+func CanAccess(payload *token.Payload, owner string, permission Permission) bool {
+	return payload.Username == owner || HasPermission(payload, permission)
+}
+
+// IsValidRole reports whether role is one of the roles of a user.
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
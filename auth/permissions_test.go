@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestHasPermission(t *testing.T) {
+	customer := &token.Payload{Username: util.RandomUsername(), Role: models.RoleCustomer}
+	legacy := &token.Payload{Username: util.RandomUsername()}
+	teller := &token.Payload{Username: util.RandomUsername(), Role: models.RoleTeller}
+	auditor := &token.Payload{Username: util.RandomUsername(), Role: models.RoleAuditor}
+	admin := &token.Payload{Username: util.RandomUsername(), Role: models.RoleAdmin}
+	unknown := &token.Payload{Username: util.RandomUsername(), Role: "root"}
+
+	require.False(t, HasPermission(customer, PermissionReadAccounts))
+	require.False(t, HasPermission(legacy, PermissionReadAccounts))
+	require.Equal(t, models.RoleCustomer, RoleOf(legacy))
+	require.False(t, HasPermission(unknown, PermissionReadAccounts))
+
+	require.True(t, HasPermission(teller, PermissionDeposit))
+	require.True(t, HasPermission(teller, PermissionReadAccounts))
+	require.False(t, HasPermission(teller, PermissionReadReports))
+
+	require.True(t, HasPermission(auditor, PermissionReadAccounts))
+	require.True(t, HasPermission(auditor, PermissionReadReports))
+	require.False(t, HasPermission(auditor, PermissionDeposit))
+
+	require.True(t, HasPermission(admin, PermissionDeposit))
+	require.True(t, HasPermission(admin, PermissionReadReports))
+	require.True(t, HasPermission(admin, PermissionBackOffice))
+	require.False(t, HasPermission(auditor, PermissionBackOffice))
+
+	require.True(t, HasPermission(teller, PermissionApproveOperations))
+	require.True(t, HasPermission(admin, PermissionApproveOperations))
+	require.False(t, HasPermission(auditor, PermissionApproveOperations))
+	require.False(t, HasPermission(customer, PermissionApproveOperations))
+
+	require.ErrorIs(t, Authorize(customer, PermissionDeposit), ErrPermissionDenied)
+	require.NoError(t, Authorize(teller, PermissionDeposit))
+}
+
+func TestHasPermissionScoped(t *testing.T) {
+	// scoped credentials of an admin only act on the resources of the admin
+	admin := &token.Payload{Username: util.RandomUsername(), Role: models.RoleAdmin, Scopes: []string{string(ScopeAccountsRead)}}
+	customer := &token.Payload{Username: util.RandomUsername(), Role: models.RoleCustomer}
+
+	require.Equal(t, models.RoleCustomer, RoleOf(admin))
+	require.False(t, HasPermission(admin, PermissionReadAccounts))
+	require.False(t, HasPermission(admin, PermissionBackOffice))
+	require.ErrorIs(t, Authorize(admin, PermissionReadReports), ErrPermissionDenied)
+	require.True(t, CanAccess(admin, admin.Username, PermissionReadAccounts))
+	require.False(t, CanAccess(admin, customer.Username, PermissionReadAccounts))
+}
+
+func TestCanAccess(t *testing.T) {
+	customer := &token.Payload{Username: util.RandomUsername(), Role: models.RoleCustomer}
+	auditor := &token.Payload{Username: util.RandomUsername(), Role: models.RoleAuditor}
+
+	require.True(t, CanAccess(customer, customer.Username, PermissionReadAccounts))
+	require.False(t, CanAccess(customer, auditor.Username, PermissionReadAccounts))
+	require.True(t, CanAccess(auditor, customer.Username, PermissionReadAccounts))
+}
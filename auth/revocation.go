@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/token"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// DefaultRevocationCacheTTL is used when no cache ttl is configured.
+const DefaultRevocationCacheTTL = 5 * time.Second
+
+var (
+	// ErrInactiveSession is returned when the session of an access token is blocked, expired or missing
+	ErrInactiveSession = errors.New("session is not active")
+	// ErrRevokedToken is returned when the token has been revoked
+	ErrRevokedToken = errors.New("token has been revoked")
+)
+
+// RevocationChecker checks that tokens and their sessions have not been revoked.
+// Lookups are cached for a short time, so a revocation made by another server takes effect
+// at most one ttl later, while a revocation made through Reset takes effect immediately.
+type RevocationChecker struct {
+	services services.Services
+	ttl      time.Duration
+
+	mutex     sync.Mutex
+	sessions  map[uuid.UUID]cachedSession
+	tokens    map[uuid.UUID]cachedToken
+	lastSweep time.Time
+}
+
+type cachedSession struct {
+	session  models.Session
+	cachedAt time.Time
+}
+
+type cachedToken struct {
+	revoked  bool
+	cachedAt time.Time
+}
+
+// NewRevocationChecker creates a RevocationChecker caching lookups for ttl.
+// A zero ttl uses DefaultRevocationCacheTTL.
+func NewRevocationChecker(services services.Services, ttl time.Duration) *RevocationChecker {
+	if ttl <= 0 {
+		ttl = DefaultRevocationCacheTTL
+	}
+
+	return &RevocationChecker{
+		services: services,
+		ttl:      ttl,
+		sessions: make(map[uuid.UUID]cachedSession),
+		tokens:   make(map[uuid.UUID]cachedToken),
+	}
+}
+
+// Check returns an error wrapping ErrRevokedToken or ErrInactiveSession if the token of payload cannot be used anymore.
+// Tokens issued before access tokens were bound to sessions carry no session id and only the denylist is checked.
+func (checker *RevocationChecker) Check(payload *token.Payload) error {
+	revoked, err := checker.isTokenRevoked(payload.ID)
+	if err != nil {
+		return fmt.Errorf("cannot check token: %w", err)
+	}
+	if revoked {
+		return ErrRevokedToken
+	}
+
+	if payload.SessionID == uuid.Nil {
+		return nil
+	}
+
+	session, err := checker.getSession(payload.SessionID)
+	if err != nil {
+		return fmt.Errorf("cannot check session: %w", err)
+	}
+	if session.ID != payload.SessionID || session.Username != payload.Username {
+		return fmt.Errorf("%w: session not found", ErrInactiveSession)
+	}
+	if session.IsBlocked {
+		return fmt.Errorf("%w: session is blocked", ErrInactiveSession)
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return fmt.Errorf("%w: session has expired", ErrInactiveSession)
+	}
+
+	return nil
+}
+
+// Reset forgets every cached lookup. It is called after a session or a token is revoked,
+// so the revocation takes effect immediately on this server.
+func (checker *RevocationChecker) Reset() {
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+
+	checker.sessions = make(map[uuid.UUID]cachedSession)
+	checker.tokens = make(map[uuid.UUID]cachedToken)
+}
+
+func (checker *RevocationChecker) isTokenRevoked(tokenID uuid.UUID) (bool, error) {
+	checker.mutex.Lock()
+	cached, ok := checker.tokens[tokenID]
+	checker.mutex.Unlock()
+	if ok && time.Since(cached.cachedAt) < checker.ttl {
+		return cached.revoked, nil
+	}
+
+	revoked, err := checker.services.IsTokenRevoked(tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+	checker.sweep()
+	checker.tokens[tokenID] = cachedToken{revoked: revoked, cachedAt: time.Now()}
+
+	return revoked, nil
+}
+
+func (checker *RevocationChecker) getSession(sessionID uuid.UUID) (models.Session, error) {
+	checker.mutex.Lock()
+	cached, ok := checker.sessions[sessionID]
+	checker.mutex.Unlock()
+	if ok && time.Since(cached.cachedAt) < checker.ttl {
+		return cached.session, nil
+	}
+
+	session, err := checker.services.GetSession(sessionID)
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+	checker.sweep()
+	checker.sessions[sessionID] = cachedSession{session: session, cachedAt: time.Now()}
+
+	return session, nil
+}
+
+// sweep drops the expired lookups, at most once per ttl, so the cache does not grow with every token ever seen.
+// The caller must hold the mutex.
+func (checker *RevocationChecker) sweep() {
+	if time.Since(checker.lastSweep) < checker.ttl {
+		return
+	}
+	checker.lastSweep = time.Now()
+
+	for id, cached := range checker.sessions {
+		if time.Since(cached.cachedAt) >= checker.ttl {
+			delete(checker.sessions, id)
+		}
+	}
+	for id, cached := range checker.tokens {
+		if time.Since(cached.cachedAt) >= checker.ttl {
+			delete(checker.tokens, id)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func newPayload(t *testing.T) *token.Payload {
+	payload, err := token.NewPayload(util.RandomUsername(), time.Minute)
+	require.NoError(t, err)
+	payload.SessionID = uuid.New()
+
+	return payload
+}
+
+func activeSession(payload *token.Payload) models.Session {
+	return models.Session{
+		ID:        payload.SessionID,
+		Username:  payload.Username,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func TestRevocationCheckerCachesLookups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	services := mockdb.NewMockServices(ctrl)
+	checker := NewRevocationChecker(services, 50*time.Millisecond)
+	payload := newPayload(t)
+
+	services.EXPECT().IsTokenRevoked(gomock.Eq(payload.ID)).Times(2).Return(false, nil)
+	services.EXPECT().GetSession(gomock.Eq(payload.SessionID)).Times(2).Return(activeSession(payload), nil)
+
+	require.NoError(t, checker.Check(payload))
+	require.NoError(t, checker.Check(payload))
+
+	// the lookups are made again once the ttl has passed
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, checker.Check(payload))
+}
+
+func TestRevocationCheckerRevokedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	services := mockdb.NewMockServices(ctrl)
+	checker := NewRevocationChecker(services, time.Minute)
+	payload := newPayload(t)
+
+	services.EXPECT().IsTokenRevoked(gomock.Eq(payload.ID)).Times(1).Return(true, nil)
+	services.EXPECT().GetSession(gomock.Any()).Times(0)
+
+	require.ErrorIs(t, checker.Check(payload), ErrRevokedToken)
+}
+
+func TestRevocationCheckerBlockedSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	services := mockdb.NewMockServices(ctrl)
+	checker := NewRevocationChecker(services, time.Minute)
+	payload := newPayload(t)
+
+	blocked := activeSession(payload)
+	blocked.IsBlocked = true
+
+	services.EXPECT().IsTokenRevoked(gomock.Eq(payload.ID)).Times(2).Return(false, nil)
+	gomock.InOrder(
+		services.EXPECT().GetSession(gomock.Eq(payload.SessionID)).Times(1).Return(activeSession(payload), nil),
+		services.EXPECT().GetSession(gomock.Eq(payload.SessionID)).Times(1).Return(blocked, nil),
+	)
+
+	require.NoError(t, checker.Check(payload))
+
+	// resetting the cache makes the revocation visible before the ttl
+	checker.Reset()
+	require.ErrorIs(t, checker.Check(payload), ErrInactiveSession)
+}
+
+func TestRevocationCheckerNoSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	services := mockdb.NewMockServices(ctrl)
+	checker := NewRevocationChecker(services, time.Minute)
+
+	payload, err := token.NewPayload(util.RandomUsername(), time.Minute)
+	require.NoError(t, err)
+
+	services.EXPECT().IsTokenRevoked(gomock.Eq(payload.ID)).Times(1).Return(false, nil)
+	services.EXPECT().GetSession(gomock.Any()).Times(0)
+
+	require.NoError(t, checker.Check(payload))
+}
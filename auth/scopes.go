@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"Simple-Bank/token"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrInsufficientScope is returned when a scoped credential is used on an endpoint none of its scopes grants
+var ErrInsufficientScope = errors.New("insufficient scope")
+
+// Scope restricts what a credential, e.g. an api key, can be used for.
+// Scopes only narrow down what the owner of the credential can do with their own resources, they never grant
+// permissions: scoped credentials have the role of a customer, whatever the role of their owner.
+type Scope string
+
+// scopes of a credential
+const (
+	// ScopeAccountsRead allows reading accounts and their balances
+	ScopeAccountsRead Scope = "accounts:read"
+	// ScopeAccountsWrite allows creating accounts
+	ScopeAccountsWrite Scope = "accounts:write"
+	// ScopeTransfersRead allows reading transfers
+	ScopeTransfersRead Scope = "transfers:read"
+	// ScopeTransfersWrite allows transferring money
+	ScopeTransfersWrite Scope = "transfers:write"
+	// ScopeUsersRead allows reading the information of users
+	ScopeUsersRead Scope = "users:read"
+)
+
+// scopes lists every scope a credential can be granted.
+var scopes = []Scope{
+	ScopeAccountsRead,
+	ScopeAccountsWrite,
+	ScopeTransfersRead,
+	ScopeTransfersWrite,
+	ScopeUsersRead,
+}
+
+// IsValidScope reports whether scope is a scope a credential can be granted.
+func IsValidScope(scope string) bool {
+	return slices.Contains(scopes, Scope(scope))
+}
+
+// IsScoped reports whether the payload is restricted to the endpoints of its scopes.
+func IsScoped(payload *token.Payload) bool {
+	return len(payload.Scopes) > 0
+}
+
+// AuthorizeScope returns an error wrapping ErrInsufficientScope if the payload is scoped and none of its scopes
+// is the scope an endpoint requires. An empty scope means the endpoint cannot be used with scoped credentials.
+func AuthorizeScope(payload *token.Payload, scope Scope) error {
+	if !IsScoped(payload) {
+		return nil
+	}
+	if scope == "" {
+		return fmt.Errorf("%w: endpoint cannot be used with scoped credentials", ErrInsufficientScope)
+	}
+	if !slices.Contains(payload.Scopes, string(scope)) {
+		return fmt.Errorf("%w: scope %s is required", ErrInsufficientScope, scope)
+	}
+
+	return nil
+}
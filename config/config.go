@@ -16,6 +16,138 @@ type Config struct {
 	TokenSymmetricKey         string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	TokenAccessTokenDuration  time.Duration `mapstructure:"TOKEN_ACCESS_TOKEN_DURATION"`
 	TokenRefreshTokenDuration time.Duration `mapstructure:"TOKEN_REFRESH_TOKEN_DURATION"`
+	ChartOfAccounts           []GLAccount   `mapstructure:"CHART_OF_ACCOUNTS"`
+	ReportsDirectory          string        `mapstructure:"REPORTS_DIRECTORY"`
+	// HotAccounts are the ids of the accounts receiving credits as pending credits
+	HotAccounts []int64 `mapstructure:"HOT_ACCOUNTS"`
+	// PendingCreditsFoldInterval is how often pending credits are folded into the balance of hot accounts
+	PendingCreditsFoldInterval time.Duration `mapstructure:"PENDING_CREDITS_FOLD_INTERVAL"`
+	// RevocationCacheTTL is how long the servers remember that a session or a token was not revoked
+	RevocationCacheTTL time.Duration `mapstructure:"REVOCATION_CACHE_TTL"`
+	// ApprovalTTL is how long a sensitive operation waits for approval before it expires
+	ApprovalTTL time.Duration `mapstructure:"APPROVAL_TTL"`
+	// ApprovalTransferThreshold is the amount from which transfers require approval, zero if they never do
+	ApprovalTransferThreshold int32 `mapstructure:"APPROVAL_TRANSFER_THRESHOLD"`
+	// OAuthAccessTokenDuration is how long the access tokens issued to OAuth clients last
+	OAuthAccessTokenDuration time.Duration `mapstructure:"OAUTH_ACCESS_TOKEN_DURATION"`
+	// OAuthAuthorizationCodeTTL is how long an OAuth client has to exchange an authorization code
+	OAuthAuthorizationCodeTTL time.Duration `mapstructure:"OAUTH_AUTHORIZATION_CODE_TTL"`
+	// TokenAlgorithm selects how tokens are signed: "paseto" (v2.local with TokenSymmetricKey, the default),
+	// "paseto_public" (v4.public) or "jwt_eddsa", the last two with TokenSigningKeys
+	TokenAlgorithm string `mapstructure:"TOKEN_ALGORITHM"`
+	// TokenSigningKeys are the Ed25519 keys of the asymmetric algorithms. The first key signs tokens,
+	// the others only verify the tokens they signed until they retire
+	TokenSigningKeys []SigningKey `mapstructure:"TOKEN_SIGNING_KEYS"`
+	// MFAEncryptionKey encrypts the secrets of two-factor authentication, it must be 32 characters long
+	MFAEncryptionKey string `mapstructure:"MFA_ENCRYPTION_KEY"`
+	// MFAChallengeTTL is how long users logging in have to enter their second factor
+	MFAChallengeTTL time.Duration `mapstructure:"MFA_CHALLENGE_TTL"`
+	// StepUpTokenDuration is how long the elevated tokens of a re-authentication are accepted
+	StepUpTokenDuration time.Duration `mapstructure:"STEP_UP_TOKEN_DURATION"`
+	// StepUpTransferThreshold is the amount from which transfers require a re-authentication, zero if they never do
+	StepUpTransferThreshold int32 `mapstructure:"STEP_UP_TRANSFER_THRESHOLD"`
+	// StepUpOperations are the other operations requiring a re-authentication, "close_account" and "change_email"
+	StepUpOperations []string `mapstructure:"STEP_UP_OPERATIONS"`
+	// LoginMaxFailures is the number of failed logins locking out a username, delays grow from half of it
+	LoginMaxFailures int32 `mapstructure:"LOGIN_MAX_FAILURES"`
+	// LoginMaxIPFailures is the number of failed logins locking out a client address
+	LoginMaxIPFailures int32 `mapstructure:"LOGIN_MAX_IP_FAILURES"`
+	// LoginLockoutDuration is how long lockouts last
+	LoginLockoutDuration time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	// MailerType selects how emails are sent: "smtp", "file" (written to MailDirectory) or "log" (the default),
+	// the last two for development
+	MailerType string `mapstructure:"MAILER_TYPE"`
+	// MailFrom is the address emails are sent from
+	MailFrom      string `mapstructure:"MAIL_FROM"`
+	MailDirectory string `mapstructure:"MAIL_DIRECTORY"`
+	SMTPHost      string `mapstructure:"SMTP_HOST"`
+	SMTPPort      string `mapstructure:"SMTP_PORT"`
+	// SMTPUsername and SMTPPassword authenticate to the SMTP server, which is not authenticated to if empty
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	// EmailVerificationTTL is how long the tokens emailed to verify emails last
+	EmailVerificationTTL time.Duration `mapstructure:"EMAIL_VERIFICATION_TTL"`
+	// EmailVerificationURL is the page verifying the emailed tokens, the emails only carry the token if empty
+	EmailVerificationURL string `mapstructure:"EMAIL_VERIFICATION_URL"`
+	// RequireEmailVerification blocks transfers of users until they verify their email
+	RequireEmailVerification bool `mapstructure:"REQUIRE_EMAIL_VERIFICATION"`
+	// PasswordResetTTL is how long the tokens emailed to reset forgotten passwords last
+	PasswordResetTTL time.Duration `mapstructure:"PASSWORD_RESET_TTL"`
+	// PasswordResetURL is the page resetting passwords with the emailed tokens, the emails only carry the token if empty
+	PasswordResetURL string `mapstructure:"PASSWORD_RESET_URL"`
+	// PasswordMinLength and PasswordMaxLength bound the length of new passwords in characters, 8 and 64 if zero
+	PasswordMinLength int `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMaxLength int `mapstructure:"PASSWORD_MAX_LENGTH"`
+	// PasswordRequiredClasses are the classes of characters new passwords must contain among "lowercase",
+	// "uppercase", "digit" and "symbol", all of them if empty and none if "none"
+	PasswordRequiredClasses []string `mapstructure:"PASSWORD_REQUIRED_CLASSES"`
+	// PasswordAllowedCharacters selects the characters new passwords may contain: "unicode" (any but control
+	// characters, the default) or "ascii" (printable ASCII characters)
+	PasswordAllowedCharacters string `mapstructure:"PASSWORD_ALLOWED_CHARACTERS"`
+	// PasswordHistorySize is the number of last passwords users cannot reuse, 5 if zero and none if negative
+	PasswordHistorySize int `mapstructure:"PASSWORD_HISTORY_SIZE"`
+	// BreachedPasswordsFile is the list of SHA-1 hashes of breached passwords new passwords are checked against,
+	// in the format of the Pwned Passwords list ordered by hash, they are not checked if empty
+	BreachedPasswordsFile string `mapstructure:"BREACHED_PASSWORDS_FILE"`
+	// PasswordlessDelivery selects how login codes are delivered: "email" (the default) or "log" for development
+	PasswordlessDelivery string `mapstructure:"PASSWORDLESS_DELIVERY"`
+	// PasswordlessCodeTTL is how long login codes and magic links last, 10 minutes if zero
+	PasswordlessCodeTTL time.Duration `mapstructure:"PASSWORDLESS_CODE_TTL"`
+	// PasswordlessLinkURL is the page logging in with magic links, only codes are delivered if empty
+	PasswordlessLinkURL string `mapstructure:"PASSWORDLESS_LINK_URL"`
+	// PasswordlessMaxRequests is the number of login codes a username or an email can request an hour, 5 if zero
+	PasswordlessMaxRequests int `mapstructure:"PASSWORDLESS_MAX_REQUESTS"`
+	// PasswordlessMaxIPRequests is the number of login codes a client address can request an hour, 50 if zero
+	PasswordlessMaxIPRequests int `mapstructure:"PASSWORDLESS_MAX_IP_REQUESTS"`
+	// NewDeviceNotifier selects how users are alerted of logins from new devices or networks: "email" (the
+	// default) or "log" for development
+	NewDeviceNotifier string `mapstructure:"NEW_DEVICE_NOTIFIER"`
+	// NewDeviceVerification requires users without two-factor authentication who log in with a password from a new
+	// device or network to complete the login with a login code delivered to them, like a passwordless login
+	NewDeviceVerification bool `mapstructure:"NEW_DEVICE_VERIFICATION"`
+	// RateLimitBackend selects where the token buckets of the rate limiter are kept: "memory" (the default), so
+	// every instance limits the requests it receives, or "postgres", so the instances of a deployment share them
+	RateLimitBackend string `mapstructure:"RATE_LIMIT_BACKEND"`
+	// RateLimitRequests, RateLimitPeriod and RateLimitBurst are the limit of every route and method without a
+	// rule in RateLimits, 300 requests and a minute if zero. A negative number of requests disables it
+	RateLimitRequests int           `mapstructure:"RATE_LIMIT_REQUESTS"`
+	RateLimitPeriod   time.Duration `mapstructure:"RATE_LIMIT_PERIOD"`
+	RateLimitBurst    int           `mapstructure:"RATE_LIMIT_BURST"`
+	// RateLimits are the limits of single routes and methods
+	RateLimits []RateLimitRule `mapstructure:"RATE_LIMITS"`
+	// TrustedProxies are the addresses and CIDR ranges of the reverse proxies in front of the servers, whose
+	// X-Forwarded-For headers tell the address of the clients. If empty, no header is believed and the address
+	// of a client is the address its requests come from
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+}
+
+// GLAccount is an account of the chart of accounts
+type GLAccount struct {
+	Code     string `mapstructure:"CODE"`
+	Name     string `mapstructure:"NAME"`
+	Category string `mapstructure:"CATEGORY"`
+}
+
+// RateLimitRule limits the requests a client address or a user makes to a route or a method
+type RateLimitRule struct {
+	// Route is a gin route, e.g. "POST /users/login", or a full grpc method, e.g. "/pb.SimpleBank/LoginUser"
+	Route string `mapstructure:"ROUTE"`
+	// Requests are allowed every Period, a minute if zero, and Burst at once, Requests if zero. A negative
+	// number of requests lifts the limit of the route
+	Requests int           `mapstructure:"REQUESTS"`
+	Period   time.Duration `mapstructure:"PERIOD"`
+	Burst    int           `mapstructure:"BURST"`
+}
+
+// SigningKey is an Ed25519 key signing or verifying tokens
+type SigningKey struct {
+	ID string `mapstructure:"ID"`
+	// PrivateKey is the base64 encoded seed of the key, only required for the key signing tokens
+	PrivateKey string `mapstructure:"PRIVATE_KEY"`
+	// PublicKey is the base64 encoded public key, derived from PrivateKey if empty
+	PublicKey string `mapstructure:"PUBLIC_KEY"`
+	// RetiresAt is when the key stops verifying tokens in RFC 3339 format, never if empty
+	RetiresAt string `mapstructure:"RETIRES_AT"`
 }
 
 func LoadConfig(path, name string) (Config, error) {
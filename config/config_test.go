@@ -20,4 +20,60 @@ func TestLoadConfig(t *testing.T) {
 	require.Equal(t, "key", config.TokenSymmetricKey)
 	require.Equal(t, "environment", config.Environment)
 	require.Equal(t, 1*time.Minute, config.TokenAccessTokenDuration)
+	require.Equal(t, "paseto_public", config.TokenAlgorithm)
+	require.Equal(t, []SigningKey{
+		{ID: "2024-02", PrivateKey: "private key"},
+		{ID: "2024-01", PublicKey: "public key", RetiresAt: "2024-03-01T00:00:00Z"},
+	}, config.TokenSigningKeys)
+	require.Equal(t, []GLAccount{{Code: "1000", Name: "Cash", Category: "asset"}}, config.ChartOfAccounts)
+	require.Equal(t, "reports", config.ReportsDirectory)
+	require.Equal(t, []int64{1, 2}, config.HotAccounts)
+	require.Equal(t, 2*time.Second, config.PendingCreditsFoldInterval)
+	require.Equal(t, 5*time.Second, config.RevocationCacheTTL)
+	require.Equal(t, 24*time.Hour, config.ApprovalTTL)
+	require.Equal(t, int32(10000), config.ApprovalTransferThreshold)
+	require.Equal(t, 10*time.Minute, config.OAuthAccessTokenDuration)
+	require.Equal(t, 30*time.Second, config.OAuthAuthorizationCodeTTL)
+	require.Equal(t, "mfa key", config.MFAEncryptionKey)
+	require.Equal(t, 3*time.Minute, config.MFAChallengeTTL)
+	require.Equal(t, 2*time.Minute, config.StepUpTokenDuration)
+	require.Equal(t, int32(5000), config.StepUpTransferThreshold)
+	require.Equal(t, []string{"close_account", "change_email"}, config.StepUpOperations)
+	require.Equal(t, int32(6), config.LoginMaxFailures)
+	require.Equal(t, int32(60), config.LoginMaxIPFailures)
+	require.Equal(t, 20*time.Minute, config.LoginLockoutDuration)
+	require.Equal(t, "smtp", config.MailerType)
+	require.Equal(t, "bank@example.com", config.MailFrom)
+	require.Equal(t, "emails", config.MailDirectory)
+	require.Equal(t, "smtp.example.com", config.SMTPHost)
+	require.Equal(t, "587", config.SMTPPort)
+	require.Equal(t, "smtp user", config.SMTPUsername)
+	require.Equal(t, "smtp password", config.SMTPPassword)
+	require.Equal(t, 48*time.Hour, config.EmailVerificationTTL)
+	require.Equal(t, "https://bank.example.com/verify_email", config.EmailVerificationURL)
+	require.True(t, config.RequireEmailVerification)
+	require.Equal(t, 30*time.Minute, config.PasswordResetTTL)
+	require.Equal(t, "https://bank.example.com/reset_password", config.PasswordResetURL)
+	require.Equal(t, 12, config.PasswordMinLength)
+	require.Equal(t, 128, config.PasswordMaxLength)
+	require.Equal(t, []string{"lowercase", "digit"}, config.PasswordRequiredClasses)
+	require.Equal(t, "ascii", config.PasswordAllowedCharacters)
+	require.Equal(t, 10, config.PasswordHistorySize)
+	require.Equal(t, "pwned-passwords-sha1-ordered-by-hash.txt", config.BreachedPasswordsFile)
+	require.Equal(t, "log", config.PasswordlessDelivery)
+	require.Equal(t, 15*time.Minute, config.PasswordlessCodeTTL)
+	require.Equal(t, "https://bank.example.com/login", config.PasswordlessLinkURL)
+	require.Equal(t, 3, config.PasswordlessMaxRequests)
+	require.Equal(t, 30, config.PasswordlessMaxIPRequests)
+	require.Equal(t, "log", config.NewDeviceNotifier)
+	require.True(t, config.NewDeviceVerification)
+	require.Equal(t, "memory", config.RateLimitBackend)
+	require.Equal(t, 120, config.RateLimitRequests)
+	require.Equal(t, time.Minute, config.RateLimitPeriod)
+	require.Equal(t, 20, config.RateLimitBurst)
+	require.Equal(t, []RateLimitRule{
+		{Route: "POST /users/login", Requests: 10, Period: time.Minute},
+		{Route: "/pb.SimpleBank/LoginUser", Requests: 10, Period: time.Minute, Burst: 5},
+	}, config.RateLimits)
+	require.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, config.TrustedProxies)
 }
@@ -0,0 +1,2 @@
+drop index if exists entries_account_id_created_at_idx;
+drop table if exists balance_snapshots;
\ No newline at end of file
@@ -0,0 +1,10 @@
+create table balance_snapshots (
+    id bigserial primary key,
+    account_id bigint references accounts(id) on delete cascade not null,
+    balance bigint not null,
+    snapshot_at timestamptz not null,
+    created_at timestamptz not null default now(),
+    unique (account_id, snapshot_at)
+);
+
+create index entries_account_id_created_at_idx on entries (account_id, created_at);
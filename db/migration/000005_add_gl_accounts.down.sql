@@ -0,0 +1,5 @@
+drop table if exists gl_accounts;
+delete from accounts where is_system;
+alter table if exists accounts drop column if exists is_system;
+
+delete from users where username = '_system';
@@ -0,0 +1,46 @@
+-- the bank's own accounts are owned by a system user that can never log in
+insert into users (username, hashed_password, fullname, email, deleted_at)
+values ('_system', '!', 'Simple Bank', 'system@simple-bank.internal', null);
+
+alter table accounts add column is_system bool not null default false;
+
+create table gl_accounts (
+    code varchar(16) primary key,
+    name varchar(64) not null,
+    category varchar(16) not null,
+    account_id bigint unique not null references accounts(id),
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+insert into accounts (owner, balance, is_system, deleted_at)
+select '_system', 0, true, null from generate_series(1, 4);
+
+insert into gl_accounts (code, name, category, account_id)
+select chart.code, chart.name, chart.category, system_accounts.id
+from (
+    values (1, '1000', 'Cash', 'asset'),
+           (2, '1900', 'Suspense', 'asset'),
+           (3, '4000', 'Fee Income', 'income'),
+           (4, '5000', 'Interest Expense', 'expense')
+) as chart(position, code, name, category)
+join (
+    select id, row_number() over (order by id) as position
+    from accounts where is_system
+) as system_accounts on system_accounts.position = chart.position;
+
+-- money already held by customers came in through cash: every entry of a customer account that is not a side of a
+-- transfer, e.g. a deposit, is mirrored on cash at the same time, so the ledger balances at any time
+insert into entries (account_id, amount, created_at, updated_at, deleted_at)
+select (select account_id from gl_accounts where code = '1000'), -entries.amount, entries.created_at, entries.created_at, null
+from entries
+join accounts on accounts.id = entries.account_id and not accounts.is_system
+where entries.deleted_at is null
+  and not exists (
+      select 1 from transfers
+      where transfers.incoming_entry_id = entries.id or transfers.outgoing_entry_id = entries.id
+  );
+
+-- the opening balance of cash is the sum of its entries
+update accounts set balance = (select coalesce(sum(amount), 0) from entries where entries.account_id = accounts.id)
+where id = (select account_id from gl_accounts where code = '1000');
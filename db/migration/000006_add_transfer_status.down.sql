@@ -0,0 +1,7 @@
+drop table if exists transfer_status_history;
+
+delete from transfers where status != 'completed' and status != 'reversed';
+alter table transfers alter column incoming_entry_id set not null;
+alter table transfers alter column outgoing_entry_id set not null;
+alter table transfers drop column if exists status_reason;
+alter table transfers drop column if exists status;
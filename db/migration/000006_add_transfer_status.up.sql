@@ -0,0 +1,22 @@
+alter table transfers add column status varchar(16) not null default 'completed';
+alter table transfers add column status_reason varchar not null default '';
+alter table transfers alter column incoming_entry_id drop not null;
+alter table transfers alter column outgoing_entry_id drop not null;
+
+create table transfer_status_history (
+    id bigserial primary key,
+    transfer_id bigint references transfers(id) on delete cascade not null,
+    from_status varchar(16) not null default '',
+    to_status varchar(16) not null,
+    reason varchar not null default '',
+    actor varchar(64) not null,
+    created_at timestamptz not null default now()
+);
+
+create index transfer_status_history_transfer_id_idx on transfer_status_history (transfer_id);
+
+-- every existing transfer has completed
+insert into transfer_status_history (transfer_id, to_status, reason, actor, created_at)
+select id, 'completed', 'migrated', '_system', created_at from transfers;
+
+alter table transfers alter column status set default 'pending';
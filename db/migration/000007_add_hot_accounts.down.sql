@@ -0,0 +1,9 @@
+-- fold the pending credits before dropping them
+update accounts a
+set balance = a.balance + p.amount
+from (select account_id, sum(amount) as amount from pending_credits group by account_id) p
+where p.account_id = a.id;
+
+drop table if exists pending_credits;
+
+alter table accounts drop column if exists is_hot;
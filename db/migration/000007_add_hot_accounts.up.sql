@@ -0,0 +1,12 @@
+alter table accounts add column is_hot bool not null default false;
+
+-- credits to hot accounts wait here until they are folded into the balance of the account
+create table pending_credits (
+    id bigserial primary key,
+    account_id bigint references accounts(id) on delete cascade not null,
+    amount bigint not null,
+    entry_id bigint references entries(id) not null,
+    created_at timestamptz not null default now()
+);
+
+create index pending_credits_account_id_idx on pending_credits (account_id);
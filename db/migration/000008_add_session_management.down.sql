@@ -0,0 +1,3 @@
+drop index if exists sessions_username_idx;
+
+alter table sessions add constraint sessions_client_ip_key unique (client_ip);
@@ -0,0 +1,4 @@
+-- a user can have several sessions from the same address
+alter table sessions drop constraint if exists sessions_client_ip_key;
+
+create index sessions_username_idx on sessions (username);
@@ -0,0 +1,4 @@
+drop index if exists sessions_family_id_idx;
+
+alter table sessions drop column if exists consumed_at;
+alter table sessions drop column if exists family_id;
@@ -0,0 +1,9 @@
+-- every renewal of a refresh token creates a new session in the family of the first one
+alter table sessions add column family_id uuid;
+update sessions set family_id = id;
+alter table sessions alter column family_id set not null;
+
+-- consumed sessions have had their refresh token exchanged for a new one
+alter table sessions add column consumed_at timestamptz;
+
+create index sessions_family_id_idx on sessions (family_id);
@@ -0,0 +1,9 @@
+-- tokens killed before they expire, e.g. because they were stolen
+create table revoked_tokens (
+    token_id uuid primary key,
+    username varchar(64) not null references users(username),
+    reason varchar not null default '',
+    revoked_by varchar(64) not null,
+    expires_at timestamptz not null,
+    created_at timestamptz not null default now()
+);
@@ -0,0 +1,3 @@
+-- the role of a user decides what it can do with the resources of other users
+alter table users add column role varchar(16) not null default 'customer';
+alter table users add constraint users_role_check check (role in ('customer', 'teller', 'auditor', 'admin'));
@@ -0,0 +1,5 @@
+drop table if exists audit_events;
+
+alter table accounts drop column if exists frozen_at;
+alter table users drop column if exists password_reset_required;
+alter table users drop column if exists frozen_at;
@@ -0,0 +1,19 @@
+-- frozen users cannot log in, frozen accounts cannot send or receive money
+alter table users add column frozen_at timestamptz;
+alter table users add column password_reset_required bool not null default false;
+alter table accounts add column frozen_at timestamptz;
+
+-- every action of the back office, with who did it and why
+create table audit_events (
+    id bigserial primary key,
+    operator varchar(64) not null references users(username),
+    action varchar(64) not null,
+    target_type varchar(32) not null,
+    target_id varchar(64) not null,
+    reason varchar not null check (reason <> ''),
+    details jsonb not null default '{}',
+    created_at timestamptz not null default now()
+);
+
+create index audit_events_target_idx on audit_events (target_type, target_id);
+create index audit_events_operator_idx on audit_events (operator);
@@ -0,0 +1,22 @@
+-- sensitive operations wait in approvals until a second user approves or rejects them
+create table approvals (
+    id bigserial primary key,
+    operation varchar(32) not null,
+    payload jsonb not null,
+    status varchar(16) not null default 'pending'
+        check (status in ('pending', 'approved', 'executed', 'failed', 'rejected', 'expired')),
+    requested_by varchar(64) not null references users(username),
+    reason varchar not null check (reason <> ''),
+    decided_by varchar(64) references users(username),
+    decision_reason varchar not null default '',
+    result jsonb,
+    error varchar not null default '',
+    created_at timestamptz not null default now(),
+    expires_at timestamptz not null,
+    decided_at timestamptz,
+    executed_at timestamptz,
+    check (decided_by is null or decided_by <> requested_by)
+);
+
+create index approvals_status_idx on approvals (status, expires_at);
+create index approvals_requested_by_idx on approvals (requested_by);
@@ -0,0 +1,19 @@
+-- api keys let server-to-server integrations authenticate without logging in
+create table api_keys (
+    id uuid primary key,
+    owner varchar(64) not null references users(username),
+    name varchar(64) not null check (name <> ''),
+    -- prefix is the public part of the key used to look it up, the secret part is only stored hashed
+    prefix varchar(16) not null unique,
+    hashed_secret varchar not null,
+    -- scopes and allowed_ips are space separated, an empty allowlist allows every address
+    scopes varchar not null check (scopes <> ''),
+    allowed_ips varchar not null default '',
+    expires_at timestamptz,
+    last_used_at timestamptz,
+    last_used_ip varchar not null default '',
+    revoked_at timestamptz,
+    created_at timestamptz not null default now()
+);
+
+create index api_keys_owner_idx on api_keys (owner);
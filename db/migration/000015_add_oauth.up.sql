@@ -0,0 +1,27 @@
+-- clients of third-party apps allowed to request scoped tokens through the OAuth2 server
+create table oauth_clients (
+    id varchar(32) primary key,
+    owner varchar(64) not null references users(username),
+    name varchar(64) not null check (name <> ''),
+    -- hashed_secret is empty for public clients, e.g. mobile apps, which cannot keep a secret
+    hashed_secret varchar not null default '',
+    -- redirect_uris and scopes are space separated
+    redirect_uris varchar not null default '',
+    scopes varchar not null check (scopes <> ''),
+    created_at timestamptz not null default now()
+);
+
+create index oauth_clients_owner_idx on oauth_clients (owner);
+
+-- authorization codes are exchanged once for an access token, proving the exchange with pkce
+create table oauth_authorization_codes (
+    hashed_code varchar primary key,
+    client_id varchar(32) not null references oauth_clients(id),
+    username varchar(64) not null references users(username),
+    redirect_uri varchar not null,
+    scopes varchar not null,
+    code_challenge varchar not null,
+    expires_at timestamptz not null,
+    consumed_at timestamptz,
+    created_at timestamptz not null default now()
+);
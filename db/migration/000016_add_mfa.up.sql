@@ -0,0 +1,33 @@
+-- time-based one-time password credentials, at most one per user
+create table totp_credentials (
+    username varchar(64) primary key references users(username),
+    -- encrypted_secret is the encrypted shared secret, it must be decrypted to check codes so it cannot be hashed
+    encrypted_secret varchar not null,
+    -- confirmed_at is null until the user proves the enrollment with a first code
+    confirmed_at timestamptz,
+    -- last_used_step is the time step of the last accepted code, so codes cannot be replayed
+    last_used_step bigint not null default 0,
+    created_at timestamptz not null default now()
+);
+
+-- recovery codes replace a code of the authenticator once each, e.g. when the device is lost
+create table recovery_codes (
+    id bigserial primary key,
+    username varchar(64) not null references users(username),
+    hashed_code varchar not null,
+    used_at timestamptz,
+    created_at timestamptz not null default now(),
+    unique (username, hashed_code)
+);
+
+-- challenges of the second step of the login of users with two-factor authentication
+create table mfa_challenges (
+    id bigserial primary key,
+    hashed_token varchar not null unique,
+    username varchar(64) not null references users(username),
+    -- attempts counts the wrong codes, the challenge is refused once it reaches the limit
+    attempts int not null default 0,
+    expires_at timestamptz not null,
+    consumed_at timestamptz,
+    created_at timestamptz not null default now()
+);
@@ -0,0 +1,11 @@
+-- failed logins, counted per username and per client address to slow down password guessing.
+-- usernames are not references since failures of usernames that do not exist are counted too
+create table login_failures (
+    -- kind is what subject is, "username" or "ip"
+    kind varchar(16) not null,
+    subject varchar not null,
+    -- failures counts the failed logins since the last successful one, or since failures were forgotten
+    failures int not null default 0,
+    last_failed_at timestamptz not null default now(),
+    primary key (kind, subject)
+);
@@ -0,0 +1,15 @@
+-- email_verified is set once the user proves they own their email, and unset when they change it
+alter table users add column email_verified boolean not null default false;
+
+-- tokens emailed to users to verify their email, only their hashes are stored
+create table email_verifications (
+    id bigserial primary key,
+    hashed_token varchar not null unique,
+    username varchar(64) not null references users(username),
+    -- email is the address the token was sent to, a token does not verify an address the user changed since
+    email varchar not null,
+    expires_at timestamptz not null,
+    created_at timestamptz not null default now()
+);
+
+create index on email_verifications (username);
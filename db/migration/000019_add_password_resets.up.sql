@@ -0,0 +1,12 @@
+-- tokens emailed to users who forgot their password, only their hashes are stored
+create table password_resets (
+    id bigserial primary key,
+    hashed_token varchar not null unique,
+    username varchar(64) not null references users(username),
+    expires_at timestamptz not null,
+    -- used_at is set when the password is reset, a token resets it once
+    used_at timestamptz,
+    created_at timestamptz not null default now()
+);
+
+create index on password_resets (username);
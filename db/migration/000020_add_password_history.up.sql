@@ -0,0 +1,13 @@
+-- hashes of the passwords users have had, the current one included, so they cannot reuse them
+create table password_history (
+    id bigserial primary key,
+    username varchar(64) not null references users(username),
+    hashed_password varchar not null,
+    created_at timestamptz not null default now()
+);
+
+create index on password_history (username, created_at);
+
+-- the current passwords of existing users start their history
+insert into password_history (username, hashed_password, created_at)
+select username, hashed_password, coalesce(updated_at, now()) from users;
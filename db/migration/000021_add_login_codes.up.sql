@@ -0,0 +1,16 @@
+-- codes and magic links emailed to users logging in without a password, only their hashes are stored
+create table login_codes (
+    id bigserial primary key,
+    username varchar(64) not null references users(username),
+    -- hashed_code is the hash of the short code users type, hashed_token the hash of the token of the magic link
+    hashed_code varchar not null,
+    hashed_token varchar not null unique,
+    -- attempts counts the wrong codes tried, the code cannot be used anymore after too many
+    attempts integer not null default 0,
+    expires_at timestamptz not null,
+    -- used_at is set when the user logs in, a code logs in once
+    used_at timestamptz,
+    created_at timestamptz not null default now()
+);
+
+create index on login_codes (username, created_at);
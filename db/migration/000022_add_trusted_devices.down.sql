@@ -0,0 +1 @@
+drop table if exists trusted_devices;
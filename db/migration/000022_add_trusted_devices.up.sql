@@ -0,0 +1,14 @@
+-- devices users logged in from, logins from other devices or networks alert their user
+create table trusted_devices (
+    id bigserial primary key,
+    username varchar(64) not null references users(username),
+    -- fingerprint identifies the device across logins, it is derived from its user agent
+    fingerprint varchar not null,
+    user_agent varchar not null,
+    -- ip_range is the network the device last logged in from, last_ip its address
+    ip_range varchar not null,
+    last_ip varchar not null,
+    created_at timestamptz not null default now(),
+    last_seen_at timestamptz not null default now(),
+    unique (username, fingerprint)
+);
@@ -0,0 +1,9 @@
+-- token buckets of the rate limiter, shared by the instances of a deployment
+create table rate_limit_buckets (
+    -- key is the route and the subject, username or client address, the bucket limits
+    key varchar primary key,
+    tokens double precision not null,
+    updated_at timestamptz not null default now()
+);
+
+create index on rate_limit_buckets (updated_at);
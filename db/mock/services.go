@@ -14,6 +14,7 @@ import (
 	services "Simple-Bank/db/services"
 	requests "Simple-Bank/requests"
 	reflect "reflect"
+	time "time"
 
 	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
@@ -42,6 +43,138 @@ func (m *MockServices) EXPECT() *MockServicesMockRecorder {
 	return m.recorder
 }
 
+// AdjustBalance mocks base method.
+func (m *MockServices) AdjustBalance(arg0 services.AdminAction, arg1 int64, arg2 int32) (models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockServicesMockRecorder) AdjustBalance(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockServices)(nil).AdjustBalance), arg0, arg1, arg2)
+}
+
+// AdminRevokeToken mocks base method.
+func (m *MockServices) AdminRevokeToken(arg0 services.AdminAction, arg1 services.RevokeTokenRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminRevokeToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdminRevokeToken indicates an expected call of AdminRevokeToken.
+func (mr *MockServicesMockRecorder) AdminRevokeToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminRevokeToken", reflect.TypeOf((*MockServices)(nil).AdminRevokeToken), arg0, arg1)
+}
+
+// CancelTransfer mocks base method.
+func (m *MockServices) CancelTransfer(arg0 services.UpdateTransferStatusRequest) (models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelTransfer", arg0)
+	ret0, _ := ret[0].(models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelTransfer indicates an expected call of CancelTransfer.
+func (mr *MockServicesMockRecorder) CancelTransfer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelTransfer", reflect.TypeOf((*MockServices)(nil).CancelTransfer), arg0)
+}
+
+// ClearLoginFailures mocks base method.
+func (m *MockServices) ClearLoginFailures(arg0 string, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearLoginFailures", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearLoginFailures indicates an expected call of ClearLoginFailures.
+func (mr *MockServicesMockRecorder) ClearLoginFailures(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearLoginFailures", reflect.TypeOf((*MockServices)(nil).ClearLoginFailures), arg0, arg1)
+}
+
+// CompleteApproval mocks base method.
+func (m *MockServices) CompleteApproval(arg0 int64, arg1 string, arg2 string) (models.Approval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteApproval", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Approval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteApproval indicates an expected call of CompleteApproval.
+func (mr *MockServicesMockRecorder) CompleteApproval(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteApproval", reflect.TypeOf((*MockServices)(nil).CompleteApproval), arg0, arg1, arg2)
+}
+
+// ConfirmTOTPCredential mocks base method.
+func (m *MockServices) ConfirmTOTPCredential(arg0 string, arg1 int64, arg2 []string) (models.TOTPCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTPCredential", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.TOTPCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTPCredential indicates an expected call of ConfirmTOTPCredential.
+func (mr *MockServicesMockRecorder) ConfirmTOTPCredential(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTPCredential", reflect.TypeOf((*MockServices)(nil).ConfirmTOTPCredential), arg0, arg1, arg2)
+}
+
+// ConsumeAuthorizationCode mocks base method.
+func (m *MockServices) ConsumeAuthorizationCode(arg0 string) (models.AuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeAuthorizationCode", arg0)
+	ret0, _ := ret[0].(models.AuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeAuthorizationCode indicates an expected call of ConsumeAuthorizationCode.
+func (mr *MockServicesMockRecorder) ConsumeAuthorizationCode(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeAuthorizationCode", reflect.TypeOf((*MockServices)(nil).ConsumeAuthorizationCode), arg0)
+}
+
+// ConsumeMFAChallenge mocks base method.
+func (m *MockServices) ConsumeMFAChallenge(arg0 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeMFAChallenge", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeMFAChallenge indicates an expected call of ConsumeMFAChallenge.
+func (mr *MockServicesMockRecorder) ConsumeMFAChallenge(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeMFAChallenge", reflect.TypeOf((*MockServices)(nil).ConsumeMFAChallenge), arg0)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockServices) CreateAPIKey(arg0 models.APIKey) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockServicesMockRecorder) CreateAPIKey(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockServices)(nil).CreateAPIKey), arg0)
+}
+
 // CreateAccount mocks base method.
 func (m *MockServices) CreateAccount(arg0 string) (models.Account, error) {
 	m.ctrl.T.Helper()
@@ -57,6 +190,126 @@ func (mr *MockServicesMockRecorder) CreateAccount(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockServices)(nil).CreateAccount), arg0)
 }
 
+// CreateApproval mocks base method.
+func (m *MockServices) CreateApproval(arg0 services.CreateApprovalRequest) (models.Approval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApproval", arg0)
+	ret0, _ := ret[0].(models.Approval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApproval indicates an expected call of CreateApproval.
+func (mr *MockServicesMockRecorder) CreateApproval(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApproval", reflect.TypeOf((*MockServices)(nil).CreateApproval), arg0)
+}
+
+// CreateAuthorizationCode mocks base method.
+func (m *MockServices) CreateAuthorizationCode(arg0 models.AuthorizationCode) (models.AuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuthorizationCode", arg0)
+	ret0, _ := ret[0].(models.AuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuthorizationCode indicates an expected call of CreateAuthorizationCode.
+func (mr *MockServicesMockRecorder) CreateAuthorizationCode(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuthorizationCode", reflect.TypeOf((*MockServices)(nil).CreateAuthorizationCode), arg0)
+}
+
+// CreateBalanceSnapshots mocks base method.
+func (m *MockServices) CreateBalanceSnapshots(arg0 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBalanceSnapshots", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBalanceSnapshots indicates an expected call of CreateBalanceSnapshots.
+func (mr *MockServicesMockRecorder) CreateBalanceSnapshots(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBalanceSnapshots", reflect.TypeOf((*MockServices)(nil).CreateBalanceSnapshots), arg0)
+}
+
+// CreateEmailVerification mocks base method.
+func (m *MockServices) CreateEmailVerification(arg0 models.EmailVerification) (models.EmailVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailVerification", arg0)
+	ret0, _ := ret[0].(models.EmailVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailVerification indicates an expected call of CreateEmailVerification.
+func (mr *MockServicesMockRecorder) CreateEmailVerification(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailVerification", reflect.TypeOf((*MockServices)(nil).CreateEmailVerification), arg0)
+}
+
+// CreateLoginCode mocks base method.
+func (m *MockServices) CreateLoginCode(arg0 models.LoginCode) (models.LoginCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginCode", arg0)
+	ret0, _ := ret[0].(models.LoginCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoginCode indicates an expected call of CreateLoginCode.
+func (mr *MockServicesMockRecorder) CreateLoginCode(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoginCode", reflect.TypeOf((*MockServices)(nil).CreateLoginCode), arg0)
+}
+
+// CreateMFAChallenge mocks base method.
+func (m *MockServices) CreateMFAChallenge(arg0 models.MFAChallenge) (models.MFAChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMFAChallenge", arg0)
+	ret0, _ := ret[0].(models.MFAChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMFAChallenge indicates an expected call of CreateMFAChallenge.
+func (mr *MockServicesMockRecorder) CreateMFAChallenge(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMFAChallenge", reflect.TypeOf((*MockServices)(nil).CreateMFAChallenge), arg0)
+}
+
+// CreateOAuthClient mocks base method.
+func (m *MockServices) CreateOAuthClient(arg0 models.OAuthClient) (models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthClient", arg0)
+	ret0, _ := ret[0].(models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthClient indicates an expected call of CreateOAuthClient.
+func (mr *MockServicesMockRecorder) CreateOAuthClient(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClient", reflect.TypeOf((*MockServices)(nil).CreateOAuthClient), arg0)
+}
+
+// CreatePasswordReset mocks base method.
+func (m *MockServices) CreatePasswordReset(arg0 models.PasswordReset) (models.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordReset", arg0)
+	ret0, _ := ret[0].(models.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordReset indicates an expected call of CreatePasswordReset.
+func (mr *MockServicesMockRecorder) CreatePasswordReset(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockServices)(nil).CreatePasswordReset), arg0)
+}
+
 // CreateSession mocks base method.
 func (m *MockServices) CreateSession(arg0 models.Session) (models.Session, error) {
 	m.ctrl.T.Helper()
@@ -87,6 +340,21 @@ func (mr *MockServicesMockRecorder) CreateUser(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockServices)(nil).CreateUser), arg0)
 }
 
+// DecideApproval mocks base method.
+func (m *MockServices) DecideApproval(arg0 services.DecideApprovalRequest) (models.Approval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideApproval", arg0)
+	ret0, _ := ret[0].(models.Approval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideApproval indicates an expected call of DecideApproval.
+func (mr *MockServicesMockRecorder) DecideApproval(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideApproval", reflect.TypeOf((*MockServices)(nil).DecideApproval), arg0)
+}
+
 // DeleteAccount mocks base method.
 func (m *MockServices) DeleteAccount(arg0 int64) (models.Account, error) {
 	m.ctrl.T.Helper()
@@ -102,6 +370,48 @@ func (mr *MockServicesMockRecorder) DeleteAccount(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockServices)(nil).DeleteAccount), arg0)
 }
 
+// DeleteRateLimitBuckets mocks base method.
+func (m *MockServices) DeleteRateLimitBuckets(arg0 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRateLimitBuckets", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRateLimitBuckets indicates an expected call of DeleteRateLimitBuckets.
+func (mr *MockServicesMockRecorder) DeleteRateLimitBuckets(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRateLimitBuckets", reflect.TypeOf((*MockServices)(nil).DeleteRateLimitBuckets), arg0)
+}
+
+// DeleteTOTPCredential mocks base method.
+func (m *MockServices) DeleteTOTPCredential(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTPCredential", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTPCredential indicates an expected call of DeleteTOTPCredential.
+func (mr *MockServicesMockRecorder) DeleteTOTPCredential(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTPCredential", reflect.TypeOf((*MockServices)(nil).DeleteTOTPCredential), arg0)
+}
+
+// DeleteTrustedDevice mocks base method.
+func (m *MockServices) DeleteTrustedDevice(arg0 string, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTrustedDevice", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTrustedDevice indicates an expected call of DeleteTrustedDevice.
+func (mr *MockServicesMockRecorder) DeleteTrustedDevice(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTrustedDevice", reflect.TypeOf((*MockServices)(nil).DeleteTrustedDevice), arg0, arg1)
+}
+
 // DepositMoney mocks base method.
 func (m *MockServices) DepositMoney(arg0 requests.DepositRequest) (models.Entry, error) {
 	m.ctrl.T.Helper()
@@ -117,6 +427,81 @@ func (mr *MockServicesMockRecorder) DepositMoney(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositMoney", reflect.TypeOf((*MockServices)(nil).DepositMoney), arg0)
 }
 
+// ExpireApprovals mocks base method.
+func (m *MockServices) ExpireApprovals(arg0 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireApprovals", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireApprovals indicates an expected call of ExpireApprovals.
+func (mr *MockServicesMockRecorder) ExpireApprovals(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireApprovals", reflect.TypeOf((*MockServices)(nil).ExpireApprovals), arg0)
+}
+
+// FailMFAChallenge mocks base method.
+func (m *MockServices) FailMFAChallenge(arg0 int64) (models.MFAChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailMFAChallenge", arg0)
+	ret0, _ := ret[0].(models.MFAChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailMFAChallenge indicates an expected call of FailMFAChallenge.
+func (mr *MockServicesMockRecorder) FailMFAChallenge(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailMFAChallenge", reflect.TypeOf((*MockServices)(nil).FailMFAChallenge), arg0)
+}
+
+// FoldPendingCredits mocks base method.
+func (m *MockServices) FoldPendingCredits() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FoldPendingCredits")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FoldPendingCredits indicates an expected call of FoldPendingCredits.
+func (mr *MockServicesMockRecorder) FoldPendingCredits() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FoldPendingCredits", reflect.TypeOf((*MockServices)(nil).FoldPendingCredits))
+}
+
+// ForcePasswordReset mocks base method.
+func (m *MockServices) ForcePasswordReset(arg0 services.AdminAction, arg1 string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForcePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForcePasswordReset indicates an expected call of ForcePasswordReset.
+func (mr *MockServicesMockRecorder) ForcePasswordReset(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForcePasswordReset", reflect.TypeOf((*MockServices)(nil).ForcePasswordReset), arg0, arg1)
+}
+
+// GetAPIKeyByPrefix mocks base method.
+func (m *MockServices) GetAPIKeyByPrefix(arg0 string) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByPrefix", arg0)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByPrefix indicates an expected call of GetAPIKeyByPrefix.
+func (mr *MockServicesMockRecorder) GetAPIKeyByPrefix(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByPrefix", reflect.TypeOf((*MockServices)(nil).GetAPIKeyByPrefix), arg0)
+}
+
 // GetAccount mocks base method.
 func (m *MockServices) GetAccount(arg0 int64) (models.Account, error) {
 	m.ctrl.T.Helper()
@@ -132,109 +517,850 @@ func (mr *MockServicesMockRecorder) GetAccount(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockServices)(nil).GetAccount), arg0)
 }
 
-// GetEntry mocks base method.
-func (m *MockServices) GetEntry(arg0 int64) (models.Entry, error) {
+// GetApproval mocks base method.
+func (m *MockServices) GetApproval(arg0 int64) (models.Approval, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntry", arg0)
-	ret0, _ := ret[0].(models.Entry)
+	ret := m.ctrl.Call(m, "GetApproval", arg0)
+	ret0, _ := ret[0].(models.Approval)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntry indicates an expected call of GetEntry.
-func (mr *MockServicesMockRecorder) GetEntry(arg0 any) *gomock.Call {
+// GetApproval indicates an expected call of GetApproval.
+func (mr *MockServicesMockRecorder) GetApproval(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockServices)(nil).GetEntry), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApproval", reflect.TypeOf((*MockServices)(nil).GetApproval), arg0)
 }
 
-// GetSession mocks base method.
-func (m *MockServices) GetSession(arg0 uuid.UUID) (models.Session, error) {
+// GetBalanceAt mocks base method.
+func (m *MockServices) GetBalanceAt(arg0 int64, arg1 time.Time) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0)
-	ret0, _ := ret[0].(models.Session)
+	ret := m.ctrl.Call(m, "GetBalanceAt", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockServicesMockRecorder) GetSession(arg0 any) *gomock.Call {
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockServicesMockRecorder) GetBalanceAt(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockServices)(nil).GetSession), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockServices)(nil).GetBalanceAt), arg0, arg1)
 }
 
-// GetTransfer mocks base method.
-func (m *MockServices) GetTransfer(arg0 int64) (models.Transfer, error) {
+// GetEmailVerification mocks base method.
+func (m *MockServices) GetEmailVerification(arg0 string) (models.EmailVerification, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransfer", arg0)
-	ret0, _ := ret[0].(models.Transfer)
+	ret := m.ctrl.Call(m, "GetEmailVerification", arg0)
+	ret0, _ := ret[0].(models.EmailVerification)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransfer indicates an expected call of GetTransfer.
-func (mr *MockServicesMockRecorder) GetTransfer(arg0 any) *gomock.Call {
+// GetEmailVerification indicates an expected call of GetEmailVerification.
+func (mr *MockServicesMockRecorder) GetEmailVerification(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockServices)(nil).GetTransfer), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailVerification", reflect.TypeOf((*MockServices)(nil).GetEmailVerification), arg0)
 }
 
-// GetUser mocks base method.
-func (m *MockServices) GetUser(arg0 string) (models.User, error) {
+// GetEntry mocks base method.
+func (m *MockServices) GetEntry(arg0 int64) (models.Entry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0)
-	ret0, _ := ret[0].(models.User)
+	ret := m.ctrl.Call(m, "GetEntry", arg0)
+	ret0, _ := ret[0].(models.Entry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockServicesMockRecorder) GetUser(arg0 any) *gomock.Call {
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockServicesMockRecorder) GetEntry(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockServices)(nil).GetUser), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockServices)(nil).GetEntry), arg0)
 }
 
-// ListAccounts mocks base method.
-func (m *MockServices) ListAccounts(arg0 services.ListAccountsRequest) ([]models.Account, error) {
+// GetLatestLoginCode mocks base method.
+func (m *MockServices) GetLatestLoginCode(arg0 string) (models.LoginCode, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccounts", arg0)
-	ret0, _ := ret[0].([]models.Account)
+	ret := m.ctrl.Call(m, "GetLatestLoginCode", arg0)
+	ret0, _ := ret[0].(models.LoginCode)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAccounts indicates an expected call of ListAccounts.
-func (mr *MockServicesMockRecorder) ListAccounts(arg0 any) *gomock.Call {
+// GetLatestLoginCode indicates an expected call of GetLatestLoginCode.
+func (mr *MockServicesMockRecorder) GetLatestLoginCode(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockServices)(nil).ListAccounts), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestLoginCode", reflect.TypeOf((*MockServices)(nil).GetLatestLoginCode), arg0)
 }
 
-// Transfer mocks base method.
-func (m *MockServices) Transfer(arg0 services.TransferRequest) (models.Transfer, error) {
+// GetLoginCodeByToken mocks base method.
+func (m *MockServices) GetLoginCodeByToken(arg0 string) (models.LoginCode, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Transfer", arg0)
-	ret0, _ := ret[0].(models.Transfer)
+	ret := m.ctrl.Call(m, "GetLoginCodeByToken", arg0)
+	ret0, _ := ret[0].(models.LoginCode)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Transfer indicates an expected call of Transfer.
-func (mr *MockServicesMockRecorder) Transfer(arg0 any) *gomock.Call {
+// GetLoginCodeByToken indicates an expected call of GetLoginCodeByToken.
+func (mr *MockServicesMockRecorder) GetLoginCodeByToken(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockServices)(nil).Transfer), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginCodeByToken", reflect.TypeOf((*MockServices)(nil).GetLoginCodeByToken), arg0)
 }
 
-// UpdateUser mocks base method.
-func (m *MockServices) UpdateUser(arg0 services.UpdateUserRequest) (models.User, error) {
+// GetLoginFailure mocks base method.
+func (m *MockServices) GetLoginFailure(arg0 string, arg1 string) (models.LoginFailure, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUser", arg0)
-	ret0, _ := ret[0].(models.User)
+	ret := m.ctrl.Call(m, "GetLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(models.LoginFailure)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateUser indicates an expected call of UpdateUser.
-func (mr *MockServicesMockRecorder) UpdateUser(arg0 any) *gomock.Call {
+// GetLoginFailure indicates an expected call of GetLoginFailure.
+func (mr *MockServicesMockRecorder) GetLoginFailure(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockServices)(nil).UpdateUser), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginFailure", reflect.TypeOf((*MockServices)(nil).GetLoginFailure), arg0, arg1)
+}
+
+// GetMFAChallenge mocks base method.
+func (m *MockServices) GetMFAChallenge(arg0 string) (models.MFAChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMFAChallenge", arg0)
+	ret0, _ := ret[0].(models.MFAChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMFAChallenge indicates an expected call of GetMFAChallenge.
+func (mr *MockServicesMockRecorder) GetMFAChallenge(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMFAChallenge", reflect.TypeOf((*MockServices)(nil).GetMFAChallenge), arg0)
+}
+
+// GetOAuthClient mocks base method.
+func (m *MockServices) GetOAuthClient(arg0 string) (models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthClient", arg0)
+	ret0, _ := ret[0].(models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthClient indicates an expected call of GetOAuthClient.
+func (mr *MockServicesMockRecorder) GetOAuthClient(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthClient", reflect.TypeOf((*MockServices)(nil).GetOAuthClient), arg0)
+}
+
+// GetPasswordReset mocks base method.
+func (m *MockServices) GetPasswordReset(arg0 string) (models.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordReset", arg0)
+	ret0, _ := ret[0].(models.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordReset indicates an expected call of GetPasswordReset.
+func (mr *MockServicesMockRecorder) GetPasswordReset(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordReset", reflect.TypeOf((*MockServices)(nil).GetPasswordReset), arg0)
+}
+
+// GetSession mocks base method.
+func (m *MockServices) GetSession(arg0 uuid.UUID) (models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0)
+	ret0, _ := ret[0].(models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockServicesMockRecorder) GetSession(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockServices)(nil).GetSession), arg0)
+}
+
+// GetTOTPCredential mocks base method.
+func (m *MockServices) GetTOTPCredential(arg0 string) (models.TOTPCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTPCredential", arg0)
+	ret0, _ := ret[0].(models.TOTPCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTPCredential indicates an expected call of GetTOTPCredential.
+func (mr *MockServicesMockRecorder) GetTOTPCredential(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTPCredential", reflect.TypeOf((*MockServices)(nil).GetTOTPCredential), arg0)
+}
+
+// GetTransfer mocks base method.
+func (m *MockServices) GetTransfer(arg0 int64) (models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfer", arg0)
+	ret0, _ := ret[0].(models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfer indicates an expected call of GetTransfer.
+func (mr *MockServicesMockRecorder) GetTransfer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockServices)(nil).GetTransfer), arg0)
+}
+
+// GetTrialBalance mocks base method.
+func (m *MockServices) GetTrialBalance(arg0 time.Time) (services.TrialBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrialBalance", arg0)
+	ret0, _ := ret[0].(services.TrialBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrialBalance indicates an expected call of GetTrialBalance.
+func (mr *MockServicesMockRecorder) GetTrialBalance(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrialBalance", reflect.TypeOf((*MockServices)(nil).GetTrialBalance), arg0)
+}
+
+// GetUser mocks base method.
+func (m *MockServices) GetUser(arg0 string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockServicesMockRecorder) GetUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockServices)(nil).GetUser), arg0)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockServices) GetUserByEmail(arg0 string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockServicesMockRecorder) GetUserByEmail(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockServices)(nil).GetUserByEmail), arg0)
+}
+
+// IsTokenRevoked mocks base method.
+func (m *MockServices) IsTokenRevoked(arg0 uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenRevoked", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockServicesMockRecorder) IsTokenRevoked(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockServices)(nil).IsTokenRevoked), arg0)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockServices) ListAPIKeys(arg0 string) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockServicesMockRecorder) ListAPIKeys(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockServices)(nil).ListAPIKeys), arg0)
+}
+
+// ListAccountEntries mocks base method.
+func (m *MockServices) ListAccountEntries(arg0 services.AdminAction, arg1 services.ListEntriesRequest) ([]models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountEntries", arg0, arg1)
+	ret0, _ := ret[0].([]models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountEntries indicates an expected call of ListAccountEntries.
+func (mr *MockServicesMockRecorder) ListAccountEntries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountEntries", reflect.TypeOf((*MockServices)(nil).ListAccountEntries), arg0, arg1)
+}
+
+// ListAccounts mocks base method.
+func (m *MockServices) ListAccounts(arg0 services.ListAccountsRequest) ([]models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", arg0)
+	ret0, _ := ret[0].([]models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockServicesMockRecorder) ListAccounts(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockServices)(nil).ListAccounts), arg0)
+}
+
+// ListActiveSessions mocks base method.
+func (m *MockServices) ListActiveSessions(arg0 string) ([]models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveSessions", arg0)
+	ret0, _ := ret[0].([]models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveSessions indicates an expected call of ListActiveSessions.
+func (mr *MockServicesMockRecorder) ListActiveSessions(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveSessions", reflect.TypeOf((*MockServices)(nil).ListActiveSessions), arg0)
+}
+
+// ListApprovals mocks base method.
+func (m *MockServices) ListApprovals(arg0 services.ListApprovalsRequest) ([]models.Approval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApprovals", arg0)
+	ret0, _ := ret[0].([]models.Approval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApprovals indicates an expected call of ListApprovals.
+func (mr *MockServicesMockRecorder) ListApprovals(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApprovals", reflect.TypeOf((*MockServices)(nil).ListApprovals), arg0)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockServices) ListAuditEvents(arg0 services.AdminAction, arg1 services.ListAuditEventsRequest) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockServicesMockRecorder) ListAuditEvents(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockServices)(nil).ListAuditEvents), arg0, arg1)
+}
+
+// ListGLAccounts mocks base method.
+func (m *MockServices) ListGLAccounts() ([]models.GLAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGLAccounts")
+	ret0, _ := ret[0].([]models.GLAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGLAccounts indicates an expected call of ListGLAccounts.
+func (mr *MockServicesMockRecorder) ListGLAccounts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGLAccounts", reflect.TypeOf((*MockServices)(nil).ListGLAccounts))
+}
+
+// ListOAuthClients mocks base method.
+func (m *MockServices) ListOAuthClients(arg0 string) ([]models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOAuthClients", arg0)
+	ret0, _ := ret[0].([]models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOAuthClients indicates an expected call of ListOAuthClients.
+func (mr *MockServicesMockRecorder) ListOAuthClients(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOAuthClients", reflect.TypeOf((*MockServices)(nil).ListOAuthClients), arg0)
+}
+
+// ListPasswordHistory mocks base method.
+func (m *MockServices) ListPasswordHistory(arg0 string, arg1 int) ([]models.PasswordHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPasswordHistory", arg0, arg1)
+	ret0, _ := ret[0].([]models.PasswordHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPasswordHistory indicates an expected call of ListPasswordHistory.
+func (mr *MockServicesMockRecorder) ListPasswordHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPasswordHistory", reflect.TypeOf((*MockServices)(nil).ListPasswordHistory), arg0, arg1)
+}
+
+// ListTransferStatusHistory mocks base method.
+func (m *MockServices) ListTransferStatusHistory(arg0 int64) ([]models.TransferStatusHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransferStatusHistory", arg0)
+	ret0, _ := ret[0].([]models.TransferStatusHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransferStatusHistory indicates an expected call of ListTransferStatusHistory.
+func (mr *MockServicesMockRecorder) ListTransferStatusHistory(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransferStatusHistory", reflect.TypeOf((*MockServices)(nil).ListTransferStatusHistory), arg0)
+}
+
+// ListTrustedDevices mocks base method.
+func (m *MockServices) ListTrustedDevices(arg0 string) ([]models.TrustedDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrustedDevices", arg0)
+	ret0, _ := ret[0].([]models.TrustedDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrustedDevices indicates an expected call of ListTrustedDevices.
+func (mr *MockServicesMockRecorder) ListTrustedDevices(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrustedDevices", reflect.TypeOf((*MockServices)(nil).ListTrustedDevices), arg0)
+}
+
+// ListUserAccounts mocks base method.
+func (m *MockServices) ListUserAccounts(arg0 services.AdminAction, arg1 string) ([]models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserAccounts indicates an expected call of ListUserAccounts.
+func (mr *MockServicesMockRecorder) ListUserAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserAccounts", reflect.TypeOf((*MockServices)(nil).ListUserAccounts), arg0, arg1)
+}
+
+// ListUserSessions mocks base method.
+func (m *MockServices) ListUserSessions(arg0 services.AdminAction, arg1 string) ([]models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserSessions", arg0, arg1)
+	ret0, _ := ret[0].([]models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserSessions indicates an expected call of ListUserSessions.
+func (mr *MockServicesMockRecorder) ListUserSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserSessions", reflect.TypeOf((*MockServices)(nil).ListUserSessions), arg0, arg1)
+}
+
+// RecordLoginCodeAttempt mocks base method.
+func (m *MockServices) RecordLoginCodeAttempt(arg0 int64) (models.LoginCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginCodeAttempt", arg0)
+	ret0, _ := ret[0].(models.LoginCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginCodeAttempt indicates an expected call of RecordLoginCodeAttempt.
+func (mr *MockServicesMockRecorder) RecordLoginCodeAttempt(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginCodeAttempt", reflect.TypeOf((*MockServices)(nil).RecordLoginCodeAttempt), arg0)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockServices) RecordLoginFailure(arg0 string, arg1 string, arg2 time.Time) (models.LoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.LoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockServicesMockRecorder) RecordLoginFailure(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockServices)(nil).RecordLoginFailure), arg0, arg1, arg2)
+}
+
+// RehashPassword mocks base method.
+func (m *MockServices) RehashPassword(arg0 string, arg1 string, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashPassword", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RehashPassword indicates an expected call of RehashPassword.
+func (mr *MockServicesMockRecorder) RehashPassword(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashPassword", reflect.TypeOf((*MockServices)(nil).RehashPassword), arg0, arg1, arg2)
+}
+
+// ResetPassword mocks base method.
+func (m *MockServices) ResetPassword(arg0 models.PasswordReset, arg1 string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", arg0, arg1)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockServicesMockRecorder) ResetPassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockServices)(nil).ResetPassword), arg0, arg1)
+}
+
+// ReverseTransfer mocks base method.
+func (m *MockServices) ReverseTransfer(arg0 services.UpdateTransferStatusRequest) (models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTransfer", arg0)
+	ret0, _ := ret[0].(models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransfer indicates an expected call of ReverseTransfer.
+func (mr *MockServicesMockRecorder) ReverseTransfer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransfer", reflect.TypeOf((*MockServices)(nil).ReverseTransfer), arg0)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockServices) RevokeAPIKey(arg0 string, arg1 uuid.UUID) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockServicesMockRecorder) RevokeAPIKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockServices)(nil).RevokeAPIKey), arg0, arg1)
+}
+
+// RevokeOtherSessions mocks base method.
+func (m *MockServices) RevokeOtherSessions(arg0 string, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeOtherSessions indicates an expected call of RevokeOtherSessions.
+func (mr *MockServicesMockRecorder) RevokeOtherSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherSessions", reflect.TypeOf((*MockServices)(nil).RevokeOtherSessions), arg0, arg1)
+}
+
+// RevokeSession mocks base method.
+func (m *MockServices) RevokeSession(arg0 string, arg1 uuid.UUID) (models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", arg0, arg1)
+	ret0, _ := ret[0].(models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockServicesMockRecorder) RevokeSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockServices)(nil).RevokeSession), arg0, arg1)
+}
+
+// RevokeSessionFamily mocks base method.
+func (m *MockServices) RevokeSessionFamily(arg0 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessionFamily", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSessionFamily indicates an expected call of RevokeSessionFamily.
+func (mr *MockServicesMockRecorder) RevokeSessionFamily(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessionFamily", reflect.TypeOf((*MockServices)(nil).RevokeSessionFamily), arg0)
+}
+
+// RevokeToken mocks base method.
+func (m *MockServices) RevokeToken(arg0 services.RevokeTokenRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockServicesMockRecorder) RevokeToken(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockServices)(nil).RevokeToken), arg0)
+}
+
+// RotateSession mocks base method.
+func (m *MockServices) RotateSession(arg0 uuid.UUID, arg1 models.Session) (models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSession", arg0, arg1)
+	ret0, _ := ret[0].(models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSession indicates an expected call of RotateSession.
+func (mr *MockServicesMockRecorder) RotateSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSession", reflect.TypeOf((*MockServices)(nil).RotateSession), arg0, arg1)
+}
+
+// SaveTOTPCredential mocks base method.
+func (m *MockServices) SaveTOTPCredential(arg0 models.TOTPCredential) (models.TOTPCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTPCredential", arg0)
+	ret0, _ := ret[0].(models.TOTPCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveTOTPCredential indicates an expected call of SaveTOTPCredential.
+func (mr *MockServicesMockRecorder) SaveTOTPCredential(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTPCredential", reflect.TypeOf((*MockServices)(nil).SaveTOTPCredential), arg0)
+}
+
+// SearchUsers mocks base method.
+func (m *MockServices) SearchUsers(arg0 services.AdminAction, arg1 services.SearchUsersRequest) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", arg0, arg1)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockServicesMockRecorder) SearchUsers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockServices)(nil).SearchUsers), arg0, arg1)
+}
+
+// SetAccountFrozen mocks base method.
+func (m *MockServices) SetAccountFrozen(arg0 services.AdminAction, arg1 int64, arg2 bool) (models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccountFrozen", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetAccountFrozen indicates an expected call of SetAccountFrozen.
+func (mr *MockServicesMockRecorder) SetAccountFrozen(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountFrozen", reflect.TypeOf((*MockServices)(nil).SetAccountFrozen), arg0, arg1, arg2)
+}
+
+// SetHotAccounts mocks base method.
+func (m *MockServices) SetHotAccounts(arg0 []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHotAccounts", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHotAccounts indicates an expected call of SetHotAccounts.
+func (mr *MockServicesMockRecorder) SetHotAccounts(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHotAccounts", reflect.TypeOf((*MockServices)(nil).SetHotAccounts), arg0)
+}
+
+// SetUserFrozen mocks base method.
+func (m *MockServices) SetUserFrozen(arg0 services.AdminAction, arg1 string, arg2 bool) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserFrozen", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserFrozen indicates an expected call of SetUserFrozen.
+func (mr *MockServicesMockRecorder) SetUserFrozen(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserFrozen", reflect.TypeOf((*MockServices)(nil).SetUserFrozen), arg0, arg1, arg2)
+}
+
+// SyncChartOfAccounts mocks base method.
+func (m *MockServices) SyncChartOfAccounts(arg0 []models.GLAccount) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncChartOfAccounts", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncChartOfAccounts indicates an expected call of SyncChartOfAccounts.
+func (mr *MockServicesMockRecorder) SyncChartOfAccounts(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncChartOfAccounts", reflect.TypeOf((*MockServices)(nil).SyncChartOfAccounts), arg0)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockServices) TouchAPIKey(arg0 uuid.UUID, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockServicesMockRecorder) TouchAPIKey(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockServices)(nil).TouchAPIKey), arg0, arg1, arg2)
+}
+
+// Transfer mocks base method.
+func (m *MockServices) Transfer(arg0 services.TransferRequest) (models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", arg0)
+	ret0, _ := ret[0].(models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockServicesMockRecorder) Transfer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockServices)(nil).Transfer), arg0)
+}
+
+// TrustDevice mocks base method.
+func (m *MockServices) TrustDevice(arg0 models.TrustedDevice) (models.TrustedDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrustDevice", arg0)
+	ret0, _ := ret[0].(models.TrustedDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TrustDevice indicates an expected call of TrustDevice.
+func (mr *MockServicesMockRecorder) TrustDevice(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrustDevice", reflect.TypeOf((*MockServices)(nil).TrustDevice), arg0)
+}
+
+// UnlockUser mocks base method.
+func (m *MockServices) UnlockUser(arg0 services.AdminAction, arg1 string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", arg0, arg1)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockServicesMockRecorder) UnlockUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockServices)(nil).UnlockUser), arg0, arg1)
+}
+
+// UpdateRateLimitBucket mocks base method.
+func (m *MockServices) UpdateRateLimitBucket(arg0 string, arg1 func(float64, time.Time, bool) float64, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRateLimitBucket", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRateLimitBucket indicates an expected call of UpdateRateLimitBucket.
+func (mr *MockServicesMockRecorder) UpdateRateLimitBucket(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRateLimitBucket", reflect.TypeOf((*MockServices)(nil).UpdateRateLimitBucket), arg0, arg1, arg2)
+}
+
+// UpdateUser mocks base method.
+func (m *MockServices) UpdateUser(arg0 services.UpdateUserRequest) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", arg0)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockServicesMockRecorder) UpdateUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockServices)(nil).UpdateUser), arg0)
+}
+
+// UseLoginCode mocks base method.
+func (m *MockServices) UseLoginCode(arg0 models.LoginCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseLoginCode", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseLoginCode indicates an expected call of UseLoginCode.
+func (mr *MockServicesMockRecorder) UseLoginCode(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseLoginCode", reflect.TypeOf((*MockServices)(nil).UseLoginCode), arg0)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockServices) UseRecoveryCode(arg0 string, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockServicesMockRecorder) UseRecoveryCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockServices)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockServices) UseTOTPStep(arg0 string, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockServicesMockRecorder) UseTOTPStep(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockServices)(nil).UseTOTPStep), arg0, arg1)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockServices) VerifyEmail(arg0 models.EmailVerification) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", arg0)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockServicesMockRecorder) VerifyEmail(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockServices)(nil).VerifyEmail), arg0)
 }
 
 // WithdrawMoney mocks base method.
@@ -6,10 +6,17 @@ import (
 )
 
 type Account struct {
-	ID        int64          `gorm:"column:id"`
-	Owner     string         `gorm:"column:owner"`
-	Balance   int64          `gorm:"column:balance"`
+	ID       int64  `gorm:"column:id"`
+	Owner    string `gorm:"column:owner"`
+	Balance  int64  `gorm:"column:balance"`
+	IsSystem bool   `gorm:"column:is_system"`
+	// IsHot marks accounts receiving so many credits that they are recorded as pending credits
+	// instead of updating the balance under a lock
+	IsHot     bool           `gorm:"column:is_hot"`
 	CreatedAt time.Time      `gorm:"column:created_at"`
 	UpdatedAt time.Time      `gorm:"column:updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
+
+	// FrozenAt is when the account was frozen by the back office, nil if the account is not frozen
+	FrozenAt *time.Time `gorm:"column:frozen_at"`
 }
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// APIKey lets a user authenticate server-to-server integrations without logging in.
+// Only the prefix of the key is stored in clear, its secret is stored hashed.
+type APIKey struct {
+	ID           uuid.UUID `gorm:"column:id"`
+	Owner        string    `gorm:"column:owner"`
+	Name         string    `gorm:"column:name"`
+	Prefix       string    `gorm:"column:prefix"`
+	HashedSecret string    `gorm:"column:hashed_secret"`
+	// Scopes is the space separated list of the scopes granted to the key
+	Scopes string `gorm:"column:scopes"`
+	// AllowedIPs is the space separated list of the addresses and CIDR ranges allowed to use the key,
+	// empty if every address is allowed
+	AllowedIPs string     `gorm:"column:allowed_ips"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+	LastUsedIP string     `gorm:"column:last_used_ip"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+}
+
+// ScopeList returns the scopes granted to the key.
+func (key APIKey) ScopeList() []string {
+	return strings.Fields(key.Scopes)
+}
+
+// AllowedIPList returns the addresses and CIDR ranges allowed to use the key.
+func (key APIKey) AllowedIPList() []string {
+	return strings.Fields(key.AllowedIPs)
+}
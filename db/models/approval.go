@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// operations requiring the approval of a second user
+const (
+	// OperationAdjustBalance is a manual adjustment of the balance of an account
+	OperationAdjustBalance = "adjust_balance"
+	// OperationTransfer is a transfer of an amount above the approval threshold
+	OperationTransfer = "transfer"
+	// OperationCloseAccount is the closure of an account
+	OperationCloseAccount = "close_account"
+)
+
+// statuses of an approval
+const (
+	// ApprovalStatusPending is the status of a requested operation waiting for a decision
+	ApprovalStatusPending = "pending"
+	// ApprovalStatusApproved is the status of an approved operation that is being executed
+	ApprovalStatusApproved = "approved"
+	// ApprovalStatusExecuted is the status of an approved operation that has been executed
+	ApprovalStatusExecuted = "executed"
+	// ApprovalStatusFailed is the status of an approved operation whose execution failed
+	ApprovalStatusFailed = "failed"
+	// ApprovalStatusRejected is the status of a rejected operation
+	ApprovalStatusRejected = "rejected"
+	// ApprovalStatusExpired is the status of an operation that was not decided in time
+	ApprovalStatusExpired = "expired"
+)
+
+// Approval is an operation waiting for, or having received, the decision of a user other than its requester.
+type Approval struct {
+	ID             int64      `gorm:"column:id"`
+	Operation      string     `gorm:"column:operation"`
+	Payload        string     `gorm:"column:payload;type:jsonb"` // JSON object with the parameters of the operation
+	Status         string     `gorm:"column:status"`
+	RequestedBy    string     `gorm:"column:requested_by"`
+	Reason         string     `gorm:"column:reason"`
+	DecidedBy      *string    `gorm:"column:decided_by"`
+	DecisionReason string     `gorm:"column:decision_reason"`
+	Result         *string    `gorm:"column:result;type:jsonb"` // JSON result of the executed operation
+	Error          string     `gorm:"column:error"`             // why the execution failed
+	CreatedAt      time.Time  `gorm:"column:created_at"`
+	ExpiresAt      time.Time  `gorm:"column:expires_at"`
+	DecidedAt      *time.Time `gorm:"column:decided_at"`
+	ExecutedAt     *time.Time `gorm:"column:executed_at"`
+}
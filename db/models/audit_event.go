@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// actions of the back office recorded in the audit trail
+const (
+	AuditActionSearchUsers        = "search_users"
+	AuditActionListUserAccounts   = "list_user_accounts"
+	AuditActionListUserSessions   = "list_user_sessions"
+	AuditActionListAccountEntries = "list_account_entries"
+	AuditActionFreezeUser         = "freeze_user"
+	AuditActionUnfreezeUser       = "unfreeze_user"
+	AuditActionFreezeAccount      = "freeze_account"
+	AuditActionUnfreezeAccount    = "unfreeze_account"
+	AuditActionForcePasswordReset = "force_password_reset"
+	AuditActionUnlockUser         = "unlock_user"
+	AuditActionAdjustBalance      = "adjust_balance"
+	AuditActionRevokeToken        = "revoke_token"
+	AuditActionListAuditEvents    = "list_audit_events"
+	AuditActionRequestApproval    = "request_approval"
+	AuditActionApproveOperation   = "approve_operation"
+	AuditActionRejectOperation    = "reject_operation"
+	AuditActionExpireApproval     = "expire_approval"
+)
+
+// types of the targets of audited actions
+const (
+	AuditTargetUser     = "user"
+	AuditTargetAccount  = "account"
+	AuditTargetToken    = "token"
+	AuditTargetAudit    = "audit"
+	AuditTargetApproval = "approval"
+)
+
+// AuditEvent records an action of the back office.
+type AuditEvent struct {
+	ID         int64     `gorm:"column:id"`
+	Operator   string    `gorm:"column:operator"` // username of the staff member who acted
+	Action     string    `gorm:"column:action"`
+	TargetType string    `gorm:"column:target_type"`
+	TargetID   string    `gorm:"column:target_id"`
+	Reason     string    `gorm:"column:reason"`
+	Details    string    `gorm:"column:details;type:jsonb"` // JSON object with the parameters of the action
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
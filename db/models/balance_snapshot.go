@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// BalanceSnapshot is the closing balance of an account at SnapshotAt.
+// It includes every entry of the account created before SnapshotAt.
+type BalanceSnapshot struct {
+	ID         int64     `gorm:"column:id"`
+	AccountID  int64     `gorm:"column:account_id"`
+	Balance    int64     `gorm:"column:balance"`
+	SnapshotAt time.Time `gorm:"column:snapshot_at"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// EmailVerification is a token emailed to a user to verify their email.
+type EmailVerification struct {
+	ID          int64  `gorm:"column:id"`
+	HashedToken string `gorm:"column:hashed_token"`
+	Username    string `gorm:"column:username"`
+	// Email is the address the token was sent to
+	Email     string    `gorm:"column:email"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// SystemUsername is the owner of the bank's own accounts. It is not a valid username,
+// so no customer can register or log in as it.
+const SystemUsername = "_system"
+
+// codes of the general ledger accounts the services post to
+const (
+	GLCodeCash            = "1000"
+	GLCodeSuspense        = "1900"
+	GLCodeFeeIncome       = "4000"
+	GLCodeInterestExpense = "5000"
+)
+
+// GLAccount is an entry of the chart of accounts. Its balance is kept in the system owned
+// account with id = AccountID.
+type GLAccount struct {
+	Code      string    `gorm:"column:code;primaryKey"`
+	Name      string    `gorm:"column:name"`
+	Category  string    `gorm:"column:category"` // one of asset, liability, equity, income, expense
+	AccountID int64     `gorm:"column:account_id"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// LoginCode is a short code and a magic link token delivered to a user logging in without a password.
+type LoginCode struct {
+	ID       int64  `gorm:"column:id"`
+	Username string `gorm:"column:username"`
+	// HashedCode is the hash of the code the user types, HashedToken the hash of the token of the magic link
+	HashedCode  string `gorm:"column:hashed_code"`
+	HashedToken string `gorm:"column:hashed_token"`
+	// Attempts is the number of wrong codes tried
+	Attempts  int32     `gorm:"column:attempts"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+	// UsedAt is when the user logged in with the code, nil until then
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// kinds of the subjects of failed logins
+const (
+	LoginFailureKindUsername = "username"
+	LoginFailureKindIP       = "ip"
+)
+
+// LoginFailure counts the failed logins of a username or of a client address.
+type LoginFailure struct {
+	Kind         string    `gorm:"column:kind;primaryKey"`
+	Subject      string    `gorm:"column:subject;primaryKey"`
+	Failures     int32     `gorm:"column:failures"`
+	LastFailedAt time.Time `gorm:"column:last_failed_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// TOTPCredential is the time-based one-time password credential of a user.
+type TOTPCredential struct {
+	Username string `gorm:"column:username;primaryKey"`
+	// EncryptedSecret is the encrypted shared secret of the authenticator of the user
+	EncryptedSecret string `gorm:"column:encrypted_secret"`
+	// ConfirmedAt is when the user confirmed the enrollment with a first code, nil until then
+	ConfirmedAt *time.Time `gorm:"column:confirmed_at"`
+	// LastUsedStep is the time step of the last accepted code, so codes cannot be replayed
+	LastUsedStep int64     `gorm:"column:last_used_step"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+}
+
+// IsConfirmed reports whether the credential is confirmed, so logins require a code.
+func (credential TOTPCredential) IsConfirmed() bool {
+	return credential.ConfirmedAt != nil
+}
+
+// RecoveryCode replaces a code of the authenticator of a user once.
+type RecoveryCode struct {
+	ID         int64      `gorm:"column:id"`
+	Username   string     `gorm:"column:username"`
+	HashedCode string     `gorm:"column:hashed_code"`
+	UsedAt     *time.Time `gorm:"column:used_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+}
+
+// MFAChallenge is the second step of the login of a user with two-factor authentication.
+type MFAChallenge struct {
+	ID          int64      `gorm:"column:id"`
+	HashedToken string     `gorm:"column:hashed_token"`
+	Username    string     `gorm:"column:username"`
+	Attempts    int32      `gorm:"column:attempts"`
+	ExpiresAt   time.Time  `gorm:"column:expires_at"`
+	ConsumedAt  *time.Time `gorm:"column:consumed_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// OAuthClient is a third-party app allowed to request scoped tokens through the OAuth2 server.
+type OAuthClient struct {
+	ID    string `gorm:"column:id"`
+	Owner string `gorm:"column:owner"`
+	Name  string `gorm:"column:name"`
+	// HashedSecret is the hash of the secret of a confidential client, empty for public clients
+	HashedSecret string `gorm:"column:hashed_secret"`
+	// RedirectURIs is the space separated list of the uris authorization codes can be sent to
+	RedirectURIs string `gorm:"column:redirect_uris"`
+	// Scopes is the space separated list of the scopes the client can request
+	Scopes    string    `gorm:"column:scopes"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+// TableName overrides the table name, since gorm splits the OAuth initialism.
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// IsConfidential reports whether the client authenticates with a secret.
+func (client OAuthClient) IsConfidential() bool {
+	return client.HashedSecret != ""
+}
+
+// RedirectURIList returns the uris authorization codes can be sent to.
+func (client OAuthClient) RedirectURIList() []string {
+	return strings.Fields(client.RedirectURIs)
+}
+
+// ScopeList returns the scopes the client can request.
+func (client OAuthClient) ScopeList() []string {
+	return strings.Fields(client.Scopes)
+}
+
+// AuthorizationCode is granted by a user to an OAuth client, which exchanges it once for an access token.
+type AuthorizationCode struct {
+	HashedCode  string `gorm:"column:hashed_code;primaryKey"`
+	ClientID    string `gorm:"column:client_id"`
+	Username    string `gorm:"column:username"`
+	RedirectURI string `gorm:"column:redirect_uri"`
+	// Scopes is the space separated list of the scopes granted by the user
+	Scopes string `gorm:"column:scopes"`
+	// CodeChallenge is the S256 pkce challenge the client must answer when exchanging the code
+	CodeChallenge string     `gorm:"column:code_challenge"`
+	ExpiresAt     time.Time  `gorm:"column:expires_at"`
+	ConsumedAt    *time.Time `gorm:"column:consumed_at"`
+	CreatedAt     time.Time  `gorm:"column:created_at"`
+}
+
+// TableName overrides the table name, since authorization codes belong to the OAuth2 server.
+func (AuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// ScopeList returns the scopes granted by the user.
+func (code AuthorizationCode) ScopeList() []string {
+	return strings.Fields(code.Scopes)
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PasswordHistory is the hash of a password a user has had, so they cannot reuse it.
+type PasswordHistory struct {
+	ID             int64     `gorm:"column:id"`
+	Username       string    `gorm:"column:username"`
+	HashedPassword string    `gorm:"column:hashed_password"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+}
+
+// TableName overrides the table name, since a history is not pluralized.
+func (PasswordHistory) TableName() string {
+	return "password_history"
+}
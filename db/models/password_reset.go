@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PasswordReset is a token emailed to a user who forgot their password.
+type PasswordReset struct {
+	ID          int64     `gorm:"column:id"`
+	HashedToken string    `gorm:"column:hashed_token"`
+	Username    string    `gorm:"column:username"`
+	ExpiresAt   time.Time `gorm:"column:expires_at"`
+	// UsedAt is when the password was reset with the token, nil until then
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+}
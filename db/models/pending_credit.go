@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PendingCredit is a credit to a hot account that is not yet part of the balance of the account.
+type PendingCredit struct {
+	ID        int64     `gorm:"column:id"`
+	AccountID int64     `gorm:"column:account_id"`
+	Amount    int64     `gorm:"column:amount"`
+	EntryID   int64     `gorm:"column:entry_id"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// RateLimitBucket is the token bucket of a subject on a route, requests take tokens that refill over time.
+type RateLimitBucket struct {
+	Key       string    `gorm:"column:key;primaryKey"`
+	Tokens    float64   `gorm:"column:tokens"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
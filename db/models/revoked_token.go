@@ -0,0 +1,16 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// RevokedToken is a token that cannot be used anymore, even though it has not expired.
+type RevokedToken struct {
+	TokenID   uuid.UUID `gorm:"column:token_id;primaryKey"`
+	Username  string    `gorm:"column:username"`
+	Reason    string    `gorm:"column:reason"`
+	RevokedBy string    `gorm:"column:revoked_by"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
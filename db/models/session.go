@@ -16,4 +16,9 @@ type Session struct {
 	CreatedAt    time.Time      `gorm:"column:created_at"`
 	ExpiresAt    time.Time      `gorm:"column:expires_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at"`
+
+	// FamilyID is the id of the first session of the chain of sessions created by renewing refresh tokens
+	FamilyID uuid.UUID `gorm:"column:family_id"`
+	// ConsumedAt is when the refresh token of the session was exchanged for a new one, nil if it was not
+	ConsumedAt *time.Time `gorm:"column:consumed_at"`
 }
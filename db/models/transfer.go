@@ -5,14 +5,46 @@ import (
 	"time"
 )
 
+// statuses of a transfer
+const (
+	// TransferStatusPending is the status of a requested transfer that has not moved any money yet
+	TransferStatusPending = "pending"
+	// TransferStatusCompleted is the status of a transfer whose money has been moved
+	TransferStatusCompleted = "completed"
+	// TransferStatusFailed is the status of a pending transfer that could not be completed
+	TransferStatusFailed = "failed"
+	// TransferStatusReversed is the status of a completed transfer whose money has been moved back
+	TransferStatusReversed = "reversed"
+	// TransferStatusCancelled is the status of a pending transfer that has been cancelled
+	TransferStatusCancelled = "cancelled"
+)
+
 type Transfer struct {
 	ID              int64          `gorm:"column:id"`
 	FromAccountID   int64          `gorm:"column:from_account_id"`
 	ToAccountID     int64          `gorm:"column:to_account_id"`
 	Amount          int32          `gorm:"column:amount"` // Amount range: [1, maxint32]
-	IncomingEntryID int64          `gorm:"column:incoming_entry_id"`
-	OutgoingEntryID int64          `gorm:"column:outgoing_entry_id"`
+	Status          string         `gorm:"column:status"`
+	StatusReason    string         `gorm:"column:status_reason"`     // reason of the latest status change
+	IncomingEntryID int64          `gorm:"column:incoming_entry_id"` // zero until the transfer completes
+	OutgoingEntryID int64          `gorm:"column:outgoing_entry_id"` // zero until the transfer completes
 	CreatedAt       time.Time      `gorm:"column:created_at"`
 	UpdatedAt       time.Time      `gorm:"column:updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"column:deleted_at"`
 }
+
+// TransferStatusHistory records a status change of a transfer.
+type TransferStatusHistory struct {
+	ID         int64     `gorm:"column:id"`
+	TransferID int64     `gorm:"column:transfer_id"`
+	FromStatus string    `gorm:"column:from_status"` // empty when the transfer is created
+	ToStatus   string    `gorm:"column:to_status"`
+	Reason     string    `gorm:"column:reason"`
+	Actor      string    `gorm:"column:actor"` // username of who caused the change
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+// TableName overrides the table name, since the history of a transfer is not a plural noun.
+func (TransferStatusHistory) TableName() string {
+	return "transfer_status_history"
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TrustedDevice is a device a user logged in from. Logins from other devices or networks alert the user.
+type TrustedDevice struct {
+	ID       int64  `gorm:"column:id"`
+	Username string `gorm:"column:username"`
+	// Fingerprint identifies the device across logins
+	Fingerprint string `gorm:"column:fingerprint"`
+	UserAgent   string `gorm:"column:user_agent"`
+	// IPRange is the network the device last logged in from, LastIP its address
+	IPRange    string    `gorm:"column:ip_range"`
+	LastIP     string    `gorm:"column:last_ip"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+	LastSeenAt time.Time `gorm:"column:last_seen_at"`
+}
@@ -5,6 +5,18 @@ import (
 	"time"
 )
 
+// roles of a user
+const (
+	// RoleCustomer is the role of users who can only access their own resources
+	RoleCustomer = "customer"
+	// RoleTeller is the role of bank staff who can look up customers and deposit money into any account
+	RoleTeller = "teller"
+	// RoleAuditor is the role of bank staff with read-only access to everything
+	RoleAuditor = "auditor"
+	// RoleAdmin is the role of bank staff who can do everything
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	Username       string         `gorm:"column:username"`
 	HashedPassword string         `gorm:"column:hashed_password"`
@@ -13,4 +25,12 @@ type User struct {
 	CreatedAt      time.Time      `gorm:"column:created_at"`
 	UpdatedAt      time.Time      `gorm:"column:updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"column:deleted_at"`
+
+	Role string `gorm:"column:role"`
+	// FrozenAt is when the user was frozen by the back office, nil if the user is not frozen
+	FrozenAt *time.Time `gorm:"column:frozen_at"`
+	// PasswordResetRequired is set by the back office to make the user change their password
+	PasswordResetRequired bool `gorm:"column:password_reset_required"`
+	// EmailVerified is true once the user proved they own Email, and false again when they change it
+	EmailVerified bool `gorm:"column:email_verified"`
 }
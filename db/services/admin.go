@@ -0,0 +1,389 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMissingReason is returned when an action of the back office has no reason
+	ErrMissingReason = errors.New("a reason is required")
+	// ErrAccountFrozen is returned when money is moved from or to a frozen account
+	ErrAccountFrozen = errors.New("account is frozen")
+	// ErrInvalidAdjustment is returned when a manual adjustment has no amount
+	ErrInvalidAdjustment = errors.New("adjustment amount must not be zero")
+)
+
+// recordAuditEvent writes an action of the back office to the audit trail.
+// It must run in the transaction of the action, so that no action happens without being recorded.
+func recordAuditEvent(tx *gorm.DB, action AdminAction, name, targetType, targetID string, details map[string]any) error {
+	if strings.TrimSpace(action.Reason) == "" {
+		return ErrMissingReason
+	}
+	if details == nil {
+		details = map[string]any{}
+	}
+
+	encodedDetails, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.AuditEvent{
+		Operator:   action.Operator,
+		Action:     name,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     strings.TrimSpace(action.Reason),
+		Details:    string(encodedDetails),
+		CreatedAt:  time.Now().UTC(),
+	}).Error
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(pattern string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(pattern)
+}
+
+// SearchUsers returns the users whose username or email contains the query, ordered by username.
+func (services *SQLServices) SearchUsers(action AdminAction, req SearchUsersRequest) ([]models.User, error) {
+	users := []models.User{}
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordAuditEvent(tx, action, models.AuditActionSearchUsers, models.AuditTargetUser, req.Query, nil); err != nil {
+			return err
+		}
+
+		pattern := "%" + escapeLike(req.Query) + "%"
+		return tx.
+			Where("username <> ? AND (username ILIKE ? OR email ILIKE ?)", models.SystemUsername, pattern, pattern).
+			Order("username").
+			Limit(req.PageSize).
+			Offset((req.PageNumber - 1) * req.PageSize).
+			Find(&users).Error
+	}); err != nil {
+		return []models.User{}, err
+	}
+
+	return users, nil
+}
+
+// ListUserAccounts returns every account of a user.
+func (services *SQLServices) ListUserAccounts(action AdminAction, username string) ([]models.Account, error) {
+	accounts := []models.Account{}
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordAuditEvent(tx, action, models.AuditActionListUserAccounts, models.AuditTargetUser, username, nil); err != nil {
+			return err
+		}
+
+		return tx.Where("owner = ? AND is_system = false", username).Order("id").Find(&accounts).Error
+	}); err != nil {
+		return []models.Account{}, err
+	}
+	if err := services.addPendingCredits(accounts); err != nil {
+		return []models.Account{}, err
+	}
+
+	return accounts, nil
+}
+
+// ListUserSessions returns every session of a user, including blocked, expired and consumed ones, newest first.
+func (services *SQLServices) ListUserSessions(action AdminAction, username string) ([]models.Session, error) {
+	sessions := []models.Session{}
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordAuditEvent(tx, action, models.AuditActionListUserSessions, models.AuditTargetUser, username, nil); err != nil {
+			return err
+		}
+
+		return tx.Where("username = ?", username).Order("created_at DESC").Find(&sessions).Error
+	}); err != nil {
+		return []models.Session{}, err
+	}
+
+	return sessions, nil
+}
+
+// ListAccountEntries returns the entries of an account, newest first.
+func (services *SQLServices) ListAccountEntries(action AdminAction, req ListEntriesRequest) ([]models.Entry, error) {
+	entries := []models.Entry{}
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		targetID := strconv.FormatInt(req.AccountID, 10)
+		if err := recordAuditEvent(tx, action, models.AuditActionListAccountEntries, models.AuditTargetAccount, targetID, nil); err != nil {
+			return err
+		}
+
+		return tx.
+			Where("account_id = ?", req.AccountID).
+			Order("id DESC").
+			Limit(req.PageSize).
+			Offset((req.PageNumber - 1) * req.PageSize).
+			Find(&entries).Error
+	}); err != nil {
+		return []models.Entry{}, err
+	}
+
+	return entries, nil
+}
+
+// SetUserFrozen freezes or unfreezes a user. Freezing a user also blocks all of its sessions.
+// It returns gorm.ErrRecordNotFound if the user does not exist.
+func (services *SQLServices) SetUserFrozen(action AdminAction, username string, frozen bool) (models.User, error) {
+	var user models.User
+
+	name := models.AuditActionUnfreezeUser
+	var frozenAt *time.Time
+	if frozen {
+		name = models.AuditActionFreezeUser
+		now := time.Now().UTC()
+		frozenAt = &now
+	}
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordAuditEvent(tx, action, name, models.AuditTargetUser, username, nil); err != nil {
+			return err
+		}
+
+		if err := updateUser(tx, username, map[string]interface{}{"frozen_at": frozenAt}); err != nil {
+			return err
+		}
+		if frozen {
+			if err := blockUserSessions(tx, username); err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("username = ?", username).First(&user).Error
+	}); err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// ForcePasswordReset requires a user to change their password and blocks all of its sessions.
+// It returns gorm.ErrRecordNotFound if the user does not exist.
+func (services *SQLServices) ForcePasswordReset(action AdminAction, username string) (models.User, error) {
+	var user models.User
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordAuditEvent(tx, action, models.AuditActionForcePasswordReset, models.AuditTargetUser, username, nil); err != nil {
+			return err
+		}
+
+		if err := updateUser(tx, username, map[string]interface{}{"password_reset_required": true}); err != nil {
+			return err
+		}
+		if err := blockUserSessions(tx, username); err != nil {
+			return err
+		}
+
+		return tx.Where("username = ?", username).First(&user).Error
+	}); err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// UnlockUser forgets the failed logins of a user, who can log in again right away.
+// It returns gorm.ErrRecordNotFound if the user does not exist.
+func (services *SQLServices) UnlockUser(action AdminAction, username string) (models.User, error) {
+	var user models.User
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordAuditEvent(tx, action, models.AuditActionUnlockUser, models.AuditTargetUser, username, nil); err != nil {
+			return err
+		}
+
+		if err := tx.Where("username = ?", username).First(&user).Error; err != nil {
+			return err
+		}
+
+		return tx.
+			Where("kind = ? AND subject = ?", models.LoginFailureKindUsername, username).
+			Delete(&models.LoginFailure{}).Error
+	}); err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// SetAccountFrozen freezes or unfreezes a customer account. Frozen accounts cannot send or receive money,
+// except through manual adjustments.
+// It returns gorm.ErrRecordNotFound if the customer account does not exist.
+func (services *SQLServices) SetAccountFrozen(action AdminAction, accountID int64, frozen bool) (models.Account, error) {
+	var account models.Account
+
+	name := models.AuditActionUnfreezeAccount
+	var frozenAt *time.Time
+	if frozen {
+		name = models.AuditActionFreezeAccount
+		now := time.Now().UTC()
+		frozenAt = &now
+	}
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		targetID := strconv.FormatInt(accountID, 10)
+		if err := recordAuditEvent(tx, action, name, models.AuditTargetAccount, targetID, nil); err != nil {
+			return err
+		}
+
+		res := tx.Model(&models.Account{}).
+			Where("id = ? AND is_system = false", accountID).
+			Updates(map[string]interface{}{"frozen_at": frozenAt, "updated_at": time.Now().UTC()})
+		if err := res.Error; err != nil {
+			return err
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.First(&account, accountID).Error
+	}); err != nil {
+		return models.Account{}, err
+	}
+
+	return account, nil
+}
+
+// AdjustBalance posts a manual adjustment of amount to a customer account, balanced against the suspense account
+// of the general ledger. Adjustments also apply to frozen accounts.
+func (services *SQLServices) AdjustBalance(action AdminAction, accountID int64, amount int32) (models.Entry, error) {
+	if amount == 0 {
+		return models.Entry{}, ErrInvalidAdjustment
+	}
+
+	var entry models.Entry
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		targetID := strconv.FormatInt(accountID, 10)
+		details := map[string]any{"amount": amount}
+		if err := recordAuditEvent(tx, action, models.AuditActionAdjustBalance, models.AuditTargetAccount, targetID, details); err != nil {
+			return err
+		}
+
+		var account models.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND is_system = false", accountID).
+			First(&account).Error; err != nil {
+			return err
+		}
+		if account.IsHot {
+			if _, err := foldPendingCredits(tx, &account); err != nil {
+				return err
+			}
+		}
+
+		entry = models.Entry{
+			AccountID: accountID,
+			Amount:    amount,
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&account).Updates(map[string]interface{}{
+			"balance":    account.Balance + int64(amount),
+			"updated_at": time.Now().UTC(),
+		}).Error; err != nil {
+			return err
+		}
+
+		_, err := postToGLAccount(tx, models.GLCodeSuspense, -amount)
+		return err
+	}); err != nil {
+		return models.Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// AdminRevokeToken denies a token until it expires on behalf of the back office, e.g. because it was stolen.
+func (services *SQLServices) AdminRevokeToken(action AdminAction, req RevokeTokenRequest) error {
+	return services.DB.Transaction(func(tx *gorm.DB) error {
+		details := map[string]any{"username": req.Username}
+		if err := recordAuditEvent(tx, action, models.AuditActionRevokeToken, models.AuditTargetToken, req.TokenID.String(), details); err != nil {
+			return err
+		}
+
+		req.RevokedBy = action.Operator
+		req.Reason = action.Reason
+		return revokeToken(tx, req)
+	})
+}
+
+// ListAuditEvents returns the events of the audit trail matching the filters of the request, newest first.
+func (services *SQLServices) ListAuditEvents(action AdminAction, req ListAuditEventsRequest) ([]models.AuditEvent, error) {
+	events := []models.AuditEvent{}
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		details := map[string]any{"operator": req.Operator, "target_type": req.TargetType, "target_id": req.TargetID}
+		if err := recordAuditEvent(tx, action, models.AuditActionListAuditEvents, models.AuditTargetAudit, "", details); err != nil {
+			return err
+		}
+
+		query := tx.Order("id DESC").Limit(req.PageSize).Offset((req.PageNumber - 1) * req.PageSize)
+		if req.Operator != "" {
+			query = query.Where("operator = ?", req.Operator)
+		}
+		if req.TargetType != "" {
+			query = query.Where("target_type = ?", req.TargetType)
+		}
+		if req.TargetID != "" {
+			query = query.Where("target_id = ?", req.TargetID)
+		}
+
+		return query.Find(&events).Error
+	}); err != nil {
+		return []models.AuditEvent{}, err
+	}
+
+	return events, nil
+}
+
+// updateUser updates columns of a user, returning gorm.ErrRecordNotFound if the user does not exist.
+func updateUser(tx *gorm.DB, username string, columns map[string]interface{}) error {
+	columns["updated_at"] = time.Now().UTC()
+
+	res := tx.Model(&models.User{}).Where("username = ?", username).Updates(columns)
+	if err := res.Error; err != nil {
+		return err
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// blockUserSessions blocks every session of a user.
+func blockUserSessions(tx *gorm.DB, username string) error {
+	return tx.Model(&models.Session{}).
+		Where("username = ? AND NOT is_blocked", username).
+		Update("is_blocked", true).Error
+}
+
+// checkAccountsNotFrozen returns an error wrapping ErrAccountFrozen if any of the accounts is frozen.
+func checkAccountsNotFrozen(tx *gorm.DB, accountIDs ...int64) error {
+	var frozen []int64
+	if err := tx.Model(&models.Account{}).
+		Where("id IN ? AND frozen_at IS NOT NULL", accountIDs).
+		Pluck("id", &frozen).Error; err != nil {
+		return err
+	}
+	if len(frozen) > 0 {
+		return fmt.Errorf("%w: account %d", ErrAccountFrozen, frozen[0])
+	}
+
+	return nil
+}
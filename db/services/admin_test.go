@@ -0,0 +1,128 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/requests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"strconv"
+	"testing"
+)
+
+func TestSetAccountFrozen(t *testing.T) {
+	operator := createRandomUser(t)
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+	account1 := createAccount(t, user1.Username)
+	account2 := createAccount(t, user2.Username)
+
+	action := AdminAction{Operator: operator.Username, Reason: "suspicious activity"}
+	frozen, err := services.SetAccountFrozen(action, account2.ID, true)
+	require.NoError(t, err)
+	require.NotNil(t, frozen.FrozenAt)
+
+	// money cannot move to or from a frozen account
+	transfer, err := services.Transfer(TransferRequest{
+		Owner:         user1.Username,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrTransferFailed)
+	require.Equal(t, models.TransferStatusFailed, transfer.Status)
+	require.Equal(t, "account is frozen", transfer.StatusReason)
+
+	_, err = services.DepositMoney(requests.DepositRequest{AccountID: account2.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	unfrozen, err := services.SetAccountFrozen(action, account2.ID, false)
+	require.NoError(t, err)
+	require.Nil(t, unfrozen.FrozenAt)
+	createTransfer(t, account1, account2, 10)
+
+	events, err := services.ListAuditEvents(action, ListAuditEventsRequest{
+		TargetType: models.AuditTargetAccount,
+		TargetID:   strconv.FormatInt(account2.ID, 10),
+		PageSize:   10,
+		PageNumber: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, models.AuditActionUnfreezeAccount, events[0].Action)
+	require.Equal(t, models.AuditActionFreezeAccount, events[1].Action)
+	require.Equal(t, operator.Username, events[1].Operator)
+	require.Equal(t, "suspicious activity", events[1].Reason)
+}
+
+func TestSetUserFrozen(t *testing.T) {
+	operator := createRandomUser(t)
+	session := createSession(t)
+
+	action := AdminAction{Operator: operator.Username, Reason: "account takeover"}
+	frozen, err := services.SetUserFrozen(action, session.Username, true)
+	require.NoError(t, err)
+	require.NotNil(t, frozen.FrozenAt)
+
+	// freezing a user logs them out
+	stored, err := services.GetSession(session.ID)
+	require.NoError(t, err)
+	require.True(t, stored.IsBlocked)
+
+	_, err = services.SetUserFrozen(action, "missing_user", true)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	operator := createRandomUser(t)
+	user := createRandomUser(t)
+
+	updated, err := services.ForcePasswordReset(AdminAction{Operator: operator.Username, Reason: "leaked password"}, user.Username)
+	require.NoError(t, err)
+	require.True(t, updated.PasswordResetRequired)
+
+	// changing the password clears the requirement
+	newPassword := "new_password"
+	_, err = services.UpdateUser(UpdateUserRequest{Username: user.Username, Password: &newPassword})
+	require.NoError(t, err)
+
+	stored, err := services.GetUser(user.Username)
+	require.NoError(t, err)
+	require.False(t, stored.PasswordResetRequired)
+	require.NotEqual(t, user.HashedPassword, stored.HashedPassword)
+}
+
+func TestAdjustBalance(t *testing.T) {
+	operator := createRandomUser(t)
+	user := createRandomUser(t)
+	account := createAccount(t, user.Username)
+
+	action := AdminAction{Operator: operator.Username, Reason: "refund of a fee"}
+	entry, err := services.AdjustBalance(action, account.ID, 25)
+	require.NoError(t, err)
+	require.Equal(t, int32(25), entry.Amount)
+
+	adjusted, err := services.GetAccount(account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+25, adjusted.Balance)
+
+	trialBalance, err := services.GetTrialBalance(entry.CreatedAt)
+	require.NoError(t, err)
+	require.True(t, trialBalance.Balanced())
+
+	_, err = services.AdjustBalance(action, account.ID, 0)
+	require.ErrorIs(t, err, ErrInvalidAdjustment)
+}
+
+func TestAdminActionMissingReason(t *testing.T) {
+	operator := createRandomUser(t)
+	user := createRandomUser(t)
+	account := createAccount(t, user.Username)
+
+	_, err := services.AdjustBalance(AdminAction{Operator: operator.Username, Reason: " "}, account.ID, 25)
+	require.ErrorIs(t, err, ErrMissingReason)
+
+	// nothing happens without being recorded
+	stored, err := services.GetAccount(account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, stored.Balance)
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"github.com/google/uuid"
+	"time"
+)
+
+// CreateAPIKey stores a new api key.
+func (services *SQLServices) CreateAPIKey(key models.APIKey) (models.APIKey, error) {
+	if err := services.DB.Create(&key).Error; err != nil {
+		return models.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyByPrefix returns the api key with the given prefix, revoked or not.
+func (services *SQLServices) GetAPIKeyByPrefix(prefix string) (models.APIKey, error) {
+	var key models.APIKey
+
+	if err := services.DB.Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		return models.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys returns the api keys of a user that have not been revoked, newest first.
+func (services *SQLServices) ListAPIKeys(owner string) ([]models.APIKey, error) {
+	var keys []models.APIKey
+
+	if err := services.DB.
+		Where("owner = ? AND revoked_at IS NULL", owner).
+		Order("created_at DESC").
+		Find(&keys).Error; err != nil {
+		return []models.APIKey{}, err
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an api key of a user, which cannot be used anymore.
+// It returns gorm.ErrRecordNotFound if the user has no api key with the given id.
+func (services *SQLServices) RevokeAPIKey(owner string, id uuid.UUID) (models.APIKey, error) {
+	var key models.APIKey
+
+	if err := services.DB.Where("id = ? AND owner = ?", id, owner).First(&key).Error; err != nil {
+		return models.APIKey{}, err
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	now := time.Now().UTC()
+	if err := services.DB.Model(&key).Update("revoked_at", now).Error; err != nil {
+		return models.APIKey{}, err
+	}
+	key.RevokedAt = &now
+
+	return key, nil
+}
+
+// TouchAPIKey records that an api key was used from clientIP at usedAt.
+func (services *SQLServices) TouchAPIKey(id uuid.UUID, clientIP string, usedAt time.Time) error {
+	return services.DB.
+		Model(&models.APIKey{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"last_used_at": usedAt, "last_used_ip": clientIP}).Error
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func createAPIKey(t *testing.T, owner string) models.APIKey {
+	key, err := services.CreateAPIKey(models.APIKey{
+		ID:           uuid.New(),
+		Owner:        owner,
+		Name:         "integration",
+		Prefix:       util.RandomString(12, util.LOWERCASE),
+		HashedSecret: util.RandomString(64, util.LOWERCASE),
+		Scopes:       "accounts:read",
+	})
+	require.NoError(t, err)
+	require.NotZero(t, key.CreatedAt)
+
+	return key
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	user := createRandomUser(t)
+	key := createAPIKey(t, user.Username)
+	createAPIKey(t, user.Username)
+
+	stored, err := services.GetAPIKeyByPrefix(key.Prefix)
+	require.NoError(t, err)
+	require.Equal(t, key.ID, stored.ID)
+	require.Nil(t, stored.LastUsedAt)
+
+	clientIP := util.RandomIP()
+	require.NoError(t, services.TouchAPIKey(key.ID, clientIP, time.Now().UTC()))
+	stored, err = services.GetAPIKeyByPrefix(key.Prefix)
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastUsedAt)
+	require.Equal(t, clientIP, stored.LastUsedIP)
+
+	// users cannot revoke the keys of other users
+	_, err = services.RevokeAPIKey(createRandomUser(t).Username, key.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	revoked, err := services.RevokeAPIKey(user.Username, key.ID)
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+
+	keys, err := services.ListAPIKeys(user.Username)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.NotEqual(t, key.ID, keys[0].ID)
+
+	// revoked keys are still found, so they are refused as revoked rather than unknown
+	stored, err = services.GetAPIKeyByPrefix(key.Prefix)
+	require.NoError(t, err)
+	require.NotNil(t, stored.RevokedAt)
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrApprovalNotPending is returned when deciding on an operation that has already been decided
+	ErrApprovalNotPending = errors.New("operation is not pending approval")
+	// ErrApprovalExpired is returned when deciding on an operation that was not decided in time
+	ErrApprovalExpired = errors.New("approval has expired")
+	// ErrSelfApproval is returned when the requester of an operation tries to decide on it
+	ErrSelfApproval = errors.New("operations must be decided by a user other than their requester")
+)
+
+// expiredApprovalReason is the reason recorded in the audit trail when an approval expires
+const expiredApprovalReason = "not decided before it expired"
+
+// CreateApproval stores an operation as pending until another user approves or rejects it.
+func (services *SQLServices) CreateApproval(req CreateApprovalRequest) (models.Approval, error) {
+	approval := models.Approval{
+		Operation:   req.Operation,
+		Payload:     req.Payload,
+		Status:      models.ApprovalStatusPending,
+		RequestedBy: req.RequestedBy,
+		Reason:      req.Reason,
+		CreatedAt:   time.Now().UTC(),
+		ExpiresAt:   req.ExpiresAt.UTC(),
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		return models.Approval{}, ErrMissingReason
+	}
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&approval).Error; err != nil {
+			return err
+		}
+
+		action := AdminAction{Operator: req.RequestedBy, Reason: req.Reason}
+		targetID := strconv.FormatInt(approval.ID, 10)
+		return recordAuditEvent(tx, action, models.AuditActionRequestApproval, models.AuditTargetApproval, targetID,
+			map[string]any{"operation": req.Operation})
+	}); err != nil {
+		return models.Approval{}, err
+	}
+
+	return approval, nil
+}
+
+// GetApproval returns an approval.
+func (services *SQLServices) GetApproval(id int64) (models.Approval, error) {
+	var approval models.Approval
+	if err := services.DB.First(&approval, id).Error; err != nil {
+		return models.Approval{}, err
+	}
+
+	return approval, nil
+}
+
+// ListApprovals returns the approvals matching the filters of the request, newest first.
+func (services *SQLServices) ListApprovals(req ListApprovalsRequest) ([]models.Approval, error) {
+	approvals := []models.Approval{}
+
+	query := services.DB.Order("id DESC").Limit(req.PageSize).Offset((req.PageNumber - 1) * req.PageSize)
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.RequestedBy != "" {
+		query = query.Where("requested_by = ?", req.RequestedBy)
+	}
+	if err := query.Find(&approvals).Error; err != nil {
+		return []models.Approval{}, err
+	}
+
+	return approvals, nil
+}
+
+// DecideApproval approves or rejects a pending operation. An approved operation is not executed here:
+// its status becomes approved, so that no other decision can be made while the caller executes it,
+// and the caller records the outcome with CompleteApproval.
+// If the approval has expired, it is marked as expired and ErrApprovalExpired is returned.
+func (services *SQLServices) DecideApproval(req DecideApprovalRequest) (models.Approval, error) {
+	var approval models.Approval
+	expired := false
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		approval = models.Approval{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&approval, req.ID).Error; err != nil {
+			return err
+		}
+		if approval.Status != models.ApprovalStatusPending {
+			return ErrApprovalNotPending
+		}
+		if req.DecidedBy == approval.RequestedBy {
+			return ErrSelfApproval
+		}
+		if time.Now().After(approval.ExpiresAt) {
+			expired = true
+			return expireApproval(tx, &approval)
+		}
+
+		name, status := models.AuditActionRejectOperation, models.ApprovalStatusRejected
+		if req.Approve {
+			name, status = models.AuditActionApproveOperation, models.ApprovalStatusApproved
+		}
+
+		action := AdminAction{Operator: req.DecidedBy, Reason: req.Reason}
+		targetID := strconv.FormatInt(approval.ID, 10)
+		if err := recordAuditEvent(tx, action, name, models.AuditTargetApproval, targetID,
+			map[string]any{"operation": approval.Operation}); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		approval.Status = status
+		approval.DecidedBy = &req.DecidedBy
+		approval.DecisionReason = action.Reason
+		approval.DecidedAt = &now
+
+		return tx.Model(&approval).Updates(map[string]interface{}{
+			"status":          approval.Status,
+			"decided_by":      req.DecidedBy,
+			"decision_reason": approval.DecisionReason,
+			"decided_at":      now,
+		}).Error
+	}); err != nil {
+		return models.Approval{}, err
+	}
+	if expired {
+		return approval, ErrApprovalExpired
+	}
+
+	return approval, nil
+}
+
+// CompleteApproval records the outcome of the execution of an approved operation:
+// its JSON result if it was executed, or why it failed if failure is not empty.
+func (services *SQLServices) CompleteApproval(id int64, result string, failure string) (models.Approval, error) {
+	var approval models.Approval
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		approval = models.Approval{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&approval, id).Error; err != nil {
+			return err
+		}
+		if approval.Status != models.ApprovalStatusApproved {
+			return ErrApprovalNotPending
+		}
+
+		now := time.Now().UTC()
+		columns := map[string]interface{}{"executed_at": now}
+		if failure != "" {
+			columns["status"] = models.ApprovalStatusFailed
+			columns["error"] = failure
+		} else {
+			columns["status"] = models.ApprovalStatusExecuted
+			columns["result"] = result
+		}
+		if err := tx.Model(&approval).Updates(columns).Error; err != nil {
+			return err
+		}
+
+		return tx.First(&approval, id).Error
+	}); err != nil {
+		return models.Approval{}, err
+	}
+
+	return approval, nil
+}
+
+// ExpireApprovals marks the pending approvals that expired before now as expired and returns how many expired.
+func (services *SQLServices) ExpireApprovals(now time.Time) (int64, error) {
+	var count int64
+
+	err := services.DB.Transaction(func(tx *gorm.DB) error {
+		var approvals []models.Approval
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at < ?", models.ApprovalStatusPending, now).
+			Find(&approvals).Error; err != nil {
+			return err
+		}
+
+		for i := range approvals {
+			if err := expireApproval(tx, &approvals[i]); err != nil {
+				return err
+			}
+		}
+		count = int64(len(approvals))
+
+		return nil
+	})
+
+	return count, err
+}
+
+// expireApproval marks a pending approval as expired, on behalf of the system user.
+func expireApproval(tx *gorm.DB, approval *models.Approval) error {
+	action := AdminAction{Operator: models.SystemUsername, Reason: expiredApprovalReason}
+	targetID := strconv.FormatInt(approval.ID, 10)
+	if err := recordAuditEvent(tx, action, models.AuditActionExpireApproval, models.AuditTargetApproval, targetID,
+		map[string]any{"operation": approval.Operation}); err != nil {
+		return err
+	}
+
+	approval.Status = models.ApprovalStatusExpired
+	return tx.Model(approval).Update("status", approval.Status).Error
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createApproval(t *testing.T, requester string, expiresAt time.Time) models.Approval {
+	approval, err := services.CreateApproval(CreateApprovalRequest{
+		Operation:   models.OperationCloseAccount,
+		Payload:     `{"account_id": 1}`,
+		RequestedBy: requester,
+		Reason:      "closing",
+		ExpiresAt:   expiresAt,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, approval.ID)
+	require.Equal(t, models.ApprovalStatusPending, approval.Status)
+
+	return approval
+}
+
+func TestDecideApproval(t *testing.T) {
+	requester := createRandomUser(t)
+	approver := createRandomUser(t)
+	approval := createApproval(t, requester.Username, time.Now().Add(time.Hour))
+
+	_, err := services.DecideApproval(DecideApprovalRequest{
+		ID:        approval.ID,
+		Approve:   true,
+		DecidedBy: requester.Username,
+		Reason:    "approving my own request",
+	})
+	require.ErrorIs(t, err, ErrSelfApproval)
+
+	approved, err := services.DecideApproval(DecideApprovalRequest{
+		ID:        approval.ID,
+		Approve:   true,
+		DecidedBy: approver.Username,
+		Reason:    "verified",
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.ApprovalStatusApproved, approved.Status)
+	require.Equal(t, approver.Username, *approved.DecidedBy)
+
+	// an operation is decided once
+	_, err = services.DecideApproval(DecideApprovalRequest{
+		ID:        approval.ID,
+		DecidedBy: approver.Username,
+		Reason:    "changed my mind",
+	})
+	require.ErrorIs(t, err, ErrApprovalNotPending)
+
+	executed, err := services.CompleteApproval(approval.ID, `{"account_id": 1}`, "")
+	require.NoError(t, err)
+	require.Equal(t, models.ApprovalStatusExecuted, executed.Status)
+	require.NotNil(t, executed.ExecutedAt)
+
+	events, err := services.ListAuditEvents(AdminAction{Operator: approver.Username, Reason: "review"}, ListAuditEventsRequest{
+		TargetType: models.AuditTargetApproval,
+		PageSize:   10,
+		PageNumber: 1,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+	require.Equal(t, models.AuditActionApproveOperation, events[0].Action)
+	require.Equal(t, approver.Username, events[0].Operator)
+}
+
+func TestExpireApprovals(t *testing.T) {
+	requester := createRandomUser(t)
+	approver := createRandomUser(t)
+	expired := createApproval(t, requester.Username, time.Now().Add(-time.Minute))
+
+	// deciding on an expired operation expires it
+	decided, err := services.DecideApproval(DecideApprovalRequest{
+		ID:        expired.ID,
+		Approve:   true,
+		DecidedBy: approver.Username,
+		Reason:    "too late",
+	})
+	require.ErrorIs(t, err, ErrApprovalExpired)
+	require.Equal(t, models.ApprovalStatusExpired, decided.Status)
+
+	pending := createApproval(t, requester.Username, time.Now().Add(-time.Minute))
+	count, err := services.ExpireApprovals(time.Now())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, count, int64(1))
+
+	stored, err := services.GetApproval(pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.ApprovalStatusExpired, stored.Status)
+}
+
+func TestCreateApprovalMissingReason(t *testing.T) {
+	requester := createRandomUser(t)
+
+	_, err := services.CreateApproval(CreateApprovalRequest{
+		Operation:   models.OperationCloseAccount,
+		Payload:     `{}`,
+		RequestedBy: requester.Username,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrMissingReason)
+}
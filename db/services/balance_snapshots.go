@@ -0,0 +1,67 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// CreateBalanceSnapshots records the closing balance of every account as of snapshotAt.
+//
+// The closing balance is the current balance, including pending credits, minus every entry created at or after snapshotAt,
+// so the job may run some time after the cutoff and still record the balance at the cutoff.
+// Accounts that already have a snapshot at snapshotAt are skipped, which makes it safe to rerun.
+// It returns the number of snapshots recorded.
+func (services *SQLServices) CreateBalanceSnapshots(snapshotAt time.Time) (int64, error) {
+	res := services.DB.Exec(`
+		INSERT INTO balance_snapshots (account_id, balance, snapshot_at, created_at)
+		SELECT a.id,
+		       a.balance + COALESCE((
+		           SELECT SUM(p.amount) FROM pending_credits p WHERE p.account_id = a.id
+		       ), 0) - COALESCE((
+		           SELECT SUM(e.amount) FROM entries e
+		           WHERE e.account_id = a.id AND e.created_at >= ? AND e.deleted_at IS NULL
+		       ), 0),
+		       ?,
+		       now()
+		FROM accounts a
+		WHERE a.deleted_at IS NULL AND a.created_at < ?
+		ON CONFLICT (account_id, snapshot_at) DO NOTHING`,
+		snapshotAt, snapshotAt, snapshotAt,
+	)
+	if err := res.Error; err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected, nil
+}
+
+// GetBalanceAt returns the balance of an account as of the given time.
+//
+// It starts from the latest snapshot taken at or before at and replays the entries created after it.
+// If the account has no such snapshot, every entry of the account up to at is replayed.
+func (services *SQLServices) GetBalanceAt(accountID int64, at time.Time) (int64, error) {
+	var snapshot models.BalanceSnapshot
+	if err := services.DB.
+		Where("account_id = ? AND snapshot_at <= ?", accountID, at).
+		Order("snapshot_at DESC").
+		First(&snapshot).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	query := services.DB.
+		Model(&models.Entry{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("account_id = ? AND created_at <= ?", accountID, at)
+	if snapshot.ID != 0 {
+		query = query.Where("created_at >= ?", snapshot.SnapshotAt)
+	}
+
+	var replayed int64
+	if err := query.Scan(&replayed).Error; err != nil {
+		return 0, err
+	}
+
+	return snapshot.Balance + replayed, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"Simple-Bank/requests"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func deposit(t *testing.T, accountID int64, amount int32) {
+	_, err := services.DepositMoney(requests.DepositRequest{
+		AccountID: accountID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+}
+
+func TestGetBalanceAt(t *testing.T) {
+	user := createRandomUser(t)
+	account := createAccount(t, user.Username)
+
+	t.Run("NoSnapshot", func(t *testing.T) {
+		deposit(t, account.ID, 100)
+		afterFirstDeposit := time.Now()
+		deposit(t, account.ID, 50)
+
+		balance, err := services.GetBalanceAt(account.ID, afterFirstDeposit)
+		require.NoError(t, err)
+		require.Equal(t, int64(100), balance)
+
+		balance, err = services.GetBalanceAt(account.ID, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, int64(150), balance)
+
+		balance, err = services.GetBalanceAt(account.ID, account.CreatedAt.Add(-time.Hour))
+		require.NoError(t, err)
+		require.Zero(t, balance)
+	})
+	t.Run("FromSnapshot", func(t *testing.T) {
+		snapshotAt := time.Now()
+		count, err := services.CreateBalanceSnapshots(snapshotAt)
+		require.NoError(t, err)
+		require.True(t, count > 0)
+
+		// recreating the snapshots at the same time is a no-op
+		count, err = services.CreateBalanceSnapshots(snapshotAt)
+		require.NoError(t, err)
+		require.Zero(t, count)
+
+		deposit(t, account.ID, 25)
+
+		balance, err := services.GetBalanceAt(account.ID, snapshotAt)
+		require.NoError(t, err)
+		require.Equal(t, int64(150), balance)
+
+		balance, err = services.GetBalanceAt(account.ID, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, int64(175), balance)
+	})
+	t.Run("SnapshotTakenLate", func(t *testing.T) {
+		cutoff := time.Now()
+		deposit(t, account.ID, 10)
+
+		_, err := services.CreateBalanceSnapshots(cutoff)
+		require.NoError(t, err)
+
+		balance, err := services.GetBalanceAt(account.ID, cutoff)
+		require.NoError(t, err)
+		require.Equal(t, int64(175), balance)
+
+		balance, err = services.GetBalanceAt(account.ID, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, int64(185), balance)
+	})
+}
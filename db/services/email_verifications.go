@@ -0,0 +1,57 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrEmailChanged is returned when a verification is used after the user changed the email it was sent to
+var ErrEmailChanged = errors.New("email has changed since the verification was sent")
+
+// CreateEmailVerification stores a verification token emailed to a user.
+func (services *SQLServices) CreateEmailVerification(verification models.EmailVerification) (models.EmailVerification, error) {
+	if err := services.DB.Create(&verification).Error; err != nil {
+		return models.EmailVerification{}, err
+	}
+
+	return verification, nil
+}
+
+// GetEmailVerification returns the verification with the given hashed token.
+func (services *SQLServices) GetEmailVerification(hashedToken string) (models.EmailVerification, error) {
+	var verification models.EmailVerification
+
+	if err := services.DB.Where("hashed_token = ?", hashedToken).First(&verification).Error; err != nil {
+		return models.EmailVerification{}, err
+	}
+
+	return verification, nil
+}
+
+// VerifyEmail marks the email of the user of verification as verified, and deletes every verification of the user
+// so none can be used again. It returns ErrEmailChanged if the user no longer has the email it was sent to.
+func (services *SQLServices) VerifyEmail(verification models.EmailVerification) (models.User, error) {
+	var user models.User
+
+	err := services.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&user).
+			Clauses(clause.Returning{}).
+			Where("username = ? AND email = ?", verification.Username, verification.Email).
+			Update("email_verified", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrEmailChanged
+		}
+
+		return tx.Where("username = ?", verification.Username).Delete(&models.EmailVerification{}).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func createRandomEmailVerification(t *testing.T, user models.User) models.EmailVerification {
+	verification, err := services.CreateEmailVerification(models.EmailVerification{
+		HashedToken: util.RandomString(64, util.LOWERCASE),
+		Username:    user.Username,
+		Email:       user.Email,
+		ExpiresAt:   time.Now().Add(time.Hour).UTC(),
+	})
+	require.NoError(t, err)
+	require.NotZero(t, verification.ID)
+
+	return verification
+}
+
+func TestVerifyEmail(t *testing.T) {
+	user := createRandomUser(t)
+	require.False(t, user.EmailVerified)
+
+	verification := createRandomEmailVerification(t, user)
+	other := createRandomEmailVerification(t, user)
+
+	found, err := services.GetEmailVerification(verification.HashedToken)
+	require.NoError(t, err)
+	require.Equal(t, verification.Email, found.Email)
+
+	verified, err := services.VerifyEmail(found)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, verified.Username)
+	require.True(t, verified.EmailVerified)
+
+	// every verification of the user is used up
+	_, err = services.GetEmailVerification(verification.HashedToken)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = services.GetEmailVerification(other.HashedToken)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// updating the user with the same email keeps it verified, a new email must be verified again
+	sameEmail := user.Email
+	updated, err := services.UpdateUser(UpdateUserRequest{Username: user.Username, Email: &sameEmail})
+	require.NoError(t, err)
+	require.True(t, updated.EmailVerified)
+
+	newEmail := util.RandomEmail()
+	updated, err = services.UpdateUser(UpdateUserRequest{Username: user.Username, Email: &newEmail})
+	require.NoError(t, err)
+	require.False(t, updated.EmailVerified)
+}
+
+func TestVerifyChangedEmail(t *testing.T) {
+	user := createRandomUser(t)
+	verification := createRandomEmailVerification(t, user)
+
+	newEmail := util.RandomEmail()
+	_, err := services.UpdateUser(UpdateUserRequest{Username: user.Username, Email: &newEmail})
+	require.NoError(t, err)
+
+	_, err = services.VerifyEmail(verification)
+	require.ErrorIs(t, err, ErrEmailChanged)
+
+	user, err = services.GetUser(user.Username)
+	require.NoError(t, err)
+	require.False(t, user.EmailVerified)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"strconv"
+	"time"
+)
+
+// TrialBalanceLine is the balance of a single general ledger account in a trial balance.
+type TrialBalanceLine struct {
+	Code      string
+	Name      string
+	Category  string
+	AccountID int64
+	Balance   int64
+}
+
+// TrialBalance lists the balances of all general ledger accounts and the total of all customer accounts at a time.
+// Since every movement of money is recorded on two sides, Total must always be zero.
+type TrialBalance struct {
+	// At is the time the balances are reported at
+	At time.Time
+	// GLAccounts are the balances of the general ledger accounts ordered by code
+	GLAccounts []TrialBalanceLine
+	// CustomerAccounts is the number of customer accounts
+	CustomerAccounts int64
+	// CustomerAccountsTotal is the sum of the balances of all customer accounts
+	CustomerAccountsTotal int64
+	// Total is the sum of the balances of all customer and general ledger accounts
+	Total int64
+}
+
+// Balanced reports whether the sum of all balances is zero.
+func (trialBalance TrialBalance) Balanced() bool {
+	return trialBalance.Total == 0
+}
+
+// WriteCSV writes the trial balance in CSV format: one row per general ledger account,
+// followed by the customer accounts total and the grand total.
+func (trialBalance TrialBalance) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	records := [][]string{{"code", "name", "category", "balance"}}
+	for _, line := range trialBalance.GLAccounts {
+		records = append(records, []string{line.Code, line.Name, line.Category, strconv.FormatInt(line.Balance, 10)})
+	}
+	records = append(records,
+		[]string{"", fmt.Sprintf("Customer accounts (%d)", trialBalance.CustomerAccounts), "liability",
+			strconv.FormatInt(trialBalance.CustomerAccountsTotal, 10)},
+		[]string{"", "Total", "", strconv.FormatInt(trialBalance.Total, 10)},
+	)
+
+	return writer.WriteAll(records)
+}
+
+// ListGLAccounts returns the chart of accounts ordered by code.
+func (services *SQLServices) ListGLAccounts() ([]models.GLAccount, error) {
+	var glAccounts []models.GLAccount
+
+	if err := services.DB.Order("code").Find(&glAccounts).Error; err != nil {
+		return []models.GLAccount{}, err
+	}
+
+	return glAccounts, nil
+}
+
+// SyncChartOfAccounts makes sure every account of the given chart of accounts exists.
+// Missing accounts are created with a zero balance and the name and category of existing ones are updated.
+// Accounts missing from the chart are left untouched, since they may hold a balance.
+func (services *SQLServices) SyncChartOfAccounts(chart []models.GLAccount) error {
+	return services.DB.Transaction(func(tx *gorm.DB) error {
+		for _, entry := range chart {
+			var glAccount models.GLAccount
+			err := tx.Where("code = ?", entry.Code).First(&glAccount).Error
+			if err == nil {
+				if err := tx.Model(&glAccount).Updates(map[string]interface{}{
+					"name":       entry.Name,
+					"category":   entry.Category,
+					"updated_at": time.Now().UTC(),
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			account := models.Account{
+				Owner:     models.SystemUsername,
+				IsSystem:  true,
+				CreatedAt: time.Now().UTC(),
+				UpdatedAt: time.Now().UTC(),
+			}
+			if err := tx.Create(&account).Error; err != nil {
+				return err
+			}
+
+			glAccount = models.GLAccount{
+				Code:      entry.Code,
+				Name:      entry.Name,
+				Category:  entry.Category,
+				AccountID: account.ID,
+				CreatedAt: time.Now().UTC(),
+				UpdatedAt: time.Now().UTC(),
+			}
+			if err := tx.Create(&glAccount).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetTrialBalance returns the trial balance of the bank at the given time. Balances are computed like
+// GetBalanceAt, from the latest snapshot of each account taken at or before at plus the entries after it.
+func (services *SQLServices) GetTrialBalance(at time.Time) (TrialBalance, error) {
+	var rows []struct {
+		AccountID int64
+		IsSystem  bool
+		Code      *string
+		Name      *string
+		Category  *string
+		Balance   int64
+	}
+
+	if err := services.DB.Raw(`
+		SELECT a.id AS account_id, a.is_system, g.code, g.name, g.category,
+		       COALESCE(s.balance, 0) + COALESCE((
+		           SELECT SUM(e.amount) FROM entries e
+		           WHERE e.account_id = a.id AND e.deleted_at IS NULL AND e.created_at <= @at
+		             AND (s.snapshot_at IS NULL OR e.created_at >= s.snapshot_at)
+		       ), 0) AS balance
+		FROM accounts a
+		LEFT JOIN gl_accounts g ON g.account_id = a.id
+		LEFT JOIN LATERAL (
+		    SELECT balance, snapshot_at FROM balance_snapshots
+		    WHERE account_id = a.id AND snapshot_at <= @at
+		    ORDER BY snapshot_at DESC LIMIT 1
+		) s ON true
+		WHERE a.created_at <= @at
+		ORDER BY g.code, a.id`,
+		map[string]interface{}{"at": at},
+	).Scan(&rows).Error; err != nil {
+		return TrialBalance{}, err
+	}
+
+	trialBalance := TrialBalance{At: at}
+	for _, row := range rows {
+		trialBalance.Total += row.Balance
+
+		if row.Code == nil {
+			if row.IsSystem {
+				return TrialBalance{}, fmt.Errorf("system account %d is not in the chart of accounts", row.AccountID)
+			}
+			trialBalance.CustomerAccounts++
+			trialBalance.CustomerAccountsTotal += row.Balance
+			continue
+		}
+
+		trialBalance.GLAccounts = append(trialBalance.GLAccounts, TrialBalanceLine{
+			Code:      *row.Code,
+			Name:      *row.Name,
+			Category:  *row.Category,
+			AccountID: row.AccountID,
+			Balance:   row.Balance,
+		})
+	}
+
+	return trialBalance, nil
+}
+
+// postToGLAccount records an entry with the given amount on the general ledger account with the given code,
+// as the other side of a movement of money in or out of a customer account.
+func postToGLAccount(tx *gorm.DB, code string, amount int32) (models.Entry, error) {
+	var glAccount models.GLAccount
+	if err := tx.Where("code = ?", code).First(&glAccount).Error; err != nil {
+		return models.Entry{}, fmt.Errorf("cannot find general ledger account %s: %w", code, err)
+	}
+
+	entry := models.Entry{
+		AccountID: glAccount.AccountID,
+		Amount:    amount,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return models.Entry{}, err
+	}
+
+	if err := tx.Model(&models.Account{}).
+		Where("id = ?", glAccount.AccountID).
+		Updates(map[string]interface{}{
+			"balance":    gorm.Expr("balance + ?", amount),
+			"updated_at": time.Now().UTC(),
+		}).Error; err != nil {
+		return models.Entry{}, err
+	}
+
+	return entry, nil
+}
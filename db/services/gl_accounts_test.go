@@ -0,0 +1,114 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/requests"
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func glBalance(t *testing.T, trialBalance TrialBalance, code string) int64 {
+	for _, line := range trialBalance.GLAccounts {
+		if line.Code == code {
+			return line.Balance
+		}
+	}
+	require.Failf(t, "missing general ledger account", "code %s", code)
+	return 0
+}
+
+func TestListGLAccounts(t *testing.T) {
+	glAccounts, err := services.ListGLAccounts()
+	require.NoError(t, err)
+
+	var codes []string
+	for _, glAccount := range glAccounts {
+		codes = append(codes, glAccount.Code)
+
+		account, err := services.GetAccount(glAccount.AccountID)
+		require.NoError(t, err)
+		require.True(t, account.IsSystem)
+		require.Equal(t, models.SystemUsername, account.Owner)
+	}
+	require.Subset(t, codes, []string{
+		models.GLCodeCash,
+		models.GLCodeSuspense,
+		models.GLCodeFeeIncome,
+		models.GLCodeInterestExpense,
+	})
+
+	accounts, err := services.ListAccounts(ListAccountsRequest{
+		Owner:      models.SystemUsername,
+		PageSize:   5,
+		PageNumber: 1,
+	})
+	require.Error(t, err)
+	require.Empty(t, accounts)
+}
+
+func TestSyncChartOfAccounts(t *testing.T) {
+	code := "9" + time.Now().Format("150405.000")
+	chart := []models.GLAccount{{Code: code, Name: "Test", Category: "equity"}}
+
+	require.NoError(t, services.SyncChartOfAccounts(chart))
+
+	chart[0].Name = "Renamed"
+	require.NoError(t, services.SyncChartOfAccounts(chart))
+
+	glAccounts, err := services.ListGLAccounts()
+	require.NoError(t, err)
+
+	found := 0
+	for _, glAccount := range glAccounts {
+		if glAccount.Code == code {
+			found++
+			require.Equal(t, "Renamed", glAccount.Name)
+			require.Equal(t, "equity", glAccount.Category)
+		}
+	}
+	require.Equal(t, 1, found)
+}
+
+func TestGetTrialBalance(t *testing.T) {
+	user := createRandomUser(t)
+	account := createAccount(t, user.Username)
+
+	before, err := services.GetTrialBalance(time.Now())
+	require.NoError(t, err)
+
+	deposit(t, account.ID, 100)
+	_, err = services.WithdrawMoney(requests.WithdrawRequest{AccountID: account.ID, Amount: 30})
+	require.NoError(t, err)
+
+	after, err := services.GetTrialBalance(time.Now())
+	require.NoError(t, err)
+
+	require.Equal(t, before.Total, after.Total)
+	require.Equal(t, int64(70), after.CustomerAccountsTotal-before.CustomerAccountsTotal)
+	require.Equal(t, int64(-70), glBalance(t, after, models.GLCodeCash)-glBalance(t, before, models.GLCodeCash))
+	require.Equal(t, before.CustomerAccounts+1, after.CustomerAccounts)
+}
+
+func TestTrialBalanceWriteCSV(t *testing.T) {
+	trialBalance := TrialBalance{
+		At: time.Now(),
+		GLAccounts: []TrialBalanceLine{
+			{Code: "1000", Name: "Cash", Category: "asset", Balance: -150},
+			{Code: "4000", Name: "Fee Income", Category: "income", Balance: -10},
+		},
+		CustomerAccounts:      2,
+		CustomerAccountsTotal: 160,
+		Total:                 0,
+	}
+	require.True(t, trialBalance.Balanced())
+
+	var buffer bytes.Buffer
+	require.NoError(t, trialBalance.WriteCSV(&buffer))
+	require.Equal(t, "code,name,category,balance\n"+
+		"1000,Cash,asset,-150\n"+
+		"4000,Fee Income,income,-10\n"+
+		",Customer accounts (2),liability,160\n"+
+		",Total,,0\n", buffer.String())
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"database/sql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// SetHotAccounts marks exactly the accounts with the given ids as hot.
+//
+// Credits to a hot account are recorded as pending credits instead of updating its balance, so transfers to it
+// only lock their source account. Pending credits are folded into the balance by FoldPendingCredits, or right away
+// whenever money leaves the account. Accounts that stop being hot get their pending credits folded.
+func (services *SQLServices) SetHotAccounts(accountIDs []int64) error {
+	return services.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Where("is_hot")
+		if len(accountIDs) > 0 {
+			query = query.Where("id NOT IN ?", accountIDs)
+		}
+		var cooled []models.Account
+		if err := query.Find(&cooled).Error; err != nil {
+			return err
+		}
+
+		for i := range cooled {
+			if _, err := foldPendingCredits(tx, &cooled[i]); err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(&cooled[i]).Updates(map[string]interface{}{
+				"balance":    cooled[i].Balance,
+				"is_hot":     false,
+				"updated_at": time.Now().UTC(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(accountIDs) == 0 {
+			return nil
+		}
+
+		return tx.Model(&models.Account{}).
+			Where("id IN ? AND NOT is_hot", accountIDs).
+			Updates(map[string]interface{}{
+				"is_hot":     true,
+				"updated_at": time.Now().UTC(),
+			}).Error
+	})
+}
+
+// FoldPendingCredits adds the pending credits of every account to its balance.
+// It returns the number of pending credits folded.
+func (services *SQLServices) FoldPendingCredits() (int64, error) {
+	var accountIDs []int64
+	if err := services.DB.Model(&models.PendingCredit{}).Distinct("account_id").Pluck("account_id", &accountIDs).Error; err != nil {
+		return 0, err
+	}
+
+	var folded int64
+	for _, accountID := range accountIDs {
+		var count int64
+		if err := services.runInTransaction("fold_pending_credits", sql.LevelDefault, func(tx *gorm.DB) error {
+			var account models.Account
+			if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&account, accountID).Error; err != nil {
+				return err
+			}
+
+			var err error
+			count, err = foldPendingCredits(tx, &account)
+			if err != nil || count == 0 {
+				return err
+			}
+
+			return tx.Unscoped().Model(&account).Updates(map[string]interface{}{
+				"balance":    account.Balance,
+				"updated_at": time.Now().UTC(),
+			}).Error
+		}); err != nil {
+			return folded, err
+		}
+		// the attempts that were rolled back and retried do not count
+		folded += count
+	}
+
+	return folded, nil
+}
+
+// foldPendingCredits removes the pending credits of an account and adds them to account.Balance,
+// without saving the account. The account must be locked by tx.
+// It returns the number of pending credits folded.
+func foldPendingCredits(tx *gorm.DB, account *models.Account) (int64, error) {
+	var folded struct {
+		Count  int64
+		Amount int64
+	}
+	if err := tx.Raw(`
+		WITH folded AS (
+		    DELETE FROM pending_credits WHERE account_id = ? RETURNING amount
+		)
+		SELECT COUNT(*) AS count, COALESCE(SUM(amount), 0) AS amount FROM folded`,
+		account.ID,
+	).Scan(&folded).Error; err != nil {
+		return 0, err
+	}
+
+	account.Balance += folded.Amount
+
+	return folded.Count, nil
+}
+
+// addPendingCredits adds the pending credits of hot accounts to their balance, so that reads see
+// every completed credit.
+func (services *SQLServices) addPendingCredits(accounts []models.Account) error {
+	for i := range accounts {
+		if !accounts[i].IsHot {
+			continue
+		}
+
+		var pending int64
+		if err := services.DB.Model(&models.PendingCredit{}).
+			Select("COALESCE(SUM(amount), 0)").
+			Where("account_id = ?", accounts[i].ID).
+			Scan(&pending).Error; err != nil {
+			return err
+		}
+		accounts[i].Balance += pending
+	}
+
+	return nil
+}
+
+// moveMoneyToHotAccount moves amount from an account to a hot account. Only the source account is locked:
+// the credit is recorded as pending instead of updating the balance of the hot account.
+func moveMoneyToHotAccount(tx *gorm.DB, fromAccountID, toAccountID int64, amount int32) (fromEntry models.Entry, toEntry models.Entry, err error) {
+	var fromAccount models.Account
+	if err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&fromAccount, fromAccountID).Error; err != nil {
+		return fromEntry, toEntry, err
+	}
+	if fromAccount.IsHot {
+		if _, err = foldPendingCredits(tx, &fromAccount); err != nil {
+			return fromEntry, toEntry, err
+		}
+	}
+
+	fromAccount.Balance -= int64(amount)
+	if err = tx.Save(&fromAccount).Error; err != nil {
+		return fromEntry, toEntry, err
+	}
+
+	fromEntry = models.Entry{
+		AccountID: fromAccountID,
+		Amount:    -amount,
+	}
+	toEntry = models.Entry{
+		AccountID: toAccountID,
+		Amount:    amount,
+	}
+	if err = tx.Create(&fromEntry).Error; err != nil {
+		return fromEntry, toEntry, err
+	}
+	if err = tx.Create(&toEntry).Error; err != nil {
+		return fromEntry, toEntry, err
+	}
+
+	err = tx.Create(&models.PendingCredit{
+		AccountID: toAccountID,
+		Amount:    int64(amount),
+		EntryID:   toEntry.ID,
+		CreatedAt: time.Now().UTC(),
+	}).Error
+
+	return fromEntry, toEntry, err
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTransferToHotAccount(t *testing.T) {
+	merchant := createRandomUser(t)
+	hotAccount := createAccount(t, merchant.Username)
+	require.NoError(t, services.SetHotAccounts([]int64{hotAccount.ID}))
+	defer func() {
+		require.NoError(t, services.SetHotAccounts(nil))
+	}()
+
+	customers := 10
+	var amount int32 = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, customers)
+	sources := make([]models.Account, customers)
+	for i := range sources {
+		user := createRandomUser(t)
+		sources[i] = createAccount(t, user.Username)
+		deposit(t, sources[i].ID, 100)
+
+		wg.Add(1)
+		go func(source models.Account) {
+			defer wg.Done()
+			_, err := services.Transfer(TransferRequest{
+				Owner:         source.Owner,
+				FromAccountID: source.ID,
+				ToAccountID:   hotAccount.ID,
+				Amount:        amount,
+			})
+			errs <- err
+		}(sources[i])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	expectedBalance := int64(customers) * int64(amount)
+
+	// reads include the pending credits
+	account, err := services.GetAccount(hotAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, expectedBalance, account.Balance)
+
+	for _, source := range sources {
+		account, err := services.GetAccount(source.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(100-amount), account.Balance)
+	}
+
+	folded, err := services.FoldPendingCredits()
+	require.NoError(t, err)
+	require.True(t, folded >= int64(customers))
+
+	var stored models.Account
+	require.NoError(t, services.(*SQLServices).DB.First(&stored, hotAccount.ID).Error)
+	require.Equal(t, expectedBalance, stored.Balance)
+
+	var pending int64
+	require.NoError(t, services.(*SQLServices).DB.Model(&models.PendingCredit{}).
+		Where("account_id = ?", hotAccount.ID).Count(&pending).Error)
+	require.Zero(t, pending)
+}
+
+func TestTransferFromHotAccount(t *testing.T) {
+	merchant := createRandomUser(t)
+	hotAccount := createAccount(t, merchant.Username)
+	require.NoError(t, services.SetHotAccounts([]int64{hotAccount.ID}))
+	defer func() {
+		require.NoError(t, services.SetHotAccounts(nil))
+	}()
+
+	customer := createRandomUser(t)
+	customerAccount := createAccount(t, customer.Username)
+	deposit(t, customerAccount.ID, 100)
+
+	_, err := services.Transfer(TransferRequest{
+		Owner:         customer.Username,
+		FromAccountID: customerAccount.ID,
+		ToAccountID:   hotAccount.ID,
+		Amount:        60,
+	})
+	require.NoError(t, err)
+
+	// the debit folds the pending credit and sees the whole balance
+	_, err = services.Transfer(TransferRequest{
+		Owner:         merchant.Username,
+		FromAccountID: hotAccount.ID,
+		ToAccountID:   customerAccount.ID,
+		Amount:        50,
+	})
+	require.NoError(t, err)
+
+	var stored models.Account
+	require.NoError(t, services.(*SQLServices).DB.First(&stored, hotAccount.ID).Error)
+	require.Equal(t, int64(10), stored.Balance)
+
+	account, err := services.GetAccount(customerAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(90), account.Balance)
+}
+
+func TestSetHotAccounts(t *testing.T) {
+	merchant := createRandomUser(t)
+	hotAccount := createAccount(t, merchant.Username)
+
+	require.NoError(t, services.SetHotAccounts([]int64{hotAccount.ID}))
+	account, err := services.GetAccount(hotAccount.ID)
+	require.NoError(t, err)
+	require.True(t, account.IsHot)
+
+	customer := createRandomUser(t)
+	customerAccount := createAccount(t, customer.Username)
+	deposit(t, customerAccount.ID, 100)
+	_, err = services.Transfer(TransferRequest{
+		Owner:         customer.Username,
+		FromAccountID: customerAccount.ID,
+		ToAccountID:   hotAccount.ID,
+		Amount:        60,
+	})
+	require.NoError(t, err)
+
+	// the pending credits of the account leaving the hot set are folded into its balance
+	require.NoError(t, services.SetHotAccounts(nil))
+	var stored models.Account
+	require.NoError(t, services.(*SQLServices).DB.First(&stored, hotAccount.ID).Error)
+	require.False(t, stored.IsHot)
+	require.Equal(t, int64(60), stored.Balance)
+
+	var pending int64
+	require.NoError(t, services.(*SQLServices).DB.Model(&models.PendingCredit{}).
+		Where("account_id = ?", hotAccount.ID).Count(&pending).Error)
+	require.Zero(t, pending)
+}
+
+// BenchmarkTransferToSingleAccount compares the throughput of concurrent transfers from many accounts
+// to a single account, with and without the hot account mode.
+func BenchmarkTransferToSingleAccount(b *testing.B) {
+	for _, hot := range []bool{false, true} {
+		name := "Regular"
+		if hot {
+			name = "Hot"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			merchant := createRandomUser(b)
+			destination := createAccount(b, merchant.Username)
+			if hot {
+				require.NoError(b, services.SetHotAccounts([]int64{destination.ID}))
+				defer func() {
+					require.NoError(b, services.SetHotAccounts(nil))
+				}()
+			}
+
+			// every goroutine transfers from its own account, so that only the destination is contended
+			sources := make([]models.Account, runtime.GOMAXPROCS(0))
+			for i := range sources {
+				user := createRandomUser(b)
+				sources[i] = createAccount(b, user.Username)
+			}
+			var next atomic.Int64
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				source := sources[next.Add(1)-1]
+
+				for pb.Next() {
+					if _, err := services.Transfer(TransferRequest{
+						Owner:         source.Owner,
+						FromAccountID: source.ID,
+						ToAccountID:   destination.ID,
+						Amount:        1,
+					}); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+			b.StopTimer()
+
+			if _, err := services.FoldPendingCredits(); err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
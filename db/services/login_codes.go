@@ -0,0 +1,87 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// ErrLoginCodeUsed is returned when a login code is used more than once
+var ErrLoginCodeUsed = errors.New("login code has already been used")
+
+// CreateLoginCode stores a login code delivered to a user.
+func (services *SQLServices) CreateLoginCode(code models.LoginCode) (models.LoginCode, error) {
+	if err := services.DB.Create(&code).Error; err != nil {
+		return models.LoginCode{}, err
+	}
+
+	return code, nil
+}
+
+// GetLoginCodeByToken returns the login code with the given hashed magic link token.
+func (services *SQLServices) GetLoginCodeByToken(hashedToken string) (models.LoginCode, error) {
+	var code models.LoginCode
+
+	if err := services.DB.Where("hashed_token = ?", hashedToken).First(&code).Error; err != nil {
+		return models.LoginCode{}, err
+	}
+
+	return code, nil
+}
+
+// GetLatestLoginCode returns the login code delivered last to a user, the only one users can type.
+func (services *SQLServices) GetLatestLoginCode(username string) (models.LoginCode, error) {
+	var code models.LoginCode
+
+	if err := services.DB.
+		Where("username = ?", username).
+		Order("created_at DESC, id DESC").
+		First(&code).Error; err != nil {
+		return models.LoginCode{}, err
+	}
+
+	return code, nil
+}
+
+// RecordLoginCodeAttempt counts a wrong code tried against a login code, and returns the updated login code.
+func (services *SQLServices) RecordLoginCodeAttempt(id int64) (models.LoginCode, error) {
+	var code models.LoginCode
+
+	// the count is incremented in a single statement, so concurrent attempts are all counted
+	result := services.DB.Model(&code).
+		Clauses(clause.Returning{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return models.LoginCode{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.LoginCode{}, gorm.ErrRecordNotFound
+	}
+
+	return code, nil
+}
+
+// UseLoginCode marks a login code as used, and uses up every other login code of the user.
+// It returns ErrLoginCodeUsed if the code was already used.
+func (services *SQLServices) UseLoginCode(code models.LoginCode) error {
+	return services.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+
+		result := tx.Model(&models.LoginCode{}).
+			Where("id = ? AND used_at IS NULL", code.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLoginCodeUsed
+		}
+
+		return tx.Model(&models.LoginCode{}).
+			Where("username = ? AND used_at IS NULL", code.Username).
+			Update("used_at", now).Error
+	})
+}
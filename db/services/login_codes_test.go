@@ -0,0 +1,77 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func createRandomLoginCode(t *testing.T, username string) models.LoginCode {
+	code, err := services.CreateLoginCode(models.LoginCode{
+		Username:    username,
+		HashedCode:  util.RandomString(64, util.LOWERCASE),
+		HashedToken: util.RandomString(64, util.LOWERCASE),
+		ExpiresAt:   time.Now().Add(10 * time.Minute).UTC(),
+		CreatedAt:   time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	require.NotZero(t, code.ID)
+
+	return code
+}
+
+func TestGetLoginCode(t *testing.T) {
+	user := createRandomUser(t)
+	first := createRandomLoginCode(t, user.Username)
+	latest := createRandomLoginCode(t, user.Username)
+
+	found, err := services.GetLoginCodeByToken(first.HashedToken)
+	require.NoError(t, err)
+	require.Equal(t, first.ID, found.ID)
+	require.Equal(t, first.HashedCode, found.HashedCode)
+	require.Nil(t, found.UsedAt)
+
+	found, err = services.GetLatestLoginCode(user.Username)
+	require.NoError(t, err)
+	require.Equal(t, latest.ID, found.ID)
+
+	_, err = services.GetLoginCodeByToken(util.RandomString(64, util.LOWERCASE))
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = services.GetLatestLoginCode(createRandomUser(t).Username)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestRecordLoginCodeAttempt(t *testing.T) {
+	code := createRandomLoginCode(t, createRandomUser(t).Username)
+
+	for attempts := int32(1); attempts <= 3; attempts++ {
+		updated, err := services.RecordLoginCodeAttempt(code.ID)
+		require.NoError(t, err)
+		require.Equal(t, attempts, updated.Attempts)
+		require.Equal(t, code.HashedToken, updated.HashedToken)
+	}
+
+	_, err := services.RecordLoginCodeAttempt(-1)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestUseLoginCode(t *testing.T) {
+	user := createRandomUser(t)
+	code := createRandomLoginCode(t, user.Username)
+	other := createRandomLoginCode(t, user.Username)
+
+	require.NoError(t, services.UseLoginCode(code))
+	require.ErrorIs(t, services.UseLoginCode(code), ErrLoginCodeUsed)
+
+	// the other codes of the user are used up
+	require.ErrorIs(t, services.UseLoginCode(other), ErrLoginCodeUsed)
+	found, err := services.GetLoginCodeByToken(other.HashedToken)
+	require.NoError(t, err)
+	require.NotNil(t, found.UsedAt)
+
+	// but not the codes of other users
+	require.NoError(t, services.UseLoginCode(createRandomLoginCode(t, createRandomUser(t).Username)))
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// GetLoginFailure returns the failed logins of a username or of a client address.
+// It returns gorm.ErrRecordNotFound if there are none.
+func (services *SQLServices) GetLoginFailure(kind, subject string) (models.LoginFailure, error) {
+	var failure models.LoginFailure
+
+	if err := services.DB.Where("kind = ? AND subject = ?", kind, subject).First(&failure).Error; err != nil {
+		return models.LoginFailure{}, err
+	}
+
+	return failure, nil
+}
+
+// RecordLoginFailure counts a failed login of a username or of a client address and returns the updated count.
+// Failures older than forgetBefore are forgotten, so the count starts over.
+func (services *SQLServices) RecordLoginFailure(kind, subject string, forgetBefore time.Time) (models.LoginFailure, error) {
+	failure := models.LoginFailure{
+		Kind:         kind,
+		Subject:      subject,
+		Failures:     1,
+		LastFailedAt: time.Now().UTC(),
+	}
+
+	// the count is incremented in a single statement, so concurrent failures are all counted
+	if err := services.DB.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "kind"}, {Name: "subject"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"failures": gorm.Expr(
+					"CASE WHEN login_failures.last_failed_at < ? THEN 1 ELSE login_failures.failures + 1 END",
+					forgetBefore.UTC(),
+				),
+				"last_failed_at": gorm.Expr("excluded.last_failed_at"),
+			}),
+		},
+		clause.Returning{},
+	).Create(&failure).Error; err != nil {
+		return models.LoginFailure{}, err
+	}
+
+	return failure, nil
+}
+
+// ClearLoginFailures forgets the failed logins of a username or of a client address.
+func (services *SQLServices) ClearLoginFailures(kind, subject string) error {
+	return services.DB.Where("kind = ? AND subject = ?", kind, subject).Delete(&models.LoginFailure{}).Error
+}
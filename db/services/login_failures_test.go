@@ -0,0 +1,45 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestLoginFailures(t *testing.T) {
+	username := util.RandomUsername()
+	forgetBefore := time.Now().Add(-time.Hour)
+
+	_, err := services.GetLoginFailure(models.LoginFailureKindUsername, username)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	for i := int32(1); i <= 3; i++ {
+		failure, err := services.RecordLoginFailure(models.LoginFailureKindUsername, username, forgetBefore)
+		require.NoError(t, err)
+		require.Equal(t, i, failure.Failures)
+	}
+
+	// failures of an address with the same name are counted apart
+	failure, err := services.RecordLoginFailure(models.LoginFailureKindIP, username, forgetBefore)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), failure.Failures)
+
+	failure, err = services.GetLoginFailure(models.LoginFailureKindUsername, username)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), failure.Failures)
+	require.WithinDuration(t, time.Now(), failure.LastFailedAt, time.Second)
+
+	// failures older than forgetBefore are forgotten
+	failure, err = services.RecordLoginFailure(models.LoginFailureKindUsername, username, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), failure.Failures)
+
+	require.NoError(t, services.ClearLoginFailures(models.LoginFailureKindUsername, username))
+	_, err = services.GetLoginFailure(models.LoginFailureKindUsername, username)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = services.GetLoginFailure(models.LoginFailureKindIP, username)
+	require.NoError(t, err)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"Simple-Bank/db/models"
 	"database/sql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -22,11 +23,27 @@ func TestMain(m *testing.M) {
 
 	exitCode := m.Run()
 
+	db.Exec("DELETE FROM password_resets")
+	db.Exec("DELETE FROM email_verifications")
+	db.Exec("DELETE FROM login_failures")
+	db.Exec("DELETE FROM mfa_challenges")
+	db.Exec("DELETE FROM recovery_codes")
+	db.Exec("DELETE FROM totp_credentials")
+	db.Exec("DELETE FROM oauth_authorization_codes")
+	db.Exec("DELETE FROM oauth_clients")
+	db.Exec("DELETE FROM api_keys")
+	db.Exec("DELETE FROM approvals")
+	db.Exec("DELETE FROM audit_events")
+	db.Exec("DELETE FROM revoked_tokens")
 	db.Exec("DELETE FROM sessions")
+	db.Exec("DELETE FROM balance_snapshots")
+	db.Exec("DELETE FROM pending_credits")
 	db.Exec("DELETE FROM entries")
+	db.Exec("DELETE FROM transfer_status_history")
 	db.Exec("DELETE FROM transfers")
-	db.Exec("DELETE FROM accounts")
-	db.Exec("DELETE FROM users")
+	db.Exec("DELETE FROM accounts WHERE is_system = false")
+	db.Exec("UPDATE accounts SET balance = 0 WHERE is_system = true")
+	db.Exec("DELETE FROM users WHERE username != ?", models.SystemUsername)
 
 	DB, err := db.DB()
 	if err != nil {
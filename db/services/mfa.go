@@ -0,0 +1,202 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	// ErrTOTPAlreadyEnabled is returned when enrolling or confirming a user whose totp credential is already confirmed
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTOTPCodeUsed is returned when a totp code of a time step already used is used again
+	ErrTOTPCodeUsed = errors.New("code has already been used")
+	// ErrMFAChallengeUsed is returned when a login challenge is completed more than once
+	ErrMFAChallengeUsed = errors.New("challenge has already been used")
+)
+
+// SaveTOTPCredential stores the totp credential of a user being enrolled, replacing any unconfirmed credential.
+// It returns ErrTOTPAlreadyEnabled if the user has a confirmed credential.
+func (services *SQLServices) SaveTOTPCredential(credential models.TOTPCredential) (models.TOTPCredential, error) {
+	err := services.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.TOTPCredential
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("username = ?", credential.Username).
+			First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			if existing.IsConfirmed() {
+				return ErrTOTPAlreadyEnabled
+			}
+			if err := tx.Delete(&existing).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&credential).Error
+	})
+	if err != nil {
+		return models.TOTPCredential{}, err
+	}
+
+	return credential, nil
+}
+
+// GetTOTPCredential returns the totp credential of a user, confirmed or not.
+func (services *SQLServices) GetTOTPCredential(username string) (models.TOTPCredential, error) {
+	var credential models.TOTPCredential
+
+	if err := services.DB.Where("username = ?", username).First(&credential).Error; err != nil {
+		return models.TOTPCredential{}, err
+	}
+
+	return credential, nil
+}
+
+// ConfirmTOTPCredential confirms the totp credential of a user with the time step of a first code,
+// and replaces the recovery codes of the user with the given hashed codes.
+func (services *SQLServices) ConfirmTOTPCredential(username string, step int64, hashedRecoveryCodes []string) (models.TOTPCredential, error) {
+	var credential models.TOTPCredential
+
+	err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("username = ?", username).
+			First(&credential).Error; err != nil {
+			return err
+		}
+		if credential.IsConfirmed() {
+			return ErrTOTPAlreadyEnabled
+		}
+
+		now := time.Now().UTC()
+		if err := tx.Model(&credential).Updates(map[string]any{"confirmed_at": now, "last_used_step": step}).Error; err != nil {
+			return err
+		}
+		credential.ConfirmedAt = &now
+		credential.LastUsedStep = step
+
+		return replaceRecoveryCodes(tx, username, hashedRecoveryCodes)
+	})
+	if err != nil {
+		return models.TOTPCredential{}, err
+	}
+
+	return credential, nil
+}
+
+// UseTOTPStep records that a code of the given time step was accepted for a user.
+// It returns ErrTOTPCodeUsed if a code of this step or of a later one was already accepted.
+func (services *SQLServices) UseTOTPStep(username string, step int64) error {
+	result := services.DB.Model(&models.TOTPCredential{}).
+		Where("username = ? AND last_used_step < ?", username, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTOTPCodeUsed
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code of a user as used.
+// It returns gorm.ErrRecordNotFound if the user has no such unused code.
+func (services *SQLServices) UseRecoveryCode(username, hashedCode string) error {
+	result := services.DB.Model(&models.RecoveryCode{}).
+		Where("username = ? AND hashed_code = ? AND used_at IS NULL", username, hashedCode).
+		Update("used_at", time.Now().UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteTOTPCredential disables the two-factor authentication of a user, deleting their credential
+// and their recovery codes.
+func (services *SQLServices) DeleteTOTPCredential(username string) error {
+	return services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("username = ?", username).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("username = ?", username).Delete(&models.TOTPCredential{}).Error
+	})
+}
+
+// CreateMFAChallenge stores a login challenge.
+func (services *SQLServices) CreateMFAChallenge(challenge models.MFAChallenge) (models.MFAChallenge, error) {
+	if err := services.DB.Create(&challenge).Error; err != nil {
+		return models.MFAChallenge{}, err
+	}
+
+	return challenge, nil
+}
+
+// GetMFAChallenge returns the login challenge with the given hashed token.
+func (services *SQLServices) GetMFAChallenge(hashedToken string) (models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+
+	if err := services.DB.Where("hashed_token = ?", hashedToken).First(&challenge).Error; err != nil {
+		return models.MFAChallenge{}, err
+	}
+
+	return challenge, nil
+}
+
+// FailMFAChallenge records a wrong code for a login challenge and returns the updated challenge.
+func (services *SQLServices) FailMFAChallenge(id int64) (models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+
+	if err := services.DB.Model(&challenge).
+		Clauses(clause.Returning{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error; err != nil {
+		return models.MFAChallenge{}, err
+	}
+	if challenge.ID == 0 {
+		return models.MFAChallenge{}, gorm.ErrRecordNotFound
+	}
+
+	return challenge, nil
+}
+
+// ConsumeMFAChallenge marks a login challenge as completed.
+// It returns ErrMFAChallengeUsed if it was already completed.
+func (services *SQLServices) ConsumeMFAChallenge(id int64) error {
+	result := services.DB.Model(&models.MFAChallenge{}).
+		Where("id = ? AND consumed_at IS NULL", id).
+		Update("consumed_at", time.Now().UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFAChallengeUsed
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, username string, hashedCodes []string) error {
+	if err := tx.Where("username = ?", username).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(hashedCodes) == 0 {
+		return nil
+	}
+
+	codes := make([]models.RecoveryCode, len(hashedCodes))
+	for i, hashedCode := range hashedCodes {
+		codes[i] = models.RecoveryCode{Username: username, HashedCode: hashedCode}
+	}
+
+	return tx.Create(&codes).Error
+}
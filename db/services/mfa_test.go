@@ -0,0 +1,74 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestTOTPCredential(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := services.SaveTOTPCredential(models.TOTPCredential{Username: user.Username, EncryptedSecret: "first"})
+	require.NoError(t, err)
+	// an unconfirmed credential is replaced by a new enrollment
+	_, err = services.SaveTOTPCredential(models.TOTPCredential{Username: user.Username, EncryptedSecret: "second"})
+	require.NoError(t, err)
+
+	credential, err := services.GetTOTPCredential(user.Username)
+	require.NoError(t, err)
+	require.Equal(t, "second", credential.EncryptedSecret)
+	require.False(t, credential.IsConfirmed())
+
+	hashedCodes := []string{util.RandomString(64, util.LOWERCASE), util.RandomString(64, util.LOWERCASE)}
+	credential, err = services.ConfirmTOTPCredential(user.Username, 100, hashedCodes)
+	require.NoError(t, err)
+	require.True(t, credential.IsConfirmed())
+
+	_, err = services.ConfirmTOTPCredential(user.Username, 101, hashedCodes)
+	require.ErrorIs(t, err, ErrTOTPAlreadyEnabled)
+	_, err = services.SaveTOTPCredential(models.TOTPCredential{Username: user.Username, EncryptedSecret: "third"})
+	require.ErrorIs(t, err, ErrTOTPAlreadyEnabled)
+
+	// codes of the step of the confirmation or of earlier steps cannot be replayed
+	require.ErrorIs(t, services.UseTOTPStep(user.Username, 100), ErrTOTPCodeUsed)
+	require.NoError(t, services.UseTOTPStep(user.Username, 101))
+	require.ErrorIs(t, services.UseTOTPStep(user.Username, 101), ErrTOTPCodeUsed)
+
+	require.NoError(t, services.UseRecoveryCode(user.Username, hashedCodes[0]))
+	require.ErrorIs(t, services.UseRecoveryCode(user.Username, hashedCodes[0]), gorm.ErrRecordNotFound)
+
+	require.NoError(t, services.DeleteTOTPCredential(user.Username))
+	_, err = services.GetTOTPCredential(user.Username)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.ErrorIs(t, services.UseRecoveryCode(user.Username, hashedCodes[1]), gorm.ErrRecordNotFound)
+}
+
+func TestMFAChallenge(t *testing.T) {
+	user := createRandomUser(t)
+
+	challenge, err := services.CreateMFAChallenge(models.MFAChallenge{
+		HashedToken: util.RandomString(64, util.LOWERCASE),
+		Username:    user.Username,
+		ExpiresAt:   time.Now().Add(time.Minute).UTC(),
+	})
+	require.NoError(t, err)
+	require.NotZero(t, challenge.ID)
+
+	stored, err := services.GetMFAChallenge(challenge.HashedToken)
+	require.NoError(t, err)
+	require.Equal(t, challenge.ID, stored.ID)
+
+	failed, err := services.FailMFAChallenge(challenge.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), failed.Attempts)
+
+	require.NoError(t, services.ConsumeMFAChallenge(challenge.ID))
+	require.ErrorIs(t, services.ConsumeMFAChallenge(challenge.ID), ErrMFAChallengeUsed)
+
+	_, err = services.GetMFAChallenge(util.RandomString(64, util.LOWERCASE))
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
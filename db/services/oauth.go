@@ -0,0 +1,81 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// ErrAuthorizationCodeUsed is returned when an authorization code is exchanged more than once
+var ErrAuthorizationCodeUsed = errors.New("authorization code has already been used")
+
+// CreateOAuthClient stores a new OAuth client.
+func (services *SQLServices) CreateOAuthClient(client models.OAuthClient) (models.OAuthClient, error) {
+	if err := services.DB.Create(&client).Error; err != nil {
+		return models.OAuthClient{}, err
+	}
+
+	return client, nil
+}
+
+// GetOAuthClient returns the OAuth client with the given id.
+func (services *SQLServices) GetOAuthClient(id string) (models.OAuthClient, error) {
+	var client models.OAuthClient
+
+	if err := services.DB.Where("id = ?", id).First(&client).Error; err != nil {
+		return models.OAuthClient{}, err
+	}
+
+	return client, nil
+}
+
+// ListOAuthClients returns the OAuth clients registered by a user, newest first.
+func (services *SQLServices) ListOAuthClients(owner string) ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+
+	if err := services.DB.Where("owner = ?", owner).Order("created_at DESC").Find(&clients).Error; err != nil {
+		return []models.OAuthClient{}, err
+	}
+
+	return clients, nil
+}
+
+// CreateAuthorizationCode stores an authorization code granted by a user to an OAuth client.
+func (services *SQLServices) CreateAuthorizationCode(code models.AuthorizationCode) (models.AuthorizationCode, error) {
+	if err := services.DB.Create(&code).Error; err != nil {
+		return models.AuthorizationCode{}, err
+	}
+
+	return code, nil
+}
+
+// ConsumeAuthorizationCode marks the authorization code with the given hash as used and returns it.
+// It returns ErrAuthorizationCodeUsed along with the code if it was already used.
+func (services *SQLServices) ConsumeAuthorizationCode(hashedCode string) (models.AuthorizationCode, error) {
+	var code models.AuthorizationCode
+
+	err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("hashed_code = ?", hashedCode).
+			First(&code).Error; err != nil {
+			return err
+		}
+		if code.ConsumedAt != nil {
+			return ErrAuthorizationCodeUsed
+		}
+
+		now := time.Now().UTC()
+		code.ConsumedAt = &now
+		return tx.Model(&code).Update("consumed_at", now).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrAuthorizationCodeUsed) {
+			return code, err
+		}
+		return models.AuthorizationCode{}, err
+	}
+
+	return code, nil
+}
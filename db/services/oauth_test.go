@@ -0,0 +1,70 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func createOAuthClient(t *testing.T, owner string) models.OAuthClient {
+	client, err := services.CreateOAuthClient(models.OAuthClient{
+		ID:           util.RandomString(32, util.LOWERCASE),
+		Owner:        owner,
+		Name:         "budgeting app",
+		RedirectURIs: "https://app.example.com/callback",
+		Scopes:       "accounts:read",
+	})
+	require.NoError(t, err)
+	require.NotZero(t, client.CreatedAt)
+
+	return client
+}
+
+func TestOAuthClients(t *testing.T) {
+	user := createRandomUser(t)
+	client := createOAuthClient(t, user.Username)
+	createOAuthClient(t, user.Username)
+
+	stored, err := services.GetOAuthClient(client.ID)
+	require.NoError(t, err)
+	require.Equal(t, client.Owner, stored.Owner)
+	require.False(t, stored.IsConfidential())
+
+	clients, err := services.ListOAuthClients(user.Username)
+	require.NoError(t, err)
+	require.Len(t, clients, 2)
+
+	_, err = services.GetOAuthClient(util.RandomString(32, util.LOWERCASE))
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestConsumeAuthorizationCode(t *testing.T) {
+	user := createRandomUser(t)
+	client := createOAuthClient(t, user.Username)
+
+	code, err := services.CreateAuthorizationCode(models.AuthorizationCode{
+		HashedCode:    util.RandomString(64, util.LOWERCASE),
+		ClientID:      client.ID,
+		Username:      user.Username,
+		RedirectURI:   "https://app.example.com/callback",
+		Scopes:        "accounts:read",
+		CodeChallenge: util.RandomString(43, util.ALPHANUMERIC),
+		ExpiresAt:     time.Now().Add(time.Minute).UTC(),
+	})
+	require.NoError(t, err)
+
+	consumed, err := services.ConsumeAuthorizationCode(code.HashedCode)
+	require.NoError(t, err)
+	require.NotNil(t, consumed.ConsumedAt)
+	require.Equal(t, user.Username, consumed.Username)
+
+	// codes can only be exchanged once
+	_, err = services.ConsumeAuthorizationCode(code.HashedCode)
+	require.ErrorIs(t, err, ErrAuthorizationCodeUsed)
+
+	_, err = services.ConsumeAuthorizationCode(util.RandomString(64, util.LOWERCASE))
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+// MaxPasswordHistory is the number of passwords kept in the history of a user, the current one included
+const MaxPasswordHistory = 24
+
+// ListPasswordHistory returns the last limit passwords of a user, the current one included, the latest first.
+func (services *SQLServices) ListPasswordHistory(username string, limit int) ([]models.PasswordHistory, error) {
+	var history []models.PasswordHistory
+
+	if err := services.DB.
+		Where("username = ?", username).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&history).Error; err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// recordPassword adds the hash of the new password of a user to their history, and forgets their passwords
+// beyond MaxPasswordHistory.
+func recordPassword(tx *gorm.DB, username, hashedPassword string) error {
+	if err := tx.Create(&models.PasswordHistory{
+		Username:       username,
+		HashedPassword: hashedPassword,
+		CreatedAt:      time.Now().UTC(),
+	}).Error; err != nil {
+		return err
+	}
+
+	return tx.Exec(`DELETE FROM password_history WHERE username = ? AND id NOT IN (
+		SELECT id FROM password_history WHERE username = ? ORDER BY created_at DESC, id DESC LIMIT ?)`,
+		username, username, MaxPasswordHistory).Error
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPasswordHistory(t *testing.T) {
+	user := createRandomUser(t)
+
+	history, err := services.ListPasswordHistory(user.Username, 5)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, user.HashedPassword, history[0].HashedPassword)
+
+	password := util.RandomPassword()
+	updated, err := services.UpdateUser(UpdateUserRequest{Username: user.Username, Password: &password})
+	require.NoError(t, err)
+
+	reset := createRandomPasswordReset(t, user.Username)
+	found, err := services.GetPasswordReset(reset.HashedToken)
+	require.NoError(t, err)
+	resetUser, err := services.ResetPassword(found, util.RandomPassword())
+	require.NoError(t, err)
+
+	history, err = services.ListPasswordHistory(user.Username, 5)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	require.Equal(t, resetUser.HashedPassword, history[0].HashedPassword)
+	require.Equal(t, updated.HashedPassword, history[1].HashedPassword)
+	require.Equal(t, user.HashedPassword, history[2].HashedPassword)
+	require.WithinDuration(t, time.Now(), history[0].CreatedAt, time.Second)
+
+	history, err = services.ListPasswordHistory(user.Username, 2)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	// changing other fields does not add to the history
+	fullname := util.RandomFullname()
+	_, err = services.UpdateUser(UpdateUserRequest{Username: user.Username, Fullname: &fullname})
+	require.NoError(t, err)
+	history, err = services.ListPasswordHistory(user.Username, 5)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+}
+
+func TestPasswordHistoryPruned(t *testing.T) {
+	user := createRandomUser(t)
+
+	for i := 0; i < MaxPasswordHistory; i++ {
+		password := util.RandomPassword()
+		_, err := services.UpdateUser(UpdateUserRequest{Username: user.Username, Password: &password})
+		require.NoError(t, err)
+	}
+
+	history, err := services.ListPasswordHistory(user.Username, MaxPasswordHistory+10)
+	require.NoError(t, err)
+	require.Len(t, history, MaxPasswordHistory)
+	for _, entry := range history {
+		require.NotEqual(t, user.HashedPassword, entry.HashedPassword)
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ErrPasswordResetUsed is returned when a password reset token is used more than once
+var ErrPasswordResetUsed = errors.New("password reset has already been used")
+
+// GetUserByEmail returns the user with the given email.
+func (services *SQLServices) GetUserByEmail(email string) (models.User, error) {
+	var user models.User
+
+	if err := services.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// CreatePasswordReset stores a password reset token emailed to a user.
+func (services *SQLServices) CreatePasswordReset(reset models.PasswordReset) (models.PasswordReset, error) {
+	if err := services.DB.Create(&reset).Error; err != nil {
+		return models.PasswordReset{}, err
+	}
+
+	return reset, nil
+}
+
+// GetPasswordReset returns the password reset with the given hashed token.
+func (services *SQLServices) GetPasswordReset(hashedToken string) (models.PasswordReset, error) {
+	var reset models.PasswordReset
+
+	if err := services.DB.Where("hashed_token = ?", hashedToken).First(&reset).Error; err != nil {
+		return models.PasswordReset{}, err
+	}
+
+	return reset, nil
+}
+
+// ResetPassword sets the password of the user of a password reset, adding it to the password history, and uses up
+// every reset of the user.
+// Since the password may have been stolen, every session of the user is blocked, and the failed logins of the
+// user are forgotten. It returns ErrPasswordResetUsed if the reset was already used.
+func (services *SQLServices) ResetPassword(reset models.PasswordReset, password string) (models.User, error) {
+	hashedPassword, err := util.HashPassword(password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	var user models.User
+	err = services.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+
+		res := tx.Model(&models.PasswordReset{}).
+			Where("id = ? AND used_at IS NULL", reset.ID).
+			Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrPasswordResetUsed
+		}
+		if err := tx.Model(&models.PasswordReset{}).
+			Where("username = ? AND used_at IS NULL", reset.Username).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		if err := updateUser(tx, reset.Username, map[string]interface{}{
+			"hashed_password":         hashedPassword,
+			"password_reset_required": false,
+		}); err != nil {
+			return err
+		}
+		if err := recordPassword(tx, reset.Username, hashedPassword); err != nil {
+			return err
+		}
+		if err := blockUserSessions(tx, reset.Username); err != nil {
+			return err
+		}
+		if err := tx.
+			Where("kind = ? AND subject = ?", models.LoginFailureKindUsername, reset.Username).
+			Delete(&models.LoginFailure{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("username = ?", reset.Username).First(&user).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// RehashPassword replaces the password hash of a user with a hash of the same password using current parameters.
+// The hash is only replaced if it is still oldHash, so a password changed in the meantime is kept.
+func (services *SQLServices) RehashPassword(username, oldHash, newHash string) error {
+	return services.DB.Model(&models.User{}).
+		Where("username = ? AND hashed_password = ?", username, oldHash).
+		Update("hashed_password", newHash).Error
+}
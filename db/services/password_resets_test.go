@@ -0,0 +1,87 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func createRandomPasswordReset(t *testing.T, username string) models.PasswordReset {
+	reset, err := services.CreatePasswordReset(models.PasswordReset{
+		HashedToken: util.RandomString(64, util.LOWERCASE),
+		Username:    username,
+		ExpiresAt:   time.Now().Add(time.Hour).UTC(),
+	})
+	require.NoError(t, err)
+	require.NotZero(t, reset.ID)
+
+	return reset
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	user := createRandomUser(t)
+
+	found, err := services.GetUserByEmail(user.Email)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, found.Username)
+
+	_, err = services.GetUserByEmail(util.RandomEmail())
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestResetPassword(t *testing.T) {
+	user := createRandomUser(t)
+	session := createUserSession(t, user.Username, time.Now().Add(time.Hour))
+	_, err := services.RecordLoginFailure(models.LoginFailureKindUsername, user.Username, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	reset := createRandomPasswordReset(t, user.Username)
+	other := createRandomPasswordReset(t, user.Username)
+
+	found, err := services.GetPasswordReset(reset.HashedToken)
+	require.NoError(t, err)
+	require.Nil(t, found.UsedAt)
+
+	password := util.RandomPassword()
+	updated, err := services.ResetPassword(found, password)
+	require.NoError(t, err)
+	require.NoError(t, util.CheckPassword(password, updated.HashedPassword))
+
+	// every session is blocked, and the failed logins are forgotten
+	stored, err := services.GetSession(session.ID)
+	require.NoError(t, err)
+	require.True(t, stored.IsBlocked)
+	_, err = services.GetLoginFailure(models.LoginFailureKindUsername, user.Username)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// the reset and the other resets of the user cannot be used again
+	_, err = services.ResetPassword(found, util.RandomPassword())
+	require.ErrorIs(t, err, ErrPasswordResetUsed)
+	_, err = services.ResetPassword(other, util.RandomPassword())
+	require.ErrorIs(t, err, ErrPasswordResetUsed)
+
+	found, err = services.GetPasswordReset(reset.HashedToken)
+	require.NoError(t, err)
+	require.NotNil(t, found.UsedAt)
+}
+
+func TestRehashPassword(t *testing.T) {
+	user := createRandomUser(t)
+
+	newHash, err := util.HashPassword(util.RandomPassword())
+	require.NoError(t, err)
+	require.NoError(t, services.RehashPassword(user.Username, user.HashedPassword, newHash))
+
+	found, err := services.GetUser(user.Username)
+	require.NoError(t, err)
+	require.Equal(t, newHash, found.HashedPassword)
+
+	// a hash changed in the meantime is kept
+	require.NoError(t, services.RehashPassword(user.Username, user.HashedPassword, "stale"))
+	found, err = services.GetUser(user.Username)
+	require.NoError(t, err)
+	require.Equal(t, newHash, found.HashedPassword)
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// UpdateRateLimitBucket updates the tokens of the bucket with key to those returned by update, called with the
+// tokens the bucket held and when, or with isNew if the bucket did not exist. The bucket is locked until it is
+// updated, so concurrent requests take its tokens one after the other.
+func (services *SQLServices) UpdateRateLimitBucket(key string, update func(tokens float64, updatedAt time.Time, isNew bool) float64, now time.Time) error {
+	return services.DB.Transaction(func(tx *gorm.DB) error {
+		bucket := models.RateLimitBucket{
+			Key:       key,
+			UpdatedAt: now.UTC(),
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&bucket)
+		if result.Error != nil {
+			return result.Error
+		}
+		isNew := result.RowsAffected == 1
+		if !isNew {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("key = ?", key).
+				First(&bucket).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.RateLimitBucket{}).
+			Where("key = ?", key).
+			Updates(map[string]any{
+				"tokens":     update(bucket.Tokens, bucket.UpdatedAt, isNew),
+				"updated_at": now.UTC(),
+			}).Error
+	})
+}
+
+// DeleteRateLimitBuckets deletes the buckets last updated before updatedBefore.
+func (services *SQLServices) DeleteRateLimitBuckets(updatedBefore time.Time) error {
+	return services.DB.Where("updated_at < ?", updatedBefore.UTC()).Delete(&models.RateLimitBucket{}).Error
+}
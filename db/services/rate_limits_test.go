@@ -0,0 +1,54 @@
+package services
+
+import (
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// updateBucket updates the bucket with key to tokens and returns the tokens it held and whether it was new.
+func updateBucket(t *testing.T, key string, tokens float64, now time.Time) (float64, time.Time, bool) {
+	var (
+		previous  float64
+		updatedAt time.Time
+		isNew     bool
+	)
+	err := services.UpdateRateLimitBucket(key, func(bucketTokens float64, bucketUpdatedAt time.Time, bucketIsNew bool) float64 {
+		previous, updatedAt, isNew = bucketTokens, bucketUpdatedAt, bucketIsNew
+		return tokens
+	}, now)
+	require.NoError(t, err)
+
+	return previous, updatedAt, isNew
+}
+
+func TestUpdateRateLimitBucket(t *testing.T) {
+	key := "test:" + util.RandomString(32, util.LOWERCASE)
+	createdAt := time.Now().Add(-time.Minute).UTC()
+
+	_, _, isNew := updateBucket(t, key, 4, createdAt)
+	require.True(t, isNew)
+
+	tokens, updatedAt, isNew := updateBucket(t, key, 3, time.Now())
+	require.False(t, isNew)
+	require.Equal(t, float64(4), tokens)
+	require.WithinDuration(t, createdAt, updatedAt, time.Millisecond)
+
+	tokens, _, _ = updateBucket(t, key, 2, time.Now())
+	require.Equal(t, float64(3), tokens)
+}
+
+func TestDeleteRateLimitBuckets(t *testing.T) {
+	stale := "test:" + util.RandomString(32, util.LOWERCASE)
+	fresh := "test:" + util.RandomString(32, util.LOWERCASE)
+	updateBucket(t, stale, 1, time.Now().Add(-time.Hour))
+	updateBucket(t, fresh, 1, time.Now())
+
+	require.NoError(t, services.DeleteRateLimitBuckets(time.Now().Add(-time.Minute)))
+
+	_, _, isNew := updateBucket(t, stale, 1, time.Now())
+	require.True(t, isNew)
+	_, _, isNew = updateBucket(t, fresh, 1, time.Now())
+	require.False(t, isNew)
+}
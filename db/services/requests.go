@@ -1,5 +1,10 @@
 package services
 
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
 // UpdateUserRequest represents a request to update user information.
 type UpdateUserRequest struct {
 	// Username of the user to update.
@@ -33,3 +38,107 @@ type TransferRequest struct {
 	// Amount is the amount of money to be transferred from FromAccountID to ToAccountID
 	Amount int32
 }
+
+// UpdateTransferStatusRequest represents a request to move a transfer to another status
+type UpdateTransferStatusRequest struct {
+	// TransferID is the id of the transfer
+	TransferID int64
+	// Actor is the username of the user requesting the change
+	Actor string
+	// Reason is why the status of the transfer is changed
+	Reason string
+}
+
+// RevokeTokenRequest represents a request to deny a token until it expires
+type RevokeTokenRequest struct {
+	// TokenID is the id of the token
+	TokenID uuid.UUID
+	// Username is the username of the owner of the token
+	Username string
+	// ExpiresAt is when the token expires, after which it does not need to be denied anymore
+	ExpiresAt time.Time
+	// RevokedBy is the username of the user revoking the token
+	RevokedBy string
+	// Reason is why the token is revoked
+	Reason string
+}
+
+// AdminAction identifies who performs an action of the back office and why, for the audit trail
+type AdminAction struct {
+	// Operator is the username of the staff member performing the action
+	Operator string
+	// Reason is why the action is performed, it is mandatory
+	Reason string
+}
+
+// SearchUsersRequest represents a request to search users by username or email
+type SearchUsersRequest struct {
+	// Query is matched against any part of the username and the email, case-insensitively
+	Query string
+	// PageSize represents number of users in a page
+	PageSize int
+	// PageNumber page number
+	PageNumber int
+}
+
+// ListEntriesRequest represents a request to get a list of the entries of an account, newest first
+type ListEntriesRequest struct {
+	// AccountID is the id of the account
+	AccountID int64
+	// PageSize represents number of entries in a page
+	PageSize int
+	// PageNumber page number
+	PageNumber int
+}
+
+// ListAuditEventsRequest represents a request to get a list of audit events, newest first
+type ListAuditEventsRequest struct {
+	// Operator filters the events by the username of the operator (optional)
+	Operator string
+	// TargetType filters the events by the type of their target (optional)
+	TargetType string
+	// TargetID filters the events by the id of their target (optional)
+	TargetID string
+	// PageSize represents number of events in a page
+	PageSize int
+	// PageNumber page number
+	PageNumber int
+}
+
+// CreateApprovalRequest represents a request to store an operation until another user approves it
+type CreateApprovalRequest struct {
+	// Operation is the kind of the operation, e.g. models.OperationTransfer
+	Operation string
+	// Payload is a JSON object with the parameters of the operation
+	Payload string
+	// RequestedBy is the username of the user requesting the operation
+	RequestedBy string
+	// Reason is why the operation is requested, it is mandatory
+	Reason string
+	// ExpiresAt is when the operation expires if nobody decided on it
+	ExpiresAt time.Time
+}
+
+// DecideApprovalRequest represents the decision of a user on a pending operation
+type DecideApprovalRequest struct {
+	// ID is the id of the approval
+	ID int64
+	// Approve is true to approve the operation and false to reject it
+	Approve bool
+	// DecidedBy is the username of the deciding user, who must not be the requester
+	DecidedBy string
+	// Reason is why the operation is approved or rejected, it is mandatory
+	Reason string
+}
+
+// ListApprovalsRequest represents a request to list approvals
+type ListApprovalsRequest struct {
+	// Status filters the approvals by status (optional)
+	Status string
+	// RequestedBy filters the approvals by the username of the requester (optional)
+	RequestedBy string
+	// PageSize represents number of approvals in a page
+	PageSize int
+	// PageNumber page number
+	PageNumber int
+}
@@ -4,7 +4,7 @@ import (
 	"Simple-Bank/db/models"
 	"Simple-Bank/requests"
 	"Simple-Bank/util"
-	"fmt"
+	"database/sql"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -13,11 +13,14 @@ import (
 
 type SQLServices struct {
 	DB *gorm.DB
+	// TxRetryPolicy bounds the retries of transactions failing with a deadlock or a serialization failure
+	TxRetryPolicy TxRetryPolicy
 }
 
 func NewSQLServices(db *gorm.DB) Services {
 	return &SQLServices{
-		DB: db,
+		DB:            db,
+		TxRetryPolicy: DefaultTxRetryPolicy,
 	}
 }
 
@@ -41,11 +44,11 @@ func (services *SQLServices) DeleteAccount(id int64) (models.Account, error) {
 	var deletedAccount models.Account
 
 	if err := services.DB.Transaction(func(tx *gorm.DB) error {
-		if err := services.DB.Delete(&deletedAccount, id).Error; err != nil {
+		if err := tx.Delete(&deletedAccount, id).Error; err != nil {
 			return err
 		}
 
-		if err := services.DB.Unscoped().First(&deletedAccount, id).Error; err != nil {
+		if err := tx.Unscoped().First(&deletedAccount, id).Error; err != nil {
 			return err
 		}
 		return nil
@@ -60,6 +63,10 @@ func (services *SQLServices) DepositMoney(req requests.DepositRequest) (models.E
 	var newEntry models.Entry
 
 	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkAccountsNotFrozen(tx, req.AccountID); err != nil {
+			return err
+		}
+
 		newEntry = models.Entry{
 			AccountID: req.AccountID,
 			Amount:    req.Amount,
@@ -71,11 +78,16 @@ func (services *SQLServices) DepositMoney(req requests.DepositRequest) (models.E
 
 		var account models.Account
 		if err := tx.First(&account, req.AccountID).Error; err != nil {
-			return nil
+			return err
 		}
 
 		account.Balance += int64(req.Amount)
-		if err := tx.Save(account).Error; err != nil {
+		if err := tx.Save(&account).Error; err != nil {
+			return err
+		}
+
+		// deposited money comes in through the bank's cash
+		if _, err := postToGLAccount(tx, models.GLCodeCash, -req.Amount); err != nil {
 			return err
 		}
 
@@ -91,6 +103,10 @@ func (services *SQLServices) WithdrawMoney(req requests.WithdrawRequest) (models
 	var newEntry models.Entry
 
 	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkAccountsNotFrozen(tx, req.AccountID); err != nil {
+			return err
+		}
+
 		newEntry = models.Entry{
 			AccountID: req.AccountID,
 			Amount:    -req.Amount,
@@ -102,11 +118,22 @@ func (services *SQLServices) WithdrawMoney(req requests.WithdrawRequest) (models
 
 		var account models.Account
 		if err := tx.First(&account, req.AccountID).Error; err != nil {
-			return nil
+			return err
+		}
+
+		if account.IsHot {
+			if _, err := foldPendingCredits(tx, &account); err != nil {
+				return err
+			}
 		}
 
 		account.Balance -= int64(req.Amount)
-		if err := tx.Save(account).Error; err != nil {
+		if err := tx.Save(&account).Error; err != nil {
+			return err
+		}
+
+		// withdrawn money goes out through the bank's cash
+		if _, err := postToGLAccount(tx, models.GLCodeCash, req.Amount); err != nil {
 			return err
 		}
 
@@ -118,77 +145,55 @@ func (services *SQLServices) WithdrawMoney(req requests.WithdrawRequest) (models
 	return newEntry, nil
 }
 
+// Transfer moves money from a source account owned by req.Owner to a destination account.
+//
+// The transfer is first persisted as pending and then executed in its own transaction. If the execution fails,
+// the transfer is persisted as failed with a reason safe to show to clients, and returned along with an error
+// wrapping ErrTransferFailed.
+//
+// The execution runs with serializable isolation and is retried on deadlocks and serialization failures.
 func (services *SQLServices) Transfer(req TransferRequest) (models.Transfer, error) {
-	var newTransfer models.Transfer
-
-	if err := services.DB.Transaction(func(tx *gorm.DB) error {
-		var srcAccount, dstAccount models.Account
-
-		// always acquire the lock of the account with the lower account id
-		if req.FromAccountID < req.ToAccountID {
-			var err error
-			srcAccount, dstAccount, err = acquireLock(tx, req.FromAccountID, req.ToAccountID)
-			if err != nil {
-				return err
-			}
-		} else {
-			var err error
-			dstAccount, srcAccount, err = acquireLock(tx, req.ToAccountID, req.FromAccountID)
-			if err != nil {
-				return err
-			}
-		}
-
-		if srcAccount.Owner != req.Owner {
-			err := fmt.Errorf("user is not the owner of the source account")
-			return err
-		}
-		srcAccount.Balance -= int64(req.Amount)
-		dstAccount.Balance += int64(req.Amount)
-
-		if err := tx.Save(&srcAccount).Error; err != nil {
-			return err
-		}
-		if err := tx.Save(&dstAccount).Error; err != nil {
-			return err
-		}
+	var srcAccount models.Account
+	if err := services.DB.First(&srcAccount, req.FromAccountID).Error; err != nil {
+		return models.Transfer{}, err
+	}
+	if srcAccount.Owner != req.Owner {
+		return models.Transfer{}, ErrNotAccountOwner
+	}
 
-		FromEntry := models.Entry{
-			AccountID: req.FromAccountID,
-			Amount:    -req.Amount,
-		}
-		ToEntry := models.Entry{
-			AccountID: req.ToAccountID,
-			Amount:    req.Amount,
-		}
-		if err := tx.Create(&FromEntry).Error; err != nil {
-			return err
-		}
-		if err := tx.Create(&ToEntry).Error; err != nil {
+	newTransfer := models.Transfer{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Status:        models.TransferStatusPending,
+		StatusReason:  "transfer requested",
+		CreatedAt:     time.Now().UTC(),
+		UpdatedAt:     time.Now().UTC(),
+	}
+	if err := services.runInTransaction("create_transfer", sql.LevelDefault, func(tx *gorm.DB) error {
+		// entries are created when the transfer completes
+		if err := tx.Omit("incoming_entry_id", "outgoing_entry_id").Create(&newTransfer).Error; err != nil {
 			return err
 		}
 
-		newTransfer = models.Transfer{
-			FromAccountID:   req.FromAccountID,
-			ToAccountID:     req.ToAccountID,
-			Amount:          req.Amount,
-			OutgoingEntryID: FromEntry.ID,
-			IncomingEntryID: ToEntry.ID,
-		}
-
-		if err := tx.Create(&newTransfer).Error; err != nil {
-			return err
-		}
+		return recordTransferStatus(tx, newTransfer, "", req.Owner)
+	}); err != nil {
+		return models.Transfer{}, err
+	}
 
-		return nil
+	var completedTransfer models.Transfer
+	if err := services.runInTransaction("execute_transfer", sql.LevelSerializable, func(tx *gorm.DB) error {
+		completedTransfer = newTransfer
+		return executeTransfer(tx, &completedTransfer, req.Owner)
 	}); err != nil {
-		return newTransfer, err
+		return services.failTransfer(newTransfer, err, req.Owner)
 	}
 
-	return newTransfer, nil
+	return completedTransfer, nil
 }
 
 // ListAccounts retrieves a list of accounts owned by a specified user with pagination.
+// System owned accounts of the general ledger are never listed.
 //
 // It takes a ListAccountsRequest containing information about the owner, page number, and page size.
 // It returns a slice of models.Account representing the accounts retrieved from the database, along with an error if any.
@@ -200,7 +205,7 @@ func (services *SQLServices) ListAccounts(req ListAccountsRequest) ([]models.Acc
 
 	offset := (req.PageNumber - 1) * req.PageSize
 	res := services.DB.
-		Find(&accountsList, "owner = ?", req.Owner).
+		Find(&accountsList, "owner = ? AND is_system = false", req.Owner).
 		Limit(req.PageSize).
 		Offset(offset)
 
@@ -210,6 +215,9 @@ func (services *SQLServices) ListAccounts(req ListAccountsRequest) ([]models.Acc
 	if res.RowsAffected == 0 {
 		return []models.Account{}, gorm.ErrRecordNotFound
 	}
+	if err := services.addPendingCredits(accountsList); err != nil {
+		return []models.Account{}, err
+	}
 
 	return accountsList, nil
 }
@@ -221,7 +229,12 @@ func (services *SQLServices) GetAccount(id int64) (models.Account, error) {
 		return models.Account{}, err
 	}
 
-	return account, nil
+	accounts := []models.Account{account}
+	if err := services.addPendingCredits(accounts); err != nil {
+		return models.Account{}, err
+	}
+
+	return accounts[0], nil
 }
 
 func (services *SQLServices) GetTransfer(id int64) (models.Transfer, error) {
@@ -258,9 +271,15 @@ func (services *SQLServices) CreateUser(req requests.CreateUserRequest) (models.
 		CreatedAt:      time.Now().UTC(),
 		UpdatedAt:      time.Now().UTC(),
 		DeletedAt:      gorm.DeletedAt{},
+		Role:           models.RoleCustomer,
 	}
 
-	if err := services.DB.Create(&newUser).Error; err != nil {
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newUser).Error; err != nil {
+			return err
+		}
+		return recordPassword(tx, newUser.Username, hashedPassword)
+	}); err != nil {
 		return models.User{}, err
 	}
 
@@ -279,7 +298,12 @@ func (services *SQLServices) GetUser(username string) (models.User, error) {
 	return user, nil
 }
 
+// CreateSession creates a session. A session without a family starts a new family.
 func (services *SQLServices) CreateSession(session models.Session) (models.Session, error) {
+	if session.FamilyID == uuid.Nil {
+		session.FamilyID = session.ID
+	}
+
 	if err := services.DB.Create(&session).Error; err != nil {
 		return models.Session{}, err
 	}
@@ -300,7 +324,8 @@ func (services *SQLServices) GetSession(id uuid.UUID) (models.Session, error) {
 }
 
 // UpdateUser updates the user information in the database based on the provided request.
-// It hashes the password if provided, and updates the fullname and email fields if they are not nil.
+// It hashes the password if provided and adds it to the password history, and updates the fullname and email fields
+// if they are not nil.
 // It returns the updated user model and any error encountered.
 func (services *SQLServices) UpdateUser(req UpdateUserRequest) (models.User, error) {
 	updateData := map[string]interface{}{}
@@ -310,24 +335,43 @@ func (services *SQLServices) UpdateUser(req UpdateUserRequest) (models.User, err
 		if err != nil {
 			return models.User{}, err
 		}
-		updateData["password"] = hashedPassword
+		updateData["hashed_password"] = hashedPassword
+		// a new password satisfies a reset forced by the back office
+		updateData["password_reset_required"] = false
 	}
 	if req.Fullname != nil {
 		updateData["fullname"] = req.Fullname
 	}
 	if req.Email != nil {
 		updateData["email"] = req.Email
+		// the right-hand side reads the email before the update, so only a new email must be verified again
+		updateData["email_verified"] = gorm.Expr("email_verified AND email = ?", *req.Email)
 	}
 
 	var user models.User
-	if err := services.DB.
-		Model(&models.User{}).
-		Where("username = ?", req.Username).
-		Updates(updateData).
-		First(&user).Error; err != nil {
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&models.User{}).
+			Where("username = ?", req.Username).
+			Updates(updateData).
+			First(&user).Error; err != nil {
+			return err
+		}
+		if req.Password != nil {
+			return recordPassword(tx, user.Username, user.HashedPassword)
+		}
+		return nil
+	}); err != nil {
 		return models.User{}, err
 	}
 
+	// a new password unlocks a user locked out by failed logins
+	if req.Password != nil {
+		if err := services.ClearLoginFailures(models.LoginFailureKindUsername, req.Username); err != nil {
+			return models.User{}, err
+		}
+	}
+
 	return user, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"Simple-Bank/db/models"
 	"Simple-Bank/requests"
 	"github.com/google/uuid"
+	"time"
 )
 
 type Services interface {
@@ -21,6 +22,82 @@ type Services interface {
 	GetSession(id uuid.UUID) (models.Session, error)
 	CreateSession(session models.Session) (models.Session, error)
 	UpdateUser(req UpdateUserRequest) (models.User, error)
+	CreateBalanceSnapshots(snapshotAt time.Time) (int64, error)
+	GetBalanceAt(accountID int64, at time.Time) (int64, error)
+	ListGLAccounts() ([]models.GLAccount, error)
+	SyncChartOfAccounts(chart []models.GLAccount) error
+	GetTrialBalance(at time.Time) (TrialBalance, error)
+	CancelTransfer(req UpdateTransferStatusRequest) (models.Transfer, error)
+	ReverseTransfer(req UpdateTransferStatusRequest) (models.Transfer, error)
+	ListTransferStatusHistory(transferID int64) ([]models.TransferStatusHistory, error)
+	SetHotAccounts(accountIDs []int64) error
+	FoldPendingCredits() (int64, error)
+	ListActiveSessions(username string) ([]models.Session, error)
+	RevokeSession(username string, id uuid.UUID) (models.Session, error)
+	RevokeOtherSessions(username string, keepID uuid.UUID) (int64, error)
+	RotateSession(consumedID uuid.UUID, next models.Session) (models.Session, error)
+	RevokeSessionFamily(familyID uuid.UUID) (int64, error)
+	RevokeToken(req RevokeTokenRequest) error
+	IsTokenRevoked(tokenID uuid.UUID) (bool, error)
+	SearchUsers(action AdminAction, req SearchUsersRequest) ([]models.User, error)
+	ListUserAccounts(action AdminAction, username string) ([]models.Account, error)
+	ListUserSessions(action AdminAction, username string) ([]models.Session, error)
+	ListAccountEntries(action AdminAction, req ListEntriesRequest) ([]models.Entry, error)
+	SetUserFrozen(action AdminAction, username string, frozen bool) (models.User, error)
+	ForcePasswordReset(action AdminAction, username string) (models.User, error)
+	UnlockUser(action AdminAction, username string) (models.User, error)
+	SetAccountFrozen(action AdminAction, accountID int64, frozen bool) (models.Account, error)
+	AdjustBalance(action AdminAction, accountID int64, amount int32) (models.Entry, error)
+	AdminRevokeToken(action AdminAction, req RevokeTokenRequest) error
+	ListAuditEvents(action AdminAction, req ListAuditEventsRequest) ([]models.AuditEvent, error)
+	CreateApproval(req CreateApprovalRequest) (models.Approval, error)
+	GetApproval(id int64) (models.Approval, error)
+	ListApprovals(req ListApprovalsRequest) ([]models.Approval, error)
+	DecideApproval(req DecideApprovalRequest) (models.Approval, error)
+	CompleteApproval(id int64, result string, failure string) (models.Approval, error)
+	ExpireApprovals(now time.Time) (int64, error)
+	CreateAPIKey(key models.APIKey) (models.APIKey, error)
+	GetAPIKeyByPrefix(prefix string) (models.APIKey, error)
+	ListAPIKeys(owner string) ([]models.APIKey, error)
+	RevokeAPIKey(owner string, id uuid.UUID) (models.APIKey, error)
+	TouchAPIKey(id uuid.UUID, clientIP string, usedAt time.Time) error
+	CreateOAuthClient(client models.OAuthClient) (models.OAuthClient, error)
+	GetOAuthClient(id string) (models.OAuthClient, error)
+	ListOAuthClients(owner string) ([]models.OAuthClient, error)
+	CreateAuthorizationCode(code models.AuthorizationCode) (models.AuthorizationCode, error)
+	ConsumeAuthorizationCode(hashedCode string) (models.AuthorizationCode, error)
+	SaveTOTPCredential(credential models.TOTPCredential) (models.TOTPCredential, error)
+	GetTOTPCredential(username string) (models.TOTPCredential, error)
+	ConfirmTOTPCredential(username string, step int64, hashedRecoveryCodes []string) (models.TOTPCredential, error)
+	UseTOTPStep(username string, step int64) error
+	UseRecoveryCode(username, hashedCode string) error
+	DeleteTOTPCredential(username string) error
+	CreateMFAChallenge(challenge models.MFAChallenge) (models.MFAChallenge, error)
+	GetMFAChallenge(hashedToken string) (models.MFAChallenge, error)
+	FailMFAChallenge(id int64) (models.MFAChallenge, error)
+	ConsumeMFAChallenge(id int64) error
+	GetLoginFailure(kind, subject string) (models.LoginFailure, error)
+	RecordLoginFailure(kind, subject string, forgetBefore time.Time) (models.LoginFailure, error)
+	ClearLoginFailures(kind, subject string) error
+	CreateEmailVerification(verification models.EmailVerification) (models.EmailVerification, error)
+	GetEmailVerification(hashedToken string) (models.EmailVerification, error)
+	VerifyEmail(verification models.EmailVerification) (models.User, error)
+	GetUserByEmail(email string) (models.User, error)
+	CreatePasswordReset(reset models.PasswordReset) (models.PasswordReset, error)
+	GetPasswordReset(hashedToken string) (models.PasswordReset, error)
+	ResetPassword(reset models.PasswordReset, password string) (models.User, error)
+	RehashPassword(username, oldHash, newHash string) error
+	ListPasswordHistory(username string, limit int) ([]models.PasswordHistory, error)
+	CreateLoginCode(code models.LoginCode) (models.LoginCode, error)
+	GetLoginCodeByToken(hashedToken string) (models.LoginCode, error)
+	GetLatestLoginCode(username string) (models.LoginCode, error)
+	RecordLoginCodeAttempt(id int64) (models.LoginCode, error)
+	UseLoginCode(code models.LoginCode) error
+	ListTrustedDevices(username string) ([]models.TrustedDevice, error)
+	TrustDevice(device models.TrustedDevice) (models.TrustedDevice, error)
+	DeleteTrustedDevice(username string, id int64) error
+	UpdateRateLimitBucket(key string, update func(tokens float64, updatedAt time.Time, isNew bool) float64, now time.Time) error
+	DeleteRateLimitBuckets(updatedBefore time.Time) error
 }
 
 var _ Services = (*SQLServices)(nil)
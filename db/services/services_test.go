@@ -13,7 +13,7 @@ import (
 	"time"
 )
 
-func createAccount(t *testing.T, owner string) models.Account {
+func createAccount(t testing.TB, owner string) models.Account {
 
 	createdTime := time.Now().Truncate(time.Nanosecond).Local()
 
@@ -289,7 +289,7 @@ func TestTransferDeadLock(t *testing.T) {
 
 }
 
-func createRandomUser(t *testing.T) models.User {
+func createRandomUser(t testing.TB) models.User {
 	createUserRequest := requests.CreateUserRequest{
 		Username: util.RandomUsername(),
 		Email:    util.RandomEmail(),
@@ -309,6 +309,7 @@ func createRandomUser(t *testing.T) models.User {
 	require.Equal(t, createUserRequest.FullName, user.FullName)
 	require.WithinDuration(t, createdTime, user.CreatedAt, time.Second)
 	require.WithinDuration(t, createdTime, user.UpdatedAt, time.Second)
+	require.Equal(t, models.RoleCustomer, user.Role)
 
 	return user
 }
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// ErrRefreshTokenReused is returned when the refresh token of a consumed session is used again.
+// Since only one party can legitimately hold the token, the whole family of the session is revoked.
+var ErrRefreshTokenReused = errors.New("refresh token has already been used")
+
+// ListActiveSessions returns the sessions of a user that are neither blocked, expired nor consumed, newest first.
+func (services *SQLServices) ListActiveSessions(username string) ([]models.Session, error) {
+	var sessions []models.Session
+
+	if err := services.DB.
+		Where("username = ? AND NOT is_blocked AND consumed_at IS NULL AND expires_at > ?", username, time.Now().UTC()).
+		Order("created_at DESC").
+		Find(&sessions).Error; err != nil {
+		return []models.Session{}, err
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession blocks a session of a user along with every other session of its family.
+// It returns gorm.ErrRecordNotFound if the user has no session with the given id.
+func (services *SQLServices) RevokeSession(username string, id uuid.UUID) (models.Session, error) {
+	var session models.Session
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND username = ?", id, username).First(&session).Error; err != nil {
+			return err
+		}
+
+		session.IsBlocked = true
+		return tx.Model(&models.Session{}).
+			Where("family_id = ?", session.FamilyID).
+			Update("is_blocked", true).Error
+	}); err != nil {
+		return models.Session{}, err
+	}
+
+	return session, nil
+}
+
+// RevokeOtherSessions blocks every active session of a user except the family of the session with id keepID.
+// It returns the number of sessions blocked.
+func (services *SQLServices) RevokeOtherSessions(username string, keepID uuid.UUID) (int64, error) {
+	keptFamily := services.DB.Model(&models.Session{}).Select("family_id").Where("id = ?", keepID)
+
+	res := services.DB.
+		Model(&models.Session{}).
+		Where("username = ? AND family_id NOT IN (?) AND NOT is_blocked", username, keptFamily).
+		Update("is_blocked", true)
+	if err := res.Error; err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected, nil
+}
+
+// RotateSession consumes the session with id consumedID and creates next as the following session of its family.
+//
+// If the session was already consumed, its refresh token is being reused: the whole family is revoked
+// and ErrRefreshTokenReused is returned along with the reused session.
+func (services *SQLServices) RotateSession(consumedID uuid.UUID, next models.Session) (models.Session, error) {
+	var consumed models.Session
+
+	err := services.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", consumedID).
+			First(&consumed).Error; err != nil {
+			return err
+		}
+		if consumed.ConsumedAt != nil {
+			return ErrRefreshTokenReused
+		}
+
+		if err := tx.Model(&consumed).Update("consumed_at", time.Now().UTC()).Error; err != nil {
+			return err
+		}
+
+		next.FamilyID = consumed.FamilyID
+		return tx.Create(&next).Error
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if _, err := services.RevokeSessionFamily(consumed.FamilyID); err != nil {
+			return models.Session{}, err
+		}
+		return consumed, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	return next, nil
+}
+
+// RevokeSessionFamily blocks every session of a family.
+// It returns the number of sessions blocked.
+func (services *SQLServices) RevokeSessionFamily(familyID uuid.UUID) (int64, error) {
+	res := services.DB.
+		Model(&models.Session{}).
+		Where("family_id = ? AND NOT is_blocked", familyID).
+		Update("is_blocked", true)
+	if err := res.Error; err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected, nil
+}
+
+// RevokeToken denies a token until it expires. Revoking a token twice keeps the first revocation.
+func (services *SQLServices) RevokeToken(req RevokeTokenRequest) error {
+	return revokeToken(services.DB, req)
+}
+
+// revokeToken denies a token within tx.
+func revokeToken(tx *gorm.DB, req RevokeTokenRequest) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RevokedToken{
+		TokenID:   req.TokenID,
+		Username:  req.Username,
+		Reason:    req.Reason,
+		RevokedBy: req.RevokedBy,
+		ExpiresAt: req.ExpiresAt.UTC(),
+		CreatedAt: time.Now().UTC(),
+	}).Error
+}
+
+// IsTokenRevoked reports whether the token with the given id has been revoked.
+func (services *SQLServices) IsTokenRevoked(tokenID uuid.UUID) (bool, error) {
+	var count int64
+	if err := services.DB.Model(&models.RevokedToken{}).Where("token_id = ?", tokenID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
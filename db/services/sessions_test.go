@@ -0,0 +1,159 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func createUserSession(t *testing.T, username string, expiresAt time.Time) models.Session {
+	session, err := services.CreateSession(models.Session{
+		ID:           uuid.New(),
+		Username:     username,
+		RefreshToken: "refresh token",
+		UserAgent:    "user agent",
+		ClientIP:     util.RandomIP(),
+		CreatedAt:    time.Now(),
+		ExpiresAt:    expiresAt,
+	})
+	require.NoError(t, err)
+
+	return session
+}
+
+func TestListActiveSessions(t *testing.T) {
+	user := createRandomUser(t)
+	active1 := createUserSession(t, user.Username, time.Now().Add(time.Hour))
+	active2 := createUserSession(t, user.Username, time.Now().Add(time.Hour))
+	createUserSession(t, user.Username, time.Now().Add(-time.Hour))
+	blocked := createUserSession(t, user.Username, time.Now().Add(time.Hour))
+	_, err := services.RevokeSession(user.Username, blocked.ID)
+	require.NoError(t, err)
+
+	sessions, err := services.ListActiveSessions(user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	ids := []uuid.UUID{sessions[0].ID, sessions[1].ID}
+	require.ElementsMatch(t, []uuid.UUID{active1.ID, active2.ID}, ids)
+}
+
+func TestRevokeSession(t *testing.T) {
+	user := createRandomUser(t)
+	session := createUserSession(t, user.Username, time.Now().Add(time.Hour))
+
+	t.Run("OtherUser", func(t *testing.T) {
+		other := createRandomUser(t)
+		_, err := services.RevokeSession(other.Username, session.ID)
+		require.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+	t.Run("OK", func(t *testing.T) {
+		revoked, err := services.RevokeSession(user.Username, session.ID)
+		require.NoError(t, err)
+		require.True(t, revoked.IsBlocked)
+
+		stored, err := services.GetSession(session.ID)
+		require.NoError(t, err)
+		require.True(t, stored.IsBlocked)
+	})
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	user := createRandomUser(t)
+	current := createUserSession(t, user.Username, time.Now().Add(time.Hour))
+	createUserSession(t, user.Username, time.Now().Add(time.Hour))
+	createUserSession(t, user.Username, time.Now().Add(time.Hour))
+
+	other := createRandomUser(t)
+	otherSession := createUserSession(t, other.Username, time.Now().Add(time.Hour))
+
+	revoked, err := services.RevokeOtherSessions(user.Username, current.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), revoked)
+
+	sessions, err := services.ListActiveSessions(user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, current.ID, sessions[0].ID)
+
+	stored, err := services.GetSession(otherSession.ID)
+	require.NoError(t, err)
+	require.False(t, stored.IsBlocked)
+}
+
+func TestRotateSession(t *testing.T) {
+	user := createRandomUser(t)
+	first := createUserSession(t, user.Username, time.Now().Add(time.Hour))
+	require.Equal(t, first.ID, first.FamilyID)
+
+	next := models.Session{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		RefreshToken: "next refresh token",
+		UserAgent:    "user agent",
+		ClientIP:     util.RandomIP(),
+		CreatedAt:    time.Now(),
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	second, err := services.RotateSession(first.ID, next)
+	require.NoError(t, err)
+	require.Equal(t, next.ID, second.ID)
+	require.Equal(t, first.FamilyID, second.FamilyID)
+
+	consumed, err := services.GetSession(first.ID)
+	require.NoError(t, err)
+	require.NotNil(t, consumed.ConsumedAt)
+
+	// consumed sessions are not active anymore
+	sessions, err := services.ListActiveSessions(user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, second.ID, sessions[0].ID)
+
+	t.Run("Reuse", func(t *testing.T) {
+		next.ID = uuid.New()
+		_, err := services.RotateSession(first.ID, next)
+		require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+		// the whole family is revoked
+		for _, id := range []uuid.UUID{first.ID, second.ID} {
+			session, err := services.GetSession(id)
+			require.NoError(t, err)
+			require.True(t, session.IsBlocked)
+		}
+
+		// and no session is created for the reused token
+		created, err := services.GetSession(next.ID)
+		require.NoError(t, err)
+		require.Equal(t, uuid.Nil, created.ID)
+	})
+}
+
+func TestRevokeToken(t *testing.T) {
+	user := createRandomUser(t)
+	tokenID := uuid.New()
+
+	revoked, err := services.IsTokenRevoked(tokenID)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	req := RevokeTokenRequest{
+		TokenID:   tokenID,
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+		RevokedBy: user.Username,
+		Reason:    "stolen",
+	}
+	require.NoError(t, services.RevokeToken(req))
+	// revoking twice is not an error
+	require.NoError(t, services.RevokeToken(req))
+
+	revoked, err = services.IsTokenRevoked(tokenID)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
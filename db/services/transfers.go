@@ -0,0 +1,250 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	// ErrNotAccountOwner is returned when a user tries to move money out of an account they do not own
+	ErrNotAccountOwner = errors.New("user is not the owner of the source account")
+	// ErrTransferFailed is returned when a transfer has been persisted but could not be completed
+	ErrTransferFailed = errors.New("transfer failed")
+	// ErrInvalidTransferStatus is returned when a transfer cannot move from its current status to the requested one
+	ErrInvalidTransferStatus = errors.New("invalid transfer status transition")
+)
+
+// transferTransitions lists the statuses a transfer can move to from each status.
+var transferTransitions = map[string][]string{
+	models.TransferStatusPending: {
+		models.TransferStatusCompleted,
+		models.TransferStatusFailed,
+		models.TransferStatusCancelled,
+	},
+	models.TransferStatusCompleted: {
+		models.TransferStatusReversed,
+	},
+}
+
+// canTransitionTransfer reports whether a transfer can move from status from to status to.
+func canTransitionTransfer(from, to string) bool {
+	for _, status := range transferTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CancelTransfer cancels a pending transfer.
+func (services *SQLServices) CancelTransfer(req UpdateTransferStatusRequest) (models.Transfer, error) {
+	var transfer models.Transfer
+
+	if err := services.runInTransaction("cancel_transfer", sql.LevelDefault, func(tx *gorm.DB) error {
+		transfer = models.Transfer{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&transfer, req.TransferID).Error; err != nil {
+			return err
+		}
+
+		return transitionTransfer(tx, &transfer, models.TransferStatusCancelled, req.Reason, req.Actor)
+	}); err != nil {
+		return models.Transfer{}, err
+	}
+
+	return transfer, nil
+}
+
+// ReverseTransfer moves the money of a completed transfer back to its source account.
+// Like the execution of a transfer, it runs with serializable isolation and is retried on conflicts.
+func (services *SQLServices) ReverseTransfer(req UpdateTransferStatusRequest) (models.Transfer, error) {
+	var transfer models.Transfer
+
+	if err := services.runInTransaction("reverse_transfer", sql.LevelSerializable, func(tx *gorm.DB) error {
+		transfer = models.Transfer{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&transfer, req.TransferID).Error; err != nil {
+			return err
+		}
+		if !canTransitionTransfer(transfer.Status, models.TransferStatusReversed) {
+			return fmt.Errorf("%w: from %s to %s", ErrInvalidTransferStatus, transfer.Status, models.TransferStatusReversed)
+		}
+
+		// the money moves from the destination account back to the source account
+		if _, _, err := moveMoneyWithEntries(tx, transfer.ToAccountID, transfer.FromAccountID, transfer.Amount); err != nil {
+			return err
+		}
+
+		return transitionTransfer(tx, &transfer, models.TransferStatusReversed, req.Reason, req.Actor)
+	}); err != nil {
+		return models.Transfer{}, err
+	}
+
+	return transfer, nil
+}
+
+// ListTransferStatusHistory returns the status changes of a transfer, oldest first.
+func (services *SQLServices) ListTransferStatusHistory(transferID int64) ([]models.TransferStatusHistory, error) {
+	var history []models.TransferStatusHistory
+
+	if err := services.DB.
+		Where("transfer_id = ?", transferID).
+		Order("created_at, id").
+		Find(&history).Error; err != nil {
+		return []models.TransferStatusHistory{}, err
+	}
+
+	return history, nil
+}
+
+// executeTransfer moves the money of a pending transfer and marks it as completed.
+func executeTransfer(tx *gorm.DB, transfer *models.Transfer, actor string) error {
+	if err := checkAccountsNotFrozen(tx, transfer.FromAccountID, transfer.ToAccountID); err != nil {
+		return err
+	}
+
+	fromEntry, toEntry, err := moveMoneyWithEntries(tx, transfer.FromAccountID, transfer.ToAccountID, transfer.Amount)
+	if err != nil {
+		return err
+	}
+
+	transfer.OutgoingEntryID = fromEntry.ID
+	transfer.IncomingEntryID = toEntry.ID
+	if err := tx.Model(transfer).Updates(map[string]interface{}{
+		"outgoing_entry_id": transfer.OutgoingEntryID,
+		"incoming_entry_id": transfer.IncomingEntryID,
+	}).Error; err != nil {
+		return err
+	}
+
+	return transitionTransfer(tx, transfer, models.TransferStatusCompleted, "transfer completed", actor)
+}
+
+// failTransfer persists a pending transfer that could not be executed as failed. Its status reason is shown to
+// clients, so it describes cause in plain words and the cause itself, e.g. an error of the database, is logged.
+func (services *SQLServices) failTransfer(transfer models.Transfer, cause error, actor string) (models.Transfer, error) {
+	log.Error().Err(cause).Int64("transfer_id", transfer.ID).Msg("transfer failed")
+
+	if err := services.DB.Transaction(func(tx *gorm.DB) error {
+		return transitionTransfer(tx, &transfer, models.TransferStatusFailed, transferFailureReason(cause), actor)
+	}); err != nil {
+		return models.Transfer{}, fmt.Errorf("cannot mark transfer %d as failed: %w (cause: %v)", transfer.ID, err, cause)
+	}
+
+	return transfer, fmt.Errorf("%w: %v", ErrTransferFailed, cause)
+}
+
+// transferFailureReason returns the status reason of a transfer whose execution failed with cause.
+func transferFailureReason(cause error) string {
+	switch {
+	case errors.Is(cause, ErrAccountFrozen):
+		return "account is frozen"
+	case errors.Is(cause, gorm.ErrRecordNotFound):
+		return "account not found"
+	default:
+		return "transfer could not be completed"
+	}
+}
+
+// transitionTransfer moves a transfer to a new status and records the change in its history.
+// The update only applies if the transfer still has the status it was read with.
+func transitionTransfer(tx *gorm.DB, transfer *models.Transfer, to string, reason string, actor string) error {
+	from := transfer.Status
+	if !canTransitionTransfer(from, to) {
+		return fmt.Errorf("%w: from %s to %s", ErrInvalidTransferStatus, from, to)
+	}
+
+	updatedAt := time.Now().UTC()
+	res := tx.Model(&models.Transfer{}).
+		Where("id = ? AND status = ?", transfer.ID, from).
+		Updates(map[string]interface{}{
+			"status":        to,
+			"status_reason": reason,
+			"updated_at":    updatedAt,
+		})
+	if err := res.Error; err != nil {
+		return err
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: transfer %d is no longer %s", ErrInvalidTransferStatus, transfer.ID, from)
+	}
+
+	transfer.Status = to
+	transfer.StatusReason = reason
+	transfer.UpdatedAt = updatedAt
+
+	return recordTransferStatus(tx, *transfer, from, actor)
+}
+
+// recordTransferStatus records the change of a transfer from status from to its current status.
+func recordTransferStatus(tx *gorm.DB, transfer models.Transfer, from string, actor string) error {
+	return tx.Create(&models.TransferStatusHistory{
+		TransferID: transfer.ID,
+		FromStatus: from,
+		ToStatus:   transfer.Status,
+		Reason:     transfer.StatusReason,
+		Actor:      actor,
+		CreatedAt:  transfer.UpdatedAt,
+	}).Error
+}
+
+// moveMoneyWithEntries moves amount from one account to another and records an entry on each of them.
+// Credits to hot accounts are left pending, see moveMoneyToHotAccount.
+func moveMoneyWithEntries(tx *gorm.DB, fromAccountID, toAccountID int64, amount int32) (fromEntry models.Entry, toEntry models.Entry, err error) {
+	var fromAccount, toAccount models.Account
+
+	if err = tx.Select("id", "is_hot").First(&toAccount, toAccountID).Error; err != nil {
+		return fromEntry, toEntry, err
+	}
+	if toAccount.IsHot {
+		return moveMoneyToHotAccount(tx, fromAccountID, toAccountID, amount)
+	}
+
+	// always acquire the lock of the account with the lower account id
+	if fromAccountID < toAccountID {
+		fromAccount, toAccount, err = acquireLock(tx, fromAccountID, toAccountID)
+	} else {
+		toAccount, fromAccount, err = acquireLock(tx, toAccountID, fromAccountID)
+	}
+	if err != nil {
+		return fromEntry, toEntry, err
+	}
+	if fromAccount.IsHot {
+		if _, err = foldPendingCredits(tx, &fromAccount); err != nil {
+			return fromEntry, toEntry, err
+		}
+	}
+
+	fromAccount.Balance -= int64(amount)
+	toAccount.Balance += int64(amount)
+
+	if err = tx.Save(&fromAccount).Error; err != nil {
+		return fromEntry, toEntry, err
+	}
+	if err = tx.Save(&toAccount).Error; err != nil {
+		return fromEntry, toEntry, err
+	}
+
+	fromEntry = models.Entry{
+		AccountID: fromAccountID,
+		Amount:    -amount,
+	}
+	toEntry = models.Entry{
+		AccountID: toAccountID,
+		Amount:    amount,
+	}
+	if err = tx.Create(&fromEntry).Error; err != nil {
+		return fromEntry, toEntry, err
+	}
+	if err = tx.Create(&toEntry).Error; err != nil {
+		return fromEntry, toEntry, err
+	}
+
+	return fromEntry, toEntry, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func createTransfer(t *testing.T, from, to models.Account, amount int32) models.Transfer {
+	transfer, err := services.Transfer(TransferRequest{
+		Owner:         from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.TransferStatusCompleted, transfer.Status)
+
+	return transfer
+}
+
+func TestTransferStatusHistory(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+	account1 := createAccount(t, user1.Username)
+	account2 := createAccount(t, user2.Username)
+
+	transfer := createTransfer(t, account1, account2, 10)
+
+	history, err := services.ListTransferStatusHistory(transfer.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	require.Empty(t, history[0].FromStatus)
+	require.Equal(t, models.TransferStatusPending, history[0].ToStatus)
+	require.Equal(t, user1.Username, history[0].Actor)
+
+	require.Equal(t, models.TransferStatusPending, history[1].FromStatus)
+	require.Equal(t, models.TransferStatusCompleted, history[1].ToStatus)
+	require.Equal(t, user1.Username, history[1].Actor)
+}
+
+func TestTransferNotAccountOwner(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+	account1 := createAccount(t, user1.Username)
+	account2 := createAccount(t, user2.Username)
+
+	transfer, err := services.Transfer(TransferRequest{
+		Owner:         user2.Username,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrNotAccountOwner)
+	require.Empty(t, transfer)
+}
+
+func TestTransferFailed(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+	account1 := createAccount(t, user1.Username)
+	account2 := createAccount(t, user2.Username)
+
+	// the destination account still exists for the foreign key but cannot receive money
+	_, err := services.DeleteAccount(account2.ID)
+	require.NoError(t, err)
+
+	transfer, err := services.Transfer(TransferRequest{
+		Owner:         user1.Username,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrTransferFailed)
+	require.NotZero(t, transfer.ID)
+	require.Equal(t, models.TransferStatusFailed, transfer.Status)
+	require.Equal(t, "account not found", transfer.StatusReason)
+
+	stored, err := services.GetTransfer(transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.TransferStatusFailed, stored.Status)
+	require.Zero(t, stored.OutgoingEntryID)
+	require.Zero(t, stored.IncomingEntryID)
+
+	fromAccount, err := services.GetAccount(account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, fromAccount.Balance)
+
+	history, err := services.ListTransferStatusHistory(transfer.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, models.TransferStatusFailed, history[1].ToStatus)
+}
+
+func TestReverseTransfer(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+	account1 := createAccount(t, user1.Username)
+	account2 := createAccount(t, user2.Username)
+
+	transfer := createTransfer(t, account1, account2, 10)
+
+	reversed, err := services.ReverseTransfer(UpdateTransferStatusRequest{
+		TransferID: transfer.ID,
+		Actor:      user2.Username,
+		Reason:     "sent by mistake",
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.TransferStatusReversed, reversed.Status)
+	require.Equal(t, "sent by mistake", reversed.StatusReason)
+
+	fromAccount, err := services.GetAccount(account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, fromAccount.Balance)
+
+	toAccount, err := services.GetAccount(account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, toAccount.Balance)
+
+	history, err := services.ListTransferStatusHistory(transfer.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	require.Equal(t, models.TransferStatusReversed, history[2].ToStatus)
+	require.Equal(t, user2.Username, history[2].Actor)
+
+	// a reversed transfer is final
+	_, err = services.ReverseTransfer(UpdateTransferStatusRequest{TransferID: transfer.ID})
+	require.ErrorIs(t, err, ErrInvalidTransferStatus)
+}
+
+func TestCancelTransfer(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+	account1 := createAccount(t, user1.Username)
+	account2 := createAccount(t, user2.Username)
+
+	// completed transfers can only be reversed
+	transfer := createTransfer(t, account1, account2, 10)
+	_, err := services.CancelTransfer(UpdateTransferStatusRequest{
+		TransferID: transfer.ID,
+		Actor:      user1.Username,
+	})
+	require.True(t, errors.Is(err, ErrInvalidTransferStatus))
+
+	stored, err := services.GetTransfer(transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.TransferStatusCompleted, stored.Status)
+}
+
+func TestCanTransitionTransfer(t *testing.T) {
+	require.True(t, canTransitionTransfer(models.TransferStatusPending, models.TransferStatusCompleted))
+	require.True(t, canTransitionTransfer(models.TransferStatusPending, models.TransferStatusFailed))
+	require.True(t, canTransitionTransfer(models.TransferStatusPending, models.TransferStatusCancelled))
+	require.True(t, canTransitionTransfer(models.TransferStatusCompleted, models.TransferStatusReversed))
+
+	require.False(t, canTransitionTransfer(models.TransferStatusCompleted, models.TransferStatusCancelled))
+	require.False(t, canTransitionTransfer(models.TransferStatusFailed, models.TransferStatusCompleted))
+	require.False(t, canTransitionTransfer(models.TransferStatusReversed, models.TransferStatusCompleted))
+	require.False(t, canTransitionTransfer(models.TransferStatusCancelled, models.TransferStatusPending))
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListTrustedDevices returns the trusted devices of a user, the last seen first.
+func (services *SQLServices) ListTrustedDevices(username string) ([]models.TrustedDevice, error) {
+	var devices []models.TrustedDevice
+
+	if err := services.DB.
+		Where("username = ?", username).
+		Order("last_seen_at DESC, id DESC").
+		Find(&devices).Error; err != nil {
+		return []models.TrustedDevice{}, err
+	}
+
+	return devices, nil
+}
+
+// TrustDevice stores a device a user logged in from, or updates its user agent, network and last seen time if
+// the user already has a device with its fingerprint.
+func (services *SQLServices) TrustDevice(device models.TrustedDevice) (models.TrustedDevice, error) {
+	if err := services.DB.
+		Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "username"}, {Name: "fingerprint"}},
+				DoUpdates: clause.AssignmentColumns([]string{"user_agent", "ip_range", "last_ip", "last_seen_at"}),
+			},
+			clause.Returning{},
+		).
+		Create(&device).Error; err != nil {
+		return models.TrustedDevice{}, err
+	}
+
+	return device, nil
+}
+
+// DeleteTrustedDevice removes a trusted device of a user, the next login from it alerts the user again.
+// It returns gorm.ErrRecordNotFound if the user has no trusted device with the given id.
+func (services *SQLServices) DeleteTrustedDevice(username string, id int64) error {
+	result := services.DB.Where("id = ? AND username = ?", id, username).Delete(&models.TrustedDevice{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func trustRandomDevice(t *testing.T, username string) models.TrustedDevice {
+	device, err := services.TrustDevice(models.TrustedDevice{
+		Username:    username,
+		Fingerprint: util.RandomString(64, util.LOWERCASE),
+		UserAgent:   util.RandomString(32, util.ALL),
+		IPRange:     "10.0.0.0/24",
+		LastIP:      "10.0.0.1",
+		LastSeenAt:  time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	require.NotZero(t, device.ID)
+
+	return device
+}
+
+func TestTrustDevice(t *testing.T) {
+	user := createRandomUser(t)
+	device := trustRandomDevice(t, user.Username)
+
+	// trusting the device again updates it
+	seen := device
+	seen.ID = 0
+	seen.IPRange = "10.0.1.0/24"
+	seen.LastIP = "10.0.1.7"
+	seen.LastSeenAt = time.Now().Add(time.Minute).UTC()
+	updated, err := services.TrustDevice(seen)
+	require.NoError(t, err)
+	require.Equal(t, device.ID, updated.ID)
+	require.Equal(t, "10.0.1.0/24", updated.IPRange)
+	require.Equal(t, "10.0.1.7", updated.LastIP)
+	require.WithinDuration(t, device.CreatedAt, updated.CreatedAt, time.Second)
+
+	devices, err := services.ListTrustedDevices(user.Username)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	require.Equal(t, updated.ID, devices[0].ID)
+	require.WithinDuration(t, seen.LastSeenAt, devices[0].LastSeenAt, time.Second)
+}
+
+func TestListTrustedDevices(t *testing.T) {
+	user := createRandomUser(t)
+	first := trustRandomDevice(t, user.Username)
+	last := trustRandomDevice(t, user.Username)
+	trustRandomDevice(t, createRandomUser(t).Username)
+
+	devices, err := services.ListTrustedDevices(user.Username)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	require.Equal(t, last.ID, devices[0].ID)
+	require.Equal(t, first.ID, devices[1].ID)
+
+	devices, err = services.ListTrustedDevices(createRandomUser(t).Username)
+	require.NoError(t, err)
+	require.Empty(t, devices)
+}
+
+func TestDeleteTrustedDevice(t *testing.T) {
+	user := createRandomUser(t)
+	device := trustRandomDevice(t, user.Username)
+
+	// users cannot remove the devices of other users
+	err := services.DeleteTrustedDevice(createRandomUser(t).Username, device.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, services.DeleteTrustedDevice(user.Username, device.ID))
+	err = services.DeleteTrustedDevice(user.Username, device.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	devices, err := services.ListTrustedDevices(user.Username)
+	require.NoError(t, err)
+	require.Empty(t, devices)
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"expvar"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// serializationFailureCode is the postgres error code of a serialization failure
+	serializationFailureCode = "40001"
+	// deadlockDetectedCode is the postgres error code of a detected deadlock
+	deadlockDetectedCode = "40P01"
+)
+
+var (
+	// txRetries counts the retries of each named transaction after a deadlock or serialization failure
+	txRetries = expvar.NewMap("db_transaction_retries")
+	// txRetriesExhausted counts the named transactions that still failed after their last attempt
+	txRetriesExhausted = expvar.NewMap("db_transaction_retries_exhausted")
+)
+
+// TxRetryPolicy bounds the retries of a transaction that failed because of a deadlock or a serialization failure.
+type TxRetryPolicy struct {
+	// MaxAttempts is the maximum number of times a transaction runs, including the first one
+	MaxAttempts int
+	// BaseBackoff is the upper bound of the wait before the first retry, doubled on every retry
+	BaseBackoff time.Duration
+	// MaxBackoff caps the upper bound of the wait before a retry
+	MaxBackoff time.Duration
+}
+
+// DefaultTxRetryPolicy is the retry policy used by NewSQLServices.
+var DefaultTxRetryPolicy = TxRetryPolicy{
+	MaxAttempts: 10,
+	BaseBackoff: 5 * time.Millisecond,
+	MaxBackoff:  500 * time.Millisecond,
+}
+
+// backoff returns how long to wait before the given retry (starting at 1), using full jitter:
+// a random duration between zero and the exponentially growing upper bound.
+func (policy TxRetryPolicy) backoff(retry int) time.Duration {
+	upperBound := policy.BaseBackoff << (retry - 1)
+	if upperBound <= 0 || upperBound > policy.MaxBackoff {
+		upperBound = policy.MaxBackoff
+	}
+	if upperBound <= 0 {
+		return 0
+	}
+
+	return rand.N(upperBound + 1)
+}
+
+// isRetryableError reports whether err is a postgres deadlock or serialization failure,
+// after which the whole transaction can safely run again.
+func isRetryableError(err error) bool {
+	var pgError *pgconn.PgError
+	if !errors.As(err, &pgError) {
+		return false
+	}
+
+	return pgError.Code == serializationFailureCode || pgError.Code == deadlockDetectedCode
+}
+
+// runInTransaction runs fn in a transaction with the given isolation level and retries the whole transaction,
+// according to the retry policy of the services, when it fails with a deadlock or a serialization failure.
+// fn may run several times, so it must reset any state it shares with the caller.
+// name identifies the transaction in logs and metrics.
+func (services *SQLServices) runInTransaction(name string, isolation sql.IsolationLevel, fn func(tx *gorm.DB) error) error {
+	policy := services.TxRetryPolicy
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		err = services.DB.Transaction(fn, &sql.TxOptions{Isolation: isolation})
+		if err == nil {
+			if attempt > 1 {
+				log.Info().Str("transaction", name).Int("retries", attempt-1).Msg("transaction succeeded after retries")
+			}
+			return nil
+		}
+		if !isRetryableError(err) {
+			return err
+		}
+		if attempt == policy.MaxAttempts {
+			break
+		}
+
+		backoff := policy.backoff(attempt)
+		txRetries.Add(name, 1)
+		log.Warn().Err(err).
+			Str("transaction", name).
+			Int("attempt", attempt).
+			Dur("backoff", backoff).
+			Msg("retrying transaction")
+		time.Sleep(backoff)
+	}
+
+	txRetriesExhausted.Add(name, 1)
+	log.Error().Err(err).
+		Str("transaction", name).
+		Int("attempts", policy.MaxAttempts).
+		Msg("transaction failed after retries")
+
+	return fmt.Errorf("transaction %s failed after %d attempts: %w", name, policy.MaxAttempts, err)
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	require.True(t, isRetryableError(&pgconn.PgError{Code: serializationFailureCode}))
+	require.True(t, isRetryableError(&pgconn.PgError{Code: deadlockDetectedCode}))
+	require.True(t, isRetryableError(fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: deadlockDetectedCode})))
+
+	require.False(t, isRetryableError(&pgconn.PgError{Code: "23503"}))
+	require.False(t, isRetryableError(gorm.ErrRecordNotFound))
+}
+
+func TestTxRetryPolicyBackoff(t *testing.T) {
+	policy := TxRetryPolicy{MaxAttempts: 5, BaseBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+
+	for retry := 1; retry <= 10; retry++ {
+		upperBound := policy.BaseBackoff << (retry - 1)
+		if upperBound > policy.MaxBackoff {
+			upperBound = policy.MaxBackoff
+		}
+
+		backoff := policy.backoff(retry)
+		require.GreaterOrEqual(t, backoff, time.Duration(0))
+		require.LessOrEqual(t, backoff, upperBound)
+	}
+
+	require.Zero(t, TxRetryPolicy{}.backoff(1))
+}
+
+func TestRunInTransaction(t *testing.T) {
+	sqlServices := services.(*SQLServices)
+	runner := &SQLServices{
+		DB:            sqlServices.DB,
+		TxRetryPolicy: TxRetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	}
+
+	t.Run("RetriesConflicts", func(t *testing.T) {
+		retriesBefore := txRetries.Get("test_retries_conflicts")
+
+		attempts := 0
+		err := runner.runInTransaction("test_retries_conflicts", sql.LevelSerializable, func(tx *gorm.DB) error {
+			attempts++
+			if attempts < 3 {
+				return &pgconn.PgError{Code: serializationFailureCode}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, attempts)
+		require.Nil(t, retriesBefore)
+		require.Equal(t, "2", txRetries.Get("test_retries_conflicts").String())
+	})
+	t.Run("GivesUp", func(t *testing.T) {
+		attempts := 0
+		err := runner.runInTransaction("test_gives_up", sql.LevelDefault, func(tx *gorm.DB) error {
+			attempts++
+			return &pgconn.PgError{Code: deadlockDetectedCode}
+		})
+		require.Error(t, err)
+		require.True(t, isRetryableError(err))
+		require.Equal(t, 3, attempts)
+		require.Equal(t, "1", txRetriesExhausted.Get("test_gives_up").String())
+	})
+	t.Run("DoesNotRetryOtherErrors", func(t *testing.T) {
+		attempts := 0
+		err := runner.runInTransaction("test_other_errors", sql.LevelDefault, func(tx *gorm.DB) error {
+			attempts++
+			return gorm.ErrRecordNotFound
+		})
+		require.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+		require.Equal(t, 1, attempts)
+	})
+}
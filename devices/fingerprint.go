@@ -0,0 +1,52 @@
+package devices
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"regexp"
+	"strings"
+)
+
+const (
+	// ipv4RangeBits and ipv6RangeBits are the prefixes of the networks logins are compared by: addresses of a
+	// household or an office usually share them, while they change when logging in from somewhere else
+	ipv4RangeBits = 24
+	ipv6RangeBits = 64
+)
+
+// versionPattern matches the version numbers of user agents, which change with every update of a browser
+var versionPattern = regexp.MustCompile(`\d+([._]\d+)*`)
+
+// Fingerprint identifies the device with userAgent. It is derived from the browser or the app, and the platform,
+// the user agent tells, without their versions, so updates do not turn devices into new ones.
+func Fingerprint(userAgent string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(versionPattern.ReplaceAllString(userAgent, ""))), " ")
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// IPRange returns the network of clientIP in CIDR notation, or clientIP itself if it is not an address.
+// clientIP may carry a port, like the peer addresses of grpc requests.
+func IPRange(clientIP string) string {
+	ip := net.ParseIP(normalizeIP(clientIP))
+	if ip == nil {
+		return clientIP
+	}
+
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return (&net.IPNet{IP: ipv4.Mask(net.CIDRMask(ipv4RangeBits, 32)), Mask: net.CIDRMask(ipv4RangeBits, 32)}).String()
+	}
+
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(ipv6RangeBits, 128)), Mask: net.CIDRMask(ipv6RangeBits, 128)}).String()
+}
+
+// normalizeIP removes the port of addresses.
+func normalizeIP(clientIP string) string {
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		return host
+	}
+
+	return clientIP
+}
@@ -0,0 +1,44 @@
+package devices
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const (
+	chromeWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.109 Safari/537.36"
+	chromeUpdated = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.6167.85 Safari/537.36"
+	firefoxLinux  = "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"
+)
+
+func TestFingerprint(t *testing.T) {
+	require.Len(t, Fingerprint(chromeWindows), 64)
+	require.Equal(t, Fingerprint(chromeWindows), Fingerprint(chromeWindows))
+
+	// updates of the browser keep the device
+	require.Equal(t, Fingerprint(chromeWindows), Fingerprint(chromeUpdated))
+
+	require.NotEqual(t, Fingerprint(chromeWindows), Fingerprint(firefoxLinux))
+	require.NotEqual(t, Fingerprint(chromeWindows), Fingerprint(""))
+}
+
+func TestIPRange(t *testing.T) {
+	testCases := []struct {
+		clientIP string
+		ipRange  string
+	}{
+		{clientIP: "192.168.1.17", ipRange: "192.168.1.0/24"},
+		{clientIP: "192.168.1.17:52000", ipRange: "192.168.1.0/24"},
+		{clientIP: "::ffff:192.168.1.17", ipRange: "192.168.1.0/24"},
+		{clientIP: "2001:db8:85a3:8d3:1319:8a2e:370:7348", ipRange: "2001:db8:85a3:8d3::/64"},
+		{clientIP: "[2001:db8:85a3:8d3:1319:8a2e:370:7348]:443", ipRange: "2001:db8:85a3:8d3::/64"},
+		{clientIP: "", ipRange: ""},
+		{clientIP: "not an address", ipRange: "not an address"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.clientIP, func(t *testing.T) {
+			require.Equal(t, testCase.ipRange, IPRange(testCase.clientIP))
+		})
+	}
+}
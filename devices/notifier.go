@@ -0,0 +1,95 @@
+package devices
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/mail"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+// notifier types
+const (
+	// NotifierEmail emails new-device alerts to users
+	NotifierEmail = "email"
+	// NotifierLog logs new-device alerts instead of sending them, for development
+	NotifierLog = "log"
+)
+
+// Alert tells a user that someone logged in to their account from a new device or network.
+type Alert struct {
+	User      models.User
+	UserAgent string
+	ClientIP  string
+	// NewDevice is false when a trusted device logged in from a new network
+	NewDevice bool
+	At        time.Time
+}
+
+// Notifier sends new-device alerts to users, so other channels than emails can be added.
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// NewNotifier creates the Notifier of notifierType, NotifierEmail if empty, sending emails with mailer.
+func NewNotifier(notifierType string, mailer mail.Mailer) (Notifier, error) {
+	switch notifierType {
+	case "", NotifierEmail:
+		return NewEmailNotifier(mailer), nil
+	case NotifierLog:
+		return NewLogNotifier(), nil
+	default:
+		return nil, fmt.Errorf("unknown new-device notifier %q", notifierType)
+	}
+}
+
+// EmailNotifier emails new-device alerts to the email of users.
+type EmailNotifier struct {
+	mailer mail.Mailer
+}
+
+// NewEmailNotifier creates an EmailNotifier sending emails with mailer.
+func NewEmailNotifier(mailer mail.Mailer) *EmailNotifier {
+	return &EmailNotifier{mailer: mailer}
+}
+
+func (notifier *EmailNotifier) Notify(alert Alert) error {
+	var body strings.Builder
+
+	fmt.Fprintf(&body, "Hello %s,\n\n", alert.User.FullName)
+	if alert.NewDevice {
+		body.WriteString("Your account was just logged in to from a new device:\n\n")
+	} else {
+		body.WriteString("Your account was just logged in to from a new location:\n\n")
+	}
+	fmt.Fprintf(&body, "Device: %s\nAddress: %s\nTime: %s\n\n", alert.UserAgent, alert.ClientIP, alert.At.Format(time.RFC1123))
+	body.WriteString("If it was you, you can ignore this email. Otherwise, change your password and log out of your " +
+		"sessions right away.\n")
+
+	return notifier.mailer.Send(mail.Message{
+		To:      alert.User.Email,
+		Subject: "New login to your account",
+		Body:    body.String(),
+	})
+}
+
+// LogNotifier logs new-device alerts instead of sending them, for development.
+type LogNotifier struct{}
+
+// NewLogNotifier creates a LogNotifier.
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (notifier *LogNotifier) Notify(alert Alert) error {
+	log.Info().
+		Str("username", alert.User.Username).
+		Str("user_agent", alert.UserAgent).
+		Str("client_ip", alert.ClientIP).
+		Bool("new_device", alert.NewDevice).
+		Time("at", alert.At).
+		Msg("new-device login")
+
+	return nil
+}
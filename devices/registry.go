@@ -0,0 +1,76 @@
+package devices
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// Registry remembers the devices users log in from, and alerts users when they log in from a new device or
+// network.
+type Registry struct {
+	services services.Services
+	notifier Notifier
+}
+
+// NewRegistry creates a Registry alerting users with notifier.
+func NewRegistry(services services.Services, notifier Notifier) *Registry {
+	return &Registry{
+		services: services,
+		notifier: notifier,
+	}
+}
+
+// Check reports whether user logs in from one of their trusted devices, on the network that device logged in from,
+// and alerts the user otherwise. A device on the network of another trusted device is not trusted. Users without
+// trusted devices yet, e.g. who just signed up, are not alerted. Failing to alert the user does not fail the check,
+// it is only logged.
+func (registry *Registry) Check(user models.User, userAgent, clientIP string) (bool, error) {
+	devices, err := registry.services.ListTrustedDevices(user.Username)
+	if err != nil {
+		return false, err
+	}
+
+	fingerprint, ipRange := Fingerprint(userAgent), IPRange(clientIP)
+	knownDevice := false
+	for _, device := range devices {
+		if device.Fingerprint != fingerprint {
+			continue
+		}
+		if device.IPRange == ipRange {
+			return true, nil
+		}
+		knownDevice = true
+	}
+
+	if len(devices) > 0 {
+		alert := Alert{
+			User:      user,
+			UserAgent: userAgent,
+			ClientIP:  normalizeIP(clientIP),
+			NewDevice: !knownDevice,
+			At:        time.Now(),
+		}
+		if err := registry.notifier.Notify(alert); err != nil {
+			log.Error().Err(err).Str("username", user.Username).Msg("failed to send new-device alert")
+		}
+	}
+
+	return false, nil
+}
+
+// Trust remembers the device of a login once the user proved who they are, so their next logins from it, on
+// the same network, are recognized.
+func (registry *Registry) Trust(username, userAgent, clientIP string) error {
+	_, err := registry.services.TrustDevice(models.TrustedDevice{
+		Username:    username,
+		Fingerprint: Fingerprint(userAgent),
+		UserAgent:   userAgent,
+		IPRange:     IPRange(clientIP),
+		LastIP:      normalizeIP(clientIP),
+		LastSeenAt:  time.Now().UTC(),
+	})
+
+	return err
+}
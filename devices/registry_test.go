@@ -0,0 +1,206 @@
+package devices
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/mail"
+	"Simple-Bank/util"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+// recordingNotifier records the alerts it sends.
+type recordingNotifier struct {
+	alerts []Alert
+	err    error
+}
+
+func (notifier *recordingNotifier) Notify(alert Alert) error {
+	notifier.alerts = append(notifier.alerts, alert)
+	return notifier.err
+}
+
+func randomUser() models.User {
+	return models.User{
+		Username: util.RandomUsername(),
+		FullName: util.RandomFullname(),
+		Email:    util.RandomEmail(),
+	}
+}
+
+func TestCheck(t *testing.T) {
+	user := randomUser()
+	trusted := models.TrustedDevice{
+		ID:          1,
+		Username:    user.Username,
+		Fingerprint: Fingerprint(chromeWindows),
+		UserAgent:   chromeWindows,
+		IPRange:     IPRange("192.168.1.17"),
+		LastIP:      "192.168.1.17",
+	}
+
+	testCases := []struct {
+		name      string
+		devices   []models.TrustedDevice
+		userAgent string
+		clientIP  string
+		known     bool
+		// alert is the alert sent, nil if none
+		alert *Alert
+	}{
+		{
+			name:      "Known",
+			devices:   []models.TrustedDevice{trusted},
+			userAgent: chromeUpdated,
+			clientIP:  "192.168.1.42:52000",
+			known:     true,
+		},
+		{
+			name:      "NewDevice",
+			devices:   []models.TrustedDevice{trusted},
+			userAgent: firefoxLinux,
+			clientIP:  "192.168.1.42",
+			alert:     &Alert{User: user, UserAgent: firefoxLinux, ClientIP: "192.168.1.42", NewDevice: true},
+		},
+		{
+			name:      "NewNetwork",
+			devices:   []models.TrustedDevice{trusted},
+			userAgent: chromeWindows,
+			clientIP:  "203.0.113.9:52000",
+			alert:     &Alert{User: user, UserAgent: chromeWindows, ClientIP: "203.0.113.9"},
+		},
+		{
+			name: "NetworkOfAnotherDevice",
+			devices: []models.TrustedDevice{trusted, {
+				ID:          2,
+				Username:    user.Username,
+				Fingerprint: Fingerprint(firefoxLinux),
+				IPRange:     IPRange("203.0.113.9"),
+			}},
+			userAgent: chromeWindows,
+			clientIP:  "203.0.113.10",
+			alert:     &Alert{User: user, UserAgent: chromeWindows, ClientIP: "203.0.113.10"},
+		},
+		{
+			name: "DeviceOnNetworkOfAnotherDevice",
+			devices: []models.TrustedDevice{trusted, {
+				ID:          2,
+				Username:    user.Username,
+				Fingerprint: Fingerprint(firefoxLinux),
+				IPRange:     IPRange("203.0.113.9"),
+			}},
+			userAgent: firefoxLinux,
+			clientIP:  "192.168.1.42",
+			alert:     &Alert{User: user, UserAgent: firefoxLinux, ClientIP: "192.168.1.42"},
+		},
+		{
+			name:      "FirstDevice",
+			userAgent: chromeWindows,
+			clientIP:  "192.168.1.17",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockServices := mockdb.NewMockServices(ctrl)
+			mockServices.EXPECT().ListTrustedDevices(user.Username).Times(1).Return(testCase.devices, nil)
+			notifier := &recordingNotifier{}
+
+			known, err := NewRegistry(mockServices, notifier).Check(user, testCase.userAgent, testCase.clientIP)
+			require.NoError(t, err)
+			require.Equal(t, testCase.known, known)
+
+			if testCase.alert == nil {
+				require.Empty(t, notifier.alerts)
+				return
+			}
+			require.Len(t, notifier.alerts, 1)
+			alert := notifier.alerts[0]
+			require.WithinDuration(t, time.Now(), alert.At, time.Second)
+			alert.At = time.Time{}
+			require.Equal(t, *testCase.alert, alert)
+		})
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	user := randomUser()
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+
+	mockServices.EXPECT().ListTrustedDevices(user.Username).Times(1).Return(nil, errors.New("connection lost"))
+	_, err := NewRegistry(mockServices, &recordingNotifier{}).Check(user, chromeWindows, "192.168.1.17")
+	require.Error(t, err)
+
+	// failed alerts do not fail logins
+	mockServices.EXPECT().
+		ListTrustedDevices(user.Username).
+		Times(1).
+		Return([]models.TrustedDevice{{Fingerprint: Fingerprint(firefoxLinux)}}, nil)
+	known, err := NewRegistry(mockServices, &recordingNotifier{err: errors.New("mailer down")}).Check(user, chromeWindows, "192.168.1.17")
+	require.NoError(t, err)
+	require.False(t, known)
+}
+
+func TestTrust(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	username := util.RandomUsername()
+
+	mockServices.EXPECT().
+		TrustDevice(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(device models.TrustedDevice) (models.TrustedDevice, error) {
+			require.Equal(t, username, device.Username)
+			require.Equal(t, Fingerprint(chromeWindows), device.Fingerprint)
+			require.Equal(t, chromeWindows, device.UserAgent)
+			require.Equal(t, "192.168.1.0/24", device.IPRange)
+			require.Equal(t, "192.168.1.17", device.LastIP)
+			require.WithinDuration(t, time.Now(), device.LastSeenAt, time.Second)
+			return device, nil
+		})
+
+	require.NoError(t, NewRegistry(mockServices, &recordingNotifier{}).Trust(username, chromeWindows, "192.168.1.17:52000"))
+}
+
+func TestEmailNotifier(t *testing.T) {
+	mailer := &recordingMailer{}
+	user := randomUser()
+
+	require.NoError(t, NewEmailNotifier(mailer).Notify(Alert{
+		User:      user,
+		UserAgent: firefoxLinux,
+		ClientIP:  "203.0.113.9",
+		NewDevice: true,
+		At:        time.Now(),
+	}))
+
+	require.Len(t, mailer.messages, 1)
+	require.Equal(t, user.Email, mailer.messages[0].To)
+	require.Contains(t, mailer.messages[0].Body, "new device")
+	require.Contains(t, mailer.messages[0].Body, firefoxLinux)
+	require.Contains(t, mailer.messages[0].Body, "203.0.113.9")
+
+	_, err := NewNotifier("carrier pigeon", mailer)
+	require.Error(t, err)
+	notifier, err := NewNotifier("", mailer)
+	require.NoError(t, err)
+	require.IsType(t, &EmailNotifier{}, notifier)
+	notifier, err = NewNotifier(NotifierLog, mailer)
+	require.NoError(t, err)
+	require.NoError(t, notifier.Notify(Alert{User: user}))
+}
+
+// recordingMailer records the emails it sends.
+type recordingMailer struct {
+	messages []mail.Message
+}
+
+func (mailer *recordingMailer) Send(message mail.Message) error {
+	mailer.messages = append(mailer.messages, message)
+	return nil
+}
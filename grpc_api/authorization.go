@@ -2,9 +2,7 @@ package grpc_api
 
 import (
 	"Simple-Bank/token"
-	"context"
 	"fmt"
-	"google.golang.org/grpc/metadata"
 	"strings"
 )
 
@@ -13,38 +11,43 @@ const (
 	authorizationHeader = "authorization"
 	// authorizationTypeBearer is the type of authorization bearer token.
 	authorizationTypeBearer = "bearer"
+	// authorizationTypeAPIKey is the type of authorization with an api key.
+	authorizationTypeAPIKey = "apikey"
 )
 
-// authorizeUser authorizes the user based on the access token provided in the context.
-// It extracts the access token from the authorization header and verifies it using the token maker.
-// It returns the token payload if the access token is valid, otherwise it returns an error.
-func (server *GrpcServer) authorizeUser(ctx context.Context) (*token.Payload, error) {
-	mtdt, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, fmt.Errorf("missing metadata")
-	}
-
-	values := mtdt.Get(authorizationHeader)
-	if len(values) == 0 {
+// authenticate authenticates the user of an authorization header with either an access token or an api key.
+// It verifies the access token using the token maker and checks that it was not revoked, or checks the api key
+// from clientIP, and returns the payload of the credential if it is valid, otherwise it returns an error.
+func (server *GrpcServer) authenticate(authHeader, clientIP string) (*token.Payload, error) {
+	if authHeader == "" {
 		return nil, fmt.Errorf("missing authorization header")
 	}
 
-	authHeader := values[0]
 	fields := strings.Fields(authHeader)
 	if len(fields) < 2 {
 		return nil, fmt.Errorf("invalid aithorization header format")
 	}
 
-	authType := strings.ToLower(fields[0])
-	if authType != authorizationTypeBearer {
+	switch authType := strings.ToLower(fields[0]); authType {
+	case authorizationTypeBearer:
+		accessToken := fields[1]
+		payload, err := server.tokenMaker.VerifyToken(accessToken)
+		if err != nil {
+			return nil, fmt.Errorf("invalid access token: %w", err)
+		}
+		// refresh tokens are only exchanged for new tokens, they do not authenticate requests
+		if err := payload.CheckType(token.TokenTypeAccess); err != nil {
+			return nil, err
+		}
+
+		if err := server.revocations.Check(payload); err != nil {
+			return nil, err
+		}
+
+		return payload, nil
+	case authorizationTypeAPIKey:
+		return server.apiKeys.Authenticate(fields[1], clientIP)
+	default:
 		return nil, fmt.Errorf("unsupported authorization type: %s", authType)
 	}
-
-	accessToken := fields[1]
-	payload, err := server.tokenMaker.VerifyToken(accessToken)
-	if err != nil {
-		return nil, fmt.Errorf("invalid access token: %s", accessToken)
-	}
-
-	return payload, nil
 }
@@ -2,17 +2,212 @@ package grpc_api
 
 import (
 	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
 	"Simple-Bank/pb"
+	"Simple-Bank/token"
+	"github.com/google/uuid"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"time"
 )
 
 func convert(user models.User) *pb.User {
 	return &pb.User{
-		Username:  user.Username,
-		Email:     user.Email,
-		Fullname:  user.FullName,
-		CreatedAt: timestamppb.New(user.CreatedAt.Local().Truncate(time.Second)),
-		UpdatedAt: timestamppb.New(user.UpdatedAt.Local().Truncate(time.Second)),
+		Username:      user.Username,
+		Email:         user.Email,
+		Fullname:      user.FullName,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		CreatedAt:     timestamppb.New(user.CreatedAt.Local().Truncate(time.Second)),
+		UpdatedAt:     timestamppb.New(user.UpdatedAt.Local().Truncate(time.Second)),
+	}
+}
+
+// transferStatuses maps the statuses of models.Transfer to pb.TransferStatus.
+var transferStatuses = map[string]pb.TransferStatus{
+	models.TransferStatusPending:   pb.TransferStatus_TRANSFER_STATUS_PENDING,
+	models.TransferStatusCompleted: pb.TransferStatus_TRANSFER_STATUS_COMPLETED,
+	models.TransferStatusFailed:    pb.TransferStatus_TRANSFER_STATUS_FAILED,
+	models.TransferStatusReversed:  pb.TransferStatus_TRANSFER_STATUS_REVERSED,
+	models.TransferStatusCancelled: pb.TransferStatus_TRANSFER_STATUS_CANCELLED,
+}
+
+func convertTransfer(transfer models.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:              transfer.ID,
+		FromAccountId:   transfer.FromAccountID,
+		ToAccountId:     transfer.ToAccountID,
+		Amount:          transfer.Amount,
+		Status:          transferStatuses[transfer.Status],
+		StatusReason:    transfer.StatusReason,
+		IncomingEntryId: transfer.IncomingEntryID,
+		OutgoingEntryId: transfer.OutgoingEntryID,
+		CreatedAt:       timestamppb.New(transfer.CreatedAt.Local().Truncate(time.Second)),
+		UpdatedAt:       timestamppb.New(transfer.UpdatedAt.Local().Truncate(time.Second)),
+	}
+}
+
+func convertTransferStatusChange(change models.TransferStatusHistory) *pb.TransferStatusChange {
+	return &pb.TransferStatusChange{
+		FromStatus: transferStatuses[change.FromStatus],
+		ToStatus:   transferStatuses[change.ToStatus],
+		Reason:     change.Reason,
+		Actor:      change.Actor,
+		CreatedAt:  timestamppb.New(change.CreatedAt.Local().Truncate(time.Second)),
+	}
+}
+
+func convertSession(session models.Session, currentSessionID uuid.UUID) *pb.Session {
+	return &pb.Session{
+		Id:        session.ID.String(),
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIP,
+		CreatedAt: timestamppb.New(session.CreatedAt),
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+		Current:   session.ID == currentSessionID,
+	}
+}
+
+func convertEntry(entry models.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    entry.Amount,
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+	}
+}
+
+func convertTrialBalance(trialBalance services.TrialBalance) *pb.GetTrialBalanceResponse {
+	response := &pb.GetTrialBalanceResponse{
+		At:                    timestamppb.New(trialBalance.At),
+		CustomerAccounts:      trialBalance.CustomerAccounts,
+		CustomerAccountsTotal: trialBalance.CustomerAccountsTotal,
+		Total:                 trialBalance.Total,
+		Balanced:              trialBalance.Balanced(),
+	}
+	for _, line := range trialBalance.GLAccounts {
+		response.GlAccounts = append(response.GlAccounts, &pb.TrialBalanceLine{
+			Code:     line.Code,
+			Name:     line.Name,
+			Category: line.Category,
+			Balance:  line.Balance,
+		})
+	}
+
+	return response
+}
+
+// convertTime converts an optional time, returning nil if it is not set.
+func convertTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+func convertAdminUser(user models.User) *pb.AdminUser {
+	return &pb.AdminUser{
+		User:                  convert(user),
+		FrozenAt:              convertTime(user.FrozenAt),
+		PasswordResetRequired: user.PasswordResetRequired,
+	}
+}
+
+func convertAdminSession(session models.Session) *pb.AdminSession {
+	return &pb.AdminSession{
+		Id:         session.ID.String(),
+		FamilyId:   session.FamilyID.String(),
+		UserAgent:  session.UserAgent,
+		ClientIp:   session.ClientIP,
+		IsBlocked:  session.IsBlocked,
+		CreatedAt:  timestamppb.New(session.CreatedAt),
+		ExpiresAt:  timestamppb.New(session.ExpiresAt),
+		ConsumedAt: convertTime(session.ConsumedAt),
+	}
+}
+
+func convertAccount(account models.Account) *pb.Account {
+	return &pb.Account{
+		Id:        account.ID,
+		Owner:     account.Owner,
+		Balance:   account.Balance,
+		CreatedAt: timestamppb.New(account.CreatedAt.Local().Truncate(time.Second)),
+		UpdatedAt: timestamppb.New(account.UpdatedAt.Local().Truncate(time.Second)),
+		FrozenAt:  convertTime(account.FrozenAt),
+	}
+}
+
+func convertAuditEvent(event models.AuditEvent) *pb.AuditEvent {
+	return &pb.AuditEvent{
+		Id:         event.ID,
+		Operator:   event.Operator,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetId:   event.TargetID,
+		Reason:     event.Reason,
+		Details:    event.Details,
+		CreatedAt:  timestamppb.New(event.CreatedAt),
+	}
+}
+
+func convertApproval(approval models.Approval) *pb.Approval {
+	res := &pb.Approval{
+		Id:             approval.ID,
+		Operation:      approval.Operation,
+		Payload:        approval.Payload,
+		Status:         approval.Status,
+		RequestedBy:    approval.RequestedBy,
+		Reason:         approval.Reason,
+		DecisionReason: approval.DecisionReason,
+		Error:          approval.Error,
+		CreatedAt:      timestamppb.New(approval.CreatedAt),
+		ExpiresAt:      timestamppb.New(approval.ExpiresAt),
+		DecidedAt:      convertTime(approval.DecidedAt),
+		ExecutedAt:     convertTime(approval.ExecutedAt),
+	}
+	if approval.DecidedBy != nil {
+		res.DecidedBy = *approval.DecidedBy
+	}
+	if approval.Result != nil {
+		res.Result = *approval.Result
+	}
+
+	return res
+}
+
+func convertAPIKey(key models.APIKey) *pb.APIKey {
+	return &pb.APIKey{
+		Id:         key.ID.String(),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		AllowedIps: key.AllowedIPList(),
+		ExpiresAt:  convertTime(key.ExpiresAt),
+		LastUsedAt: convertTime(key.LastUsedAt),
+		LastUsedIp: key.LastUsedIP,
+		RevokedAt:  convertTime(key.RevokedAt),
+		CreatedAt:  timestamppb.New(key.CreatedAt),
+	}
+}
+
+func convertTrustedDevice(device models.TrustedDevice) *pb.TrustedDevice {
+	return &pb.TrustedDevice{
+		Id:         device.ID,
+		UserAgent:  device.UserAgent,
+		IpRange:    device.IPRange,
+		LastIp:     device.LastIP,
+		CreatedAt:  timestamppb.New(device.CreatedAt),
+		LastSeenAt: timestamppb.New(device.LastSeenAt),
+	}
+}
+
+func convertPublicKey(key token.JSONWebKey) *pb.PublicKey {
+	return &pb.PublicKey{
+		Kty: key.KeyType,
+		Crv: key.Curve,
+		X:   key.X,
+		Kid: key.KeyID,
+		Use: key.Use,
+		Alg: key.Algorithm,
 	}
 }
@@ -1,9 +1,15 @@
 package grpc_api
 
 import (
+	"Simple-Bank/approvals"
+	"Simple-Bank/auth"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"errors"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
 func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolation {
@@ -25,7 +31,55 @@ func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) er
 	return statusDetails.Err()
 }
 
-// unAuthenticatedError creates and returns an unauthenticated gRPC error with the input error message.
+// unAuthenticatedError creates and returns an unauthenticated gRPC error with the input error message,
+// or a permission denied error if the user is authenticated but lacks a permission or a scope.
 func unAuthenticatedError(err error) error {
+	if errors.Is(err, auth.ErrPermissionDenied) || errors.Is(err, auth.ErrInsufficientScope) {
+		return status.Errorf(codes.PermissionDenied, "%s", err)
+	}
 	return status.Errorf(codes.Unauthenticated, "unauthorized: %s", err)
 }
+
+// transferFailedError converts a transfer persisted as failed, with the transfer as details so clients can see
+// why it failed.
+func transferFailedError(transfer models.Transfer) error {
+	statusFailed := status.Newf(codes.FailedPrecondition, "transfer failed: %s", transfer.StatusReason)
+
+	statusDetails, err := statusFailed.WithDetails(convertTransfer(transfer))
+	if err != nil {
+		return statusFailed.Err()
+	}
+
+	return statusDetails.Err()
+}
+
+// adminError converts the error of a failed action of the back office, describing the action with message.
+func adminError(err error, message string) error {
+	switch {
+	case errors.Is(err, services.ErrMissingReason), errors.Is(err, services.ErrInvalidAdjustment):
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return status.Errorf(codes.NotFound, "not found")
+	default:
+		return status.Errorf(codes.Internal, "failed to %s", message)
+	}
+}
+
+// approvalError converts the error of a failed request or decision of an operation, describing the action with message.
+func approvalError(err error, message string) error {
+	switch {
+	case errors.Is(err, auth.ErrPermissionDenied), errors.Is(err, services.ErrSelfApproval):
+		return status.Errorf(codes.PermissionDenied, "%s", err)
+	case errors.Is(err, services.ErrNotAccountOwner):
+		return status.Errorf(codes.PermissionDenied, "cannot act on other users` accounts")
+	case errors.Is(err, services.ErrApprovalNotPending), errors.Is(err, services.ErrApprovalExpired),
+		errors.Is(err, approvals.ErrExecutionFailed):
+		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	case errors.Is(err, services.ErrMissingReason), errors.Is(err, services.ErrInvalidAdjustment):
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return status.Errorf(codes.NotFound, "not found")
+	default:
+		return status.Errorf(codes.Internal, "failed to %s", message)
+	}
+}
@@ -0,0 +1,179 @@
+package grpc_api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/pb"
+	"Simple-Bank/ratelimit"
+	"context"
+	"fmt"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// GatewayHandler rate limits and authorizes the requests of the gateway like the interceptors of the grpc server,
+// as the gateway calls the methods in process without the interceptors, and serves them with mux. Requests are
+// matched to their method with the http annotations of the services, and the refused ones are answered with the
+// error handler of mux. Requests matching no method are refused rather than left to mux, which could still serve
+// them with the method of another http method, e.g. a form POST with the method of a GET, without authorization.
+func (server *GrpcServer) GatewayHandler(mux *runtime.ServeMux) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		// mux would serve the request with the http method of the header instead of the one it was authorized for
+		req.Header.Del(methodOverrideHeader)
+
+		method, ok := gatewayMethod(req.Method, req.URL.Path)
+		if !ok {
+			gatewayError(mux, res, req, status.Errorf(codes.NotFound, "%s %s not found", req.Method, req.URL.Path))
+			return
+		}
+
+		clientIP := server.gatewayClientIP(req)
+		if limited := server.rateLimitAddress(method, clientIP); limited != nil {
+			gatewayRateLimitError(mux, res, req, limited)
+			return
+		}
+
+		ctx := context.WithValue(req.Context(), clientIPKey{}, clientIP)
+		payload, err := server.authorize(method, req.Header.Get(authorizationHeader), clientIP)
+		if err != nil {
+			gatewayError(mux, res, req, err)
+			return
+		}
+		if payload != nil {
+			ctx = context.WithValue(ctx, authorizationPayloadKey{}, payload)
+		}
+
+		if limited := server.rateLimitUser(ctx, method); limited != nil {
+			gatewayRateLimitError(mux, res, req, limited)
+			return
+		}
+
+		mux.ServeHTTP(res, req.WithContext(ctx))
+	})
+}
+
+// methodOverrideHeader lets clients of the gateway send a request with another http method than their own
+const methodOverrideHeader = "X-HTTP-Method-Override"
+
+// BackOfficeHandler only serves the requests of the gateway with handler if their credential has the permission of
+// the back office, e.g. for the metrics of the services, and answers the others with the error handler of mux.
+func (server *GrpcServer) BackOfficeHandler(mux *runtime.ServeMux, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		policy := requirePermission(auth.PermissionBackOffice)
+		if _, err := server.authorizePolicy(policy, "", req.Header.Get(authorizationHeader), server.gatewayClientIP(req)); err != nil {
+			gatewayError(mux, res, req, err)
+			return
+		}
+
+		handler.ServeHTTP(res, req)
+	})
+}
+
+// gatewayClientIP returns the address of the client of a gateway request, believing the X-Forwarded-For headers
+// of trusted proxies only.
+func (server *GrpcServer) gatewayClientIP(req *http.Request) string {
+	return server.clientIP(req.RemoteAddr, req.Header.Values(xForwardedForHeader))
+}
+
+// gatewayError answers a request of the gateway with the http status and the body of a grpc status error.
+func gatewayError(mux *runtime.ServeMux, res http.ResponseWriter, req *http.Request, err error) {
+	_, outboundMarshaler := runtime.MarshalerForRequest(mux, req)
+	runtime.HTTPError(req.Context(), mux, outboundMarshaler, res, req, err)
+}
+
+// gatewayRateLimitError answers a request of the gateway refused by the rate limiter.
+func gatewayRateLimitError(mux *runtime.ServeMux, res http.ResponseWriter, req *http.Request, limited *ratelimit.LimitedError) {
+	res.Header().Set(retryAfterHeader, retryAfterSeconds(limited))
+	gatewayError(mux, res, req, resourceExhaustedError(limited))
+}
+
+// gatewayRoute is the http rule of a method served by the gateway.
+type gatewayRoute struct {
+	httpMethod string
+	// segments are the segments of the path of the rule, empty for the variables
+	segments   []string
+	fullMethod string
+}
+
+// gatewayRoutes are the http rules of the methods of the services.
+var gatewayRoutes = sync.OnceValue(func() []gatewayRoute {
+	var routes []gatewayRoute
+	for _, file := range []protoreflect.FileDescriptor{pb.File_service_simple_bank_proto, pb.File_service_simple_bank_admin_proto} {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				method := methods.Get(j)
+				rule, ok := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+				if !ok || rule == nil {
+					continue
+				}
+
+				httpMethod, path := httpRulePattern(rule)
+				segments := strings.Split(strings.Trim(path, "/"), "/")
+				for k, segment := range segments {
+					if strings.HasPrefix(segment, "{") {
+						segments[k] = ""
+					}
+				}
+				routes = append(routes, gatewayRoute{
+					httpMethod: httpMethod,
+					segments:   segments,
+					fullMethod: fmt.Sprintf("/%s/%s", services.Get(i).FullName(), method.Name()),
+				})
+			}
+		}
+	}
+
+	return routes
+})
+
+// gatewayMethod returns the full method the gateway serves a request with.
+func gatewayMethod(httpMethod, path string) (string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, route := range gatewayRoutes() {
+		if route.httpMethod != httpMethod || len(route.segments) != len(segments) {
+			continue
+		}
+
+		matches := true
+		for i, segment := range route.segments {
+			if segments[i] == "" || (segment != "" && segment != segments[i]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return route.fullMethod, true
+		}
+	}
+
+	return "", false
+}
+
+// httpRulePattern returns the http method and the path template of rule.
+func httpRulePattern(rule *annotations.HttpRule) (string, string) {
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		return pattern.Custom.GetKind(), pattern.Custom.GetPath()
+	default:
+		return "", ""
+	}
+}
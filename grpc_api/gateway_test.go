@@ -0,0 +1,206 @@
+package grpc_api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/db/models"
+	"Simple-Bank/pb"
+	"context"
+	"fmt"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGatewayHandlerAPIKeyAllowlist(t *testing.T) {
+	server, mockServices, _ := newTestServer(t)
+	mux := runtime.NewServeMux()
+	require.NoError(t, pb.RegisterSimpleBankHandlerServer(context.Background(), mux, server))
+	handler := server.GatewayHandler(mux)
+
+	user := models.User{Username: "alice", Role: models.RoleCustomer}
+	rawKey, key, err := auth.NewAPIKey(user.Username, "integration", []string{string(auth.ScopeTransfersRead)},
+		[]string{"203.0.113.7"}, nil)
+	require.NoError(t, err)
+	mockServices.EXPECT().GetAPIKeyByPrefix(key.Prefix).AnyTimes().Return(key, nil)
+	mockServices.EXPECT().GetUser(user.Username).AnyTimes().Return(user, nil)
+	mockServices.EXPECT().TouchAPIKey(key.ID, gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+	mockServices.EXPECT().GetTransfer(int64(42)).Times(1).Return(models.Transfer{}, gorm.ErrRecordNotFound)
+
+	testCases := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		code         int
+	}{
+		{
+			name:       "AllowedAddress",
+			remoteAddr: "203.0.113.7:40000",
+			code:       http.StatusNotFound,
+		},
+		{
+			// without trusted proxies, the forwarded address is not believed
+			name:         "ForgedForwardedAddress",
+			remoteAddr:   "198.51.100.20:40000",
+			forwardedFor: "203.0.113.7",
+			code:         http.StatusUnauthorized,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/transfers/42", nil)
+			request.RemoteAddr = testCase.remoteAddr
+			if testCase.forwardedFor != "" {
+				request.Header.Set("X-Forwarded-For", testCase.forwardedFor)
+			}
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", authorizationTypeAPIKey, rawKey))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			require.Equal(t, testCase.code, recorder.Code)
+		})
+	}
+}
+
+func TestGatewayHandlerUnmatchedRequest(t *testing.T) {
+	server, mockServices, _ := newTestServer(t)
+	// the path length fallback is left enabled, to check that the handler refuses the requests it would serve
+	mux := runtime.NewServeMux()
+	require.NoError(t, pb.RegisterSimpleBankHandlerServer(context.Background(), mux, server))
+	handler := server.GatewayHandler(mux)
+	mockServices.EXPECT().GetTrialBalance(gomock.Any()).Times(0)
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		header http.Header
+	}{
+		{
+			// the gateway serves form posts with the methods of GET rules
+			name:   "FormPost",
+			method: http.MethodPost,
+			path:   "/v1/reports/trial_balance",
+			header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+		},
+		{
+			name:   "MethodOverride",
+			method: http.MethodPost,
+			path:   "/v1/reports/trial_balance",
+			header: http.Header{
+				"Content-Type":       {"application/x-www-form-urlencoded"},
+				methodOverrideHeader: {http.MethodGet},
+			},
+		},
+		{
+			name:   "UnknownPath",
+			method: http.MethodGet,
+			path:   "/v1/unknown",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.method, testCase.path, strings.NewReader(""))
+			for key, values := range testCase.header {
+				request.Header[key] = values
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusNotFound, recorder.Code)
+		})
+	}
+}
+
+func TestGatewayMethod(t *testing.T) {
+	testCases := []struct {
+		name       string
+		httpMethod string
+		path       string
+		method     string
+		ok         bool
+	}{
+		{
+			name:       "Static",
+			httpMethod: http.MethodPost,
+			path:       "/v1/login_user",
+			method:     pb.SimpleBank_LoginUser_FullMethodName,
+			ok:         true,
+		},
+		{
+			name:       "LongerStatic",
+			httpMethod: http.MethodPost,
+			path:       "/v1/login_user/mfa",
+			method:     pb.SimpleBank_VerifyLoginMFA_FullMethodName,
+			ok:         true,
+		},
+		{
+			name:       "Variable",
+			httpMethod: http.MethodGet,
+			path:       "/v1/transfers/42",
+			method:     pb.SimpleBank_GetTransfer_FullMethodName,
+			ok:         true,
+		},
+		{
+			name:       "SamePathOtherHTTPMethod",
+			httpMethod: http.MethodDelete,
+			path:       "/v1/sessions/0b7e5a36-44c3-4d0e-9a3c-6c4f0e0c2b1a",
+			method:     pb.SimpleBank_RevokeSession_FullMethodName,
+			ok:         true,
+		},
+		{
+			name:       "VariableInTheMiddle",
+			httpMethod: http.MethodPost,
+			path:       "/v1/admin/users/alice/freeze",
+			method:     pb.SimpleBankAdmin_FreezeUser_FullMethodName,
+			ok:         true,
+		},
+		{
+			name:       "Admin",
+			httpMethod: http.MethodGet,
+			path:       "/v1/admin/audit_events",
+			method:     pb.SimpleBankAdmin_ListAuditEvents_FullMethodName,
+			ok:         true,
+		},
+		// the requests matching no rule are refused by GatewayHandler
+		{
+			name:       "UnknownPath",
+			httpMethod: http.MethodGet,
+			path:       "/v1/unknown",
+		},
+		{
+			name:       "UnknownHTTPMethod",
+			httpMethod: http.MethodPut,
+			path:       "/v1/login_user",
+		},
+		{
+			name:       "EmptyVariable",
+			httpMethod: http.MethodPost,
+			path:       "/v1/admin/users//freeze",
+		},
+		{
+			name:       "ExtraSegment",
+			httpMethod: http.MethodGet,
+			path:       "/v1/transfers/42/entries",
+		},
+		{
+			name:       "DebugVars",
+			httpMethod: http.MethodGet,
+			path:       "/debug/vars",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			method, ok := gatewayMethod(testCase.httpMethod, testCase.path)
+			require.Equal(t, testCase.ok, ok)
+			require.Equal(t, testCase.method, method)
+		})
+	}
+}
@@ -1,16 +1,25 @@
 package grpc_api
 
 import (
+	"Simple-Bank/auth"
+	"Simple-Bank/db/models"
+	"Simple-Bank/passwords"
 	"Simple-Bank/pb"
+	"Simple-Bank/stepup"
 	"Simple-Bank/util"
+	"fmt"
+	"github.com/google/uuid"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 func validateCreateUserRequest(req *pb.CreateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
 	if err := util.ValidateUsername(req.GetUsername()); err != nil {
 		violations = append(violations, fieldViolation("username", err))
 	}
-	if err := util.ValidatePassword(req.GetPassword()); err != nil {
+	if err := validatePasswordLength(req.GetPassword()); err != nil {
 		violations = append(violations, fieldViolation("password", err))
 	}
 	if err := util.ValidateFullname(req.GetFullname()); err != nil {
@@ -27,7 +36,7 @@ func validateLoginUseRequest(req *pb.LoginUserRequest) (violations []*errdetails
 	if err := util.ValidateUsername(req.GetUsername()); err != nil {
 		violations = append(violations, fieldViolation("username", err))
 	}
-	if err := util.ValidatePassword(req.GetPassword()); err != nil {
+	if err := validatePasswordLength(req.GetPassword()); err != nil {
 		violations = append(violations, fieldViolation("password", err))
 	}
 
@@ -39,10 +48,9 @@ func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdeta
 		violations = append(violations, fieldViolation("username", err))
 	}
 
+	// passwords cannot be changed without the current password anymore
 	if req.Password != nil {
-		if err := util.ValidatePassword(req.GetPassword()); err != nil {
-			violations = append(violations, fieldViolation("password", err))
-		}
+		violations = append(violations, fieldViolation("password", fmt.Errorf("use ChangePassword, which requires the current password")))
 	}
 
 	if req.Fullname != nil {
@@ -59,3 +67,339 @@ func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdeta
 
 	return violations
 }
+
+func validateCreateTransferRequest(req *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetFromAccountId() < 1 {
+		violations = append(violations, fieldViolation("from_account_id", fmt.Errorf("must be a positive account id")))
+	}
+	if req.GetToAccountId() < 1 {
+		violations = append(violations, fieldViolation("to_account_id", fmt.Errorf("must be a positive account id")))
+	}
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", fmt.Errorf("must be different from from_account_id")))
+	}
+	if req.GetAmount() < 1 {
+		violations = append(violations, fieldViolation("amount", fmt.Errorf("must be positive")))
+	}
+
+	return violations
+}
+
+func validateGetTransferRequest(req *pb.GetTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetId() < 1 {
+		violations = append(violations, fieldViolation("id", fmt.Errorf("must be a positive transfer id")))
+	}
+
+	return violations
+}
+
+func validateRevokeSessionRequest(req *pb.RevokeSessionRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", fmt.Errorf("must be a valid session id")))
+	}
+
+	return violations
+}
+
+func validateDepositRequest(req *pb.DepositRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetAccountId() < 1 {
+		violations = append(violations, fieldViolation("account_id", fmt.Errorf("must be a positive account id")))
+	}
+	if req.GetAmount() < 1 {
+		violations = append(violations, fieldViolation("amount", fmt.Errorf("must be positive")))
+	}
+
+	return violations
+}
+
+// validateReason checks the reason every action of the back office requires.
+func validateReason(reason string) *errdetails.BadRequest_FieldViolation {
+	if strings.TrimSpace(reason) == "" {
+		return fieldViolation("reason", fmt.Errorf("must not be empty"))
+	}
+
+	return nil
+}
+
+// validatePage checks the page of a listing of the back office.
+func validatePage(pageID int64, pageSize int32) (violations []*errdetails.BadRequest_FieldViolation) {
+	if pageID < 1 {
+		violations = append(violations, fieldViolation("page_id", fmt.Errorf("must be positive")))
+	}
+	if pageSize < 5 || pageSize > 50 {
+		violations = append(violations, fieldViolation("page_size", fmt.Errorf("must be between 5 and 50")))
+	}
+
+	return violations
+}
+
+func validateSearchUsersRequest(req *pb.SearchUsersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	violations = validatePage(req.GetPageId(), req.GetPageSize())
+	if violation := validateReason(req.GetReason()); violation != nil {
+		violations = append(violations, violation)
+	}
+
+	return violations
+}
+
+func validateAdminUserRequest(username, reason string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := util.ValidateUsername(username); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+	if violation := validateReason(reason); violation != nil {
+		violations = append(violations, violation)
+	}
+
+	return violations
+}
+
+func validateListAccountEntriesRequest(req *pb.ListAccountEntriesRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetAccountId() < 1 {
+		violations = append(violations, fieldViolation("account_id", fmt.Errorf("must be a positive account id")))
+	}
+	violations = append(violations, validatePage(req.GetPageId(), req.GetPageSize())...)
+	if violation := validateReason(req.GetReason()); violation != nil {
+		violations = append(violations, violation)
+	}
+
+	return violations
+}
+
+func validateAdminAccountActionRequest(req *pb.AdminAccountActionRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetAccountId() < 1 {
+		violations = append(violations, fieldViolation("account_id", fmt.Errorf("must be a positive account id")))
+	}
+	if violation := validateReason(req.GetReason()); violation != nil {
+		violations = append(violations, violation)
+	}
+
+	return violations
+}
+
+func validateAdjustBalanceRequest(req *pb.AdjustBalanceRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetAccountId() < 1 {
+		violations = append(violations, fieldViolation("account_id", fmt.Errorf("must be a positive account id")))
+	}
+	if req.GetAmount() == 0 {
+		violations = append(violations, fieldViolation("amount", fmt.Errorf("must not be zero")))
+	}
+	if violation := validateReason(req.GetReason()); violation != nil {
+		violations = append(violations, violation)
+	}
+
+	return violations
+}
+
+func validateAdminRevokeTokenRequest(req *pb.AdminRevokeTokenRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if _, err := uuid.Parse(req.GetTokenId()); err != nil {
+		violations = append(violations, fieldViolation("token_id", fmt.Errorf("must be a valid token id")))
+	}
+	if err := util.ValidateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+	if violation := validateReason(req.GetReason()); violation != nil {
+		violations = append(violations, violation)
+	}
+
+	return violations
+}
+
+func validateListAuditEventsRequest(req *pb.ListAuditEventsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	violations = validatePage(req.GetPageId(), req.GetPageSize())
+	if violation := validateReason(req.GetReason()); violation != nil {
+		violations = append(violations, violation)
+	}
+
+	return violations
+}
+
+func validateListApprovalsRequest(req *pb.ListApprovalsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	violations = validatePage(req.GetPageId(), req.GetPageSize())
+	switch req.GetStatus() {
+	case "", models.ApprovalStatusPending, models.ApprovalStatusApproved, models.ApprovalStatusExecuted,
+		models.ApprovalStatusFailed, models.ApprovalStatusRejected, models.ApprovalStatusExpired:
+	default:
+		violations = append(violations, fieldViolation("status", fmt.Errorf("must be a status of an approval")))
+	}
+
+	return violations
+}
+
+func validateGetApprovalRequest(req *pb.GetApprovalRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetId() < 1 {
+		violations = append(violations, fieldViolation("id", fmt.Errorf("must be a positive approval id")))
+	}
+
+	return violations
+}
+
+func validateDecideApprovalRequest(req *pb.DecideApprovalRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetId() < 1 {
+		violations = append(violations, fieldViolation("id", fmt.Errorf("must be a positive approval id")))
+	}
+	if violation := validateReason(req.GetReason()); violation != nil {
+		violations = append(violations, violation)
+	}
+
+	return violations
+}
+
+func validateCloseAccountRequest(req *pb.CloseAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetAccountId() < 1 {
+		violations = append(violations, fieldViolation("account_id", fmt.Errorf("must be a positive account id")))
+	}
+	if violation := validateReason(req.GetReason()); violation != nil {
+		violations = append(violations, violation)
+	}
+
+	return violations
+}
+
+func validateCreateAPIKeyRequest(req *pb.CreateAPIKeyRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if name := strings.TrimSpace(req.GetName()); name == "" || len(name) > 64 {
+		violations = append(violations, fieldViolation("name", fmt.Errorf("must be between 1 and 64 characters")))
+	}
+	if err := auth.ValidateScopes(req.GetScopes()); err != nil {
+		violations = append(violations, fieldViolation("scopes", err))
+	}
+	if err := auth.ValidateAllowedIPs(req.GetAllowedIps()); err != nil {
+		violations = append(violations, fieldViolation("allowed_ips", err))
+	}
+	if req.ExpiresAt != nil && !req.GetExpiresAt().AsTime().After(time.Now()) {
+		violations = append(violations, fieldViolation("expires_at", fmt.Errorf("must be in the future")))
+	}
+
+	return violations
+}
+
+func validateRevokeAPIKeyRequest(req *pb.RevokeAPIKeyRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", fmt.Errorf("must be a valid api key id")))
+	}
+
+	return violations
+}
+
+func validateRemoveTrustedDeviceRequest(req *pb.RemoveTrustedDeviceRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetId() < 1 {
+		violations = append(violations, fieldViolation("id", fmt.Errorf("must be a positive integer")))
+	}
+
+	return violations
+}
+
+func validateVerifyLoginMFARequest(req *pb.VerifyLoginMFARequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetMfaChallengeToken() == "" {
+		violations = append(violations, fieldViolation("mfa_challenge_token", fmt.Errorf("must not be empty")))
+	}
+	if err := validateMFACode(req.GetCode()); err != nil {
+		violations = append(violations, fieldViolation("code", err))
+	}
+
+	return violations
+}
+
+func validateMFACode(code string) error {
+	if code == "" || len(code) > 32 {
+		return fmt.Errorf("must contain from 1 to 32 characters")
+	}
+
+	return nil
+}
+
+func validateStepUpRequest(req *pb.StepUpRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if !stepup.IsValidOperation(req.GetOperation()) {
+		violations = append(violations, fieldViolation("operation", fmt.Errorf("must be transfer, close_account or change_email")))
+	}
+	if req.GetPassword() == "" && req.GetCode() == "" {
+		violations = append(violations, fieldViolation("password", fmt.Errorf("password or code must be provided")))
+	}
+	if len(req.GetCode()) > 32 {
+		violations = append(violations, fieldViolation("code", fmt.Errorf("must contain at most 32 characters")))
+	}
+
+	return violations
+}
+
+func validateVerifyEmailRequest(req *pb.VerifyEmailRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetToken() == "" || len(req.GetToken()) > 128 {
+		violations = append(violations, fieldViolation("token", fmt.Errorf("must contain from 1 to 128 characters")))
+	}
+
+	return violations
+}
+
+func validateForgotPasswordRequest(req *pb.ForgotPasswordRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := util.ValidateEmail(req.GetEmail()); err != nil {
+		violations = append(violations, fieldViolation("email", err))
+	}
+
+	return violations
+}
+
+func validateResetPasswordRequest(req *pb.ResetPasswordRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetToken() == "" || len(req.GetToken()) > 128 {
+		violations = append(violations, fieldViolation("token", fmt.Errorf("must contain from 1 to 128 characters")))
+	}
+	if err := validatePasswordLength(req.GetNewPassword()); err != nil {
+		violations = append(violations, fieldViolation("new_password", err))
+	}
+
+	return violations
+}
+
+func validateChangePasswordRequest(req *pb.ChangePasswordRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validatePasswordLength(req.GetCurrentPassword()); err != nil {
+		violations = append(violations, fieldViolation("current_password", err))
+	}
+	if err := validatePasswordLength(req.GetNewPassword()); err != nil {
+		violations = append(violations, fieldViolation("new_password", err))
+	}
+
+	return violations
+}
+
+// validatePasswordLength only bounds the length of passwords, the password policy checks new passwords since it is
+// configured, and passwords logging in may predate the policy.
+func validatePasswordLength(password string) error {
+	if password == "" || utf8.RuneCountInString(password) > passwords.MaxLength {
+		return fmt.Errorf("must contain from 1 to %d characters", passwords.MaxLength)
+	}
+
+	return nil
+}
+
+func validateRequestLoginCodeRequest(req *pb.RequestLoginCodeRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateLoginIdentifier(req.GetIdentifier()); err != nil {
+		violations = append(violations, fieldViolation("identifier", err))
+	}
+
+	return violations
+}
+
+func validateVerifyLoginCodeRequest(req *pb.VerifyLoginCodeRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetToken() != "" {
+		if len(req.GetToken()) > 128 {
+			violations = append(violations, fieldViolation("token", fmt.Errorf("must contain from 1 to 128 characters")))
+		}
+		return violations
+	}
+
+	if err := validateLoginIdentifier(req.GetIdentifier()); err != nil {
+		violations = append(violations, fieldViolation("identifier", err))
+	}
+	if req.GetCode() == "" || len(req.GetCode()) > 32 {
+		violations = append(violations, fieldViolation("code", fmt.Errorf("must contain from 1 to 32 characters")))
+	}
+
+	return violations
+}
+
+// validateLoginIdentifier validates the username or the email of a user logging in without a password.
+func validateLoginIdentifier(identifier string) error {
+	if identifier == "" || len(identifier) > 254 {
+		return fmt.Errorf("must contain from 1 to 254 characters")
+	}
+
+	return nil
+}
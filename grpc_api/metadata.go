@@ -2,8 +2,11 @@ package grpc_api
 
 import (
 	"context"
+	"fmt"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"net"
+	"strings"
 )
 
 type Metadata struct {
@@ -17,26 +20,95 @@ const (
 	xForwardedForHeader        = "x-forwarded-for"
 )
 
+// clientIPKey is the context key of the address of the client of a gateway request, resolved by GatewayHandler.
+type clientIPKey struct{}
+
 func (server *GrpcServer) extractMetaData(context context.Context) *Metadata {
 	mtdt := &Metadata{}
 
-	if md, ok := metadata.FromIncomingContext(context); ok {
-		if userAgents := md.Get(grpcGatewayUserAgentHeader); len(userAgents) > 0 {
-			mtdt.userAgent = userAgents[0]
-		}
+	md, _ := metadata.FromIncomingContext(context)
+	if userAgents := md.Get(grpcGatewayUserAgentHeader); len(userAgents) > 0 {
+		mtdt.userAgent = userAgents[0]
+	}
+
+	if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
+		mtdt.userAgent = userAgents[0]
+	}
+
+	// the gateway calls the methods in process, without a peer, and resolves the address of its clients itself
+	if clientIP, ok := context.Value(clientIPKey{}).(string); ok {
+		mtdt.clientIP = clientIP
+	} else if p, ok := peer.FromContext(context); ok {
+		mtdt.clientIP = server.clientIP(p.Addr.String(), md.Get(xForwardedForHeader))
+	}
+
+	return mtdt
+}
+
+// clientIP returns the address of the client of a request received from remoteAddr, with or without a port,
+// through the proxies its X-Forwarded-For headers list. Like gin, the forwarded addresses are walked back from
+// remoteAddr only as long as they were added by trusted proxies, so clients cannot pick their address by sending
+// the header themselves.
+func (server *GrpcServer) clientIP(remoteAddr string, forwardedFor []string) string {
+	clientIP := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		clientIP = host
+	}
 
-		if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
-			mtdt.userAgent = userAgents[0]
+	var hops []string
+	for _, value := range forwardedFor {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0 && server.isTrustedProxy(clientIP); i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
 		}
+		clientIP = hop
+	}
+
+	return clientIP
+}
+
+// isTrustedProxy reports whether address is one of the trusted proxies.
+func (server *GrpcServer) isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
 
-		if clientIPs := md.Get(xForwardedForHeader); len(clientIPs) > 0 {
-			mtdt.clientIP = clientIPs[0]
+	for _, network := range server.trustedProxies {
+		if network.Contains(ip) {
+			return true
 		}
 	}
 
-	if p, ok := peer.FromContext(context); ok {
-		mtdt.clientIP = p.Addr.String()
+	return false
+}
+
+// parseTrustedProxies parses the addresses and CIDR ranges of the trusted proxies.
+func parseTrustedProxies(trustedProxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("trusted proxy %q is neither an address nor a CIDR range", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q is neither an address nor a CIDR range", proxy)
+		}
+		networks = append(networks, network)
 	}
 
-	return mtdt
+	return networks, nil
 }
@@ -0,0 +1,105 @@
+package grpc_api
+
+import (
+	"Simple-Bank/config"
+	"Simple-Bank/util"
+	"context"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	server, _, _ := newTestServerWithConfig(t, &config.Config{
+		TokenSymmetricKey: util.RandomString(32, util.ALL),
+		TrustedProxies:    []string{"10.0.0.0/8", "192.168.1.1"},
+	})
+
+	testCases := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		clientIP     string
+	}{
+		{
+			name:       "Direct",
+			remoteAddr: "203.0.113.7:52000",
+			clientIP:   "203.0.113.7",
+		},
+		{
+			// clients cannot pick their address by sending the header themselves
+			name:         "ForgedHeader",
+			remoteAddr:   "203.0.113.7:52000",
+			forwardedFor: []string{"198.51.100.1"},
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "TrustedProxy",
+			remoteAddr:   "192.168.1.1:52000",
+			forwardedFor: []string{"203.0.113.7"},
+			clientIP:     "203.0.113.7",
+		},
+		{
+			// the first address was sent by the client, and only the address the trusted proxy added is believed
+			name:         "ForgedHeaderThroughTrustedProxy",
+			remoteAddr:   "192.168.1.1:52000",
+			forwardedFor: []string{"198.51.100.1, 203.0.113.7"},
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "TrustedProxies",
+			remoteAddr:   "192.168.1.1:52000",
+			forwardedFor: []string{"198.51.100.1, 203.0.113.7", "10.1.2.3"},
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "InvalidForwardedAddress",
+			remoteAddr:   "192.168.1.1:52000",
+			forwardedFor: []string{"not an address"},
+			clientIP:     "192.168.1.1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.clientIP, server.clientIP(testCase.remoteAddr, testCase.forwardedFor))
+		})
+	}
+}
+
+func TestExtractMetaDataClientIP(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	forged := metadata.Pairs(xForwardedForHeader, "198.51.100.1", userAgentHeader, "grpc-go")
+
+	// the address of the peer of grpc requests
+	ctx := metadata.NewIncomingContext(context.Background(), forged)
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 52000}})
+	mtdt := server.extractMetaData(ctx)
+	require.Equal(t, "203.0.113.7", mtdt.clientIP)
+	require.Equal(t, "grpc-go", mtdt.userAgent)
+
+	// the address the gateway resolved for its requests, which have no peer
+	ctx = metadata.NewIncomingContext(context.Background(), forged)
+	ctx = context.WithValue(ctx, clientIPKey{}, "203.0.113.8")
+	require.Equal(t, "203.0.113.8", server.extractMetaData(ctx).clientIP)
+
+	// the header alone is never believed
+	ctx = metadata.NewIncomingContext(context.Background(), forged)
+	require.Empty(t, server.extractMetaData(ctx).clientIP)
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	networks, err := parseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", "2001:db8::1"})
+	require.NoError(t, err)
+	require.Len(t, networks, 3)
+	require.True(t, networks[1].Contains(net.ParseIP("192.168.1.1")))
+	require.False(t, networks[1].Contains(net.ParseIP("192.168.1.2")))
+	require.True(t, networks[2].Contains(net.ParseIP("2001:db8::1")))
+
+	_, err = parseTrustedProxies([]string{"proxy.example.com"})
+	require.Error(t, err)
+	_, err = parseTrustedProxies([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+}
@@ -0,0 +1,233 @@
+package grpc_api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/pb"
+	"Simple-Bank/token"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodPolicy declares who can call a method.
+type methodPolicy struct {
+	// public methods can be called without credentials, e.g. to create a user or to log in
+	public bool
+	// permission is the permission the role of the user requires besides valid credentials, if any
+	permission auth.Permission
+}
+
+var (
+	// publicMethod can be called by anyone
+	publicMethod = methodPolicy{public: true}
+	// authenticatedMethod requires valid credentials, its handler restricts users to their own resources
+	authenticatedMethod = methodPolicy{}
+)
+
+// requirePermission returns the policy of a method requiring valid credentials whose role has permission.
+func requirePermission(permission auth.Permission) methodPolicy {
+	return methodPolicy{permission: permission}
+}
+
+// methodPolicies declares the policy of every method. Methods missing from the map cannot be called at all,
+// so a new method is refused until it is declared here.
+var methodPolicies = map[string]methodPolicy{
+	pb.SimpleBank_CreateUser_FullMethodName:        publicMethod,
+	pb.SimpleBank_LoginUser_FullMethodName:         publicMethod,
+	pb.SimpleBank_VerifyLoginMFA_FullMethodName:    publicMethod,
+	pb.SimpleBank_RequestLoginCode_FullMethodName:  publicMethod,
+	pb.SimpleBank_VerifyLoginCode_FullMethodName:   publicMethod,
+	pb.SimpleBank_RenewAccessToken_FullMethodName:  publicMethod,
+	pb.SimpleBank_ListPublicKeys_FullMethodName:    publicMethod,
+	pb.SimpleBank_VerifyEmail_FullMethodName:       publicMethod,
+	pb.SimpleBank_ForgotPassword_FullMethodName:    publicMethod,
+	pb.SimpleBank_ResetPassword_FullMethodName:     publicMethod,
+	pb.SimpleBank_GetPasswordPolicy_FullMethodName: publicMethod,
+
+	pb.SimpleBank_UpdateUser_FullMethodName:              authenticatedMethod,
+	pb.SimpleBank_CreateTransfer_FullMethodName:          authenticatedMethod,
+	pb.SimpleBank_GetTransfer_FullMethodName:             authenticatedMethod,
+	pb.SimpleBank_ListSessions_FullMethodName:            authenticatedMethod,
+	pb.SimpleBank_RevokeSession_FullMethodName:           authenticatedMethod,
+	pb.SimpleBank_Logout_FullMethodName:                  authenticatedMethod,
+	pb.SimpleBank_LogoutOtherSessions_FullMethodName:     authenticatedMethod,
+	pb.SimpleBank_CloseAccount_FullMethodName:            authenticatedMethod,
+	pb.SimpleBank_ListApprovals_FullMethodName:           authenticatedMethod,
+	pb.SimpleBank_GetApproval_FullMethodName:             authenticatedMethod,
+	pb.SimpleBank_ApproveOperation_FullMethodName:        authenticatedMethod,
+	pb.SimpleBank_RejectOperation_FullMethodName:         authenticatedMethod,
+	pb.SimpleBank_CreateAPIKey_FullMethodName:            authenticatedMethod,
+	pb.SimpleBank_ListAPIKeys_FullMethodName:             authenticatedMethod,
+	pb.SimpleBank_RevokeAPIKey_FullMethodName:            authenticatedMethod,
+	pb.SimpleBank_EnrollTOTP_FullMethodName:              authenticatedMethod,
+	pb.SimpleBank_ConfirmTOTP_FullMethodName:             authenticatedMethod,
+	pb.SimpleBank_DisableTOTP_FullMethodName:             authenticatedMethod,
+	pb.SimpleBank_StepUp_FullMethodName:                  authenticatedMethod,
+	pb.SimpleBank_ResendVerificationEmail_FullMethodName: authenticatedMethod,
+	pb.SimpleBank_ChangePassword_FullMethodName:          authenticatedMethod,
+	pb.SimpleBank_ListTrustedDevices_FullMethodName:      authenticatedMethod,
+	pb.SimpleBank_RemoveTrustedDevice_FullMethodName:     authenticatedMethod,
+
+	pb.SimpleBank_Deposit_FullMethodName:         requirePermission(auth.PermissionDeposit),
+	pb.SimpleBank_GetTrialBalance_FullMethodName: requirePermission(auth.PermissionReadReports),
+
+	pb.SimpleBankAdmin_SearchUsers_FullMethodName:        requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_ListUserAccounts_FullMethodName:   requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_ListUserSessions_FullMethodName:   requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_FreezeUser_FullMethodName:         requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_UnfreezeUser_FullMethodName:       requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_ForcePasswordReset_FullMethodName: requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_UnlockUser_FullMethodName:         requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_ListAccountEntries_FullMethodName: requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_FreezeAccount_FullMethodName:      requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_UnfreezeAccount_FullMethodName:    requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_AdjustBalance_FullMethodName:      requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_RevokeToken_FullMethodName:        requirePermission(auth.PermissionBackOffice),
+	pb.SimpleBankAdmin_ListAuditEvents_FullMethodName:    requirePermission(auth.PermissionBackOffice),
+}
+
+// methodScopes declares the scope a scoped credential, e.g. an api key, needs to call a method.
+// Methods missing from the map cannot be called with scoped credentials.
+var methodScopes = map[string]auth.Scope{
+	pb.SimpleBank_CreateTransfer_FullMethodName: auth.ScopeTransfersWrite,
+	pb.SimpleBank_GetTransfer_FullMethodName:    auth.ScopeTransfersRead,
+}
+
+// errNoPolicy is returned for the methods missing from methodPolicies
+var errNoPolicy = errors.New("method has no authorization policy")
+
+// authorizationPayloadKey is the context key of the payload of the credential authorized by the interceptors.
+type authorizationPayloadKey struct{}
+
+// AuthorizationInterceptor authorizes every request against the policy of its method before it is handled, and
+// passes the payload of the credential on to the handler. Requests to methods without a policy are refused.
+func (server *GrpcServer) AuthorizationInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	ctx, err := server.authorizeRequest(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// AuthorizationStreamInterceptor authorizes the streams like AuthorizationInterceptor authorizes the requests.
+func (server *GrpcServer) AuthorizationStreamInterceptor(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := server.authorizeRequest(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authorizedStream is a stream whose context carries the payload of its credential.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authorizedStream) Context() context.Context {
+	return stream.ctx
+}
+
+// authorizeRequest authorizes a request to method with the authorization header of its metadata, and returns its
+// context with the payload of the credential, if the method is not public.
+func (server *GrpcServer) authorizeRequest(ctx context.Context, method string) (context.Context, error) {
+	var authHeader string
+	if mtdt, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := mtdt.Get(authorizationHeader); len(values) > 0 {
+			authHeader = values[0]
+		}
+	}
+
+	payload, err := server.authorize(method, authHeader, server.extractMetaData(ctx).clientIP)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return ctx, nil
+	}
+
+	return context.WithValue(ctx, authorizationPayloadKey{}, payload), nil
+}
+
+// authorize checks the credential of authHeader against the policy of method, and returns its payload, or nil
+// for public methods. It returns a grpc status error if the method has no policy or the credential is refused.
+func (server *GrpcServer) authorize(method, authHeader, clientIP string) (*token.Payload, error) {
+	policy, ok := methodPolicies[method]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "%s: %s", errNoPolicy, method)
+	}
+
+	return server.authorizePolicy(policy, methodScopes[method], authHeader, clientIP)
+}
+
+// authorizePolicy checks the credential of authHeader against policy, scoped credentials requiring scope, and
+// returns its payload, or nil for public policies. It returns a grpc status error if the credential is refused.
+func (server *GrpcServer) authorizePolicy(policy methodPolicy, scope auth.Scope, authHeader, clientIP string) (*token.Payload, error) {
+	if policy.public {
+		return nil, nil
+	}
+
+	payload, err := server.authenticate(authHeader, clientIP)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+	// the user is authenticated, but their role or the scopes of their credential do not allow the method
+	if err := authorizeMethod(policy, scope, payload); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+
+	return payload, nil
+}
+
+// authorizeMethod checks the permission policy requires, if any, and that scoped credentials have scope.
+func authorizeMethod(policy methodPolicy, scope auth.Scope, payload *token.Payload) error {
+	if err := auth.AuthorizeScope(payload, scope); err != nil {
+		return err
+	}
+	if policy.permission == "" {
+		return nil
+	}
+
+	return auth.Authorize(payload, policy.permission)
+}
+
+// authorizationPayload returns the payload of the credential the interceptors authorized the request with.
+func authorizationPayload(ctx context.Context) (*token.Payload, error) {
+	payload, ok := ctx.Value(authorizationPayloadKey{}).(*token.Payload)
+	if !ok {
+		return nil, fmt.Errorf("missing authorization")
+	}
+
+	return payload, nil
+}
+
+// permittedPayload returns the payload of the credential the interceptors authorized the request with if its role
+// has permission. The handlers of methods requiring a permission check it again, so they never serve a request
+// without credentials, whoever calls them.
+func permittedPayload(ctx context.Context, permission auth.Permission) (*token.Payload, error) {
+	payload, err := authorizationPayload(ctx)
+	if err == nil {
+		err = auth.Authorize(payload, permission)
+	}
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	return payload, nil
+}
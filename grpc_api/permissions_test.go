@@ -0,0 +1,225 @@
+package grpc_api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/config"
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/mail"
+	"Simple-Bank/pb"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) (*GrpcServer, *mockdb.MockServices, token.Maker) {
+	return newTestServerWithConfig(t, &config.Config{TokenSymmetricKey: util.RandomString(32, util.ALL)})
+}
+
+func newTestServerWithConfig(t *testing.T, testConfig *config.Config) (*GrpcServer, *mockdb.MockServices, token.Maker) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	mockServices.EXPECT().IsTokenRevoked(gomock.Any()).AnyTimes().Return(false, nil)
+
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32, util.ALL))
+	require.NoError(t, err)
+
+	server, err := NewServer(testConfig, mockServices, tokenMaker, mail.NewLogMailer())
+	require.NoError(t, err)
+
+	return server, mockServices, tokenMaker
+}
+
+// bearer returns the authorization header of an access token of a user with role, bound to an active session.
+func bearer(t *testing.T, mockServices *mockdb.MockServices, tokenMaker token.Maker, role string) string {
+	username, sessionID := util.RandomUsername(), uuid.New()
+	accessToken, _, err := tokenMaker.CreateSessionToken(username, role, sessionID, time.Minute)
+	require.NoError(t, err)
+
+	mockServices.EXPECT().
+		GetSession(sessionID).
+		AnyTimes().
+		Return(models.Session{ID: sessionID, Username: username, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	return fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)
+}
+
+func TestMethodPolicies(t *testing.T) {
+	for _, desc := range []grpc.ServiceDesc{pb.SimpleBank_ServiceDesc, pb.SimpleBankAdmin_ServiceDesc} {
+		for _, method := range desc.Methods {
+			fullMethod := fmt.Sprintf("/%s/%s", desc.ServiceName, method.MethodName)
+			require.Contains(t, methodPolicies, fullMethod, "%s has no authorization policy", fullMethod)
+		}
+		for _, stream := range desc.Streams {
+			fullMethod := fmt.Sprintf("/%s/%s", desc.ServiceName, stream.StreamName)
+			require.Contains(t, methodPolicies, fullMethod, "%s has no authorization policy", fullMethod)
+		}
+	}
+
+	for method := range methodScopes {
+		require.Contains(t, methodPolicies, method)
+	}
+}
+
+func TestAuthorizationInterceptor(t *testing.T) {
+	server, mockServices, tokenMaker := newTestServer(t)
+
+	scopedToken, _, err := tokenMaker.CreateScopedToken(util.RandomUsername(), models.RoleCustomer,
+		[]string{string(auth.ScopeTransfersRead)}, "", time.Minute)
+	require.NoError(t, err)
+	refreshToken, _, err := tokenMaker.CreateRefreshToken(util.RandomUsername(), models.RoleCustomer, uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		method     string
+		authHeader string
+		code       codes.Code
+	}{
+		{
+			name:   "Public",
+			method: pb.SimpleBank_LoginUser_FullMethodName,
+			code:   codes.OK,
+		},
+		{
+			name:       "Authenticated",
+			method:     pb.SimpleBank_ListSessions_FullMethodName,
+			authHeader: bearer(t, mockServices, tokenMaker, models.RoleCustomer),
+			code:       codes.OK,
+		},
+		{
+			name:   "NoAuthorization",
+			method: pb.SimpleBank_ListSessions_FullMethodName,
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:       "InvalidToken",
+			method:     pb.SimpleBank_ListSessions_FullMethodName,
+			authHeader: fmt.Sprintf("%s %s", authorizationTypeBearer, "invalid"),
+			code:       codes.Unauthenticated,
+		},
+		{
+			name:       "RefreshToken",
+			method:     pb.SimpleBank_ListSessions_FullMethodName,
+			authHeader: fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken),
+			code:       codes.Unauthenticated,
+		},
+		{
+			name:       "Permission",
+			method:     pb.SimpleBankAdmin_FreezeUser_FullMethodName,
+			authHeader: bearer(t, mockServices, tokenMaker, models.RoleAdmin),
+			code:       codes.OK,
+		},
+		{
+			name:       "PermissionDenied",
+			method:     pb.SimpleBankAdmin_FreezeUser_FullMethodName,
+			authHeader: bearer(t, mockServices, tokenMaker, models.RoleCustomer),
+			code:       codes.PermissionDenied,
+		},
+		{
+			name:       "PermissionOfAnotherRole",
+			method:     pb.SimpleBank_GetTrialBalance_FullMethodName,
+			authHeader: bearer(t, mockServices, tokenMaker, models.RoleTeller),
+			code:       codes.PermissionDenied,
+		},
+		{
+			name:   "PermissionWithoutAuthorization",
+			method: pb.SimpleBankAdmin_FreezeUser_FullMethodName,
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:       "Scope",
+			method:     pb.SimpleBank_GetTransfer_FullMethodName,
+			authHeader: fmt.Sprintf("%s %s", authorizationTypeBearer, scopedToken),
+			code:       codes.OK,
+		},
+		{
+			name:       "InsufficientScope",
+			method:     pb.SimpleBank_CreateTransfer_FullMethodName,
+			authHeader: fmt.Sprintf("%s %s", authorizationTypeBearer, scopedToken),
+			code:       codes.PermissionDenied,
+		},
+		{
+			// methods without a policy are refused, whoever calls them
+			name:       "UnlistedMethod",
+			method:     "/pb.SimpleBank/Unlisted",
+			authHeader: bearer(t, mockServices, tokenMaker, models.RoleAdmin),
+			code:       codes.PermissionDenied,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			if testCase.authHeader != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(authorizationHeader, testCase.authHeader))
+			}
+
+			handled := false
+			handler := func(ctx context.Context, req any) (any, error) {
+				handled = true
+				return nil, nil
+			}
+			info := &grpc.UnaryServerInfo{FullMethod: testCase.method}
+
+			_, err := server.AuthorizationInterceptor(ctx, nil, info, handler)
+			require.Equal(t, testCase.code, status.Code(err))
+			require.Equal(t, testCase.code == codes.OK, handled)
+		})
+	}
+}
+
+func TestAuthenticateInvalidToken(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	accessToken := util.RandomString(40, util.ALL)
+
+	_, err := server.authenticate(fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken), "")
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), accessToken)
+}
+
+func TestHandlersRequirePermission(t *testing.T) {
+	server, mockServices, _ := newTestServer(t)
+	mockServices.EXPECT().GetTrialBalance(gomock.Any()).Times(0)
+	mockServices.EXPECT().DepositMoney(gomock.Any()).Times(0)
+	mockServices.EXPECT().SetUserFrozen(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	handlers := map[string]func(ctx context.Context) error{
+		"GetTrialBalance": func(ctx context.Context) error {
+			_, err := server.GetTrialBalance(ctx, &pb.GetTrialBalanceRequest{})
+			return err
+		},
+		"Deposit": func(ctx context.Context) error {
+			_, err := server.Deposit(ctx, &pb.DepositRequest{AccountId: 1, Amount: 10})
+			return err
+		},
+		"FreezeUser": func(ctx context.Context) error {
+			_, err := server.FreezeUser(ctx, &pb.AdminUserActionRequest{Username: util.RandomUsername(), Reason: "fraud"})
+			return err
+		},
+	}
+
+	customer, err := token.NewPayload(util.RandomUsername(), time.Minute)
+	require.NoError(t, err)
+	customer.Role = models.RoleCustomer
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			// the handlers never serve a request the interceptors did not authorize, whoever calls them
+			require.Equal(t, codes.Unauthenticated, status.Code(handler(context.Background())))
+
+			ctx := context.WithValue(context.Background(), authorizationPayloadKey{}, customer)
+			require.Equal(t, codes.PermissionDenied, status.Code(handler(ctx)))
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package grpc_api
+
+import (
+	"Simple-Bank/config"
+	"Simple-Bank/ratelimit"
+	"Simple-Bank/token"
+	"context"
+	"errors"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"math"
+	"strconv"
+)
+
+// retryAfterHeader tells clients refused by the rate limiter how many seconds to wait before trying again
+const retryAfterHeader = "retry-after"
+
+// RateLimitInterceptor limits the requests of each client address to each method. It must run before
+// AuthorizationInterceptor, so the requests with invalid credentials are limited too.
+func (server *GrpcServer) RateLimitInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if limited := server.rateLimitAddress(info.FullMethod, server.extractMetaData(ctx).clientIP); limited != nil {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeader, retryAfterSeconds(limited)))
+		return nil, resourceExhaustedError(limited)
+	}
+
+	return handler(ctx, req)
+}
+
+// UserRateLimitInterceptor limits the requests of each user authorized by AuthorizationInterceptor, which must run
+// first, to each method, whatever their address.
+func (server *GrpcServer) UserRateLimitInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if limited := server.rateLimitUser(ctx, info.FullMethod); limited != nil {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeader, retryAfterSeconds(limited)))
+		return nil, resourceExhaustedError(limited)
+	}
+
+	return handler(ctx, req)
+}
+
+// rateLimitAddress takes a token of the bucket of clientIP on method, and returns a *ratelimit.LimitedError if
+// the bucket is empty.
+func (server *GrpcServer) rateLimitAddress(method, clientIP string) *ratelimit.LimitedError {
+	return server.rateLimit(method, ratelimit.IPSubject(clientIP))
+}
+
+// rateLimitUser takes a token of the bucket of the user of the request on method if it was authorized, and returns
+// a *ratelimit.LimitedError if the bucket is empty.
+func (server *GrpcServer) rateLimitUser(ctx context.Context, method string) *ratelimit.LimitedError {
+	payload, ok := ctx.Value(authorizationPayloadKey{}).(*token.Payload)
+	if !ok {
+		return nil
+	}
+
+	return server.rateLimit(method, ratelimit.UserSubject(payload.Username))
+}
+
+// rateLimit takes a token of the bucket of subject on method. Requests are let through if the limiter cannot
+// reach its store, so an outage of the store does not take the server down.
+func (server *GrpcServer) rateLimit(method, subject string) *ratelimit.LimitedError {
+	err := server.rateLimiter.Allow(method, subject)
+	var limited *ratelimit.LimitedError
+	if errors.As(err, &limited) {
+		return limited
+	}
+	if err != nil {
+		log.Error().Err(err).Str("method", method).Msg("cannot rate limit the request")
+	}
+
+	return nil
+}
+
+// resourceExhaustedError converts the error of a request refused by the rate limiter, with the delay before the
+// next retry as details.
+func resourceExhaustedError(limited *ratelimit.LimitedError) error {
+	statusLimited := status.New(codes.ResourceExhausted, limited.Error())
+	statusDetails, err := statusLimited.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(limited.RetryAfter)})
+	if err != nil {
+		return statusLimited.Err()
+	}
+
+	return statusDetails.Err()
+}
+
+func retryAfterSeconds(limited *ratelimit.LimitedError) string {
+	return strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds())))
+}
+
+// rateLimitRules returns the limits of the methods configured in rules.
+func rateLimitRules(rules []config.RateLimitRule) []ratelimit.Rule {
+	limits := make([]ratelimit.Rule, 0, len(rules))
+	for _, rule := range rules {
+		limits = append(limits, ratelimit.Rule{
+			Route: rule.Route,
+			Limit: ratelimit.Limit{Requests: rule.Requests, Period: rule.Period, Burst: rule.Burst},
+		})
+	}
+
+	return limits
+}
@@ -0,0 +1,86 @@
+package grpc_api
+
+import (
+	"Simple-Bank/config"
+	"Simple-Bank/db/models"
+	"Simple-Bank/pb"
+	"Simple-Bank/util"
+	"context"
+	"fmt"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimitInterceptors(t *testing.T) {
+	server, mockServices, tokenMaker := newTestServerWithConfig(t, &config.Config{
+		TokenSymmetricKey: util.RandomString(32, util.ALL),
+		RateLimits: []config.RateLimitRule{
+			{Route: pb.SimpleBank_ListSessions_FullMethodName, Requests: 1, Period: time.Minute},
+		},
+	})
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SimpleBank_ListSessions_FullMethodName}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}
+
+	// call runs the interceptors in the order of the server
+	call := func(authHeader, address string) codes.Code {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, authHeader))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(address), Port: 52000}})
+		_, err := server.RateLimitInterceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+			return server.AuthorizationInterceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+				return server.UserRateLimitInterceptor(ctx, req, info, handler)
+			})
+		})
+		return status.Code(err)
+	}
+
+	// requests with invalid credentials are limited before they are authenticated
+	invalid := fmt.Sprintf("%s %s", authorizationTypeBearer, "invalid")
+	require.Equal(t, codes.Unauthenticated, call(invalid, "10.0.0.1"))
+	require.Equal(t, codes.ResourceExhausted, call(invalid, "10.0.0.1"))
+
+	// users are limited whatever their address, and addresses whatever their user
+	authHeader := bearer(t, mockServices, tokenMaker, models.RoleCustomer)
+	require.Equal(t, codes.OK, call(authHeader, "10.0.0.2"))
+	require.Equal(t, codes.ResourceExhausted, call(authHeader, "10.0.0.3"))
+	require.Equal(t, codes.ResourceExhausted, call(bearer(t, mockServices, tokenMaker, models.RoleCustomer), "10.0.0.2"))
+	require.Equal(t, codes.OK, call(bearer(t, mockServices, tokenMaker, models.RoleCustomer), "10.0.0.4"))
+}
+
+func TestGatewayHandlerRateLimit(t *testing.T) {
+	server, _, _ := newTestServerWithConfig(t, &config.Config{
+		TokenSymmetricKey: util.RandomString(32, util.ALL),
+		RateLimits: []config.RateLimitRule{
+			{Route: pb.SimpleBank_ListSessions_FullMethodName, Requests: 1, Period: time.Minute},
+		},
+	})
+	mux := runtime.NewServeMux()
+	require.NoError(t, pb.RegisterSimpleBankHandlerServer(context.Background(), mux, server))
+	handler := server.GatewayHandler(mux)
+
+	serve := func() *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/v1/sessions", nil)
+		request.RemoteAddr = "10.0.0.1:40000"
+		request.Header.Set("Authorization", fmt.Sprintf("%s %s", authorizationTypeBearer, "invalid"))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// requests with invalid credentials are limited before they are authenticated
+	require.Equal(t, http.StatusUnauthorized, serve().Code)
+	recorder := serve()
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.NotEmpty(t, recorder.Header().Get(retryAfterHeader))
+}
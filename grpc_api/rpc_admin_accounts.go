@@ -0,0 +1,85 @@
+package grpc_api
+
+import (
+	"Simple-Bank/db/services"
+	"Simple-Bank/pb"
+	"context"
+)
+
+// ListAccountEntries returns the entries of any account.
+func (server *GrpcServer) ListAccountEntries(context context.Context, req *pb.ListAccountEntriesRequest) (*pb.ListAccountEntriesResponse, error) {
+	action, err := server.adminAction(context, req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListAccountEntriesRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	entries, err := server.dbServices.ListAccountEntries(action, services.ListEntriesRequest{
+		AccountID:  req.GetAccountId(),
+		PageSize:   int(req.GetPageSize()),
+		PageNumber: int(req.GetPageId()),
+	})
+	if err != nil {
+		return nil, adminError(err, "list entries")
+	}
+
+	response := &pb.ListAccountEntriesResponse{}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, convertEntry(entry))
+	}
+
+	return response, nil
+}
+
+// FreezeAccount freezes a customer account, which cannot send or receive money anymore.
+func (server *GrpcServer) FreezeAccount(context context.Context, req *pb.AdminAccountActionRequest) (*pb.AdminAccountActionResponse, error) {
+	return server.setAccountFrozen(context, req, true)
+}
+
+// UnfreezeAccount unfreezes a customer account.
+func (server *GrpcServer) UnfreezeAccount(context context.Context, req *pb.AdminAccountActionRequest) (*pb.AdminAccountActionResponse, error) {
+	return server.setAccountFrozen(context, req, false)
+}
+
+func (server *GrpcServer) setAccountFrozen(context context.Context, req *pb.AdminAccountActionRequest, frozen bool) (*pb.AdminAccountActionResponse, error) {
+	action, err := server.adminAction(context, req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateAdminAccountActionRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.dbServices.SetAccountFrozen(action, req.GetAccountId(), frozen)
+	if err != nil {
+		return nil, adminError(err, "update account")
+	}
+
+	return &pb.AdminAccountActionResponse{Account: convertAccount(account)}, nil
+}
+
+// AdjustBalance requests a manual adjustment to a customer account, posted once another staff member approves it.
+func (server *GrpcServer) AdjustBalance(context context.Context, req *pb.AdjustBalanceRequest) (*pb.AdjustBalanceResponse, error) {
+	action, err := server.adminAction(context, req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateAdjustBalanceRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	approval, err := server.approvals.RequestAdjustment(action.Operator, action.Reason, req.GetAccountId(), req.GetAmount())
+	if err != nil {
+		return nil, adminError(err, "request adjustment")
+	}
+
+	return &pb.AdjustBalanceResponse{Approval: convertApproval(approval)}, nil
+}
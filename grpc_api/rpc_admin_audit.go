@@ -0,0 +1,38 @@
+package grpc_api
+
+import (
+	"Simple-Bank/db/services"
+	"Simple-Bank/pb"
+	"context"
+)
+
+// ListAuditEvents returns the audit trail of the back office.
+func (server *GrpcServer) ListAuditEvents(context context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	action, err := server.adminAction(context, req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListAuditEventsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	events, err := server.dbServices.ListAuditEvents(action, services.ListAuditEventsRequest{
+		Operator:   req.GetOperator(),
+		TargetType: req.GetTargetType(),
+		TargetID:   req.GetTargetId(),
+		PageSize:   int(req.GetPageSize()),
+		PageNumber: int(req.GetPageId()),
+	})
+	if err != nil {
+		return nil, adminError(err, "list audit events")
+	}
+
+	response := &pb.ListAuditEventsResponse{}
+	for _, event := range events {
+		response.Events = append(response.Events, convertAuditEvent(event))
+	}
+
+	return response, nil
+}
@@ -0,0 +1,35 @@
+package grpc_api
+
+import (
+	"Simple-Bank/db/services"
+	"Simple-Bank/pb"
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+// RevokeToken denies any token of any user, e.g. because it was stolen.
+func (server *GrpcServer) RevokeToken(context context.Context, req *pb.AdminRevokeTokenRequest) (*pb.AdminRevokeTokenResponse, error) {
+	action, err := server.adminAction(context, req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateAdminRevokeTokenRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	// the token cannot be decoded, so it is denied for as long as any token can live
+	lifetime := max(server.config.TokenAccessTokenDuration, server.config.TokenRefreshTokenDuration)
+	if err := server.dbServices.AdminRevokeToken(action, services.RevokeTokenRequest{
+		TokenID:   uuid.MustParse(req.GetTokenId()),
+		Username:  req.GetUsername(),
+		ExpiresAt: time.Now().Add(lifetime),
+	}); err != nil {
+		return nil, adminError(err, "revoke token")
+	}
+	server.revocations.Reset()
+
+	return &pb.AdminRevokeTokenResponse{}, nil
+}
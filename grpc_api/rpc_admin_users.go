@@ -0,0 +1,170 @@
+package grpc_api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/db/services"
+	"Simple-Bank/pb"
+	"context"
+)
+
+// adminAction returns the operator of the back office and the reason of their action, written to the audit trail.
+func (server *GrpcServer) adminAction(context context.Context, reason string) (services.AdminAction, error) {
+	payload, err := permittedPayload(context, auth.PermissionBackOffice)
+	if err != nil {
+		return services.AdminAction{}, err
+	}
+
+	return services.AdminAction{Operator: payload.Username, Reason: reason}, nil
+}
+
+// SearchUsers returns the users whose username or email contains the query.
+func (server *GrpcServer) SearchUsers(context context.Context, req *pb.SearchUsersRequest) (*pb.SearchUsersResponse, error) {
+	action, err := server.adminAction(context, req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateSearchUsersRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	users, err := server.dbServices.SearchUsers(action, services.SearchUsersRequest{
+		Query:      req.GetQuery(),
+		PageSize:   int(req.GetPageSize()),
+		PageNumber: int(req.GetPageId()),
+	})
+	if err != nil {
+		return nil, adminError(err, "search users")
+	}
+
+	response := &pb.SearchUsersResponse{}
+	for _, user := range users {
+		response.Users = append(response.Users, convertAdminUser(user))
+	}
+
+	return response, nil
+}
+
+// ListUserAccounts returns every account of any user.
+func (server *GrpcServer) ListUserAccounts(context context.Context, req *pb.ListUserAccountsRequest) (*pb.ListUserAccountsResponse, error) {
+	action, err := server.adminAction(context, req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateAdminUserRequest(req.GetUsername(), req.GetReason())
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	accounts, err := server.dbServices.ListUserAccounts(action, req.GetUsername())
+	if err != nil {
+		return nil, adminError(err, "list accounts")
+	}
+
+	response := &pb.ListUserAccountsResponse{}
+	for _, account := range accounts {
+		response.Accounts = append(response.Accounts, convertAccount(account))
+	}
+
+	return response, nil
+}
+
+// ListUserSessions returns every session of any user, including blocked and expired ones.
+func (server *GrpcServer) ListUserSessions(context context.Context, req *pb.ListUserSessionsRequest) (*pb.ListUserSessionsResponse, error) {
+	action, err := server.adminAction(context, req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateAdminUserRequest(req.GetUsername(), req.GetReason())
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	sessions, err := server.dbServices.ListUserSessions(action, req.GetUsername())
+	if err != nil {
+		return nil, adminError(err, "list sessions")
+	}
+
+	response := &pb.ListUserSessionsResponse{}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, convertAdminSession(session))
+	}
+
+	return response, nil
+}
+
+// FreezeUser freezes a user, who cannot log in anymore and whose sessions are blocked.
+func (server *GrpcServer) FreezeUser(context context.Context, req *pb.AdminUserActionRequest) (*pb.AdminUserActionResponse, error) {
+	return server.setUserFrozen(context, req, true)
+}
+
+// UnfreezeUser unfreezes a user, who can log in again.
+func (server *GrpcServer) UnfreezeUser(context context.Context, req *pb.AdminUserActionRequest) (*pb.AdminUserActionResponse, error) {
+	return server.setUserFrozen(context, req, false)
+}
+
+func (server *GrpcServer) setUserFrozen(context context.Context, req *pb.AdminUserActionRequest, frozen bool) (*pb.AdminUserActionResponse, error) {
+	action, err := server.adminAction(context, req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateAdminUserRequest(req.GetUsername(), req.GetReason())
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := server.dbServices.SetUserFrozen(action, req.GetUsername(), frozen)
+	if err != nil {
+		return nil, adminError(err, "update user")
+	}
+	if frozen {
+		server.revocations.Reset()
+	}
+
+	return &pb.AdminUserActionResponse{User: convertAdminUser(user)}, nil
+}
+
+// ForcePasswordReset requires a user to change their password and logs them out of every session.
+func (server *GrpcServer) ForcePasswordReset(context context.Context, req *pb.AdminUserActionRequest) (*pb.AdminUserActionResponse, error) {
+	action, err := server.adminAction(context, req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateAdminUserRequest(req.GetUsername(), req.GetReason())
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := server.dbServices.ForcePasswordReset(action, req.GetUsername())
+	if err != nil {
+		return nil, adminError(err, "update user")
+	}
+	server.revocations.Reset()
+
+	return &pb.AdminUserActionResponse{User: convertAdminUser(user)}, nil
+}
+
+// UnlockUser forgets the failed logins of a user locked out by them, who can log in again right away.
+func (server *GrpcServer) UnlockUser(context context.Context, req *pb.AdminUserActionRequest) (*pb.AdminUserActionResponse, error) {
+	action, err := server.adminAction(context, req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateAdminUserRequest(req.GetUsername(), req.GetReason())
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := server.dbServices.UnlockUser(action, req.GetUsername())
+	if err != nil {
+		return nil, adminError(err, "unlock user")
+	}
+
+	return &pb.AdminUserActionResponse{User: convertAdminUser(user)}, nil
+}
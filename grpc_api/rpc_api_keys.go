@@ -0,0 +1,87 @@
+package grpc_api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/pb"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"time"
+)
+
+// CreateAPIKey creates an api key for the user. The key is only returned in this response.
+func (server *GrpcServer) CreateAPIKey(context context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateCreateAPIKeyRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		expiration := req.GetExpiresAt().AsTime()
+		expiresAt = &expiration
+	}
+
+	rawKey, key, err := auth.NewAPIKey(payload.Username, req.GetName(), req.GetScopes(), req.GetAllowedIps(), expiresAt)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create api key")
+	}
+
+	key, err = server.dbServices.CreateAPIKey(key)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create api key")
+	}
+
+	return &pb.CreateAPIKeyResponse{ApiKey: convertAPIKey(key), Key: rawKey}, nil
+}
+
+// ListAPIKeys returns the api keys of the user that have not been revoked.
+func (server *GrpcServer) ListAPIKeys(context context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	keys, err := server.dbServices.ListAPIKeys(payload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list api keys")
+	}
+
+	response := &pb.ListAPIKeysResponse{}
+	for _, key := range keys {
+		response.ApiKeys = append(response.ApiKeys, convertAPIKey(key))
+	}
+
+	return response, nil
+}
+
+// RevokeAPIKey revokes an api key of the user, which cannot be used anymore.
+func (server *GrpcServer) RevokeAPIKey(context context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateRevokeAPIKeyRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	key, err := server.dbServices.RevokeAPIKey(payload.Username, uuid.MustParse(req.GetId()))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "api key not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to revoke api key")
+	}
+
+	return &pb.RevokeAPIKeyResponse{ApiKey: convertAPIKey(key)}, nil
+}
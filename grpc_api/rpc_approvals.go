@@ -0,0 +1,108 @@
+package grpc_api
+
+import (
+	"Simple-Bank/approvals"
+	"Simple-Bank/auth"
+	"Simple-Bank/db/services"
+	"Simple-Bank/pb"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListApprovals lists the operations of every user to users allowed to approve operations,
+// and the operations requested by the user to other users.
+func (server *GrpcServer) ListApprovals(context context.Context, req *pb.ListApprovalsRequest) (*pb.ListApprovalsResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateListApprovalsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	listRequest := services.ListApprovalsRequest{
+		Status:     req.GetStatus(),
+		PageSize:   int(req.GetPageSize()),
+		PageNumber: int(req.GetPageId()),
+	}
+	if !auth.HasPermission(payload, auth.PermissionApproveOperations) && !auth.HasPermission(payload, auth.PermissionBackOffice) {
+		listRequest.RequestedBy = payload.Username
+	}
+
+	list, err := server.dbServices.ListApprovals(listRequest)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list approvals")
+	}
+
+	response := &pb.ListApprovalsResponse{}
+	for _, approval := range list {
+		response.Approvals = append(response.Approvals, convertApproval(approval))
+	}
+
+	return response, nil
+}
+
+// GetApproval returns an operation to its requester and to the users allowed to decide on it.
+func (server *GrpcServer) GetApproval(context context.Context, req *pb.GetApprovalRequest) (*pb.GetApprovalResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateGetApprovalRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	approval, err := server.dbServices.GetApproval(req.GetId())
+	if err != nil {
+		return nil, approvalError(err, "get approval")
+	}
+	if approval.RequestedBy != payload.Username && !approvals.CanDecide(payload, approval) {
+		return nil, status.Errorf(codes.PermissionDenied, "cannot see the operations of other users")
+	}
+
+	return &pb.GetApprovalResponse{Approval: convertApproval(approval)}, nil
+}
+
+// ApproveOperation approves an operation requested by another user and executes it.
+func (server *GrpcServer) ApproveOperation(context context.Context, req *pb.DecideApprovalRequest) (*pb.DecideApprovalResponse, error) {
+	return server.decideOperation(context, req, true)
+}
+
+// RejectOperation rejects an operation requested by another user.
+func (server *GrpcServer) RejectOperation(context context.Context, req *pb.DecideApprovalRequest) (*pb.DecideApprovalResponse, error) {
+	return server.decideOperation(context, req, false)
+}
+
+func (server *GrpcServer) decideOperation(context context.Context, req *pb.DecideApprovalRequest, approve bool) (*pb.DecideApprovalResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateDecideApprovalRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	decide := server.approvals.Reject
+	if approve {
+		decide = server.approvals.Approve
+	}
+
+	approval, err := decide(payload, req.GetId(), req.GetReason())
+	if err != nil {
+		if errors.Is(err, approvals.ErrExecutionFailed) {
+			// the failed approval has been persisted; return it so the client can see why it failed
+			return &pb.DecideApprovalResponse{Approval: convertApproval(approval)}, nil
+		}
+		return nil, approvalError(err, "decide on operation")
+	}
+
+	return &pb.DecideApprovalResponse{Approval: convertApproval(approval)}, nil
+}
@@ -0,0 +1,33 @@
+package grpc_api
+
+import (
+	"Simple-Bank/pb"
+	"Simple-Bank/stepup"
+	"context"
+)
+
+// CloseAccount requests the closure of an account of the user, which is closed once another user approves it.
+func (server *GrpcServer) CloseAccount(context context.Context, req *pb.CloseAccountRequest) (*pb.CloseAccountResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateCloseAccountRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if server.stepUp.RequiresStepUp(stepup.OperationCloseAccount) {
+		if err := server.checkStepUp(context, payload, stepup.OperationCloseAccount); err != nil {
+			return nil, err
+		}
+	}
+
+	approval, err := server.approvals.RequestAccountClosure(payload.Username, req.GetReason(), req.GetAccountId())
+	if err != nil {
+		return nil, approvalError(err, "request account closure")
+	}
+
+	return &pb.CloseAccountResponse{Approval: convertApproval(approval)}, nil
+}
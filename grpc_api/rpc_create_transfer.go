@@ -0,0 +1,83 @@
+package grpc_api
+
+import (
+	"Simple-Bank/db/services"
+	"Simple-Bank/pb"
+	"Simple-Bank/stepup"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// foreignKeyViolationCode is the postgres error code of a foreign key violation
+const foreignKeyViolationCode = "23503"
+
+// CreateTransfer transfers money from an account of the user to another account.
+// A transfer that could not be completed is persisted as failed and refused with a failed precondition error
+// carrying the transfer, and a transfer reaching the approval threshold is held until another user approves it.
+func (server *GrpcServer) CreateTransfer(context context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateCreateTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if err := server.checkEmailVerified(payload.Username); err != nil {
+		return nil, err
+	}
+
+	// large transfers require a recent re-authentication, even before they wait for approval
+	if server.stepUp.TransferRequiresStepUp(req.GetAmount()) {
+		if err := server.checkStepUp(context, payload, stepup.OperationTransfer); err != nil {
+			return nil, err
+		}
+	}
+
+	transferRequest := services.TransferRequest{
+		Owner:         payload.Username,
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+	}
+
+	if server.approvals.TransferRequiresApproval(req.GetAmount()) {
+		approval, err := server.approvals.RequestTransfer(transferRequest)
+		if err != nil {
+			if errors.Is(err, services.ErrNotAccountOwner) {
+				return nil, status.Errorf(codes.PermissionDenied, "cannot transfer money from other users` accounts")
+			}
+			return nil, approvalError(err, "request transfer")
+		}
+
+		return &pb.CreateTransferResponse{Approval: convertApproval(approval)}, nil
+	}
+
+	transfer, err := server.dbServices.Transfer(transferRequest)
+	if err != nil {
+		if errors.Is(err, services.ErrTransferFailed) {
+			return nil, transferFailedError(transfer)
+		}
+		if errors.Is(err, services.ErrNotAccountOwner) {
+			return nil, status.Errorf(codes.PermissionDenied, "cannot transfer money from other users` accounts")
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == foreignKeyViolationCode {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to transfer money")
+	}
+
+	response := &pb.CreateTransferResponse{Transfer: convertTransfer(transfer)}
+
+	return response, nil
+}
@@ -0,0 +1,49 @@
+package grpc_api
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/pb"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"context"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestCreateTransferFailed(t *testing.T) {
+	server, mockServices, _ := newTestServer(t)
+	payload, err := token.NewPayload(util.RandomUsername(), time.Minute)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), authorizationPayloadKey{}, payload)
+
+	failedTransfer := models.Transfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        10,
+		Status:        models.TransferStatusFailed,
+		StatusReason:  "account is frozen",
+	}
+	mockServices.EXPECT().
+		Transfer(gomock.Any()).
+		Times(1).
+		Return(failedTransfer, services.ErrTransferFailed)
+
+	response, err := server.CreateTransfer(ctx, &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: 10})
+	require.Nil(t, response)
+
+	statusFailed, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.FailedPrecondition, statusFailed.Code())
+	require.Contains(t, statusFailed.Message(), failedTransfer.StatusReason)
+	require.Len(t, statusFailed.Details(), 1)
+	transfer, ok := statusFailed.Details()[0].(*pb.Transfer)
+	require.True(t, ok)
+	require.Equal(t, failedTransfer.ID, transfer.GetId())
+	require.Equal(t, failedTransfer.StatusReason, transfer.GetStatusReason())
+}
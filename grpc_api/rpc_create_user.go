@@ -1,11 +1,14 @@
 package grpc_api
 
 import (
+	"Simple-Bank/passwords"
 	"Simple-Bank/pb"
 	"Simple-Bank/requests"
 	"context"
 	"errors"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -16,6 +19,12 @@ func (server *GrpcServer) CreateUser(context context.Context, req *pb.CreateUser
 		err := invalidArgumentError(violations)
 		return nil, err
 	}
+	if err := server.passwordPolicy.Validate(req.GetPassword()); err != nil {
+		if errors.Is(err, passwords.ErrPasswordRejected) {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("password", err)})
+		}
+		return nil, status.Errorf(codes.Internal, "failed to check password")
+	}
 
 	newUser, err := server.dbServices.CreateUser(requests.CreateUserRequest{
 		Username: req.GetUsername(),
@@ -36,6 +45,11 @@ func (server *GrpcServer) CreateUser(context context.Context, req *pb.CreateUser
 		return nil, status.Errorf(codes.Internal, "failed to create user")
 	}
 
+	// the user is created even if the email cannot be sent, they can ask for another one
+	if err := server.emails.Send(newUser); err != nil {
+		log.Error().Err(err).Str("username", newUser.Username).Msg("cannot send the email verification")
+	}
+
 	response := &pb.CreateUserResponse{User: convert(newUser)}
 
 	return response, nil
@@ -0,0 +1,48 @@
+package grpc_api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/db/services"
+	"Simple-Bank/pb"
+	"Simple-Bank/requests"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// Deposit deposits money into any account. Only users allowed to deposit, e.g. tellers, can deposit.
+func (server *GrpcServer) Deposit(context context.Context, req *pb.DepositRequest) (*pb.DepositResponse, error) {
+	if _, err := permittedPayload(context, auth.PermissionDeposit); err != nil {
+		return nil, err
+	}
+
+	violations := validateDepositRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	entry, err := server.dbServices.DepositMoney(requests.DepositRequest{
+		AccountID: req.GetAccountId(),
+		Amount:    req.GetAmount(),
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrAccountFrozen) {
+			return nil, status.Errorf(codes.FailedPrecondition, "account is frozen")
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == foreignKeyViolationCode {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to deposit money")
+	}
+
+	response := &pb.DepositResponse{Entry: convertEntry(entry)}
+
+	return response, nil
+}
@@ -0,0 +1,62 @@
+package grpc_api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/pb"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// GetTransfer returns a transfer and the history of its status.
+// Only the owners of its source and destination accounts, and users allowed to read any transfer, can get a transfer.
+func (server *GrpcServer) GetTransfer(context context.Context, req *pb.GetTransferRequest) (*pb.GetTransferResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateGetTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	transfer, err := server.dbServices.GetTransfer(req.GetId())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "transfer not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get transfer")
+	}
+
+	isParty := auth.HasPermission(payload, auth.PermissionReadTransfers)
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		if isParty {
+			break
+		}
+		account, err := server.dbServices.GetAccount(accountID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.Internal, "failed to get transfer")
+		}
+		if err == nil && account.Owner == payload.Username {
+			isParty = true
+		}
+	}
+	if !isParty {
+		return nil, status.Errorf(codes.PermissionDenied, "cannot get transfers of other users")
+	}
+
+	history, err := server.dbServices.ListTransferStatusHistory(transfer.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get transfer history")
+	}
+
+	response := &pb.GetTransferResponse{Transfer: convertTransfer(transfer)}
+	for _, change := range history {
+		response.History = append(response.History, convertTransferStatusChange(change))
+	}
+
+	return response, nil
+}
@@ -0,0 +1,30 @@
+package grpc_api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/pb"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// GetTrialBalance returns the trial balance as of the requested time, or as of now if it is not provided.
+// Only users allowed to read the reports, e.g. auditors, can get it.
+func (server *GrpcServer) GetTrialBalance(context context.Context, req *pb.GetTrialBalanceRequest) (*pb.GetTrialBalanceResponse, error) {
+	if _, err := permittedPayload(context, auth.PermissionReadReports); err != nil {
+		return nil, err
+	}
+
+	at := time.Now()
+	if req.GetAt() != nil {
+		at = req.GetAt().AsTime()
+	}
+
+	trialBalance, err := server.dbServices.GetTrialBalance(at)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get trial balance")
+	}
+
+	return convertTrialBalance(trialBalance), nil
+}
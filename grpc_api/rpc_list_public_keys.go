@@ -0,0 +1,17 @@
+package grpc_api
+
+import (
+	"Simple-Bank/pb"
+	"context"
+)
+
+// ListPublicKeys returns the public keys verifying access tokens, so other services can verify them
+// without holding a secret. It requires no authentication.
+func (server *GrpcServer) ListPublicKeys(context context.Context, req *pb.ListPublicKeysRequest) (*pb.ListPublicKeysResponse, error) {
+	response := &pb.ListPublicKeysResponse{}
+	for _, key := range server.tokenMaker.PublicKeys() {
+		response.Keys = append(response.Keys, convertPublicKey(key))
+	}
+
+	return response, nil
+}
@@ -0,0 +1,28 @@
+package grpc_api
+
+import (
+	"Simple-Bank/pb"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListSessions returns the active sessions of the user.
+func (server *GrpcServer) ListSessions(context context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	sessions, err := server.dbServices.ListActiveSessions(payload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list sessions")
+	}
+
+	response := &pb.ListSessionsResponse{}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, convertSession(session, payload.SessionID))
+	}
+
+	return response, nil
+}
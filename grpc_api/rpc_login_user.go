@@ -1,17 +1,16 @@
 package grpc_api
 
 import (
+	"Simple-Bank/auth"
 	"Simple-Bank/db/models"
+	"Simple-Bank/login"
+	"Simple-Bank/passwordless"
 	"Simple-Bank/pb"
-	"Simple-Bank/util"
 	"context"
-	"database/sql"
 	"errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
-	"gorm.io/gorm"
-	"time"
 )
 
 func (server *GrpcServer) LoginUser(context context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
@@ -20,58 +19,67 @@ func (server *GrpcServer) LoginUser(context context.Context, req *pb.LoginUserRe
 		return nil, invalidArgumentError(violations)
 	}
 
-	user, err := server.dbServices.GetUser(req.GetUsername())
+	user, err := server.logins.Authenticate(req.GetUsername(), req.GetPassword(), server.extractMetaData(context).clientIP)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, status.Errorf(codes.NotFound, "user not found")
+		switch {
+		case errors.Is(err, auth.ErrLoginLocked):
+			return nil, status.Errorf(codes.ResourceExhausted, "%s", err)
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+		default:
+			return nil, status.Errorf(codes.Internal, "failed to log in")
 		}
-		return nil, status.Errorf(codes.Internal, "failed to find user")
 	}
 
-	if err := util.CheckPassword(req.Password, user.HashedPassword); err != nil {
-		return nil, status.Errorf(codes.NotFound, "incorrect password")
-	}
+	return server.completeLogin(context, user, login.MethodPassword)
+}
 
-	accessToken, accessTokenPayload, err := server.tokenMaker.CreateToken(
-		req.GetUsername(),
-		server.config.TokenAccessTokenDuration,
-	)
+// completeLogin logs in a user who proved who they are with method: it starts a two-factor challenge if they
+// enabled it, delivers a login code if their device must be verified, or starts a session otherwise.
+func (server *GrpcServer) completeLogin(context context.Context, user models.User, method login.Method) (*pb.LoginUserResponse, error) {
+	result, err := server.login.Complete(user, server.loginClient(context), method)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create access token")
+		switch {
+		case errors.Is(err, login.ErrUserFrozen):
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		case errors.Is(err, passwordless.ErrTooManyRequests):
+			return nil, status.Errorf(codes.ResourceExhausted, "%s", err)
+		default:
+			return nil, status.Errorf(codes.Internal, "failed to log in")
+		}
 	}
 
-	refreshToken, refreshTokenPayload, err := server.tokenMaker.CreateToken(
-		req.Username,
-		server.config.TokenRefreshTokenDuration)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create refresh token")
+	switch {
+	case result.MFAChallengeToken != "":
+		response := &pb.LoginUserResponse{
+			MfaRequired:           true,
+			MfaChallengeToken:     result.MFAChallengeToken,
+			MfaChallengeExpiresAt: timestamppb.New(result.MFAChallengeExpiresAt),
+		}
+		return response, nil
+	case result.DeviceVerificationRequired:
+		return &pb.LoginUserResponse{DeviceVerificationRequired: true}, nil
+	default:
+		return newLoginResponse(user, *result.Tokens), nil
 	}
+}
 
+// loginClient returns the device a request is made from. Its address is only taken from the X-Forwarded-For
+// headers of the trusted proxies of the server.
+func (server *GrpcServer) loginClient(context context.Context) login.Client {
 	metadata := server.extractMetaData(context)
-	session := models.Session{
-		ID:           refreshTokenPayload.ID,
-		Username:     req.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    metadata.userAgent,
-		ClientIP:     metadata.clientIP,
-		IsBlocked:    false,
-		CreatedAt:    time.Now().UTC(),
-		ExpiresAt:    time.Now().UTC(),
-		DeletedAt:    gorm.DeletedAt{},
-	}
-	session, err = server.dbServices.CreateSession(session)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create session")
-	}
+	return login.Client{UserAgent: metadata.userAgent, ClientIP: metadata.clientIP}
+}
 
-	response := &pb.LoginUserResponse{
+// newLoginResponse returns the tokens of the session a user who fully logged in started.
+func newLoginResponse(user models.User, tokens login.Tokens) *pb.LoginUserResponse {
+	return &pb.LoginUserResponse{
 		User:                  convert(user),
-		AccessToken:           accessToken,
-		AccessTokenExpiresAt:  timestamppb.New(accessTokenPayload.ExpiredAt),
-		RefreshToken:          refreshToken,
-		RefreshTokenExpiresAt: timestamppb.New(refreshTokenPayload.ExpiredAt),
-		SessionId:             session.ID.String(),
+		AccessToken:           tokens.AccessToken,
+		AccessTokenExpiresAt:  timestamppb.New(tokens.AccessTokenPayload.ExpiredAt),
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(tokens.RefreshTokenPayload.ExpiredAt),
+		SessionId:             tokens.Session.ID.String(),
+		PasswordResetRequired: user.PasswordResetRequired,
 	}
-
-	return response, nil
 }
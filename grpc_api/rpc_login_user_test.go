@@ -0,0 +1,56 @@
+package grpc_api
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/pb"
+	"Simple-Bank/util"
+	"context"
+	"fmt"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoginUserForgedForwardedAddress(t *testing.T) {
+	server, mockServices, _ := newTestServer(t)
+	mux := runtime.NewServeMux()
+	require.NoError(t, pb.RegisterSimpleBankHandlerServer(context.Background(), mux, server))
+
+	password := util.RandomPassword()
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+	user := models.User{Username: util.RandomUsername(), HashedPassword: hashedPassword, Role: models.RoleCustomer}
+
+	// the failures count against the address the request came from, not the one it claims to come from
+	mockServices.EXPECT().
+		GetLoginFailure(models.LoginFailureKindUsername, user.Username).
+		Times(1).
+		Return(models.LoginFailure{}, gorm.ErrRecordNotFound)
+	mockServices.EXPECT().
+		GetLoginFailure(models.LoginFailureKindIP, "203.0.113.7").
+		Times(1).
+		Return(models.LoginFailure{}, gorm.ErrRecordNotFound)
+	mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+	mockServices.EXPECT().
+		RecordLoginFailure(models.LoginFailureKindUsername, user.Username, gomock.Any()).
+		Times(1).
+		Return(models.LoginFailure{Failures: 1}, nil)
+	mockServices.EXPECT().
+		RecordLoginFailure(models.LoginFailureKindIP, "203.0.113.7", gomock.Any()).
+		Times(1).
+		Return(models.LoginFailure{Failures: 1}, nil)
+
+	body := fmt.Sprintf(`{"username": %q, "password": %q}`, user.Username, "wrong "+password)
+	request := httptest.NewRequest(http.MethodPost, "/v1/login_user", strings.NewReader(body))
+	request.RemoteAddr = "203.0.113.7:40000"
+	request.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	recorder := httptest.NewRecorder()
+	server.GatewayHandler(mux).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
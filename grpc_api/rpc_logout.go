@@ -0,0 +1,51 @@
+package grpc_api
+
+import (
+	"Simple-Bank/pb"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// Logout blocks the session of the request.
+func (server *GrpcServer) Logout(context context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+	if payload.SessionID == uuid.Nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "access token is not bound to a session")
+	}
+
+	if _, err := server.dbServices.RevokeSession(payload.Username, payload.SessionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to logout")
+	}
+	server.revocations.Reset()
+
+	return &pb.LogoutResponse{}, nil
+}
+
+// LogoutOtherSessions blocks every session of the user except the one of the request.
+func (server *GrpcServer) LogoutOtherSessions(context context.Context, req *pb.LogoutOtherSessionsRequest) (*pb.LogoutOtherSessionsResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+	if payload.SessionID == uuid.Nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "access token is not bound to a session")
+	}
+
+	revoked, err := server.dbServices.RevokeOtherSessions(payload.Username, payload.SessionID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to logout other sessions")
+	}
+	server.revocations.Reset()
+
+	return &pb.LogoutOtherSessionsResponse{RevokedSessions: revoked}, nil
+}
@@ -0,0 +1,123 @@
+package grpc_api
+
+import (
+	"Simple-Bank/db/services"
+	"Simple-Bank/mfa"
+	"Simple-Bank/pb"
+	"context"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerifyLoginMFA completes the login of a user with two-factor authentication, exchanging the challenge returned
+// by LoginUser and a code of the authenticator app of the user, or a recovery code, for tokens and a session.
+func (server *GrpcServer) VerifyLoginMFA(context context.Context, req *pb.VerifyLoginMFARequest) (*pb.LoginUserResponse, error) {
+	violations := validateVerifyLoginMFARequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	username, err := server.mfa.CompleteChallenge(req.GetMfaChallengeToken(), req.GetCode())
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidChallenge) || errors.Is(err, mfa.ErrInvalidCode) || errors.Is(err, mfa.ErrTooManyAttempts) {
+			return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to verify code")
+	}
+
+	user, err := server.dbServices.GetUser(username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+	// the user may have been frozen since the password was checked
+	if user.FrozenAt != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "user is frozen")
+	}
+
+	tokens, err := server.login.StartSession(user, server.loginClient(context))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create session")
+	}
+
+	return newLoginResponse(user, tokens), nil
+}
+
+// EnrollTOTP generates a new secret for the authenticator app of the user. Two-factor authentication is only
+// enabled once the user confirms the enrollment with a code.
+func (server *GrpcServer) EnrollTOTP(context context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	enrollment, err := server.mfa.Enroll(payload.Username)
+	if err != nil {
+		if errors.Is(err, services.ErrTOTPAlreadyEnabled) {
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to enroll authenticator app")
+	}
+
+	response := &pb.EnrollTOTPResponse{Secret: enrollment.Secret, OtpauthUri: enrollment.URI}
+
+	return response, nil
+}
+
+// ConfirmTOTP enables the two-factor authentication of the user with a first code of their authenticator app,
+// and returns their recovery codes.
+func (server *GrpcServer) ConfirmTOTP(context context.Context, req *pb.ConfirmTOTPRequest) (*pb.ConfirmTOTPResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	if err := validateMFACode(req.GetCode()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("code", err)})
+	}
+
+	recoveryCodes, err := server.mfa.Confirm(payload.Username, req.GetCode())
+	if err != nil {
+		switch {
+		case errors.Is(err, mfa.ErrNotEnabled):
+			return nil, status.Errorf(codes.NotFound, "%s", err)
+		case errors.Is(err, services.ErrTOTPAlreadyEnabled):
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		case errors.Is(err, mfa.ErrInvalidCode):
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		default:
+			return nil, status.Errorf(codes.Internal, "failed to confirm authenticator app")
+		}
+	}
+
+	response := &pb.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}
+
+	return response, nil
+}
+
+// DisableTOTP disables the two-factor authentication of the user, who must prove it with a code of their
+// authenticator app or a recovery code.
+func (server *GrpcServer) DisableTOTP(context context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	if err := validateMFACode(req.GetCode()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("code", err)})
+	}
+
+	if err := server.mfa.Disable(payload.Username, req.GetCode()); err != nil {
+		switch {
+		case errors.Is(err, mfa.ErrNotEnabled):
+			return nil, status.Errorf(codes.NotFound, "%s", err)
+		case errors.Is(err, mfa.ErrInvalidCode):
+			return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+		default:
+			return nil, status.Errorf(codes.Internal, "failed to disable two-factor authentication")
+		}
+	}
+
+	return &pb.DisableTOTPResponse{}, nil
+}
@@ -0,0 +1,55 @@
+package grpc_api
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/login"
+	"Simple-Bank/passwordless"
+	"Simple-Bank/pb"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestLoginCode delivers a login code and a magic link to the user with the given username or email. It
+// answers alike whether or not the user exists, so it does not reveal which users are registered.
+func (server *GrpcServer) RequestLoginCode(context context.Context, req *pb.RequestLoginCodeRequest) (*pb.RequestLoginCodeResponse, error) {
+	violations := validateRequestLoginCodeRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if err := server.passwordless.Request(req.GetIdentifier(), server.extractMetaData(context).clientIP); err != nil {
+		if errors.Is(err, passwordless.ErrTooManyRequests) {
+			return nil, status.Errorf(codes.ResourceExhausted, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to request a login code")
+	}
+
+	return &pb.RequestLoginCodeResponse{}, nil
+}
+
+// VerifyLoginCode logs in a user with a login code or a magic link delivered by RequestLoginCode, like LoginUser
+// does with a password.
+func (server *GrpcServer) VerifyLoginCode(context context.Context, req *pb.VerifyLoginCodeRequest) (*pb.LoginUserResponse, error) {
+	violations := validateVerifyLoginCodeRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	var user models.User
+	var err error
+	if req.GetToken() != "" {
+		user, err = server.passwordless.VerifyLink(req.GetToken())
+	} else {
+		user, err = server.passwordless.VerifyCode(req.GetIdentifier(), req.GetCode())
+	}
+	if err != nil {
+		if errors.Is(err, passwordless.ErrInvalidCode) {
+			return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to log in")
+	}
+
+	return server.completeLogin(context, user, login.MethodLoginCode)
+}
@@ -0,0 +1,90 @@
+package grpc_api
+
+import (
+	"Simple-Bank/passwords"
+	"Simple-Bank/pb"
+	"context"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ForgotPassword emails a password reset token to the user with the given email. It answers alike whether or not
+// a user has the email, so it does not reveal which emails are registered.
+func (server *GrpcServer) ForgotPassword(context context.Context, req *pb.ForgotPasswordRequest) (*pb.ForgotPasswordResponse, error) {
+	violations := validateForgotPasswordRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if err := server.passwords.RequestReset(req.GetEmail()); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to request a password reset")
+	}
+
+	return &pb.ForgotPasswordResponse{}, nil
+}
+
+// ResetPassword sets a new password with a token emailed by ForgotPassword, and logs the user out of every session.
+func (server *GrpcServer) ResetPassword(context context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	violations := validateResetPasswordRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if _, err := server.passwords.Reset(req.GetToken(), req.GetNewPassword()); err != nil {
+		if errors.Is(err, passwords.ErrInvalidToken) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		if errors.Is(err, passwords.ErrPasswordRejected) {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("new_password", err)})
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reset password")
+	}
+	server.revocations.Reset()
+
+	return &pb.ResetPasswordResponse{}, nil
+}
+
+// ChangePassword sets a new password for a user who proves they know the current one, and logs the user out of
+// every other session.
+func (server *GrpcServer) ChangePassword(context context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateChangePasswordRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	revoked, err := server.passwords.Change(payload.Username, req.GetCurrentPassword(), req.GetNewPassword(), payload.SessionID)
+	if err != nil {
+		if errors.Is(err, passwords.ErrInvalidCredentials) {
+			return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+		}
+		if errors.Is(err, passwords.ErrPasswordRejected) {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("new_password", err)})
+		}
+		return nil, status.Errorf(codes.Internal, "failed to change password")
+	}
+	server.revocations.Reset()
+
+	return &pb.ChangePasswordResponse{RevokedSessions: revoked}, nil
+}
+
+// GetPasswordPolicy returns the rules new passwords must satisfy, so clients can show them to users.
+func (server *GrpcServer) GetPasswordPolicy(context context.Context, req *pb.GetPasswordPolicyRequest) (*pb.GetPasswordPolicyResponse, error) {
+	rules := server.passwordPolicy.Rules()
+	res := &pb.GetPasswordPolicyResponse{
+		MinLength: int32(server.passwordPolicy.MinLength()),
+		MaxLength: int32(server.passwordPolicy.MaxLength()),
+		Rules:     make([]*pb.PasswordRule, len(rules)),
+	}
+	for i, rule := range rules {
+		res.Rules[i] = &pb.PasswordRule{Name: rule.Name, Description: rule.Description}
+	}
+
+	return res, nil
+}
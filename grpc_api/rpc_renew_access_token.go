@@ -0,0 +1,106 @@
+package grpc_api
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/pb"
+	"Simple-Bank/token"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+	"time"
+)
+
+// RenewAccessToken exchanges a refresh token for a new access token and a new refresh token.
+// Every refresh token can be used once: using it again revokes every session renewed from it.
+func (server *GrpcServer) RenewAccessToken(context context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	refreshTokenPayload, err := server.tokenMaker.VerifyToken(req.GetRefreshToken())
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+	// refresh tokens issued before tokens had a type have none, they are still checked against their session
+	if refreshTokenPayload.Type == token.TokenTypeAccess {
+		return nil, unAuthenticatedError(token.ErrInvalidTokenType)
+	}
+
+	// refresh tokens issued before tokens were bound to sessions have the id of their session
+	sessionID := refreshTokenPayload.SessionID
+	if sessionID == uuid.Nil {
+		sessionID = refreshTokenPayload.ID
+	}
+
+	session, err := server.dbServices.GetSession(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, unAuthenticatedError(fmt.Errorf("session not found"))
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session")
+	}
+	if err := checkRefreshSession(session, req.GetRefreshToken(), refreshTokenPayload.Username); err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	// the new tokens carry the current role of the user, so role changes apply at the latest on renewal
+	user, err := server.dbServices.GetUser(session.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, unAuthenticatedError(fmt.Errorf("user not found"))
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user")
+	}
+	if user.FrozenAt != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "user is frozen")
+	}
+
+	metadata := server.extractMetaData(context)
+	tokens, err := server.login.RotateSession(session, user, server.loginClient(context))
+	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			server.revocations.Reset()
+			log.Warn().
+				Str("event", "refresh_token_reuse").
+				Str("username", session.Username).
+				Str("session_id", session.ID.String()).
+				Str("family_id", session.FamilyID.String()).
+				Str("client_ip", metadata.clientIP).
+				Str("user_agent", metadata.userAgent).
+				Msg("refresh token reused, revoked the session family")
+			return nil, unAuthenticatedError(err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to renew session")
+	}
+
+	response := &pb.RenewAccessTokenResponse{
+		AccessToken:           tokens.AccessToken,
+		AccessTokenExpiresAt:  timestamppb.New(tokens.AccessTokenPayload.ExpiredAt),
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(tokens.RefreshTokenPayload.ExpiredAt),
+		SessionId:             tokens.Session.ID.String(),
+	}
+
+	return response, nil
+}
+
+// checkRefreshSession makes sure a refresh token can be exchanged within its session.
+func checkRefreshSession(session models.Session, refreshToken string, username string) error {
+	if session.IsBlocked {
+		return fmt.Errorf("session is blocked")
+	}
+	if session.Username != username {
+		return fmt.Errorf("incorrect session user")
+	}
+	if session.RefreshToken != refreshToken {
+		return fmt.Errorf("mismatch session token")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return fmt.Errorf("expired session")
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package grpc_api
+
+import (
+	"Simple-Bank/pb"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// RevokeSession blocks a session of the user, so that its tokens cannot be used anymore.
+func (server *GrpcServer) RevokeSession(context context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateRevokeSessionRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	session, err := server.dbServices.RevokeSession(payload.Username, uuid.MustParse(req.GetId()))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to revoke session")
+	}
+	server.revocations.Reset()
+
+	response := &pb.RevokeSessionResponse{Session: convertSession(session, payload.SessionID)}
+
+	return response, nil
+}
@@ -0,0 +1,86 @@
+package grpc_api
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/pb"
+	"Simple-Bank/stepup"
+	"Simple-Bank/token"
+	"context"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// stepUpTokenHeader is the metadata carrying the elevated token of a recent re-authentication
+const stepUpTokenHeader = "x-step-up-token"
+
+// StepUp re-authenticates the user with their password, or a code if they enabled two-factor authentication,
+// and returns a short-lived elevated token accepted for the requested operation.
+func (server *GrpcServer) StepUp(context context.Context, req *pb.StepUpRequest) (*pb.StepUpResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateStepUpRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	stepUpToken, stepUpPayload, err := server.stepUp.Elevate(
+		payload.Username,
+		stepup.Operation(req.GetOperation()),
+		req.GetPassword(),
+		req.GetCode(),
+		server.extractMetaData(context).clientIP,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrLoginLocked):
+			return nil, status.Errorf(codes.ResourceExhausted, "%s", err)
+		case errors.Is(err, stepup.ErrInvalidCredentials):
+			return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+		default:
+			return nil, status.Errorf(codes.Internal, "failed to re-authenticate")
+		}
+	}
+
+	response := &pb.StepUpResponse{
+		StepUpToken:          stepUpToken,
+		StepUpTokenExpiresAt: timestamppb.New(stepUpPayload.ExpiredAt),
+		Operation:            req.GetOperation(),
+	}
+
+	return response, nil
+}
+
+// checkStepUp checks the elevated token in the metadata of the request for operation. It returns an
+// unauthenticated error naming the operation in its details if a re-authentication is required.
+func (server *GrpcServer) checkStepUp(ctx context.Context, payload *token.Payload, operation stepup.Operation) error {
+	var stepUpToken string
+	if mtdt, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := mtdt.Get(stepUpTokenHeader); len(values) > 0 {
+			stepUpToken = values[0]
+		}
+	}
+
+	err := server.stepUp.Check(payload, operation, stepUpToken)
+	if err == nil {
+		return nil
+	}
+
+	statusRequired := status.New(codes.Unauthenticated, err.Error())
+	statusDetails, detailsErr := statusRequired.WithDetails(&errdetails.ErrorInfo{
+		Reason:   "STEP_UP_REQUIRED",
+		Domain:   "simplebank",
+		Metadata: map[string]string{"operation": string(operation)},
+	})
+	if detailsErr != nil {
+		return statusRequired.Err()
+	}
+
+	return statusDetails.Err()
+}
@@ -0,0 +1,52 @@
+package grpc_api
+
+import (
+	"Simple-Bank/pb"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// ListTrustedDevices returns the devices the user logged in from, the last seen first.
+func (server *GrpcServer) ListTrustedDevices(context context.Context, req *pb.ListTrustedDevicesRequest) (*pb.ListTrustedDevicesResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	devices, err := server.dbServices.ListTrustedDevices(payload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list trusted devices")
+	}
+
+	response := &pb.ListTrustedDevicesResponse{}
+	for _, device := range devices {
+		response.Devices = append(response.Devices, convertTrustedDevice(device))
+	}
+
+	return response, nil
+}
+
+// RemoveTrustedDevice forgets a device of the user, the next login from it alerts the user again.
+func (server *GrpcServer) RemoveTrustedDevice(context context.Context, req *pb.RemoveTrustedDeviceRequest) (*pb.RemoveTrustedDeviceResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	violations := validateRemoveTrustedDeviceRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if err := server.dbServices.DeleteTrustedDevice(payload.Username, req.GetId()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "trusted device not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to remove trusted device")
+	}
+
+	return &pb.RemoveTrustedDeviceResponse{}, nil
+}
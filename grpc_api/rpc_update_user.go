@@ -3,15 +3,17 @@ package grpc_api
 import (
 	"Simple-Bank/db/services"
 	"Simple-Bank/pb"
+	"Simple-Bank/stepup"
 	"context"
 	"errors"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 func (server *GrpcServer) UpdateUser(context context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
-	payload, err := server.authorizeUser(context)
+	payload, err := authorizationPayload(context)
 	if err != nil {
 		return nil, unAuthenticatedError(err)
 	}
@@ -26,9 +28,14 @@ func (server *GrpcServer) UpdateUser(context context.Context, req *pb.UpdateUser
 		return nil, status.Errorf(codes.PermissionDenied, "cannot update other users info")
 	}
 
+	if req.Email != nil && server.stepUp.RequiresStepUp(stepup.OperationChangeEmail) {
+		if err := server.checkStepUp(context, payload, stepup.OperationChangeEmail); err != nil {
+			return nil, err
+		}
+	}
+
 	updatedUser, err := server.dbServices.UpdateUser(services.UpdateUserRequest{
 		Username: req.Username,
-		Password: req.Password,
 		Fullname: req.Fullname,
 		Email:    req.Email,
 	})
@@ -43,6 +50,13 @@ func (server *GrpcServer) UpdateUser(context context.Context, req *pb.UpdateUser
 		return nil, status.Errorf(codes.Internal, "failed to update user")
 	}
 
+	// a new email must be verified again, the update succeeds even if the email cannot be sent
+	if req.Email != nil && !updatedUser.EmailVerified {
+		if err := server.emails.Send(updatedUser); err != nil {
+			log.Error().Err(err).Str("username", updatedUser.Username).Msg("cannot send the email verification")
+		}
+	}
+
 	response := &pb.UpdateUserResponse{User: convert(updatedUser)}
 
 	return response, nil
@@ -0,0 +1,63 @@
+package grpc_api
+
+import (
+	"Simple-Bank/pb"
+	"Simple-Bank/verification"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// VerifyEmail verifies the email of the user a token was emailed to.
+func (server *GrpcServer) VerifyEmail(context context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	violations := validateVerifyEmailRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := server.emails.Verify(req.GetToken())
+	if err != nil {
+		if errors.Is(err, verification.ErrInvalidToken) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to verify email")
+	}
+
+	response := &pb.VerifyEmailResponse{User: convert(user)}
+
+	return response, nil
+}
+
+// ResendVerificationEmail emails a new verification token to the user, e.g. when the previous one expired.
+func (server *GrpcServer) ResendVerificationEmail(context context.Context, req *pb.ResendVerificationEmailRequest) (*pb.ResendVerificationEmailResponse, error) {
+	payload, err := authorizationPayload(context)
+	if err != nil {
+		return nil, unAuthenticatedError(err)
+	}
+
+	if err := server.emails.Resend(payload.Username); err != nil {
+		switch {
+		case errors.Is(err, verification.ErrAlreadyVerified):
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to send verification email")
+	}
+
+	return &pb.ResendVerificationEmailResponse{}, nil
+}
+
+// checkEmailVerified returns a failed precondition error if the user must verify their email before they move money.
+func (server *GrpcServer) checkEmailVerified(username string) error {
+	if err := server.emails.CheckVerified(username); err != nil {
+		if errors.Is(err, verification.ErrEmailNotVerified) {
+			return status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return status.Errorf(codes.Internal, "failed to check the email of the user")
+	}
+
+	return nil
+}
@@ -1,25 +1,119 @@
 package grpc_api
 
 import (
+	"Simple-Bank/approvals"
+	"Simple-Bank/auth"
 	"Simple-Bank/config"
 	"Simple-Bank/db/services"
+	"Simple-Bank/devices"
+	"Simple-Bank/login"
+	"Simple-Bank/mail"
+	"Simple-Bank/mfa"
+	"Simple-Bank/passwordless"
+	"Simple-Bank/passwords"
 	"Simple-Bank/pb"
+	"Simple-Bank/ratelimit"
+	"Simple-Bank/stepup"
 	"Simple-Bank/token"
+	"Simple-Bank/verification"
+	"net"
 )
 
 // GrpcServer serves grpc requests for the banking service.
 type GrpcServer struct {
 	pb.UnimplementedSimpleBankServer
+	pb.UnimplementedSimpleBankAdminServer
 	dbServices services.Services
 	tokenMaker token.Maker
 	config     *config.Config
+	// revocations checks that the tokens of the requests have not been revoked
+	revocations *auth.RevocationChecker
+	// approvals holds sensitive operations until another user approves them
+	approvals *approvals.Workflow
+	// apiKeys authenticates the requests made with api keys
+	apiKeys *auth.APIKeyAuthenticator
+	// logins checks the passwords of users logging in and locks out password guessing
+	logins *auth.LoginAuthenticator
+	// mfa runs the two-factor authentication of the users who enabled it
+	mfa *mfa.Manager
+	// stepUp requires a recent re-authentication for sensitive operations
+	stepUp *stepup.Guard
+	// emails verifies that users own their email
+	emails *verification.Verifier
+	// passwords resets forgotten passwords and changes the passwords of logged-in users
+	passwords *passwords.Manager
+	// passwordPolicy is the policy new passwords must satisfy
+	passwordPolicy *passwords.Policy
+	// passwordless logs users in with codes and magic links delivered to them
+	passwordless *passwordless.Manager
+	// devices remembers the devices users log in from and alerts them of logins from new ones
+	devices *devices.Registry
+	// login completes the logins of users who proved who they are and starts their sessions
+	login *login.Flow
+	// rateLimiter limits the requests users and client addresses make to each method
+	rateLimiter *ratelimit.Limiter
+	// trustedProxies are the proxies whose X-Forwarded-For headers tell the address of the clients
+	trustedProxies []*net.IPNet
 }
 
 // NewServer creates a new grpc server.
-func NewServer(config *config.Config, services services.Services, tokenMaker token.Maker) *GrpcServer {
+func NewServer(config *config.Config, services services.Services, tokenMaker token.Maker, mailer mail.Mailer) (*GrpcServer, error) {
+	mfaManager := mfa.NewManager(services, config.MFAEncryptionKey, config.MFAChallengeTTL)
+	logins := auth.NewLoginAuthenticator(services, config.LoginMaxFailures, config.LoginMaxIPFailures, config.LoginLockoutDuration)
+	passwordPolicy, err := passwords.NewPolicy(services, config.PasswordMinLength, config.PasswordMaxLength,
+		config.PasswordRequiredClasses, config.PasswordAllowedCharacters, config.PasswordHistorySize, config.BreachedPasswordsFile)
+	if err != nil {
+		return nil, err
+	}
+	loginCodeDelivery, err := passwordless.NewDelivery(config.PasswordlessDelivery, mailer)
+	if err != nil {
+		return nil, err
+	}
+	newDeviceNotifier, err := devices.NewNotifier(config.NewDeviceNotifier, mailer)
+	if err != nil {
+		return nil, err
+	}
+	passwordlessManager := passwordless.NewManager(services, loginCodeDelivery, config.PasswordlessCodeTTL,
+		config.PasswordlessLinkURL, config.PasswordlessMaxRequests, config.PasswordlessMaxIPRequests)
+	deviceRegistry := devices.NewRegistry(services, newDeviceNotifier)
+	rateLimitStore, err := ratelimit.NewStore(config.RateLimitBackend, services)
+	if err != nil {
+		return nil, err
+	}
+	rateLimiter, err := ratelimit.NewLimiter(rateLimitStore, ratelimit.Limit{
+		Requests: config.RateLimitRequests,
+		Period:   config.RateLimitPeriod,
+		Burst:    config.RateLimitBurst,
+	}, rateLimitRules(config.RateLimits))
+	if err != nil {
+		return nil, err
+	}
+	trustedProxies, err := parseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
 	return &GrpcServer{
 		tokenMaker: tokenMaker,
 		config:     config,
 		dbServices: services,
-	}
+
+		revocations: auth.NewRevocationChecker(services, config.RevocationCacheTTL),
+		approvals:   approvals.NewWorkflow(services, config.ApprovalTTL, config.ApprovalTransferThreshold),
+		apiKeys:     auth.NewAPIKeyAuthenticator(services),
+		logins:      logins,
+		mfa:         mfaManager,
+		stepUp: stepup.NewGuard(services, mfaManager, logins, tokenMaker,
+			config.StepUpTokenDuration, config.StepUpTransferThreshold, config.StepUpOperations),
+		emails: verification.NewVerifier(services, mailer,
+			config.EmailVerificationTTL, config.EmailVerificationURL, config.RequireEmailVerification),
+		passwords:      passwords.NewManager(services, mailer, config.PasswordResetTTL, config.PasswordResetURL, passwordPolicy),
+		passwordPolicy: passwordPolicy,
+		passwordless:   passwordlessManager,
+		devices:        deviceRegistry,
+		login: login.NewFlow(services, tokenMaker, mfaManager, deviceRegistry, passwordlessManager,
+			config.TokenAccessTokenDuration, config.TokenRefreshTokenDuration, config.NewDeviceVerification),
+		rateLimiter:    rateLimiter,
+		trustedProxies: trustedProxies,
+	}, nil
 }
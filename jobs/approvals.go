@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"Simple-Bank/db/services"
+	"context"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// approvalExpiryInterval is how often expired approvals are looked for.
+const approvalExpiryInterval = time.Minute
+
+// ApprovalExpiryJob marks the operations that were not decided before they expired as expired.
+type ApprovalExpiryJob struct {
+	services services.Services
+}
+
+// NewApprovalExpiryJob creates a new job expiring approvals.
+func NewApprovalExpiryJob(services services.Services) *ApprovalExpiryJob {
+	return &ApprovalExpiryJob{services: services}
+}
+
+// Run expires approvals every minute until ctx is done.
+func (job *ApprovalExpiryJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(approvalExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		count, err := job.services.ExpireApprovals(time.Now())
+		if err != nil {
+			log.Error().Err(err).Msg("cannot expire approvals")
+			continue
+		}
+		if count > 0 {
+			log.Info().Int64("expired", count).Msg("expired approvals")
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package jobs
+
+import (
+	"Simple-Bank/db/services"
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// endOfDayGracePeriod is how long after midnight the end of day job runs,
+// giving transactions that started before midnight time to commit.
+const endOfDayGracePeriod = 5 * time.Minute
+
+// EndOfDayJob closes the books at the end of each day (UTC): it records the closing balance of every account
+// and produces the trial balance of the day.
+type EndOfDayJob struct {
+	services services.Services
+	// reportsDirectory is where trial balance reports are written as CSV files, if not empty
+	reportsDirectory string
+}
+
+// NewEndOfDayJob creates a new end of day job.
+func NewEndOfDayJob(services services.Services, reportsDirectory string) *EndOfDayJob {
+	return &EndOfDayJob{
+		services:         services,
+		reportsDirectory: reportsDirectory,
+	}
+}
+
+// Run closes the last closed day right away, in case it was missed,
+// and then once a day shortly after midnight until ctx is done.
+func (job *EndOfDayJob) Run(ctx context.Context) {
+	for {
+		lastClosedDay := startOfDay(time.Now().Add(-endOfDayGracePeriod))
+		job.snapshotBalances(lastClosedDay)
+		job.reportTrialBalance(lastClosedDay)
+
+		next := lastClosedDay.Add(24*time.Hour + endOfDayGracePeriod)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (job *EndOfDayJob) snapshotBalances(snapshotAt time.Time) {
+	count, err := job.services.CreateBalanceSnapshots(snapshotAt)
+	if err != nil {
+		log.Error().Err(err).Time("snapshot_at", snapshotAt).Msg("cannot create balance snapshots")
+		return
+	}
+
+	log.Info().Time("snapshot_at", snapshotAt).Int64("accounts", count).Msg("created balance snapshots")
+}
+
+func (job *EndOfDayJob) reportTrialBalance(at time.Time) {
+	trialBalance, err := job.services.GetTrialBalance(at)
+	if err != nil {
+		log.Error().Err(err).Time("at", at).Msg("cannot get trial balance")
+		return
+	}
+
+	logger := log.Info()
+	if !trialBalance.Balanced() {
+		logger = log.Error()
+	}
+	logger.
+		Time("at", at).
+		Int64("customer_accounts_total", trialBalance.CustomerAccountsTotal).
+		Int64("total", trialBalance.Total).
+		Bool("balanced", trialBalance.Balanced()).
+		Msg("trial balance")
+
+	if job.reportsDirectory == "" {
+		return
+	}
+	if err := writeTrialBalanceReport(job.reportsDirectory, trialBalance); err != nil {
+		log.Error().Err(err).Time("at", at).Msg("cannot write trial balance report")
+	}
+}
+
+// writeTrialBalanceReport writes the trial balance as a CSV file named after the day it closes.
+func writeTrialBalanceReport(directory string, trialBalance services.TrialBalance) error {
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return err
+	}
+
+	day := trialBalance.At.Add(-24 * time.Hour).Format(time.DateOnly)
+	file, err := os.Create(filepath.Join(directory, fmt.Sprintf("trial_balance_%s.csv", day)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return trialBalance.WriteCSV(file)
+}
+
+// startOfDay returns midnight (UTC) of the day t falls in.
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
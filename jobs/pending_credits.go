@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"Simple-Bank/db/services"
+	"context"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// defaultFoldInterval is how often pending credits are folded when no interval is configured.
+const defaultFoldInterval = time.Second
+
+// PendingCreditsJob folds the pending credits of hot accounts into their balance.
+type PendingCreditsJob struct {
+	services services.Services
+	interval time.Duration
+}
+
+// NewPendingCreditsJob creates a new job folding pending credits every interval.
+func NewPendingCreditsJob(services services.Services, interval time.Duration) *PendingCreditsJob {
+	if interval <= 0 {
+		interval = defaultFoldInterval
+	}
+
+	return &PendingCreditsJob{
+		services: services,
+		interval: interval,
+	}
+}
+
+// Run folds pending credits every interval until ctx is done.
+func (job *PendingCreditsJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		count, err := job.services.FoldPendingCredits()
+		if err != nil {
+			log.Error().Err(err).Int64("folded", count).Msg("cannot fold pending credits")
+			continue
+		}
+		if count > 0 {
+			log.Debug().Int64("folded", count).Msg("folded pending credits")
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package login
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/devices"
+	"Simple-Bank/mfa"
+	"Simple-Bank/passwordless"
+	"Simple-Bank/token"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Method is how a user proved who they are before the login completes.
+type Method int
+
+// methods users prove who they are with
+const (
+	// MethodPassword is a password, checked by auth.LoginAuthenticator
+	MethodPassword Method = iota
+	// MethodLoginCode is a login code or a magic link delivered to the user, which already proves they can read
+	// their emails
+	MethodLoginCode
+)
+
+// ErrUserFrozen is returned when a frozen user logs in
+var ErrUserFrozen = errors.New("user is frozen")
+
+// Client is the device a user logs in from.
+type Client struct {
+	UserAgent string
+	// ClientIP is the address the request came from, or the one reported by a trusted proxy. It must never be
+	// taken from headers the client controls, or any device could claim the network of a trusted one.
+	ClientIP string
+}
+
+// Tokens are the tokens of a session and the session they are bound to.
+type Tokens struct {
+	AccessToken         string
+	AccessTokenPayload  *token.Payload
+	RefreshToken        string
+	RefreshTokenPayload *token.Payload
+	Session             models.Session
+}
+
+// Result is the outcome of a login: either the tokens of a new session, or the step the user must complete first.
+type Result struct {
+	// Tokens are the tokens of the new session, nil if the user must complete another step first
+	Tokens *Tokens
+	// MFAChallengeToken is exchanged with a code of the authenticator app of the user to complete the login,
+	// empty if no two-factor challenge was started
+	MFAChallengeToken     string
+	MFAChallengeExpiresAt time.Time
+	// DeviceVerificationRequired is true when a login code was delivered to the user to verify an unknown device,
+	// and the login completes like a passwordless login with it
+	DeviceVerificationRequired bool
+}
+
+// Flow completes the logins of users who proved who they are, the same way for every API: it challenges them for
+// their second factor, or for a login code on unknown devices, and starts their sessions.
+type Flow struct {
+	services   services.Services
+	tokenMaker token.Maker
+	mfa        *mfa.Manager
+	devices    *devices.Registry
+	// codes delivers the login codes verifying unknown devices
+	codes                *passwordless.Manager
+	accessTokenDuration  time.Duration
+	refreshTokenDuration time.Duration
+	// verifyNewDevices requires users without two-factor authentication to verify unknown devices with a login code
+	verifyNewDevices bool
+}
+
+// NewFlow creates a Flow issuing tokens lasting accessTokenDuration and refreshTokenDuration. If verifyNewDevices is
+// set, users without two-factor authentication logging in with a password from an unknown device or network are
+// delivered a login code by codes, and only get their tokens once they log in with it.
+func NewFlow(
+	services services.Services,
+	tokenMaker token.Maker,
+	mfa *mfa.Manager,
+	devices *devices.Registry,
+	codes *passwordless.Manager,
+	accessTokenDuration time.Duration,
+	refreshTokenDuration time.Duration,
+	verifyNewDevices bool,
+) *Flow {
+	return &Flow{
+		services:             services,
+		tokenMaker:           tokenMaker,
+		mfa:                  mfa,
+		devices:              devices,
+		codes:                codes,
+		accessTokenDuration:  accessTokenDuration,
+		refreshTokenDuration: refreshTokenDuration,
+		verifyNewDevices:     verifyNewDevices,
+	}
+}
+
+// Complete logs in a user who proved who they are with method. Users who enabled two-factor authentication are
+// always challenged for a code of their authenticator app, whatever their device. Other users logging in with a
+// password from an unknown device are delivered a login code if new devices must be verified, which returns a
+// *passwordless.LimitedError if they requested too many. Everyone else gets a new session.
+func (flow *Flow) Complete(user models.User, client Client, method Method) (Result, error) {
+	if user.FrozenAt != nil {
+		return Result{}, ErrUserFrozen
+	}
+
+	// logins from new devices or networks alert the user
+	trustedDevice, err := flow.devices.Check(user, client.UserAgent, client.ClientIP)
+	if err != nil {
+		return Result{}, err
+	}
+
+	enabled, err := flow.mfa.Enabled(user.Username)
+	if err != nil {
+		return Result{}, err
+	}
+	if enabled {
+		challengeToken, expiresAt, err := flow.mfa.StartChallenge(user.Username)
+		if err != nil {
+			return Result{}, err
+		}
+
+		return Result{MFAChallengeToken: challengeToken, MFAChallengeExpiresAt: expiresAt}, nil
+	}
+
+	if flow.verifyNewDevices && !trustedDevice && method == MethodPassword {
+		if err := flow.codes.Request(user.Username, client.ClientIP); err != nil {
+			return Result{}, err
+		}
+
+		return Result{DeviceVerificationRequired: true}, nil
+	}
+
+	tokens, err := flow.StartSession(user, client)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{Tokens: &tokens}, nil
+}
+
+// StartSession starts a session for a user who fully logged in, and trusts the device they logged in from.
+func (flow *Flow) StartSession(user models.User, client Client) (Tokens, error) {
+	tokens, err := flow.newTokens(user, client)
+	if err != nil {
+		return tokens, err
+	}
+
+	tokens.Session, err = flow.services.CreateSession(tokens.Session)
+	if err != nil {
+		return tokens, err
+	}
+
+	// the device is trusted once the user fully logged in, two-factor authentication included
+	if err := flow.devices.Trust(user.Username, client.UserAgent, client.ClientIP); err != nil {
+		log.Error().Err(err).Str("username", user.Username).Msg("cannot trust the device of the login")
+	}
+
+	return tokens, nil
+}
+
+// RotateSession consumes a session of the user and creates the next session of its family along with new tokens
+// bound to it. It returns services.ErrRefreshTokenReused if the session was already consumed.
+func (flow *Flow) RotateSession(session models.Session, user models.User, client Client) (Tokens, error) {
+	tokens, err := flow.newTokens(user, client)
+	if err != nil {
+		return tokens, err
+	}
+
+	tokens.Session, err = flow.services.RotateSession(session.ID, tokens.Session)
+	return tokens, err
+}
+
+// newTokens creates an access token and a refresh token carrying the role of the user and bound to a new session
+// id, and the session to store them in.
+func (flow *Flow) newTokens(user models.User, client Client) (Tokens, error) {
+	var tokens Tokens
+
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		return tokens, err
+	}
+
+	tokens.AccessToken, tokens.AccessTokenPayload, err = flow.tokenMaker.CreateSessionToken(
+		user.Username,
+		user.Role,
+		sessionID,
+		flow.accessTokenDuration,
+	)
+	if err != nil {
+		return tokens, err
+	}
+
+	tokens.RefreshToken, tokens.RefreshTokenPayload, err = flow.tokenMaker.CreateRefreshToken(
+		user.Username,
+		user.Role,
+		sessionID,
+		flow.refreshTokenDuration,
+	)
+	if err != nil {
+		return tokens, err
+	}
+
+	tokens.Session = models.Session{
+		ID:           sessionID,
+		Username:     user.Username,
+		RefreshToken: tokens.RefreshToken,
+		UserAgent:    client.UserAgent,
+		ClientIP:     client.ClientIP,
+		IsBlocked:    false,
+		CreatedAt:    time.Now().UTC(),
+		ExpiresAt:    tokens.RefreshTokenPayload.ExpiredAt.UTC(),
+		DeletedAt:    gorm.DeletedAt{},
+	}
+
+	return tokens, nil
+}
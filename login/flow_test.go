@@ -0,0 +1,254 @@
+package login
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/devices"
+	"Simple-Bank/mfa"
+	"Simple-Bank/passwordless"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// recordingDelivery records the login codes it delivers.
+type recordingDelivery struct {
+	messages []passwordless.Message
+}
+
+func (delivery *recordingDelivery) Deliver(message passwordless.Message) error {
+	delivery.messages = append(delivery.messages, message)
+	return nil
+}
+
+func randomUser() models.User {
+	return models.User{
+		Username: util.RandomUsername(),
+		Role:     models.RoleCustomer,
+		FullName: util.RandomFullname(),
+		Email:    util.RandomEmail(),
+	}
+}
+
+func newTestFlow(t *testing.T, verifyNewDevices bool) (*Flow, *mockdb.MockServices, *recordingDelivery) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32, util.ALL))
+	require.NoError(t, err)
+	delivery := &recordingDelivery{}
+
+	flow := NewFlow(
+		mockServices,
+		tokenMaker,
+		mfa.NewManager(mockServices, util.RandomString(32, util.ALL), 0),
+		devices.NewRegistry(mockServices, devices.NewLogNotifier()),
+		passwordless.NewManager(mockServices, delivery, 0, "", 0, 0),
+		time.Minute,
+		time.Hour,
+		verifyNewDevices,
+	)
+
+	return flow, mockServices, delivery
+}
+
+func TestComplete(t *testing.T) {
+	client := Client{UserAgent: userAgent, ClientIP: "192.168.1.17"}
+	trustedDevice := models.TrustedDevice{
+		ID:          1,
+		Fingerprint: devices.Fingerprint(client.UserAgent),
+		IPRange:     devices.IPRange(client.ClientIP),
+	}
+	otherDevice := models.TrustedDevice{
+		ID:          2,
+		Fingerprint: devices.Fingerprint("curl/8.4.0"),
+		IPRange:     devices.IPRange("203.0.113.9"),
+	}
+	now := time.Now()
+
+	testCases := []struct {
+		name             string
+		devices          []models.TrustedDevice
+		mfaEnabled       bool
+		verifyNewDevices bool
+		method           Method
+		buildStubs       func(mockServices *mockdb.MockServices, user models.User)
+		check            func(t *testing.T, result Result, delivery *recordingDelivery)
+	}{
+		{
+			name:             "TrustedDevice",
+			devices:          []models.TrustedDevice{trustedDevice},
+			verifyNewDevices: true,
+			method:           MethodPassword,
+			buildStubs:       expectSession,
+			check:            requireSession,
+		},
+		{
+			name:       "NewDevice",
+			devices:    []models.TrustedDevice{otherDevice},
+			method:     MethodPassword,
+			buildStubs: expectSession,
+			check:      requireSession,
+		},
+		{
+			name:             "TrustedDeviceRequiresMFA",
+			devices:          []models.TrustedDevice{trustedDevice},
+			mfaEnabled:       true,
+			verifyNewDevices: true,
+			method:           MethodPassword,
+			buildStubs:       expectMFAChallenge,
+			check:            requireMFAChallenge,
+		},
+		{
+			name:             "NewDeviceRequiresMFA",
+			devices:          []models.TrustedDevice{otherDevice},
+			mfaEnabled:       true,
+			verifyNewDevices: true,
+			method:           MethodPassword,
+			buildStubs:       expectMFAChallenge,
+			check:            requireMFAChallenge,
+		},
+		{
+			name:             "NewDeviceRequiresLoginCode",
+			devices:          []models.TrustedDevice{otherDevice},
+			verifyNewDevices: true,
+			method:           MethodPassword,
+			buildStubs: func(mockServices *mockdb.MockServices, user models.User) {
+				mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				mockServices.EXPECT().CreateLoginCode(gomock.Any()).Times(1).Return(models.LoginCode{}, nil)
+				mockServices.EXPECT().CreateSession(gomock.Any()).Times(0)
+				mockServices.EXPECT().TrustDevice(gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result Result, delivery *recordingDelivery) {
+				require.True(t, result.DeviceVerificationRequired)
+				require.Nil(t, result.Tokens)
+				require.Empty(t, result.MFAChallengeToken)
+				require.Len(t, delivery.messages, 1)
+			},
+		},
+		{
+			// the login code already proves the user can read their emails
+			name:             "NewDeviceWithLoginCode",
+			devices:          []models.TrustedDevice{otherDevice},
+			verifyNewDevices: true,
+			method:           MethodLoginCode,
+			buildStubs:       expectSession,
+			check:            requireSession,
+		},
+		{
+			// a device on the network of another trusted device is not trusted
+			name: "DeviceAndNetworkOfDifferentDevices",
+			devices: []models.TrustedDevice{
+				{ID: 1, Fingerprint: trustedDevice.Fingerprint, IPRange: otherDevice.IPRange},
+				{ID: 2, Fingerprint: otherDevice.Fingerprint, IPRange: trustedDevice.IPRange},
+			},
+			verifyNewDevices: true,
+			method:           MethodPassword,
+			buildStubs: func(mockServices *mockdb.MockServices, user models.User) {
+				mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				mockServices.EXPECT().CreateLoginCode(gomock.Any()).Times(1).Return(models.LoginCode{}, nil)
+				mockServices.EXPECT().CreateSession(gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result Result, delivery *recordingDelivery) {
+				require.True(t, result.DeviceVerificationRequired)
+				require.Nil(t, result.Tokens)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			flow, mockServices, delivery := newTestFlow(t, testCase.verifyNewDevices)
+			user := randomUser()
+
+			mockServices.EXPECT().ListTrustedDevices(user.Username).Times(1).Return(testCase.devices, nil)
+			credential := models.TOTPCredential{Username: user.Username}
+			if testCase.mfaEnabled {
+				credential.ConfirmedAt = &now
+			}
+			mockServices.EXPECT().GetTOTPCredential(user.Username).Times(1).Return(credential, nil)
+			testCase.buildStubs(mockServices, user)
+
+			result, err := flow.Complete(user, client, testCase.method)
+			require.NoError(t, err)
+			testCase.check(t, result, delivery)
+		})
+	}
+}
+
+func TestCompleteFrozen(t *testing.T) {
+	flow, mockServices, _ := newTestFlow(t, true)
+	user := randomUser()
+	frozenAt := time.Now()
+	user.FrozenAt = &frozenAt
+
+	mockServices.EXPECT().ListTrustedDevices(gomock.Any()).Times(0)
+	mockServices.EXPECT().CreateSession(gomock.Any()).Times(0)
+
+	_, err := flow.Complete(user, Client{UserAgent: userAgent, ClientIP: "192.168.1.17"}, MethodPassword)
+	require.ErrorIs(t, err, ErrUserFrozen)
+}
+
+func TestCompleteWithoutTOTPCredential(t *testing.T) {
+	flow, mockServices, _ := newTestFlow(t, false)
+	user := randomUser()
+
+	mockServices.EXPECT().ListTrustedDevices(user.Username).Times(1).Return(nil, nil)
+	mockServices.EXPECT().GetTOTPCredential(user.Username).Times(1).Return(models.TOTPCredential{}, gorm.ErrRecordNotFound)
+	expectSession(mockServices, user)
+
+	result, err := flow.Complete(user, Client{UserAgent: userAgent, ClientIP: "192.168.1.17"}, MethodPassword)
+	require.NoError(t, err)
+	requireSession(t, result, nil)
+}
+
+// expectSession expects a session to be started for user, and the device of the login to be trusted.
+func expectSession(mockServices *mockdb.MockServices, user models.User) {
+	mockServices.EXPECT().
+		CreateSession(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(session models.Session) (models.Session, error) {
+			return session, nil
+		})
+	mockServices.EXPECT().TrustDevice(gomock.Any()).Times(1).Return(models.TrustedDevice{}, nil)
+	mockServices.EXPECT().CreateLoginCode(gomock.Any()).Times(0)
+}
+
+func requireSession(t *testing.T, result Result, delivery *recordingDelivery) {
+	require.NotNil(t, result.Tokens)
+	require.False(t, result.DeviceVerificationRequired)
+	require.Empty(t, result.MFAChallengeToken)
+	require.Equal(t, token.TokenTypeAccess, result.Tokens.AccessTokenPayload.Type)
+	require.Equal(t, token.TokenTypeRefresh, result.Tokens.RefreshTokenPayload.Type)
+	require.Equal(t, result.Tokens.RefreshToken, result.Tokens.Session.RefreshToken)
+	require.Equal(t, result.Tokens.Session.ID, result.Tokens.AccessTokenPayload.SessionID)
+	if delivery != nil {
+		require.Empty(t, delivery.messages)
+	}
+}
+
+// expectMFAChallenge expects a two-factor challenge to be started for user instead of a session.
+func expectMFAChallenge(mockServices *mockdb.MockServices, user models.User) {
+	mockServices.EXPECT().
+		CreateMFAChallenge(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(challenge models.MFAChallenge) (models.MFAChallenge, error) {
+			return challenge, nil
+		})
+	mockServices.EXPECT().CreateSession(gomock.Any()).Times(0)
+	mockServices.EXPECT().TrustDevice(gomock.Any()).Times(0)
+	mockServices.EXPECT().CreateLoginCode(gomock.Any()).Times(0)
+}
+
+func requireMFAChallenge(t *testing.T, result Result, delivery *recordingDelivery) {
+	require.NotEmpty(t, result.MFAChallengeToken)
+	require.False(t, result.MFAChallengeExpiresAt.IsZero())
+	require.Nil(t, result.Tokens)
+	require.False(t, result.DeviceVerificationRequired)
+	require.Empty(t, delivery.messages)
+}
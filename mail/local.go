@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer writes emails to files instead of sending them, for development.
+type FileMailer struct {
+	directory string
+	from      string
+}
+
+// NewFileMailer creates a FileMailer writing emails sent by from to directory, one .eml file per email.
+func NewFileMailer(directory, from string) *FileMailer {
+	return &FileMailer{
+		directory: directory,
+		from:      from,
+	}
+}
+
+func (mailer *FileMailer) Send(message Message) error {
+	if err := message.validate(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(mailer.directory, 0o755); err != nil {
+		return fmt.Errorf("cannot create the directory of emails: %w", err)
+	}
+
+	// the recipient is sanitized since it is part of the name of the file
+	recipient := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, message.To)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), recipient)
+
+	if err := os.WriteFile(filepath.Join(mailer.directory, name), format(mailer.from, message), 0o600); err != nil {
+		return fmt.Errorf("cannot write email: %w", err)
+	}
+
+	return nil
+}
+
+// LogMailer logs emails instead of sending them, for development. Emails are logged whole, secrets included.
+type LogMailer struct{}
+
+// NewLogMailer creates a LogMailer.
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (mailer *LogMailer) Send(message Message) error {
+	if err := message.validate(); err != nil {
+		return err
+	}
+
+	log.Info().Str("to", message.To).Str("subject", message.Subject).Str("body", message.Body).Msg("email")
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidHeader is returned when the recipient or the subject of a message contains a line break,
+// which would let them inject headers into the message
+var ErrInvalidHeader = errors.New("recipient and subject cannot contain line breaks")
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails to users.
+type Mailer interface {
+	Send(message Message) error
+}
+
+// validate checks that the headers of message cannot inject other headers.
+func (message Message) validate() error {
+	if strings.ContainsAny(message.To, "\r\n") || strings.ContainsAny(message.Subject, "\r\n") {
+		return ErrInvalidHeader
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileMailer(t *testing.T) {
+	directory := filepath.Join(t.TempDir(), "emails")
+	mailer := NewFileMailer(directory, "bank@example.com")
+
+	message := Message{To: "user@example.com", Subject: "Verify your email", Body: "token: 1234"}
+	require.NoError(t, mailer.Send(message))
+
+	files, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.True(t, strings.HasSuffix(files[0].Name(), "-user@example.com.eml"))
+
+	content, err := os.ReadFile(filepath.Join(directory, files[0].Name()))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "From: bank@example.com\r\n")
+	require.Contains(t, string(content), "To: user@example.com\r\n")
+	require.Contains(t, string(content), "Subject: Verify your email\r\n")
+	require.True(t, strings.HasSuffix(string(content), "\r\n\r\ntoken: 1234"))
+}
+
+func TestHeaderInjection(t *testing.T) {
+	mailers := []Mailer{
+		NewFileMailer(t.TempDir(), "bank@example.com"),
+		NewLogMailer(),
+		NewSMTPMailer("localhost", "25", "", "", "bank@example.com"),
+	}
+
+	for _, mailer := range mailers {
+		err := mailer.Send(Message{To: "user@example.com\r\nBcc: attacker@example.com", Subject: "subject"})
+		require.ErrorIs(t, err, ErrInvalidHeader)
+		err = mailer.Send(Message{To: "user@example.com", Subject: "subject\nBcc: attacker@example.com"})
+		require.ErrorIs(t, err, ErrInvalidHeader)
+	}
+}
@@ -0,0 +1,59 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// SMTPMailer sends emails through an SMTP server.
+type SMTPMailer struct {
+	address string
+	from    string
+	// auth authenticates to the server, nil if the server accepts emails without credentials
+	auth smtp.Auth
+}
+
+// NewSMTPMailer creates a SMTPMailer sending emails from the from address through the server at host and port,
+// authenticating with username and password unless username is empty.
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	mailer := &SMTPMailer{
+		address: net.JoinHostPort(host, port),
+		from:    from,
+	}
+	if username != "" {
+		mailer.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return mailer
+}
+
+func (mailer *SMTPMailer) Send(message Message) error {
+	if err := message.validate(); err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(mailer.address, mailer.auth, mailer.from, []string{message.To}, format(mailer.from, message)); err != nil {
+		return fmt.Errorf("cannot send email: %w", err)
+	}
+
+	return nil
+}
+
+// format formats message as an email sent by from.
+func format(from string, message Message) []byte {
+	var buffer bytes.Buffer
+
+	fmt.Fprintf(&buffer, "From: %s\r\n", from)
+	fmt.Fprintf(&buffer, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buffer, "Subject: %s\r\n", message.Subject)
+	fmt.Fprintf(&buffer, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	buffer.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buffer.WriteString("\r\n")
+	buffer.WriteString(message.Body)
+
+	return buffer.Bytes()
+}
@@ -4,12 +4,17 @@ import (
 	"Simple-Bank/api"
 	"Simple-Bank/config"
 	"Simple-Bank/db"
+	"Simple-Bank/db/models"
 	"Simple-Bank/db/services"
 	"Simple-Bank/grpc_api"
+	"Simple-Bank/jobs"
+	"Simple-Bank/mail"
 	"Simple-Bank/pb"
 	"Simple-Bank/token"
 	"context"
 	"database/sql"
+	"expvar"
+	"fmt"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -20,6 +25,8 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 )
 
 func main() {
@@ -49,18 +56,111 @@ func main() {
 		}
 	}(DB)
 
-	tokenMaker, err := token.NewPasetoMaker(configs.TokenSymmetricKey)
+	tokenMaker, err := newTokenMaker(configs)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot create token maker")
 	}
 
-	//runGinServer(configs, tokenMaker, db)
-	go runGrpcGatewayServer(configs, tokenMaker, db)
-	runGrpcServer(configs, tokenMaker, db)
+	mailer, err := newMailer(configs)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create mailer")
+	}
+
+	if err := syncChartOfAccounts(configs, services.NewSQLServices(db)); err != nil {
+		log.Fatal().Err(err).Msg("cannot sync chart of accounts")
+	}
+
+	if err := services.NewSQLServices(db).SetHotAccounts(configs.HotAccounts); err != nil {
+		log.Fatal().Err(err).Msg("cannot set hot accounts")
+	}
+
+	go jobs.NewEndOfDayJob(services.NewSQLServices(db), configs.ReportsDirectory).Run(context.Background())
+	go jobs.NewPendingCreditsJob(services.NewSQLServices(db), configs.PendingCreditsFoldInterval).Run(context.Background())
+	go jobs.NewApprovalExpiryJob(services.NewSQLServices(db)).Run(context.Background())
+
+	//runGinServer(configs, tokenMaker, mailer, db)
+	go runGrpcGatewayServer(configs, tokenMaker, mailer, db)
+	runGrpcServer(configs, tokenMaker, mailer, db)
+}
+
+// syncChartOfAccounts creates the general ledger accounts of the configured chart of accounts
+// that do not exist yet.
+func syncChartOfAccounts(config config.Config, services services.Services) error {
+	chart := make([]models.GLAccount, len(config.ChartOfAccounts))
+	for i, account := range config.ChartOfAccounts {
+		chart[i] = models.GLAccount{
+			Code:     account.Code,
+			Name:     account.Name,
+			Category: account.Category,
+		}
+	}
+
+	return services.SyncChartOfAccounts(chart)
+}
+
+// newTokenMaker creates the token maker of the configured algorithm.
+func newTokenMaker(config config.Config) (token.Maker, error) {
+	if config.TokenAlgorithm == "" || config.TokenAlgorithm == "paseto" {
+		return token.NewPasetoMaker(config.TokenSymmetricKey)
+	}
+
+	if len(config.TokenSigningKeys) == 0 {
+		return nil, fmt.Errorf("token algorithm %q requires signing keys", config.TokenAlgorithm)
+	}
+	keys := make([]token.SigningKey, len(config.TokenSigningKeys))
+	for i, key := range config.TokenSigningKeys {
+		var retiresAt time.Time
+		if key.RetiresAt != "" {
+			var err error
+			if retiresAt, err = time.Parse(time.RFC3339, key.RetiresAt); err != nil {
+				return nil, fmt.Errorf("cannot parse the retirement of key %q: %w", key.ID, err)
+			}
+		}
+
+		parsed, err := token.ParseSigningKey(key.ID, key.PrivateKey, key.PublicKey, retiresAt)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = parsed
+	}
+
+	keyRing, err := token.NewKeyRing(keys[0], keys[1:]...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.TokenAlgorithm {
+	case "paseto_public":
+		return token.NewPublicPasetoMaker(keyRing)
+	case "jwt_eddsa":
+		return token.NewEdDSAJWTMaker(keyRing)
+	default:
+		return nil, fmt.Errorf("unknown token algorithm %q", config.TokenAlgorithm)
+	}
+}
+
+// newMailer creates the mailer of the configured type.
+func newMailer(config config.Config) (mail.Mailer, error) {
+	switch config.MailerType {
+	case "", "log":
+		return mail.NewLogMailer(), nil
+	case "file":
+		if config.MailDirectory == "" {
+			return nil, fmt.Errorf("mailer type %q requires a mail directory", config.MailerType)
+		}
+		return mail.NewFileMailer(config.MailDirectory, config.MailFrom), nil
+	case "smtp":
+		if config.SMTPHost == "" || config.SMTPPort == "" || config.MailFrom == "" {
+			return nil, fmt.Errorf("mailer type %q requires a smtp host, port and from address", config.MailerType)
+		}
+		return mail.NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer type %q", config.MailerType)
+	}
 }
 
-func runGinServer(config config.Config, tokenMaker token.Maker, db *gorm.DB) {
-	server, err := api.NewServer(&config, services.NewSQLServices(db), tokenMaker)
+func runGinServer(config config.Config, tokenMaker token.Maker, mailer mail.Mailer, db *gorm.DB) {
+	server, err := api.NewServer(&config, services.NewSQLServices(db), tokenMaker, mailer)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot create server")
 	}
@@ -70,12 +170,22 @@ func runGinServer(config config.Config, tokenMaker token.Maker, db *gorm.DB) {
 	}
 }
 
-func runGrpcServer(config config.Config, tokenMaker token.Maker, db *gorm.DB) {
-	server := grpc_api.NewServer(&config, services.NewSQLServices(db), tokenMaker)
+func runGrpcServer(config config.Config, tokenMaker token.Maker, mailer mail.Mailer, db *gorm.DB) {
+	server, err := grpc_api.NewServer(&config, services.NewSQLServices(db), tokenMaker, mailer)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create server")
+	}
 
-	grpcLogger := grpc.UnaryInterceptor(grpc_api.GrpcLogger)
-	grpcServer := grpc.NewServer(grpcLogger)
+	interceptors := grpc.ChainUnaryInterceptor(
+		grpc_api.GrpcLogger,
+		server.RateLimitInterceptor,
+		server.AuthorizationInterceptor,
+		server.UserRateLimitInterceptor,
+	)
+	streamInterceptors := grpc.ChainStreamInterceptor(server.AuthorizationStreamInterceptor)
+	grpcServer := grpc.NewServer(interceptors, streamInterceptors)
 	pb.RegisterSimpleBankServer(grpcServer, server)
+	pb.RegisterSimpleBankAdminServer(grpcServer, server)
 	reflection.Register(grpcServer)
 
 	serverAddress := config.GrpcServerHost + ":" + config.GrpcServerPort
@@ -91,8 +201,11 @@ func runGrpcServer(config config.Config, tokenMaker token.Maker, db *gorm.DB) {
 	}
 }
 
-func runGrpcGatewayServer(config config.Config, tokenMaker token.Maker, db *gorm.DB) {
-	server := grpc_api.NewServer(&config, services.NewSQLServices(db), tokenMaker)
+func runGrpcGatewayServer(config config.Config, tokenMaker token.Maker, mailer mail.Mailer, db *gorm.DB) {
+	server, err := grpc_api.NewServer(&config, services.NewSQLServices(db), tokenMaker, mailer)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create server")
+	}
 
 	serveMuxOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
 		MarshalOptions: protojson.MarshalOptions{
@@ -102,21 +215,35 @@ func runGrpcGatewayServer(config config.Config, tokenMaker token.Maker, db *gorm
 			DiscardUnknown: true,
 		},
 	})
-	grpcMux := runtime.NewServeMux(serveMuxOption)
+	// form posts are not served with the methods of GET rules, which GatewayHandler authorized for POST
+	grpcMux := runtime.NewServeMux(
+		serveMuxOption,
+		runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+		runtime.WithDisablePathLengthFallback(),
+	)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	err := pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, server)
+	err = pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, server)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot register handler server")
 	}
+	err = pb.RegisterSimpleBankAdminHandlerServer(ctx, grpcMux, server)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot register admin handler server")
+	}
 
 	mux := http.NewServeMux()
-	mux.Handle("/", grpcMux)
+	// the gateway calls the methods in process, so its requests are rate limited and authorized before they reach
+	// the grpc mux, and the requests matching no method never reach it
+	mux.Handle("/", server.GatewayHandler(grpcMux))
 
 	fs := http.FileServer(http.Dir("./doc/swagger"))
 	mux.Handle("/swagger/", http.StripPrefix("/swagger/", fs))
 
+	// exposes the metrics of the services, e.g. the retries of transactions, to the back office
+	mux.Handle("/debug/vars", server.BackOfficeHandler(grpcMux, expvar.Handler()))
+
 	serverAddress := config.HTTPServerHost + ":" + config.HTTPServerPort
 	listener, err := net.Listen("tcp", serverAddress)
 	if err != nil {
@@ -131,3 +258,13 @@ func runGrpcGatewayServer(config config.Config, tokenMaker token.Maker, db *gorm
 		log.Fatal().Err(err).Msg("cannot start HTTP gateway server")
 	}
 }
+
+// gatewayHeaderMatcher forwards the headers of the gateway requests the grpc server reads as metadata,
+// besides the headers forwarded by default.
+func gatewayHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, "X-Step-Up-Token") {
+		return strings.ToLower(key), true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
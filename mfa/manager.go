@@ -0,0 +1,344 @@
+package mfa
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/chacha20poly1305"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+const (
+	// Issuer names the bank in authenticator apps
+	Issuer = "Simple Bank"
+	// DefaultChallengeTTL is how long login challenges last when no ttl is configured
+	DefaultChallengeTTL = 5 * time.Minute
+	// MaxChallengeAttempts is how many wrong codes a login challenge accepts before it is refused,
+	// so codes cannot be guessed
+	MaxChallengeAttempts = 5
+	// recoveryCodeCount is the number of recovery codes given to users when they enable two-factor authentication
+	recoveryCodeCount = 10
+	// recoveryCodeBytes is the number of random bytes of a recovery code
+	recoveryCodeBytes = 5
+)
+
+var (
+	// ErrNotEnabled is returned when the two-factor authentication of a user is not enrolled or not confirmed
+	ErrNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrInvalidCode is returned when a code is wrong or has already been used
+	ErrInvalidCode = errors.New("code is invalid")
+	// ErrInvalidChallenge is returned when a login challenge is unknown, expired or already completed
+	ErrInvalidChallenge = errors.New("challenge is invalid or has expired")
+	// ErrTooManyAttempts is returned when a login challenge received too many wrong codes
+	ErrTooManyAttempts = errors.New("too many wrong codes, log in again")
+)
+
+// Manager enrolls users in two-factor authentication with time-based one-time passwords,
+// and runs the second step of their logins.
+type Manager struct {
+	services services.Services
+	// encryptionKey encrypts the shared secrets, which must be read back to check codes
+	encryptionKey []byte
+	challengeTTL  time.Duration
+}
+
+// NewManager creates a Manager encrypting secrets with encryptionKey, which must be 32 bytes long,
+// and issuing login challenges lasting challengeTTL, DefaultChallengeTTL if zero.
+func NewManager(services services.Services, encryptionKey string, challengeTTL time.Duration) *Manager {
+	if challengeTTL <= 0 {
+		challengeTTL = DefaultChallengeTTL
+	}
+
+	return &Manager{
+		services:      services,
+		encryptionKey: []byte(encryptionKey),
+		challengeTTL:  challengeTTL,
+	}
+}
+
+// Enrollment is the secret of a user being enrolled, to add to an authenticator app.
+type Enrollment struct {
+	// Secret is the base32 encoded secret, for users typing it
+	Secret string
+	// URI is the otpauth uri of the secret, for authenticator apps scanning it as a QR code
+	URI string
+}
+
+// Enroll generates a new secret for a user, replacing any unconfirmed one. Two-factor authentication is only
+// enabled once the user confirms the enrollment with a code. It returns services.ErrTOTPAlreadyEnabled if it is
+// already enabled.
+func (manager *Manager) Enroll(username string) (Enrollment, error) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return Enrollment{}, err
+	}
+	encryptedSecret, err := manager.encrypt(username, secret)
+	if err != nil {
+		return Enrollment{}, err
+	}
+
+	if _, err := manager.services.SaveTOTPCredential(models.TOTPCredential{
+		Username:        username,
+		EncryptedSecret: encryptedSecret,
+	}); err != nil {
+		return Enrollment{}, err
+	}
+
+	return Enrollment{
+		Secret: secretEncoding.EncodeToString(secret),
+		URI:    otpauthURI(Issuer, username, secret),
+	}, nil
+}
+
+// Confirm enables the two-factor authentication of a user with a code of their authenticator app.
+// It returns the recovery codes of the user, which must be shown to the user once since only their hashes are stored.
+func (manager *Manager) Confirm(username, code string) ([]string, error) {
+	credential, err := manager.services.GetTOTPCredential(username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotEnabled
+		}
+		return nil, err
+	}
+	if credential.IsConfirmed() {
+		return nil, services.ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := manager.decrypt(username, credential.EncryptedSecret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := matchTOTP(secret, normalizeCode(code), time.Now())
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	recoveryCodes := make([]string, recoveryCodeCount)
+	hashedCodes := make([]string, recoveryCodeCount)
+	for i := range recoveryCodes {
+		raw, err := randomHex(recoveryCodeBytes)
+		if err != nil {
+			return nil, err
+		}
+		recoveryCodes[i] = raw[:len(raw)/2] + "-" + raw[len(raw)/2:]
+		hashedCodes[i] = hashSecret(raw)
+	}
+
+	if _, err := manager.services.ConfirmTOTPCredential(username, step, hashedCodes); err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// Disable disables the two-factor authentication of a user, who must prove it with a code of their authenticator
+// app or a recovery code.
+func (manager *Manager) Disable(username, code string) error {
+	credential, err := manager.confirmedCredential(username)
+	if err != nil {
+		return err
+	}
+	if err := manager.verifyCode(credential, code); err != nil {
+		return err
+	}
+
+	return manager.services.DeleteTOTPCredential(username)
+}
+
+// Verify checks a code of the authenticator app of a user, or one of their recovery codes, outside of a login,
+// e.g. to confirm a sensitive operation. It returns ErrNotEnabled if the user has no second factor.
+func (manager *Manager) Verify(username, code string) error {
+	credential, err := manager.confirmedCredential(username)
+	if err != nil {
+		return err
+	}
+
+	return manager.verifyCode(credential, code)
+}
+
+// Enabled reports whether logins of a user require a second factor.
+func (manager *Manager) Enabled(username string) (bool, error) {
+	_, err := manager.confirmedCredential(username)
+	if errors.Is(err, ErrNotEnabled) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// StartChallenge starts the second step of the login of a user whose password was checked.
+// It returns the challenge token the user exchanges with a code for their tokens, and its expiry.
+func (manager *Manager) StartChallenge(username string) (string, time.Time, error) {
+	challengeToken, err := randomHex(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	challenge, err := manager.services.CreateMFAChallenge(models.MFAChallenge{
+		HashedToken: hashSecret(challengeToken),
+		Username:    username,
+		ExpiresAt:   time.Now().Add(manager.challengeTTL).UTC(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return challengeToken, challenge.ExpiresAt, nil
+}
+
+// CompleteChallenge completes the login challenge of challengeToken with a code of the authenticator app of the
+// user or a recovery code, and returns the username of the user logging in. Every challenge can be completed once.
+func (manager *Manager) CompleteChallenge(challengeToken, code string) (string, error) {
+	challenge, err := manager.services.GetMFAChallenge(hashSecret(challengeToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrInvalidChallenge
+		}
+		return "", err
+	}
+	if challenge.ConsumedAt != nil || time.Now().After(challenge.ExpiresAt) {
+		return "", ErrInvalidChallenge
+	}
+	if challenge.Attempts >= MaxChallengeAttempts {
+		return "", ErrTooManyAttempts
+	}
+
+	credential, err := manager.confirmedCredential(challenge.Username)
+	if err != nil {
+		// the user disabled two-factor authentication since the challenge was started
+		if errors.Is(err, ErrNotEnabled) {
+			return "", ErrInvalidChallenge
+		}
+		return "", err
+	}
+
+	if err := manager.verifyCode(credential, code); err != nil {
+		if !errors.Is(err, ErrInvalidCode) {
+			return "", err
+		}
+
+		challenge, err = manager.services.FailMFAChallenge(challenge.ID)
+		if err != nil {
+			return "", err
+		}
+		if challenge.Attempts >= MaxChallengeAttempts {
+			return "", ErrTooManyAttempts
+		}
+		return "", ErrInvalidCode
+	}
+
+	if err := manager.services.ConsumeMFAChallenge(challenge.ID); err != nil {
+		if errors.Is(err, services.ErrMFAChallengeUsed) {
+			return "", ErrInvalidChallenge
+		}
+		return "", err
+	}
+
+	return challenge.Username, nil
+}
+
+func (manager *Manager) confirmedCredential(username string) (models.TOTPCredential, error) {
+	credential, err := manager.services.GetTOTPCredential(username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.TOTPCredential{}, ErrNotEnabled
+		}
+		return models.TOTPCredential{}, err
+	}
+	if !credential.IsConfirmed() {
+		return models.TOTPCredential{}, ErrNotEnabled
+	}
+
+	return credential, nil
+}
+
+// verifyCode checks a code of the authenticator app of a user, which cannot be used again, or one of their
+// recovery codes, which is used up. It returns ErrInvalidCode if the code is wrong or already used.
+func (manager *Manager) verifyCode(credential models.TOTPCredential, code string) error {
+	code = normalizeCode(code)
+
+	if len(code) == totpDigits {
+		secret, err := manager.decrypt(credential.Username, credential.EncryptedSecret)
+		if err != nil {
+			return err
+		}
+		step, ok := matchTOTP(secret, code, time.Now())
+		if !ok {
+			return ErrInvalidCode
+		}
+
+		if err := manager.services.UseTOTPStep(credential.Username, step); err != nil {
+			if errors.Is(err, services.ErrTOTPCodeUsed) {
+				return ErrInvalidCode
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := manager.services.UseRecoveryCode(credential.Username, hashSecret(code)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidCode
+		}
+		return err
+	}
+
+	return nil
+}
+
+// encrypt encrypts the secret of a user, binding it to the user so it cannot be moved to another one.
+func (manager *Manager) encrypt(username string, secret []byte) (string, error) {
+	aead, err := chacha20poly1305.NewX(manager.encryptionKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid mfa encryption key: %w", err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, secret, []byte(username))), nil
+}
+
+func (manager *Manager) decrypt(username, encryptedSecret string) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(manager.encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mfa encryption key: %w", err)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encryptedSecret)
+	if err != nil || len(decoded) < aead.NonceSize() {
+		return nil, errors.New("encrypted secret is malformed")
+	}
+
+	return aead.Open(nil, decoded[:aead.NonceSize()], decoded[aead.NonceSize():], []byte(username))
+}
+
+// normalizeCode removes the separators users may type in codes, e.g. the dash of recovery codes.
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+// hashSecret hashes recovery codes and challenge tokens, which are random strings,
+// and only ever checked within a limited number of attempts, so a fast hash is enough.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
@@ -0,0 +1,183 @@
+package mfa
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestManager(t *testing.T) (*Manager, *mockdb.MockServices) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+
+	return NewManager(mockServices, util.RandomString(32, util.ALL), 0), mockServices
+}
+
+// enroll enrolls a user and returns the stored credential and the secret of the user.
+func enroll(t *testing.T, manager *Manager, mockServices *mockdb.MockServices, username string) (models.TOTPCredential, []byte) {
+	var credential models.TOTPCredential
+	mockServices.EXPECT().
+		SaveTOTPCredential(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(saved models.TOTPCredential) (models.TOTPCredential, error) {
+			credential = saved
+			return saved, nil
+		})
+
+	enrollment, err := manager.Enroll(username)
+	require.NoError(t, err)
+
+	uri, err := url.Parse(enrollment.URI)
+	require.NoError(t, err)
+	require.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+	require.NotContains(t, credential.EncryptedSecret, enrollment.Secret)
+
+	secret, err := secretEncoding.DecodeString(enrollment.Secret)
+	require.NoError(t, err)
+
+	return credential, secret
+}
+
+func confirmedCredential(credential models.TOTPCredential) models.TOTPCredential {
+	now := time.Now()
+	credential.ConfirmedAt = &now
+	return credential
+}
+
+func TestEnrollAndConfirm(t *testing.T) {
+	manager, mockServices := newTestManager(t)
+	username := util.RandomUsername()
+	credential, secret := enroll(t, manager, mockServices, username)
+
+	step := totpStep(time.Now())
+	mockServices.EXPECT().GetTOTPCredential(username).Times(2).Return(credential, nil)
+
+	// codes of steps too far from now are refused
+	_, err := manager.Confirm(username, totpCode(secret, step+5))
+	require.ErrorIs(t, err, ErrInvalidCode)
+
+	var hashedCodes []string
+	mockServices.EXPECT().
+		ConfirmTOTPCredential(username, step, gomock.Any()).
+		Times(1).
+		DoAndReturn(func(username string, step int64, hashed []string) (models.TOTPCredential, error) {
+			hashedCodes = hashed
+			return confirmedCredential(credential), nil
+		})
+
+	recoveryCodes, err := manager.Confirm(username, totpCode(secret, step))
+	require.NoError(t, err)
+	require.Len(t, recoveryCodes, recoveryCodeCount)
+	require.Len(t, hashedCodes, recoveryCodeCount)
+	require.Equal(t, hashSecret(normalizeCode(recoveryCodes[0])), hashedCodes[0])
+}
+
+func TestConfirmAlreadyEnabled(t *testing.T) {
+	manager, mockServices := newTestManager(t)
+	username := util.RandomUsername()
+	credential, secret := enroll(t, manager, mockServices, username)
+
+	mockServices.EXPECT().GetTOTPCredential(username).Times(1).Return(confirmedCredential(credential), nil)
+	mockServices.EXPECT().ConfirmTOTPCredential(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := manager.Confirm(username, totpCode(secret, totpStep(time.Now())))
+	require.ErrorIs(t, err, services.ErrTOTPAlreadyEnabled)
+}
+
+func TestCompleteChallenge(t *testing.T) {
+	manager, mockServices := newTestManager(t)
+	username := util.RandomUsername()
+	credential, secret := enroll(t, manager, mockServices, username)
+	credential = confirmedCredential(credential)
+
+	var challenge models.MFAChallenge
+	mockServices.EXPECT().
+		CreateMFAChallenge(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(created models.MFAChallenge) (models.MFAChallenge, error) {
+			created.ID = 1
+			challenge = created
+			return created, nil
+		})
+
+	challengeToken, expiresAt, err := manager.StartChallenge(username)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(DefaultChallengeTTL), expiresAt, time.Second)
+	require.Equal(t, hashSecret(challengeToken), challenge.HashedToken)
+
+	step := totpStep(time.Now())
+	mockServices.EXPECT().GetMFAChallenge(challenge.HashedToken).AnyTimes().Return(challenge, nil)
+	mockServices.EXPECT().GetTOTPCredential(username).AnyTimes().Return(credential, nil)
+	mockServices.EXPECT().UseTOTPStep(username, step).Times(1).Return(nil)
+	mockServices.EXPECT().ConsumeMFAChallenge(challenge.ID).Times(1).Return(nil)
+
+	loggedIn, err := manager.CompleteChallenge(challengeToken, totpCode(secret, step))
+	require.NoError(t, err)
+	require.Equal(t, username, loggedIn)
+
+	// a code cannot be replayed
+	mockServices.EXPECT().UseTOTPStep(username, step).Times(1).Return(services.ErrTOTPCodeUsed)
+	mockServices.EXPECT().FailMFAChallenge(challenge.ID).Times(1).Return(models.MFAChallenge{ID: 1, Attempts: 1}, nil)
+
+	_, err = manager.CompleteChallenge(challengeToken, totpCode(secret, step))
+	require.ErrorIs(t, err, ErrInvalidCode)
+}
+
+func TestCompleteChallengeRecoveryCode(t *testing.T) {
+	manager, mockServices := newTestManager(t)
+	username := util.RandomUsername()
+	credential, _ := enroll(t, manager, mockServices, username)
+
+	challenge := models.MFAChallenge{ID: 2, Username: username, ExpiresAt: time.Now().Add(time.Minute)}
+	mockServices.EXPECT().GetMFAChallenge(hashSecret("token")).Times(1).Return(challenge, nil)
+	mockServices.EXPECT().GetTOTPCredential(username).Times(1).Return(confirmedCredential(credential), nil)
+	mockServices.EXPECT().UseRecoveryCode(username, hashSecret("0123456789")).Times(1).Return(nil)
+	mockServices.EXPECT().ConsumeMFAChallenge(challenge.ID).Times(1).Return(nil)
+
+	loggedIn, err := manager.CompleteChallenge("token", "01234-56789")
+	require.NoError(t, err)
+	require.Equal(t, username, loggedIn)
+}
+
+func TestCompleteChallengeTooManyAttempts(t *testing.T) {
+	manager, mockServices := newTestManager(t)
+	username := util.RandomUsername()
+	credential, _ := enroll(t, manager, mockServices, username)
+
+	challenge := models.MFAChallenge{ID: 3, Username: username, Attempts: MaxChallengeAttempts - 1, ExpiresAt: time.Now().Add(time.Minute)}
+	mockServices.EXPECT().GetMFAChallenge(gomock.Any()).Times(1).Return(challenge, nil)
+	mockServices.EXPECT().GetTOTPCredential(username).Times(1).Return(confirmedCredential(credential), nil)
+	mockServices.EXPECT().UseRecoveryCode(username, gomock.Any()).Times(1).Return(gorm.ErrRecordNotFound)
+	failed := challenge
+	failed.Attempts++
+	mockServices.EXPECT().FailMFAChallenge(challenge.ID).Times(1).Return(failed, nil)
+
+	_, err := manager.CompleteChallenge("token", "wrong-code")
+	require.ErrorIs(t, err, ErrTooManyAttempts)
+
+	// once locked, even a right code is refused without being checked
+	mockServices.EXPECT().GetMFAChallenge(gomock.Any()).Times(1).Return(failed, nil)
+	mockServices.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err = manager.CompleteChallenge("token", strings.Repeat("1", totpDigits))
+	require.ErrorIs(t, err, ErrTooManyAttempts)
+}
+
+func TestCompleteChallengeExpired(t *testing.T) {
+	manager, mockServices := newTestManager(t)
+
+	challenge := models.MFAChallenge{ID: 4, Username: util.RandomUsername(), ExpiresAt: time.Now().Add(-time.Second)}
+	mockServices.EXPECT().GetMFAChallenge(gomock.Any()).Times(1).Return(challenge, nil)
+	mockServices.EXPECT().GetTOTPCredential(gomock.Any()).Times(0)
+
+	_, err := manager.CompleteChallenge("token", "123456")
+	require.ErrorIs(t, err, ErrInvalidChallenge)
+}
@@ -0,0 +1,58 @@
+package mfa
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// test vectors of RFC 6238, truncated to six digits
+	secret := []byte("12345678901234567890")
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, testCase := range testCases {
+		require.Equal(t, testCase.code, totpCode(secret, totpStep(time.Unix(testCase.unix, 0))))
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	step := totpStep(now)
+
+	matched, ok := matchTOTP(secret, totpCode(secret, step), now)
+	require.True(t, ok)
+	require.Equal(t, step, matched)
+
+	// codes of the previous step are accepted for clocks out of sync
+	matched, ok = matchTOTP(secret, totpCode(secret, step-1), now)
+	require.True(t, ok)
+	require.Equal(t, step-1, matched)
+
+	_, ok = matchTOTP(secret, totpCode(secret, step-2), now)
+	require.False(t, ok)
+}
+
+func TestOTPAuthURI(t *testing.T) {
+	uri, err := url.Parse(otpauthURI("Simple Bank", "alice", []byte("12345678901234567890")))
+	require.NoError(t, err)
+
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/Simple Bank:alice", uri.Path)
+	require.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", uri.Query().Get("secret"))
+	require.Equal(t, "Simple Bank", uri.Query().Get("issuer"))
+}
@@ -0,0 +1,26 @@
+package oauth
+
+// error codes of the OAuth2 protocol, as described by RFC 6749
+const (
+	ErrorInvalidRequest          = "invalid_request"
+	ErrorInvalidClient           = "invalid_client"
+	ErrorInvalidGrant            = "invalid_grant"
+	ErrorUnauthorizedClient      = "unauthorized_client"
+	ErrorUnsupportedGrantType    = "unsupported_grant_type"
+	ErrorUnsupportedResponseType = "unsupported_response_type"
+	ErrorInvalidScope            = "invalid_scope"
+)
+
+// Error is an error of the OAuth2 protocol, answered to clients with its code and description.
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (err *Error) Error() string {
+	if err.Description == "" {
+		return err.Code
+	}
+
+	return err.Code + ": " + err.Description
+}
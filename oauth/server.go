@@ -0,0 +1,450 @@
+package oauth
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/token"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAccessTokenDuration is how long access tokens last when no duration is configured.
+	DefaultAccessTokenDuration = 15 * time.Minute
+	// DefaultAuthorizationCodeTTL is how long authorization codes can be exchanged when no ttl is configured.
+	DefaultAuthorizationCodeTTL = time.Minute
+)
+
+// grant types of the token endpoint
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+const (
+	// responseTypeCode is the only response type of the authorization endpoint
+	responseTypeCode = "code"
+	// codeChallengeMethodS256 is the only pkce method, since plain challenges protect nothing
+	codeChallengeMethodS256 = "S256"
+	// tokenTypeBearer is the type of every access token
+	tokenTypeBearer = "Bearer"
+)
+
+// Server is an OAuth2 authorization server issuing scoped access tokens to third-party apps,
+// either on behalf of a user through the authorization code grant with pkce,
+// or on behalf of the owner of a confidential client through the client credentials grant.
+type Server struct {
+	services    services.Services
+	tokenMaker  token.Maker
+	revocations *auth.RevocationChecker
+
+	accessTokenDuration  time.Duration
+	authorizationCodeTTL time.Duration
+}
+
+// NewServer creates a Server issuing access tokens lasting accessTokenDuration and authorization codes lasting
+// authorizationCodeTTL. Zero durations use DefaultAccessTokenDuration and DefaultAuthorizationCodeTTL.
+func NewServer(
+	services services.Services,
+	tokenMaker token.Maker,
+	revocations *auth.RevocationChecker,
+	accessTokenDuration time.Duration,
+	authorizationCodeTTL time.Duration,
+) *Server {
+	if accessTokenDuration <= 0 {
+		accessTokenDuration = DefaultAccessTokenDuration
+	}
+	if authorizationCodeTTL <= 0 {
+		authorizationCodeTTL = DefaultAuthorizationCodeTTL
+	}
+
+	return &Server{
+		services:             services,
+		tokenMaker:           tokenMaker,
+		revocations:          revocations,
+		accessTokenDuration:  accessTokenDuration,
+		authorizationCodeTTL: authorizationCodeTTL,
+	}
+}
+
+// RegisterClientRequest holds the parameters of a new client.
+type RegisterClientRequest struct {
+	Name string
+	// RedirectURIs are the uris authorization codes can be sent to, required by the authorization code grant
+	RedirectURIs []string
+	// Scopes are the scopes the client can request
+	Scopes []string
+	// Confidential clients authenticate with a secret and can use the client credentials grant
+	Confidential bool
+}
+
+// RegisterClient registers a client owned by a user. It returns the secret of a confidential client,
+// which must be shown to the user once since only its hash is stored.
+func (server *Server) RegisterClient(owner string, req RegisterClientRequest) (models.OAuthClient, string, error) {
+	if err := auth.ValidateScopes(req.Scopes); err != nil {
+		return models.OAuthClient{}, "", &Error{Code: ErrorInvalidScope, Description: err.Error()}
+	}
+	for _, redirectURI := range req.RedirectURIs {
+		if err := validateRedirectURI(redirectURI); err != nil {
+			return models.OAuthClient{}, "", &Error{Code: ErrorInvalidRequest, Description: err.Error()}
+		}
+	}
+
+	id, err := randomString(16)
+	if err != nil {
+		return models.OAuthClient{}, "", err
+	}
+	client := models.OAuthClient{
+		ID:           id,
+		Owner:        owner,
+		Name:         req.Name,
+		RedirectURIs: strings.Join(req.RedirectURIs, " "),
+		Scopes:       strings.Join(req.Scopes, " "),
+	}
+
+	secret := ""
+	if req.Confidential {
+		secret, err = randomString(32)
+		if err != nil {
+			return models.OAuthClient{}, "", err
+		}
+		client.HashedSecret = hashSecret(secret)
+	}
+
+	client, err = server.services.CreateOAuthClient(client)
+	if err != nil {
+		return models.OAuthClient{}, "", err
+	}
+
+	return client, secret, nil
+}
+
+// AuthorizeRequest holds the parameters of a request to the authorization endpoint.
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scopes              []string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// Authorize grants an authorization code to a client on behalf of the user of payload, and returns the
+// redirect uri of the client carrying the code and the state of the request.
+func (server *Server) Authorize(payload *token.Payload, req AuthorizeRequest) (string, error) {
+	client, err := server.services.GetOAuthClient(req.ClientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", &Error{Code: ErrorInvalidRequest, Description: "unknown client"}
+		}
+		return "", err
+	}
+	// the redirect uri must match exactly, so codes cannot be sent anywhere else
+	if !slices.Contains(client.RedirectURIList(), req.RedirectURI) {
+		return "", &Error{Code: ErrorInvalidRequest, Description: "redirect_uri is not registered for the client"}
+	}
+	if req.ResponseType != responseTypeCode {
+		return "", &Error{Code: ErrorUnsupportedResponseType, Description: "response_type must be code"}
+	}
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != codeChallengeMethodS256 {
+		return "", &Error{Code: ErrorInvalidRequest, Description: "a S256 code_challenge is required"}
+	}
+	scopes, err := grantedScopes(client, req.Scopes)
+	if err != nil {
+		return "", err
+	}
+
+	code, err := randomString(32)
+	if err != nil {
+		return "", err
+	}
+	if _, err := server.services.CreateAuthorizationCode(models.AuthorizationCode{
+		HashedCode:    hashSecret(code),
+		ClientID:      client.ID,
+		Username:      payload.Username,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        strings.Join(scopes, " "),
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     time.Now().Add(server.authorizationCodeTTL).UTC(),
+	}); err != nil {
+		return "", err
+	}
+
+	redirectURL, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		return "", err
+	}
+	query := redirectURL.Query()
+	query.Set("code", code)
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	redirectURL.RawQuery = query.Encode()
+
+	return redirectURL.String(), nil
+}
+
+// TokenRequest holds the parameters of a request to the token endpoint.
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	// Code, RedirectURI and CodeVerifier are the parameters of the authorization code grant
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	// Scopes are the scopes requested by the client credentials grant, every scope of the client if empty
+	Scopes []string
+}
+
+// TokenResponse is the response of the token endpoint.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// Token issues an access token for a grant.
+func (server *Server) Token(req TokenRequest) (TokenResponse, error) {
+	client, err := server.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	var username string
+	var scopes []string
+
+	switch req.GrantType {
+	case GrantTypeAuthorizationCode:
+		code, err := server.consumeCode(client, req)
+		if err != nil {
+			return TokenResponse{}, err
+		}
+		username, scopes = code.Username, code.ScopeList()
+	case GrantTypeClientCredentials:
+		if !client.IsConfidential() {
+			return TokenResponse{}, &Error{Code: ErrorUnauthorizedClient, Description: "public clients cannot use client credentials"}
+		}
+		scopes, err = grantedScopes(client, req.Scopes)
+		if err != nil {
+			return TokenResponse{}, err
+		}
+		username = client.Owner
+	default:
+		return TokenResponse{}, &Error{Code: ErrorUnsupportedGrantType, Description: fmt.Sprintf("grant_type %q is not supported", req.GrantType)}
+	}
+
+	user, err := server.services.GetUser(username)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	if user.FrozenAt != nil {
+		return TokenResponse{}, &Error{Code: ErrorInvalidGrant, Description: "user is frozen"}
+	}
+
+	// the client only acts on the resources of the user, whatever the role of the user
+	accessToken, payload, err := server.tokenMaker.CreateScopedToken(user.Username, models.RoleCustomer, scopes, client.ID, server.accessTokenDuration)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	return TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   tokenTypeBearer,
+		ExpiresIn:   int64(time.Until(payload.ExpiredAt).Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// consumeCode exchanges the authorization code of a token request, checking that it was granted to client
+// and that the client answers its pkce challenge.
+func (server *Server) consumeCode(client models.OAuthClient, req TokenRequest) (models.AuthorizationCode, error) {
+	code, err := server.services.ConsumeAuthorizationCode(hashSecret(req.Code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, services.ErrAuthorizationCodeUsed) {
+			return models.AuthorizationCode{}, &Error{Code: ErrorInvalidGrant, Description: "authorization code is invalid"}
+		}
+		return models.AuthorizationCode{}, err
+	}
+	if code.ClientID != client.ID {
+		return models.AuthorizationCode{}, &Error{Code: ErrorInvalidGrant, Description: "authorization code was granted to another client"}
+	}
+	if time.Now().After(code.ExpiresAt) {
+		return models.AuthorizationCode{}, &Error{Code: ErrorInvalidGrant, Description: "authorization code has expired"}
+	}
+	if code.RedirectURI != req.RedirectURI {
+		return models.AuthorizationCode{}, &Error{Code: ErrorInvalidGrant, Description: "redirect_uri does not match the authorization request"}
+	}
+	if !verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return models.AuthorizationCode{}, &Error{Code: ErrorInvalidGrant, Description: "code_verifier does not match the code_challenge"}
+	}
+
+	return code, nil
+}
+
+// Introspection is the response of the introspection endpoint, as described by RFC 7662.
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	Audience  string `json:"aud,omitempty"`
+	TokenID   string `json:"jti,omitempty"`
+}
+
+// Introspect describes an access token to the client it was issued to. Tokens that are invalid, expired, revoked
+// or issued to anyone else are reported as inactive.
+func (server *Server) Introspect(clientID, clientSecret, rawToken string) (Introspection, error) {
+	client, err := server.authenticateClient(clientID, clientSecret)
+	if err != nil {
+		return Introspection{}, err
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(rawToken)
+	if err != nil || payload.Audience != client.ID {
+		return Introspection{Active: false}, nil
+	}
+	if err := server.revocations.Check(payload); err != nil {
+		if errors.Is(err, auth.ErrRevokedToken) || errors.Is(err, auth.ErrInactiveSession) {
+			return Introspection{Active: false}, nil
+		}
+		return Introspection{}, err
+	}
+
+	return Introspection{
+		Active:    true,
+		Scope:     strings.Join(payload.Scopes, " "),
+		ClientID:  client.ID,
+		Username:  payload.Username,
+		TokenType: tokenTypeBearer,
+		ExpiresAt: payload.ExpiredAt.Unix(),
+		IssuedAt:  payload.IssuedAt.Unix(),
+		Audience:  payload.Audience,
+		TokenID:   payload.ID.String(),
+	}, nil
+}
+
+// Revoke revokes an access token issued to a client. As required by RFC 7009, invalid and expired tokens
+// are ignored, since they cannot be used anyway.
+func (server *Server) Revoke(clientID, clientSecret, rawToken string) error {
+	client, err := server.authenticateClient(clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(rawToken)
+	if err != nil {
+		return nil
+	}
+	if payload.Audience != client.ID {
+		return &Error{Code: ErrorUnauthorizedClient, Description: "token was not issued to the client"}
+	}
+
+	if err := server.services.RevokeToken(services.RevokeTokenRequest{
+		TokenID:   payload.ID,
+		Username:  payload.Username,
+		ExpiresAt: payload.ExpiredAt,
+		RevokedBy: payload.Username,
+		Reason:    fmt.Sprintf("revoked by oauth client %s", client.ID),
+	}); err != nil {
+		return err
+	}
+	server.revocations.Reset()
+
+	return nil
+}
+
+// authenticateClient returns the client with the given id if it authenticates: confidential clients with their
+// secret, and public clients without any.
+func (server *Server) authenticateClient(clientID, clientSecret string) (models.OAuthClient, error) {
+	invalidClient := &Error{Code: ErrorInvalidClient, Description: "client authentication failed"}
+
+	client, err := server.services.GetOAuthClient(clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.OAuthClient{}, invalidClient
+		}
+		return models.OAuthClient{}, err
+	}
+
+	if !client.IsConfidential() {
+		if clientSecret != "" {
+			return models.OAuthClient{}, invalidClient
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(clientSecret)), []byte(client.HashedSecret)) != 1 {
+		return models.OAuthClient{}, invalidClient
+	}
+
+	return client, nil
+}
+
+// grantedScopes returns the requested scopes if the client can request all of them, or every scope of the client
+// if none is requested.
+func grantedScopes(client models.OAuthClient, requested []string) ([]string, error) {
+	allowed := client.ScopeList()
+	if len(requested) == 0 {
+		return allowed, nil
+	}
+
+	for _, scope := range requested {
+		if !slices.Contains(allowed, scope) {
+			return nil, &Error{Code: ErrorInvalidScope, Description: fmt.Sprintf("scope %q is not allowed for the client", scope)}
+		}
+	}
+
+	return requested, nil
+}
+
+// verifyCodeChallenge reports whether verifier answers an S256 pkce challenge.
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if verifier == "" {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func validateRedirectURI(redirectURI string) error {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+		return fmt.Errorf("redirect uri %q must be an absolute uri without fragment", redirectURI)
+	}
+
+	return nil
+}
+
+// hashSecret hashes client secrets and authorization codes, which are long random strings,
+// so a fast hash is enough.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
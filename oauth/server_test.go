@@ -0,0 +1,238 @@
+package oauth
+
+import (
+	"Simple-Bank/auth"
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const redirectURI = "https://app.example.com/callback"
+
+func newTestServer(t *testing.T) (*Server, *mockdb.MockServices, token.Maker) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	mockServices.EXPECT().IsTokenRevoked(gomock.Any()).AnyTimes().Return(false, nil)
+
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32, util.ALL))
+	require.NoError(t, err)
+
+	return NewServer(mockServices, tokenMaker, auth.NewRevocationChecker(mockServices, time.Minute), 0, 0), mockServices, tokenMaker
+}
+
+func publicClient(owner string) models.OAuthClient {
+	return models.OAuthClient{
+		ID:           util.RandomString(32, util.ALPHANUMERIC),
+		Owner:        owner,
+		Name:         "budgeting app",
+		RedirectURIs: redirectURI,
+		Scopes:       "accounts:read transfers:read",
+	}
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// authorize runs the authorization request of user for client and returns the code sent to the redirect uri.
+func authorize(t *testing.T, server *Server, mockServices *mockdb.MockServices, client models.OAuthClient, username, verifier string) (string, models.AuthorizationCode) {
+	var stored models.AuthorizationCode
+	mockServices.EXPECT().GetOAuthClient(client.ID).Times(1).Return(client, nil)
+	mockServices.EXPECT().
+		CreateAuthorizationCode(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(code models.AuthorizationCode) (models.AuthorizationCode, error) {
+			stored = code
+			return code, nil
+		})
+
+	redirect, err := server.Authorize(&token.Payload{Username: username}, AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            client.ID,
+		RedirectURI:         redirectURI,
+		Scopes:              []string{"accounts:read"},
+		State:               "xyz",
+		CodeChallenge:       codeChallenge(verifier),
+		CodeChallengeMethod: "S256",
+	})
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(redirect)
+	require.NoError(t, err)
+	require.Equal(t, "xyz", parsed.Query().Get("state"))
+	code := parsed.Query().Get("code")
+	require.Equal(t, hashSecret(code), stored.HashedCode)
+	require.Equal(t, "accounts:read", stored.Scopes)
+
+	return code, stored
+}
+
+func TestAuthorizationCodeGrant(t *testing.T) {
+	server, mockServices, tokenMaker := newTestServer(t)
+	user := models.User{Username: util.RandomUsername(), Role: models.RoleCustomer}
+	client := publicClient(util.RandomUsername())
+	verifier := util.RandomString(43, util.ALPHANUMERIC)
+
+	code, stored := authorize(t, server, mockServices, client, user.Username, verifier)
+
+	mockServices.EXPECT().GetOAuthClient(client.ID).Times(1).Return(client, nil)
+	mockServices.EXPECT().ConsumeAuthorizationCode(hashSecret(code)).Times(1).Return(stored, nil)
+	mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+
+	res, err := server.Token(TokenRequest{
+		GrantType:    GrantTypeAuthorizationCode,
+		ClientID:     client.ID,
+		Code:         code,
+		RedirectURI:  redirectURI,
+		CodeVerifier: verifier,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Bearer", res.TokenType)
+	require.Equal(t, "accounts:read", res.Scope)
+
+	payload, err := tokenMaker.VerifyToken(res.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, payload.Username)
+	require.Equal(t, []string{"accounts:read"}, payload.Scopes)
+	require.Equal(t, client.ID, payload.Audience)
+}
+
+func TestAuthorizationCodeGrantWrongVerifier(t *testing.T) {
+	server, mockServices, _ := newTestServer(t)
+	client := publicClient(util.RandomUsername())
+
+	code, stored := authorize(t, server, mockServices, client, util.RandomUsername(), util.RandomString(43, util.ALPHANUMERIC))
+
+	mockServices.EXPECT().GetOAuthClient(client.ID).Times(1).Return(client, nil)
+	mockServices.EXPECT().ConsumeAuthorizationCode(hashSecret(code)).Times(1).Return(stored, nil)
+	mockServices.EXPECT().GetUser(gomock.Any()).Times(0)
+
+	_, err := server.Token(TokenRequest{
+		GrantType:    GrantTypeAuthorizationCode,
+		ClientID:     client.ID,
+		Code:         code,
+		RedirectURI:  redirectURI,
+		CodeVerifier: util.RandomString(43, util.ALPHANUMERIC),
+	})
+	requireOAuthError(t, err, ErrorInvalidGrant)
+}
+
+func TestAuthorizationCodeGrantReusedCode(t *testing.T) {
+	server, mockServices, _ := newTestServer(t)
+	client := publicClient(util.RandomUsername())
+
+	mockServices.EXPECT().GetOAuthClient(client.ID).Times(1).Return(client, nil)
+	mockServices.EXPECT().
+		ConsumeAuthorizationCode(gomock.Any()).
+		Times(1).
+		Return(models.AuthorizationCode{ClientID: client.ID}, services.ErrAuthorizationCodeUsed)
+	mockServices.EXPECT().GetUser(gomock.Any()).Times(0)
+
+	_, err := server.Token(TokenRequest{
+		GrantType:    GrantTypeAuthorizationCode,
+		ClientID:     client.ID,
+		Code:         "used",
+		RedirectURI:  redirectURI,
+		CodeVerifier: "verifier",
+	})
+	requireOAuthError(t, err, ErrorInvalidGrant)
+}
+
+func TestAuthorizeScopeNotAllowed(t *testing.T) {
+	server, mockServices, _ := newTestServer(t)
+	client := publicClient(util.RandomUsername())
+
+	mockServices.EXPECT().GetOAuthClient(client.ID).Times(1).Return(client, nil)
+	mockServices.EXPECT().CreateAuthorizationCode(gomock.Any()).Times(0)
+
+	_, err := server.Authorize(&token.Payload{Username: util.RandomUsername()}, AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            client.ID,
+		RedirectURI:         redirectURI,
+		Scopes:              []string{"transfers:write"},
+		CodeChallenge:       codeChallenge("verifier"),
+		CodeChallengeMethod: "S256",
+	})
+	requireOAuthError(t, err, ErrorInvalidScope)
+}
+
+func TestClientCredentialsGrant(t *testing.T) {
+	server, mockServices, tokenMaker := newTestServer(t)
+	owner := models.User{Username: util.RandomUsername(), Role: models.RoleAdmin}
+
+	// public clients cannot use client credentials
+	client := publicClient(owner.Username)
+	mockServices.EXPECT().GetOAuthClient(client.ID).Times(1).Return(client, nil)
+	_, err := server.Token(TokenRequest{GrantType: GrantTypeClientCredentials, ClientID: client.ID})
+	requireOAuthError(t, err, ErrorUnauthorizedClient)
+
+	secret := util.RandomString(32, util.ALPHANUMERIC)
+	confidential := publicClient(owner.Username)
+	confidential.HashedSecret = hashSecret(secret)
+	mockServices.EXPECT().GetOAuthClient(confidential.ID).Times(2).Return(confidential, nil)
+
+	_, err = server.Token(TokenRequest{GrantType: GrantTypeClientCredentials, ClientID: confidential.ID, ClientSecret: "wrong"})
+	requireOAuthError(t, err, ErrorInvalidClient)
+
+	mockServices.EXPECT().GetUser(owner.Username).Times(1).Return(owner, nil)
+	res, err := server.Token(TokenRequest{GrantType: GrantTypeClientCredentials, ClientID: confidential.ID, ClientSecret: secret})
+	require.NoError(t, err)
+	require.Equal(t, "accounts:read transfers:read", res.Scope)
+
+	// the client of an admin only acts on the resources of the admin
+	payload, err := tokenMaker.VerifyToken(res.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, models.RoleCustomer, payload.Role)
+}
+
+func TestIntrospectAndRevoke(t *testing.T) {
+	server, mockServices, tokenMaker := newTestServer(t)
+	client := publicClient(util.RandomUsername())
+	username := util.RandomUsername()
+
+	accessToken, payload, err := tokenMaker.CreateScopedToken(username, models.RoleCustomer, []string{"accounts:read"}, client.ID, time.Minute)
+	require.NoError(t, err)
+	otherToken, _, err := tokenMaker.CreateScopedToken(username, models.RoleCustomer, []string{"accounts:read"}, "other", time.Minute)
+	require.NoError(t, err)
+
+	mockServices.EXPECT().GetOAuthClient(client.ID).AnyTimes().Return(client, nil)
+
+	introspection, err := server.Introspect(client.ID, "", accessToken)
+	require.NoError(t, err)
+	require.True(t, introspection.Active)
+	require.Equal(t, username, introspection.Username)
+	require.Equal(t, payload.ID.String(), introspection.TokenID)
+
+	// tokens issued to other clients are not described
+	introspection, err = server.Introspect(client.ID, "", otherToken)
+	require.NoError(t, err)
+	require.False(t, introspection.Active)
+
+	requireOAuthError(t, server.Revoke(client.ID, "", otherToken), ErrorUnauthorizedClient)
+	require.NoError(t, server.Revoke(client.ID, "", "invalid"))
+
+	mockServices.EXPECT().
+		RevokeToken(gomock.Cond(func(x any) bool {
+			req := x.(services.RevokeTokenRequest)
+			return req.TokenID == payload.ID && req.Username == username
+		})).
+		Times(1).
+		Return(nil)
+	require.NoError(t, server.Revoke(client.ID, "", accessToken))
+}
+
+func requireOAuthError(t *testing.T, err error, code string) {
+	var oauthErr *Error
+	require.ErrorAs(t, err, &oauthErr)
+	require.Equal(t, code, oauthErr.Code)
+}
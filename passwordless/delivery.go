@@ -0,0 +1,92 @@
+package passwordless
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/mail"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+// delivery types
+const (
+	// DeliveryEmail emails login codes to users
+	DeliveryEmail = "email"
+	// DeliveryLog logs login codes instead of delivering them, for development
+	DeliveryLog = "log"
+)
+
+// Message is a login code and a magic link delivered to a user.
+type Message struct {
+	User models.User
+	// Code is the short code the user types
+	Code string
+	// Link is the magic link logging the user in, empty if no login page is configured
+	Link      string
+	ExpiresAt time.Time
+}
+
+// Delivery delivers login codes to users, so other channels than emails can be added.
+type Delivery interface {
+	Deliver(message Message) error
+}
+
+// NewDelivery creates the Delivery of deliveryType, DeliveryEmail if empty, sending emails with mailer.
+func NewDelivery(deliveryType string, mailer mail.Mailer) (Delivery, error) {
+	switch deliveryType {
+	case "", DeliveryEmail:
+		return NewEmailDelivery(mailer), nil
+	case DeliveryLog:
+		return NewLogDelivery(), nil
+	default:
+		return nil, fmt.Errorf("unknown login code delivery %q", deliveryType)
+	}
+}
+
+// EmailDelivery emails login codes to the email of users.
+type EmailDelivery struct {
+	mailer mail.Mailer
+}
+
+// NewEmailDelivery creates an EmailDelivery sending emails with mailer.
+func NewEmailDelivery(mailer mail.Mailer) *EmailDelivery {
+	return &EmailDelivery{mailer: mailer}
+}
+
+func (delivery *EmailDelivery) Deliver(message Message) error {
+	var body strings.Builder
+
+	fmt.Fprintf(&body, "Hello %s,\n\n", message.User.FullName)
+	fmt.Fprintf(&body, "Your login code is:\n\n%s\n\n", message.Code)
+	if message.Link != "" {
+		fmt.Fprintf(&body, "You can also open this link to log in:\n\n%s\n\n", message.Link)
+	}
+	fmt.Fprintf(&body, "It expires on %s and can be used once. If you did not ask to log in, ignore this email, "+
+		"nobody can log in without the code.\n", message.ExpiresAt.Format(time.RFC1123))
+
+	return delivery.mailer.Send(mail.Message{
+		To:      message.User.Email,
+		Subject: "Your login code",
+		Body:    body.String(),
+	})
+}
+
+// LogDelivery logs login codes instead of delivering them, for development. Codes are logged whole.
+type LogDelivery struct{}
+
+// NewLogDelivery creates a LogDelivery.
+func NewLogDelivery() *LogDelivery {
+	return &LogDelivery{}
+}
+
+func (delivery *LogDelivery) Deliver(message Message) error {
+	log.Info().
+		Str("username", message.User.Username).
+		Str("code", message.Code).
+		Str("link", message.Link).
+		Time("expires_at", message.ExpiresAt).
+		Msg("login code")
+
+	return nil
+}
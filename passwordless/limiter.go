@@ -0,0 +1,59 @@
+package passwordless
+
+import (
+	"sync"
+	"time"
+)
+
+// requestLimiter counts the requests of subjects in fixed windows. Counts are kept in memory, so each server
+// limits the requests it receives.
+type requestLimiter struct {
+	window time.Duration
+
+	mutex   sync.Mutex
+	windows map[string]*limiterWindow
+	// prunedAt is when the windows that ended were last forgotten
+	prunedAt time.Time
+}
+
+type limiterWindow struct {
+	startedAt time.Time
+	requests  int
+}
+
+func newRequestLimiter(window time.Duration) *requestLimiter {
+	return &requestLimiter{
+		window:   window,
+		windows:  map[string]*limiterWindow{},
+		prunedAt: time.Now(),
+	}
+}
+
+// allow counts a request of subject and returns zero if it has made at most maxRequests in the current window,
+// or how long until the window ends otherwise. Refused requests are not counted.
+func (limiter *requestLimiter) allow(subject string, maxRequests int) time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(limiter.prunedAt) > limiter.window {
+		for key, window := range limiter.windows {
+			if now.Sub(window.startedAt) >= limiter.window {
+				delete(limiter.windows, key)
+			}
+		}
+		limiter.prunedAt = now
+	}
+
+	window, ok := limiter.windows[subject]
+	if !ok || now.Sub(window.startedAt) >= limiter.window {
+		window = &limiterWindow{startedAt: now}
+		limiter.windows[subject] = window
+	}
+	if window.requests >= maxRequests {
+		return window.startedAt.Add(limiter.window).Sub(now)
+	}
+	window.requests++
+
+	return 0
+}
@@ -0,0 +1,283 @@
+package passwordless
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultCodeTTL is how long login codes last when no ttl is configured
+	DefaultCodeTTL = 10 * time.Minute
+	// DefaultMaxRequests is the number of login codes a username or an email can request in a window when
+	// none is configured
+	DefaultMaxRequests = 5
+	// DefaultMaxIPRequests is the number of login codes a client address can request in a window when none is
+	// configured
+	DefaultMaxIPRequests = 50
+	// requestWindow is the window the requests of login codes are counted in
+	requestWindow = time.Hour
+	// maxCodeAttempts is the number of wrong codes after which a login code cannot be used anymore
+	maxCodeAttempts = 5
+	// codeDigits is the number of digits of the codes users type
+	codeDigits = 6
+	// tokenBytes is the number of random bytes of a magic link token
+	tokenBytes = 32
+)
+
+var (
+	// ErrInvalidCode is returned when a login code or a magic link is wrong, unknown, expired or already used
+	ErrInvalidCode = errors.New("login code is invalid or has expired")
+	// ErrTooManyRequests is returned when too many login codes are requested
+	ErrTooManyRequests = errors.New("too many login codes requested")
+)
+
+// LimitedError is the error of a request of a login code refused because of previous requests.
+// It wraps ErrTooManyRequests.
+type LimitedError struct {
+	// RetryAfter is how long to wait before trying again
+	RetryAfter time.Duration
+}
+
+func (err *LimitedError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrTooManyRequests, err.RetryAfter.Round(time.Second))
+}
+
+func (err *LimitedError) Unwrap() error {
+	return ErrTooManyRequests
+}
+
+// Manager logs users in without a password: it delivers them a short code, along with a magic link carrying a
+// longer token, and logs them in once when they send either back before it expires.
+type Manager struct {
+	services services.Services
+	delivery Delivery
+	codeTTL  time.Duration
+	// linkURL is the page of the frontend logging in with magic links, only codes are delivered if it is empty
+	linkURL string
+	// maxRequests and maxIPRequests are the login codes a username or an email, and a client address, can
+	// request in a window
+	maxRequests   int
+	maxIPRequests int
+	limiter       *requestLimiter
+}
+
+// NewManager creates a Manager delivering login codes lasting codeTTL, DefaultCodeTTL if zero, with magic links
+// to linkURL. Usernames and emails can request maxRequests codes an hour, and client addresses maxIPRequests,
+// DefaultMaxRequests and DefaultMaxIPRequests if zero.
+func NewManager(services services.Services, delivery Delivery, codeTTL time.Duration, linkURL string, maxRequests, maxIPRequests int) *Manager {
+	if codeTTL <= 0 {
+		codeTTL = DefaultCodeTTL
+	}
+	if maxRequests <= 0 {
+		maxRequests = DefaultMaxRequests
+	}
+	if maxIPRequests <= 0 {
+		maxIPRequests = DefaultMaxIPRequests
+	}
+
+	return &Manager{
+		services:      services,
+		delivery:      delivery,
+		codeTTL:       codeTTL,
+		linkURL:       linkURL,
+		maxRequests:   maxRequests,
+		maxIPRequests: maxIPRequests,
+		limiter:       newRequestLimiter(requestWindow),
+	}
+}
+
+// Request delivers a login code to the user with identifier as username or email. It returns nil when no user
+// has identifier, so responses do not reveal which users exist, and a *LimitedError if identifier or clientIP
+// requested too many codes, whether or not a user has identifier.
+func (manager *Manager) Request(identifier, clientIP string) error {
+	if clientIP = normalizeIP(clientIP); clientIP != "" {
+		if retryAfter := manager.limiter.allow("ip:"+clientIP, manager.maxIPRequests); retryAfter > 0 {
+			return &LimitedError{RetryAfter: retryAfter}
+		}
+	}
+	if retryAfter := manager.limiter.allow("user:"+strings.ToLower(identifier), manager.maxRequests); retryAfter > 0 {
+		return &LimitedError{RetryAfter: retryAfter}
+	}
+
+	user, err := manager.findUser(identifier)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	code, err := randomCode()
+	if err != nil {
+		return err
+	}
+	loginToken, err := randomHex(tokenBytes)
+	if err != nil {
+		return err
+	}
+
+	loginCode, err := manager.services.CreateLoginCode(models.LoginCode{
+		Username:    user.Username,
+		HashedCode:  hashSecret(code),
+		HashedToken: hashSecret(loginToken),
+		ExpiresAt:   time.Now().Add(manager.codeTTL).UTC(),
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return manager.delivery.Deliver(Message{
+		User:      user,
+		Code:      code,
+		Link:      manager.link(loginToken),
+		ExpiresAt: loginCode.ExpiresAt,
+	})
+}
+
+// VerifyCode returns the user with identifier as username or email if code is the last login code delivered to
+// them, and uses it up. It returns ErrInvalidCode if the code is wrong, expired, already used, or if too many
+// wrong codes were tried.
+func (manager *Manager) VerifyCode(identifier, code string) (models.User, error) {
+	user, err := manager.findUser(identifier)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, ErrInvalidCode
+		}
+		return models.User{}, err
+	}
+
+	loginCode, err := manager.services.GetLatestLoginCode(user.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, ErrInvalidCode
+		}
+		return models.User{}, err
+	}
+	if !usable(loginCode) {
+		return models.User{}, ErrInvalidCode
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(code)), []byte(loginCode.HashedCode)) != 1 {
+		if _, err := manager.services.RecordLoginCodeAttempt(loginCode.ID); err != nil {
+			return models.User{}, err
+		}
+		return models.User{}, ErrInvalidCode
+	}
+
+	return manager.use(loginCode, user)
+}
+
+// VerifyLink returns the user a magic link token was delivered to, and uses up its login code. It returns
+// ErrInvalidCode if the token is unknown, expired or already used.
+func (manager *Manager) VerifyLink(loginToken string) (models.User, error) {
+	loginCode, err := manager.services.GetLoginCodeByToken(hashSecret(loginToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, ErrInvalidCode
+		}
+		return models.User{}, err
+	}
+	if !usable(loginCode) {
+		return models.User{}, ErrInvalidCode
+	}
+
+	user, err := manager.services.GetUser(loginCode.Username)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return manager.use(loginCode, user)
+}
+
+// use uses up loginCode, so it logs in once, and returns its user.
+func (manager *Manager) use(loginCode models.LoginCode, user models.User) (models.User, error) {
+	if err := manager.services.UseLoginCode(loginCode); err != nil {
+		if errors.Is(err, services.ErrLoginCodeUsed) {
+			return models.User{}, ErrInvalidCode
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// findUser returns the user with identifier as email if it contains an @, as username otherwise.
+func (manager *Manager) findUser(identifier string) (models.User, error) {
+	if strings.Contains(identifier, "@") {
+		return manager.services.GetUserByEmail(identifier)
+	}
+
+	return manager.services.GetUser(identifier)
+}
+
+// link returns the magic link carrying loginToken, or an empty string if no login page is configured.
+func (manager *Manager) link(loginToken string) string {
+	link, err := url.Parse(manager.linkURL)
+	if err != nil || manager.linkURL == "" {
+		return ""
+	}
+
+	query := link.Query()
+	query.Set("token", loginToken)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
+
+// usable reports whether loginCode can still log in.
+func usable(loginCode models.LoginCode) bool {
+	return loginCode.UsedAt == nil && time.Now().Before(loginCode.ExpiresAt) && loginCode.Attempts < maxCodeAttempts
+}
+
+// hashSecret hashes codes and magic link tokens. Codes are short, but they expire quickly and cannot be tried
+// more than a few times, so a fast hash is enough.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomCode returns a random code of codeDigits digits.
+func randomCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < codeDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", codeDigits, n), nil
+}
+
+func randomHex(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
+
+// normalizeIP removes the port of addresses, e.g. the peer addresses of grpc requests.
+func normalizeIP(clientIP string) string {
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		return host
+	}
+
+	return clientIP
+}
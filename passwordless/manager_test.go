@@ -0,0 +1,299 @@
+package passwordless
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/mail"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingDelivery records the login codes it delivers.
+type recordingDelivery struct {
+	messages []Message
+}
+
+func (delivery *recordingDelivery) Deliver(message Message) error {
+	delivery.messages = append(delivery.messages, message)
+	return nil
+}
+
+func randomUser() models.User {
+	return models.User{
+		Username: util.RandomUsername(),
+		FullName: util.RandomFullname(),
+		Email:    util.RandomEmail(),
+	}
+}
+
+// requestCode requests a login code for user and returns it as stored and as delivered.
+func requestCode(t *testing.T, manager *Manager, mockServices *mockdb.MockServices, delivery *recordingDelivery, user models.User) (models.LoginCode, Message) {
+	var stored models.LoginCode
+	mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+	mockServices.EXPECT().
+		CreateLoginCode(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(code models.LoginCode) (models.LoginCode, error) {
+			code.ID = util.RandomInt(1, 1000)
+			stored = code
+			return code, nil
+		})
+
+	require.NoError(t, manager.Request(user.Username, "10.0.0.1"))
+	require.NotEmpty(t, delivery.messages)
+
+	return stored, delivery.messages[len(delivery.messages)-1]
+}
+
+func TestRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	delivery := &recordingDelivery{}
+	manager := NewManager(mockServices, delivery, 5*time.Minute, "https://bank.example.com/login", 0, 0)
+	user := randomUser()
+
+	stored, message := requestCode(t, manager, mockServices, delivery, user)
+	require.Equal(t, user.Username, stored.Username)
+	require.WithinDuration(t, time.Now().Add(5*time.Minute), stored.ExpiresAt, time.Second)
+
+	require.Equal(t, user, message.User)
+	require.Regexp(t, `^\d{6}$`, message.Code)
+	require.Equal(t, hashSecret(message.Code), stored.HashedCode)
+	link, err := url.Parse(message.Link)
+	require.NoError(t, err)
+	require.Equal(t, "bank.example.com", link.Host)
+	require.Equal(t, hashSecret(link.Query().Get("token")), stored.HashedToken)
+	require.Equal(t, stored.ExpiresAt, message.ExpiresAt)
+
+	// users can be found by email too
+	mockServices.EXPECT().GetUserByEmail(user.Email).Times(1).Return(user, nil)
+	mockServices.EXPECT().CreateLoginCode(gomock.Any()).Times(1).Return(models.LoginCode{}, nil)
+	require.NoError(t, manager.Request(user.Email, ""))
+	require.Len(t, delivery.messages, 2)
+
+	// unknown users succeed alike, so they cannot be told apart
+	mockServices.EXPECT().GetUser(gomock.Any()).Times(1).Return(models.User{}, gorm.ErrRecordNotFound)
+	require.NoError(t, manager.Request(util.RandomUsername(), ""))
+	require.Len(t, delivery.messages, 2)
+}
+
+func TestRequestWithoutLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	delivery := &recordingDelivery{}
+	manager := NewManager(mockServices, delivery, 0, "", 0, 0)
+
+	stored, message := requestCode(t, manager, mockServices, delivery, randomUser())
+	require.Empty(t, message.Link)
+	require.WithinDuration(t, time.Now().Add(DefaultCodeTTL), stored.ExpiresAt, time.Second)
+}
+
+func TestRequestLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	manager := NewManager(mockServices, &recordingDelivery{}, 0, "", 2, 3)
+	mockServices.EXPECT().GetUser(gomock.Any()).AnyTimes().Return(models.User{}, gorm.ErrRecordNotFound)
+
+	// unknown users are limited alike, so limits do not reveal which users exist
+	username := util.RandomUsername()
+	require.NoError(t, manager.Request(username, "10.0.0.1:52000"))
+	require.NoError(t, manager.Request(username, "10.0.0.2"))
+
+	var limited *LimitedError
+	err := manager.Request(username, "10.0.0.3")
+	require.ErrorAs(t, err, &limited)
+	require.ErrorIs(t, err, ErrTooManyRequests)
+	require.InDelta(t, requestWindow.Seconds(), limited.RetryAfter.Seconds(), 1)
+
+	// usernames are limited whatever their case
+	require.ErrorIs(t, manager.Request(strings.ToUpper(username), "10.0.0.4"), ErrTooManyRequests)
+
+	// addresses are limited across usernames, the port being ignored
+	require.NoError(t, manager.Request(util.RandomUsername(), "10.0.0.1"))
+	require.NoError(t, manager.Request(util.RandomUsername(), "10.0.0.1:52001"))
+	require.ErrorIs(t, manager.Request(util.RandomUsername(), "10.0.0.1:52002"), ErrTooManyRequests)
+}
+
+func TestVerifyCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	delivery := &recordingDelivery{}
+	manager := NewManager(mockServices, delivery, 0, "", 0, 0)
+	user := randomUser()
+
+	stored, message := requestCode(t, manager, mockServices, delivery, user)
+
+	mockServices.EXPECT().GetUserByEmail(user.Email).Times(1).Return(user, nil)
+	mockServices.EXPECT().GetLatestLoginCode(user.Username).Times(1).Return(stored, nil)
+	mockServices.EXPECT().UseLoginCode(stored).Times(1).Return(nil)
+
+	loggedIn, err := manager.VerifyCode(user.Email, message.Code)
+	require.NoError(t, err)
+	require.Equal(t, user, loggedIn)
+}
+
+func TestVerifyInvalidCode(t *testing.T) {
+	user := randomUser()
+	code := "123456"
+	usedAt := time.Now()
+	loginCode := models.LoginCode{
+		ID:         1,
+		Username:   user.Username,
+		HashedCode: hashSecret(code),
+		ExpiresAt:  time.Now().Add(time.Minute),
+	}
+
+	testCases := []struct {
+		name       string
+		code       string
+		buildStubs func(mockServices *mockdb.MockServices)
+	}{
+		{
+			name: "UnknownUser",
+			code: code,
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().GetUser(user.Username).Times(1).Return(models.User{}, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "NoCode",
+			code: code,
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				mockServices.EXPECT().GetLatestLoginCode(user.Username).Times(1).Return(models.LoginCode{}, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "WrongCode",
+			code: "654321",
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				mockServices.EXPECT().GetLatestLoginCode(user.Username).Times(1).Return(loginCode, nil)
+				mockServices.EXPECT().RecordLoginCodeAttempt(loginCode.ID).Times(1).Return(loginCode, nil)
+				mockServices.EXPECT().UseLoginCode(gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Expired",
+			code: code,
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				expired := loginCode
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+				mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				mockServices.EXPECT().GetLatestLoginCode(user.Username).Times(1).Return(expired, nil)
+				mockServices.EXPECT().UseLoginCode(gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Used",
+			code: code,
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				used := loginCode
+				used.UsedAt = &usedAt
+				mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				mockServices.EXPECT().GetLatestLoginCode(user.Username).Times(1).Return(used, nil)
+				mockServices.EXPECT().UseLoginCode(gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "TooManyAttempts",
+			code: code,
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				attempted := loginCode
+				attempted.Attempts = maxCodeAttempts
+				mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				mockServices.EXPECT().GetLatestLoginCode(user.Username).Times(1).Return(attempted, nil)
+				mockServices.EXPECT().UseLoginCode(gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "UsedConcurrently",
+			code: code,
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+				mockServices.EXPECT().GetLatestLoginCode(user.Username).Times(1).Return(loginCode, nil)
+				mockServices.EXPECT().UseLoginCode(loginCode).Times(1).Return(services.ErrLoginCodeUsed)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockServices := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(mockServices)
+
+			_, err := NewManager(mockServices, &recordingDelivery{}, 0, "", 0, 0).VerifyCode(user.Username, testCase.code)
+			require.ErrorIs(t, err, ErrInvalidCode)
+		})
+	}
+}
+
+func TestVerifyLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	delivery := &recordingDelivery{}
+	manager := NewManager(mockServices, delivery, 0, "https://bank.example.com/login", 0, 0)
+	user := randomUser()
+
+	stored, message := requestCode(t, manager, mockServices, delivery, user)
+	link, err := url.Parse(message.Link)
+	require.NoError(t, err)
+	loginToken := link.Query().Get("token")
+
+	mockServices.EXPECT().GetLoginCodeByToken(stored.HashedToken).Times(1).Return(stored, nil)
+	mockServices.EXPECT().GetUser(user.Username).Times(1).Return(user, nil)
+	mockServices.EXPECT().UseLoginCode(stored).Times(1).Return(nil)
+
+	loggedIn, err := manager.VerifyLink(loginToken)
+	require.NoError(t, err)
+	require.Equal(t, user, loggedIn)
+
+	mockServices.EXPECT().GetLoginCodeByToken(gomock.Any()).Times(1).Return(models.LoginCode{}, gorm.ErrRecordNotFound)
+	_, err = manager.VerifyLink(util.RandomString(64, util.LOWERCASE))
+	require.ErrorIs(t, err, ErrInvalidCode)
+}
+
+func TestEmailDelivery(t *testing.T) {
+	mailer := &recordingMailer{}
+	user := randomUser()
+
+	require.NoError(t, NewEmailDelivery(mailer).Deliver(Message{
+		User:      user,
+		Code:      "012345",
+		Link:      "https://bank.example.com/login?token=abc",
+		ExpiresAt: time.Now().Add(time.Minute),
+	}))
+
+	require.Len(t, mailer.messages, 1)
+	require.Equal(t, user.Email, mailer.messages[0].To)
+	require.Contains(t, mailer.messages[0].Body, "012345")
+	require.Regexp(t, regexp.MustCompile(`https://bank\.example\.com/login\?token=abc`), mailer.messages[0].Body)
+
+	_, err := NewDelivery("carrier pigeon", mailer)
+	require.Error(t, err)
+	delivery, err := NewDelivery("", mailer)
+	require.NoError(t, err)
+	require.IsType(t, &EmailDelivery{}, delivery)
+	delivery, err = NewDelivery(DeliveryLog, mailer)
+	require.NoError(t, err)
+	require.NoError(t, delivery.Deliver(Message{User: user, Code: "012345"}))
+}
+
+// recordingMailer records the emails it sends.
+type recordingMailer struct {
+	messages []mail.Message
+}
+
+func (mailer *recordingMailer) Send(message mail.Message) error {
+	mailer.messages = append(mailer.messages, message)
+	return nil
+}
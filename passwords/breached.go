@@ -0,0 +1,133 @@
+package passwords
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+)
+
+// rangePrefixLength is the number of hex characters of the hashes sharing a range, 16^5 ranges like the range API
+// of Pwned Passwords
+const rangePrefixLength = 5
+
+// BreachedList looks up passwords in a local list of the SHA-1 hashes of breached passwords, the format of the
+// Pwned Passwords list: a line per hash in uppercase hex, optionally followed by a colon and a count, ordered by
+// hash. Like the k-anonymity range API of Pwned Passwords, lookups only read the range of hashes starting with
+// the first characters of the hash of the password, and compare the rest of the hash locally, so the list can
+// be replaced by the API without changing lookups.
+type BreachedList struct {
+	path string
+}
+
+// NewBreachedList creates a BreachedList of the hashes of the file at path, returning an error if it cannot be
+// read. The file is read at every lookup, so it can be updated without restarting.
+func NewBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	return &BreachedList{path: path}, nil
+}
+
+// Contains reports whether password appears in the list.
+func (list *BreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, err := list.Range(hash[:rangePrefixLength])
+	if err != nil {
+		return false, err
+	}
+	for _, suffix := range suffixes {
+		if suffix == hash[rangePrefixLength:] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Range returns the rest of the hashes of the list starting with prefix.
+func (list *BreachedList) Range(prefix string) ([]string, error) {
+	prefix = strings.ToUpper(prefix)
+
+	file, err := os.Open(list.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// binary search of the first line not ordered before prefix, over the offsets of the file:
+	// an offset stands for the first line starting at or after it
+	low, high := int64(0), info.Size()
+	for low < high {
+		middle := low + (high-low)/2
+		reader, err := lineReader(file, middle)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := readHash(reader)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if err == io.EOF || hash >= prefix {
+			high = middle
+		} else {
+			low = middle + 1
+		}
+	}
+
+	reader, err := lineReader(file, low)
+	if err != nil {
+		return nil, err
+	}
+	var suffixes []string
+	for {
+		hash, err := readHash(reader)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if err == io.EOF || !strings.HasPrefix(hash, prefix) {
+			return suffixes, nil
+		}
+		suffixes = append(suffixes, hash[len(prefix):])
+	}
+}
+
+// lineReader returns a reader of file from the first line starting at or after offset.
+func lineReader(file *os.File, offset int64) (*bufio.Reader, error) {
+	if offset == 0 {
+		return bufio.NewReader(io.NewSectionReader(file, 0, 1<<62)), nil
+	}
+
+	// the line offset falls in is skipped, unless offset is right after a line break
+	reader := bufio.NewReader(io.NewSectionReader(file, offset-1, 1<<62))
+	if _, err := reader.ReadString('\n'); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+// readHash reads the next line of reader and returns its hash in uppercase, or io.EOF at the end of the file.
+func readHash(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+	return strings.ToUpper(hash), nil
+}
@@ -0,0 +1,82 @@
+package passwords
+
+import (
+	"Simple-Bank/util"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBreachedList(t *testing.T) {
+	var breached []string
+	var hashes []string
+	for i := 0; i < 1000; i++ {
+		password := util.RandomPassword()
+		sum := sha1.Sum([]byte(password))
+		breached = append(breached, password)
+		hashes = append(hashes, strings.ToUpper(hex.EncodeToString(sum[:])))
+	}
+	sort.Strings(hashes)
+
+	var lines []string
+	for i, hash := range hashes {
+		// counts are optional
+		if i%2 == 0 {
+			hash = fmt.Sprintf("%s:%d", hash, i+1)
+		}
+		lines = append(lines, hash)
+	}
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600))
+
+	list, err := NewBreachedList(path)
+	require.NoError(t, err)
+
+	for _, password := range breached {
+		contains, err := list.Contains(password)
+		require.NoError(t, err)
+		require.True(t, contains, password)
+	}
+	for i := 0; i < 100; i++ {
+		contains, err := list.Contains(util.RandomPassword())
+		require.NoError(t, err)
+		require.False(t, contains)
+	}
+
+	// the first and the last hashes of the file are in their range
+	suffixes, err := list.Range(hashes[0][:rangePrefixLength])
+	require.NoError(t, err)
+	require.Contains(t, suffixes, hashes[0][rangePrefixLength:])
+	suffixes, err = list.Range(strings.ToLower(hashes[len(hashes)-1][:rangePrefixLength]))
+	require.NoError(t, err)
+	require.Contains(t, suffixes, hashes[len(hashes)-1][rangePrefixLength:])
+
+	for _, hash := range hashes {
+		suffixes, err := list.Range(hash[:rangePrefixLength])
+		require.NoError(t, err)
+		for _, suffix := range suffixes {
+			require.Len(t, suffix, len(hash)-rangePrefixLength)
+		}
+	}
+}
+
+func TestEmptyBreachedList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	list, err := NewBreachedList(path)
+	require.NoError(t, err)
+
+	contains, err := list.Contains(util.RandomPassword())
+	require.NoError(t, err)
+	require.False(t, contains)
+
+	_, err = NewBreachedList(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+}
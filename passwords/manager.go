@@ -0,0 +1,185 @@
+package passwords
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/mail"
+	"Simple-Bank/util"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultResetTTL is how long password reset tokens last when no ttl is configured
+	DefaultResetTTL = time.Hour
+	// tokenBytes is the number of random bytes of a password reset token
+	tokenBytes = 32
+)
+
+var (
+	// ErrInvalidToken is returned when a password reset token is unknown, expired or already used
+	ErrInvalidToken = errors.New("password reset token is invalid or has expired")
+	// ErrInvalidCredentials is returned when a user changes their password with a wrong current password
+	ErrInvalidCredentials = errors.New("current password is incorrect")
+)
+
+// Manager runs the flows changing the password of users: resetting a forgotten password with a token emailed to
+// the user, and changing the password of a logged-in user who proves they know the current one.
+type Manager struct {
+	services services.Services
+	mailer   mail.Mailer
+	resetTTL time.Duration
+	// resetURL is the page of the frontend resetting passwords, the emails only carry the token if it is empty
+	resetURL string
+	// policy is the policy the new passwords must satisfy
+	policy *Policy
+}
+
+// NewManager creates a Manager emailing password reset tokens lasting resetTTL, DefaultResetTTL if zero,
+// in links to resetURL, and setting passwords satisfying policy.
+func NewManager(services services.Services, mailer mail.Mailer, resetTTL time.Duration, resetURL string, policy *Policy) *Manager {
+	if resetTTL <= 0 {
+		resetTTL = DefaultResetTTL
+	}
+
+	return &Manager{
+		services: services,
+		mailer:   mailer,
+		resetTTL: resetTTL,
+		resetURL: resetURL,
+		policy:   policy,
+	}
+}
+
+// RequestReset emails a password reset token to the user with email. It returns nil when no user has email,
+// so responses do not reveal which emails are registered.
+func (manager *Manager) RequestReset(email string) error {
+	user, err := manager.services.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	resetToken, err := randomHex(tokenBytes)
+	if err != nil {
+		return err
+	}
+
+	reset, err := manager.services.CreatePasswordReset(models.PasswordReset{
+		HashedToken: hashToken(resetToken),
+		Username:    user.Username,
+		ExpiresAt:   time.Now().Add(manager.resetTTL).UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return manager.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    manager.resetBody(user, resetToken, reset.ExpiresAt),
+	})
+}
+
+// Reset sets the password of the user a reset token was emailed to, and returns the user. Every session of the
+// user is blocked. It returns ErrInvalidToken if the token is unknown, expired or already used, and a
+// *RejectedError if password does not satisfy the policy.
+func (manager *Manager) Reset(resetToken, password string) (models.User, error) {
+	reset, err := manager.services.GetPasswordReset(hashToken(resetToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, ErrInvalidToken
+		}
+		return models.User{}, err
+	}
+	if reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return models.User{}, ErrInvalidToken
+	}
+	if err := manager.policy.ValidateChange(reset.Username, password); err != nil {
+		return models.User{}, err
+	}
+
+	user, err := manager.services.ResetPassword(reset, password)
+	if err != nil {
+		if errors.Is(err, services.ErrPasswordResetUsed) {
+			return models.User{}, ErrInvalidToken
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// Change sets the password of a user who proved they know their current password. Every other session of the
+// user than the family of keepSessionID is blocked, and it returns the number of sessions blocked.
+// It returns ErrInvalidCredentials if currentPassword is wrong, and a *RejectedError if newPassword does not
+// satisfy the policy.
+func (manager *Manager) Change(username, currentPassword, newPassword string, keepSessionID uuid.UUID) (int64, error) {
+	user, err := manager.services.GetUser(username)
+	if err != nil {
+		return 0, err
+	}
+	if err := util.CheckPassword(currentPassword, user.HashedPassword); err != nil {
+		return 0, ErrInvalidCredentials
+	}
+	if err := manager.policy.ValidateChange(username, newPassword); err != nil {
+		return 0, err
+	}
+
+	if _, err := manager.services.UpdateUser(services.UpdateUserRequest{
+		Username: username,
+		Password: &newPassword,
+	}); err != nil {
+		return 0, err
+	}
+
+	if keepSessionID == uuid.Nil {
+		return 0, nil
+	}
+
+	return manager.services.RevokeOtherSessions(username, keepSessionID)
+}
+
+// resetBody returns the text of the email carrying resetToken.
+func (manager *Manager) resetBody(user models.User, resetToken string, expiresAt time.Time) string {
+	var body strings.Builder
+
+	fmt.Fprintf(&body, "Hello %s,\n\n", user.FullName)
+	if link, err := url.Parse(manager.resetURL); err == nil && manager.resetURL != "" {
+		query := link.Query()
+		query.Set("token", resetToken)
+		link.RawQuery = query.Encode()
+		fmt.Fprintf(&body, "Open this link to choose a new password:\n\n%s\n\n", link)
+	} else {
+		fmt.Fprintf(&body, "Use this token to choose a new password:\n\n%s\n\n", resetToken)
+	}
+	fmt.Fprintf(&body, "It expires on %s. If you did not ask to reset your password, ignore this email, "+
+		"your password is unchanged.\n", expiresAt.Format(time.RFC1123))
+
+	return body.String()
+}
+
+// hashToken hashes password reset tokens, which are long random strings, so a fast hash is enough.
+func hashToken(resetToken string) string {
+	sum := sha256.Sum256([]byte(resetToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
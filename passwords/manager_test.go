@@ -0,0 +1,219 @@
+package passwords
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/mail"
+	"Simple-Bank/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// recordingMailer records the emails it sends.
+type recordingMailer struct {
+	messages []mail.Message
+}
+
+func (mailer *recordingMailer) Send(message mail.Message) error {
+	mailer.messages = append(mailer.messages, message)
+	return nil
+}
+
+func randomUser(t *testing.T) (models.User, string) {
+	password := util.RandomPassword()
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	return models.User{
+		Username:       util.RandomUsername(),
+		FullName:       util.RandomFullname(),
+		Email:          util.RandomEmail(),
+		HashedPassword: hashedPassword,
+	}, password
+}
+
+// newTestManager creates a Manager of the default policy.
+func newTestManager(t *testing.T, mockServices *mockdb.MockServices, mailer mail.Mailer, resetTTL time.Duration, resetURL string) *Manager {
+	policy, err := NewPolicy(mockServices, 0, 0, nil, "", 0, "")
+	require.NoError(t, err)
+
+	return NewManager(mockServices, mailer, resetTTL, resetURL, policy)
+}
+
+func TestRequestAndReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	mailer := &recordingMailer{}
+	manager := newTestManager(t, mockServices, mailer, 10*time.Minute, "https://bank.example.com/reset_password")
+	user, _ := randomUser(t)
+
+	var stored models.PasswordReset
+	mockServices.EXPECT().GetUserByEmail(user.Email).Times(1).Return(user, nil)
+	mockServices.EXPECT().
+		CreatePasswordReset(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(reset models.PasswordReset) (models.PasswordReset, error) {
+			stored = reset
+			return reset, nil
+		})
+
+	require.NoError(t, manager.RequestReset(user.Email))
+	require.Equal(t, user.Username, stored.Username)
+	require.WithinDuration(t, time.Now().Add(10*time.Minute), stored.ExpiresAt, time.Second)
+
+	require.Len(t, mailer.messages, 1)
+	require.Equal(t, user.Email, mailer.messages[0].To)
+	link, err := url.Parse(regexp.MustCompile(`https://\S+`).FindString(mailer.messages[0].Body))
+	require.NoError(t, err)
+	resetToken := link.Query().Get("token")
+	require.Equal(t, hashToken(resetToken), stored.HashedToken)
+
+	password := util.RandomPassword()
+	mockServices.EXPECT().GetPasswordReset(stored.HashedToken).Times(1).Return(stored, nil)
+	mockServices.EXPECT().
+		ListPasswordHistory(user.Username, DefaultHistorySize).
+		Times(1).
+		Return([]models.PasswordHistory{{Username: user.Username, HashedPassword: user.HashedPassword}}, nil)
+	mockServices.EXPECT().ResetPassword(stored, password).Times(1).Return(user, nil)
+
+	reset, err := manager.Reset(resetToken, password)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, reset.Username)
+}
+
+func TestRequestResetUnknownEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	mailer := &recordingMailer{}
+
+	mockServices.EXPECT().GetUserByEmail(gomock.Any()).Times(1).Return(models.User{}, gorm.ErrRecordNotFound)
+	mockServices.EXPECT().CreatePasswordReset(gomock.Any()).Times(0)
+
+	// unknown emails succeed alike, so they cannot be told apart from registered ones
+	require.NoError(t, newTestManager(t, mockServices, mailer, 0, "").RequestReset(util.RandomEmail()))
+	require.Empty(t, mailer.messages)
+}
+
+func TestResetInvalidToken(t *testing.T) {
+	resetToken := util.RandomString(64, util.LOWERCASE)
+	usedAt := time.Now()
+
+	testCases := []struct {
+		name       string
+		buildStubs func(mockServices *mockdb.MockServices)
+	}{
+		{
+			name: "Unknown",
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().GetPasswordReset(gomock.Any()).Times(1).Return(models.PasswordReset{}, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "Expired",
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().
+					GetPasswordReset(hashToken(resetToken)).
+					Times(1).
+					Return(models.PasswordReset{ExpiresAt: time.Now().Add(-time.Second)}, nil)
+				mockServices.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Used",
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().
+					GetPasswordReset(hashToken(resetToken)).
+					Times(1).
+					Return(models.PasswordReset{ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}, nil)
+				mockServices.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "UsedConcurrently",
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().
+					GetPasswordReset(hashToken(resetToken)).
+					Times(1).
+					Return(models.PasswordReset{ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				mockServices.EXPECT().ListPasswordHistory(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+				mockServices.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Times(1).Return(models.User{}, services.ErrPasswordResetUsed)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockServices := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(mockServices)
+
+			_, err := newTestManager(t, mockServices, &recordingMailer{}, 0, "").Reset(resetToken, util.RandomPassword())
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	manager := newTestManager(t, mockServices, &recordingMailer{}, 0, "")
+	user, password := randomUser(t)
+	newPassword := util.RandomPassword()
+	sessionID := uuid.New()
+
+	mockServices.EXPECT().GetUser(user.Username).AnyTimes().Return(user, nil)
+	mockServices.EXPECT().
+		ListPasswordHistory(user.Username, DefaultHistorySize).
+		AnyTimes().
+		Return([]models.PasswordHistory{{Username: user.Username, HashedPassword: user.HashedPassword}}, nil)
+
+	_, err := manager.Change(user.Username, "wrong password", newPassword, sessionID)
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	mockServices.EXPECT().
+		UpdateUser(services.UpdateUserRequest{Username: user.Username, Password: &newPassword}).
+		Times(2).
+		Return(user, nil)
+	mockServices.EXPECT().RevokeOtherSessions(user.Username, sessionID).Times(1).Return(int64(2), nil)
+
+	revoked, err := manager.Change(user.Username, password, newPassword, sessionID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), revoked)
+
+	// credentials without a session have no other session to keep
+	revoked, err = manager.Change(user.Username, password, newPassword, uuid.Nil)
+	require.NoError(t, err)
+	require.Zero(t, revoked)
+}
+
+func TestChangeRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	manager := newTestManager(t, mockServices, &recordingMailer{}, 0, "")
+	user, password := randomUser(t)
+
+	mockServices.EXPECT().GetUser(user.Username).AnyTimes().Return(user, nil)
+	mockServices.EXPECT().
+		ListPasswordHistory(user.Username, DefaultHistorySize).
+		Times(1).
+		Return([]models.PasswordHistory{{Username: user.Username, HashedPassword: user.HashedPassword}}, nil)
+	mockServices.EXPECT().UpdateUser(gomock.Any()).Times(0)
+
+	var rejected *RejectedError
+	_, err := manager.Change(user.Username, password, "short", uuid.New())
+	require.ErrorAs(t, err, &rejected)
+	require.Equal(t, RuleMinLength, rejected.Rule.Name)
+
+	// the current password is the last one of the history
+	_, err = manager.Change(user.Username, password, password, uuid.New())
+	require.ErrorAs(t, err, &rejected)
+	require.ErrorIs(t, err, ErrPasswordRejected)
+	require.Equal(t, RuleNotReused, rejected.Rule.Name)
+}
@@ -0,0 +1,324 @@
+package passwords
+
+import (
+	"Simple-Bank/db/services"
+	"Simple-Bank/util"
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMinLength is the shortest password allowed when no length is configured
+	DefaultMinLength = 8
+	// DefaultMaxLength is the longest password allowed when no length is configured
+	DefaultMaxLength = 64
+	// MaxLength is the longest password any policy allows, bounding the cost of hashing passwords
+	MaxLength = 256
+	// DefaultHistorySize is the number of last passwords of a user that cannot be reused when none is configured
+	DefaultHistorySize = 5
+)
+
+// names of the rules of a policy, also the names of the character classes passwords may have to contain
+const (
+	RuleMinLength         = "min_length"
+	RuleMaxLength         = "max_length"
+	RuleLowercase         = "lowercase"
+	RuleUppercase         = "uppercase"
+	RuleDigit             = "digit"
+	RuleSymbol            = "symbol"
+	RuleAllowedCharacters = "allowed_characters"
+	RuleNotBreached       = "not_breached"
+	RuleNotReused         = "not_reused"
+)
+
+// allowed characters of a policy
+const (
+	// AllowedCharactersUnicode allows letters of any script, marks, digits, symbols and spaces,
+	// but no control characters
+	AllowedCharactersUnicode = "unicode"
+	// AllowedCharactersASCII allows printable ASCII characters and spaces
+	AllowedCharactersASCII = "ascii"
+)
+
+const (
+	// noClasses is the required classes of a policy requiring none, since no classes requires all of them
+	noClasses = "none"
+	// maxHistorySize is the number of passwords kept in the history of users
+	maxHistorySize = services.MaxPasswordHistory
+)
+
+// notBreachedRule is the rule of policies checking passwords against a list of breached passwords
+var notBreachedRule = Rule{Name: RuleNotBreached, Description: "must not appear in known data breaches"}
+
+// characterClasses are the classes of characters passwords may have to contain, in the order of their rules
+var characterClasses = []struct {
+	name        string
+	description string
+	matches     func(r rune) bool
+}{
+	{name: RuleLowercase, description: "must contain a lowercase letter", matches: unicode.IsLower},
+	{name: RuleUppercase, description: "must contain an uppercase letter", matches: unicode.IsUpper},
+	{name: RuleDigit, description: "must contain a digit", matches: unicode.IsDigit},
+	{name: RuleSymbol, description: "must contain a symbol, a punctuation mark or a space", matches: func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	}},
+}
+
+// ErrPasswordRejected is wrapped by the errors of passwords that do not satisfy the policy
+var ErrPasswordRejected = errors.New("password does not satisfy the password policy")
+
+// RejectedError is the error of a password not satisfying a rule of the policy. It wraps ErrPasswordRejected.
+type RejectedError struct {
+	Rule Rule
+}
+
+func (err *RejectedError) Error() string {
+	return "password " + err.Rule.Description
+}
+
+func (err *RejectedError) Unwrap() error {
+	return ErrPasswordRejected
+}
+
+// Rule is a rule of the policy, described for clients so they can show the rules to users.
+type Rule struct {
+	// Name is one of the Rule constants
+	Name string
+	// Description completes a sentence starting with "password", e.g. "must contain a digit"
+	Description string
+}
+
+// Policy is the rules new passwords must satisfy: a length, classes of characters they must contain, the
+// characters they may contain, not appearing in a list of breached passwords, and not being one of the last
+// passwords of the user.
+type Policy struct {
+	services  services.Services
+	minLength int
+	maxLength int
+	// requiredClasses are the names of the classes of characters passwords must contain
+	requiredClasses map[string]bool
+	asciiOnly       bool
+	// historySize is the number of last passwords of a user that cannot be reused, the current one included
+	historySize int
+	// breached lists the breached passwords, nil if passwords are not checked against breaches
+	breached *BreachedList
+}
+
+// NewPolicy creates a Policy of passwords from minLength to maxLength characters, DefaultMinLength and
+// DefaultMaxLength if zero, containing a character of each of requiredClasses, every class if empty or none if
+// it is "none", and only allowedCharacters, AllowedCharactersUnicode if empty. The last historySize passwords of a
+// user cannot be reused, DefaultHistorySize if zero or none if negative, and passwords of breachedPasswordsFile
+// are rejected, see NewBreachedList, unless it is empty.
+func NewPolicy(
+	services services.Services,
+	minLength, maxLength int,
+	requiredClasses []string,
+	allowedCharacters string,
+	historySize int,
+	breachedPasswordsFile string,
+) (*Policy, error) {
+	if minLength <= 0 {
+		minLength = DefaultMinLength
+	}
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+	if maxLength > MaxLength {
+		return nil, fmt.Errorf("passwords cannot be longer than %d characters", MaxLength)
+	}
+	if minLength > maxLength {
+		return nil, fmt.Errorf("the minimum length of passwords %d exceeds their maximum length %d", minLength, maxLength)
+	}
+
+	if historySize == 0 {
+		historySize = DefaultHistorySize
+	}
+	if historySize > maxHistorySize {
+		return nil, fmt.Errorf("the password history keeps at most %d passwords", maxHistorySize)
+	}
+
+	policy := &Policy{
+		services:        services,
+		minLength:       minLength,
+		maxLength:       maxLength,
+		requiredClasses: map[string]bool{},
+		historySize:     historySize,
+	}
+
+	switch allowedCharacters {
+	case "", AllowedCharactersUnicode:
+	case AllowedCharactersASCII:
+		policy.asciiOnly = true
+	default:
+		return nil, fmt.Errorf("unknown allowed characters %q", allowedCharacters)
+	}
+
+	if len(requiredClasses) == 0 {
+		for _, class := range characterClasses {
+			policy.requiredClasses[class.name] = true
+		}
+	}
+	for _, name := range requiredClasses {
+		if name == noClasses {
+			continue
+		}
+		if !isCharacterClass(name) {
+			return nil, fmt.Errorf("unknown character class %q", name)
+		}
+		policy.requiredClasses[name] = true
+	}
+
+	if breachedPasswordsFile != "" {
+		breached, err := NewBreachedList(breachedPasswordsFile)
+		if err != nil {
+			return nil, err
+		}
+		policy.breached = breached
+	}
+
+	return policy, nil
+}
+
+// MinLength returns the shortest password allowed in characters.
+func (policy *Policy) MinLength() int {
+	return policy.minLength
+}
+
+// MaxLength returns the longest password allowed in characters.
+func (policy *Policy) MaxLength() int {
+	return policy.maxLength
+}
+
+// Rules returns the rules of the policy.
+func (policy *Policy) Rules() []Rule {
+	rules := []Rule{policy.minLengthRule(), policy.maxLengthRule()}
+	for _, class := range characterClasses {
+		if policy.requiredClasses[class.name] {
+			rules = append(rules, Rule{Name: class.name, Description: class.description})
+		}
+	}
+	rules = append(rules, policy.allowedCharactersRule())
+	if policy.breached != nil {
+		rules = append(rules, notBreachedRule)
+	}
+	if policy.historySize > 0 {
+		rules = append(rules, policy.notReusedRule())
+	}
+
+	return rules
+}
+
+// Validate returns a *RejectedError if password does not satisfy the rules of the policy other than not being
+// reused, the only rule depending on the user.
+func (policy *Policy) Validate(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < policy.minLength {
+		return &RejectedError{Rule: policy.minLengthRule()}
+	}
+	if length > policy.maxLength {
+		return &RejectedError{Rule: policy.maxLengthRule()}
+	}
+
+	for _, r := range password {
+		if !policy.isAllowed(r) {
+			return &RejectedError{Rule: policy.allowedCharactersRule()}
+		}
+	}
+
+	for _, class := range characterClasses {
+		if !policy.requiredClasses[class.name] || containsFunc(password, class.matches) {
+			continue
+		}
+		return &RejectedError{Rule: Rule{Name: class.name, Description: class.description}}
+	}
+
+	if policy.breached != nil {
+		breached, err := policy.breached.Contains(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			return &RejectedError{Rule: notBreachedRule}
+		}
+	}
+
+	return nil
+}
+
+// ValidateChange returns a *RejectedError if password does not satisfy every rule of the policy, including not
+// being one of the last passwords of the user with username.
+func (policy *Policy) ValidateChange(username, password string) error {
+	if err := policy.Validate(password); err != nil {
+		return err
+	}
+	if policy.historySize <= 0 {
+		return nil
+	}
+
+	history, err := policy.services.ListPasswordHistory(username, policy.historySize)
+	if err != nil {
+		return err
+	}
+	for _, previous := range history {
+		if util.CheckPassword(password, previous.HashedPassword) == nil {
+			return &RejectedError{Rule: policy.notReusedRule()}
+		}
+	}
+
+	return nil
+}
+
+func (policy *Policy) minLengthRule() Rule {
+	return Rule{Name: RuleMinLength, Description: fmt.Sprintf("must be at least %d characters long", policy.minLength)}
+}
+
+func (policy *Policy) maxLengthRule() Rule {
+	return Rule{Name: RuleMaxLength, Description: fmt.Sprintf("must be at most %d characters long", policy.maxLength)}
+}
+
+func (policy *Policy) allowedCharactersRule() Rule {
+	if policy.asciiOnly {
+		return Rule{Name: RuleAllowedCharacters, Description: "must only contain printable ASCII characters"}
+	}
+
+	return Rule{Name: RuleAllowedCharacters, Description: "must not contain control characters"}
+}
+
+func (policy *Policy) notReusedRule() Rule {
+	if policy.historySize == 1 {
+		return Rule{Name: RuleNotReused, Description: "must not be the current password"}
+	}
+
+	return Rule{Name: RuleNotReused, Description: fmt.Sprintf("must not be one of the last %d passwords", policy.historySize)}
+}
+
+// isAllowed reports whether passwords may contain r.
+func (policy *Policy) isAllowed(r rune) bool {
+	if policy.asciiOnly {
+		return r >= ' ' && r <= '~'
+	}
+
+	return unicode.IsGraphic(r)
+}
+
+func isCharacterClass(name string) bool {
+	for _, class := range characterClasses {
+		if class.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFunc(password string, matches func(r rune) bool) bool {
+	for _, r := range password {
+		if matches(r) {
+			return true
+		}
+	}
+
+	return false
+}
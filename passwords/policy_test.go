@@ -0,0 +1,159 @@
+package passwords
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/util"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewPolicy(t *testing.T) {
+	policy, err := NewPolicy(nil, 0, 0, nil, "", 0, "")
+	require.NoError(t, err)
+	require.Equal(t, DefaultMinLength, policy.MinLength())
+	require.Equal(t, DefaultMaxLength, policy.MaxLength())
+	require.Equal(t, DefaultHistorySize, policy.historySize)
+	require.Equal(t, []Rule{
+		{Name: RuleMinLength, Description: "must be at least 8 characters long"},
+		{Name: RuleMaxLength, Description: "must be at most 64 characters long"},
+		{Name: RuleLowercase, Description: "must contain a lowercase letter"},
+		{Name: RuleUppercase, Description: "must contain an uppercase letter"},
+		{Name: RuleDigit, Description: "must contain a digit"},
+		{Name: RuleSymbol, Description: "must contain a symbol, a punctuation mark or a space"},
+		{Name: RuleAllowedCharacters, Description: "must not contain control characters"},
+		{Name: RuleNotReused, Description: "must not be one of the last 5 passwords"},
+	}, policy.Rules())
+
+	policy, err = NewPolicy(nil, 12, 128, []string{"none"}, AllowedCharactersASCII, -1, breachedFile(t))
+	require.NoError(t, err)
+	require.Equal(t, []Rule{
+		{Name: RuleMinLength, Description: "must be at least 12 characters long"},
+		{Name: RuleMaxLength, Description: "must be at most 128 characters long"},
+		{Name: RuleAllowedCharacters, Description: "must only contain printable ASCII characters"},
+		{Name: RuleNotBreached, Description: "must not appear in known data breaches"},
+	}, policy.Rules())
+
+	for name, newPolicy := range map[string]func() (*Policy, error){
+		"TooLong":        func() (*Policy, error) { return NewPolicy(nil, 0, MaxLength+1, nil, "", 0, "") },
+		"MinAboveMax":    func() (*Policy, error) { return NewPolicy(nil, 20, 10, nil, "", 0, "") },
+		"HistoryTooLong": func() (*Policy, error) { return NewPolicy(nil, 0, 0, nil, "", maxHistorySize+1, "") },
+		"UnknownClass":   func() (*Policy, error) { return NewPolicy(nil, 0, 0, []string{"emoji"}, "", 0, "") },
+		"UnknownAllowed": func() (*Policy, error) { return NewPolicy(nil, 0, 0, nil, "latin1", 0, "") },
+		"MissingBreached": func() (*Policy, error) {
+			return NewPolicy(nil, 0, 0, nil, "", 0, filepath.Join(t.TempDir(), "missing"))
+		},
+	} {
+		_, err := newPolicy()
+		require.Error(t, err, name)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	policy, err := NewPolicy(nil, 0, 0, nil, "", 0, breachedFile(t))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		password string
+		rule     string
+	}{
+		{name: "OK", password: util.RandomPassword()},
+		{name: "Unicode", password: "Pässwörd 1 ✓"},
+		{name: "OtherScripts", password: "Пароль-пароль1"},
+		{name: "TooShort", password: "Aa1!", rule: RuleMinLength},
+		{name: "TooLong", password: "Aa1!" + strings.Repeat("a", DefaultMaxLength), rule: RuleMaxLength},
+		// lengths are counted in characters rather than bytes
+		{name: "MultiByteLength", password: "Aa1!" + strings.Repeat("é", DefaultMaxLength-4)},
+		{name: "NoLowercase", password: "PASSWORD1!", rule: RuleLowercase},
+		{name: "NoUppercase", password: "password1!", rule: RuleUppercase},
+		{name: "NoDigit", password: "Password!", rule: RuleDigit},
+		{name: "NoSymbol", password: "Password1", rule: RuleSymbol},
+		{name: "ControlCharacter", password: "Password1!\x00", rule: RuleAllowedCharacters},
+		{name: "Breached", password: "Password1!", rule: RuleNotBreached},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := policy.Validate(testCase.password)
+			if testCase.rule == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var rejected *RejectedError
+			require.ErrorAs(t, err, &rejected)
+			require.ErrorIs(t, err, ErrPasswordRejected)
+			require.Equal(t, testCase.rule, rejected.Rule.Name)
+			require.Equal(t, "password "+rejected.Rule.Description, err.Error())
+		})
+	}
+}
+
+func TestValidateASCII(t *testing.T) {
+	policy, err := NewPolicy(nil, 0, 0, []string{RuleDigit}, AllowedCharactersASCII, 0, "")
+	require.NoError(t, err)
+
+	require.NoError(t, policy.Validate("all lowercase 1"))
+
+	var rejected *RejectedError
+	require.ErrorAs(t, policy.Validate("Pässwörd 1"), &rejected)
+	require.Equal(t, RuleAllowedCharacters, rejected.Rule.Name)
+	require.ErrorAs(t, policy.Validate("no digits at all"), &rejected)
+	require.Equal(t, RuleDigit, rejected.Rule.Name)
+}
+
+func TestValidateChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	policy, err := NewPolicy(mockServices, 0, 0, nil, "", 3, "")
+	require.NoError(t, err)
+
+	username := util.RandomUsername()
+	var history []models.PasswordHistory
+	var passwords []string
+	for i := 0; i < 3; i++ {
+		password := util.RandomPassword()
+		hashedPassword, err := util.HashPassword(password)
+		require.NoError(t, err)
+		passwords = append(passwords, password)
+		history = append(history, models.PasswordHistory{Username: username, HashedPassword: hashedPassword})
+	}
+	mockServices.EXPECT().ListPasswordHistory(username, 3).AnyTimes().Return(history, nil)
+
+	require.NoError(t, policy.ValidateChange(username, util.RandomPassword()))
+	for _, password := range passwords {
+		var rejected *RejectedError
+		require.ErrorAs(t, policy.ValidateChange(username, password), &rejected)
+		require.Equal(t, RuleNotReused, rejected.Rule.Name)
+	}
+
+	// passwords failing the other rules are rejected without reading the history
+	require.ErrorIs(t, policy.ValidateChange(util.RandomUsername(), "short"), ErrPasswordRejected)
+
+	mockServices.EXPECT().ListPasswordHistory(gomock.Not(username), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+	require.ErrorIs(t, policy.ValidateChange(util.RandomUsername(), util.RandomPassword()), sql.ErrConnDone)
+
+	// without a history, passwords can be reused
+	policy, err = NewPolicy(mockServices, 0, 0, nil, "", -1, "")
+	require.NoError(t, err)
+	require.NoError(t, policy.ValidateChange(username, passwords[0]))
+}
+
+// breachedFile writes a list of breached passwords containing "Password1!" and returns its path.
+func breachedFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{
+		"0000000000000000000000000000000000000001:3",
+		// sha1 of "Password1!"
+		"32CA9FC1A0F5B6330E3F4C8C1BBECDE9BEDB9573:120",
+		"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:1",
+	}, "\r\n")), 0o600))
+
+	return path
+}
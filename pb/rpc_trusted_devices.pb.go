@@ -0,0 +1,326 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        v5.26.1
+// source: rpc_trusted_devices.proto
+
+// Package declaration for protocol buffer definitions.
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Message for listing the trusted devices of the user.
+type ListTrustedDevicesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListTrustedDevicesRequest) Reset() {
+	*x = ListTrustedDevicesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_trusted_devices_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListTrustedDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrustedDevicesRequest) ProtoMessage() {}
+
+func (x *ListTrustedDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_trusted_devices_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrustedDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ListTrustedDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_trusted_devices_proto_rawDescGZIP(), []int{0}
+}
+
+// Response message for listing trusted devices.
+type ListTrustedDevicesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Devices the user logged in from, the last seen first.
+	Devices []*TrustedDevice `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+}
+
+func (x *ListTrustedDevicesResponse) Reset() {
+	*x = ListTrustedDevicesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_trusted_devices_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListTrustedDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrustedDevicesResponse) ProtoMessage() {}
+
+func (x *ListTrustedDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_trusted_devices_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrustedDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ListTrustedDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_trusted_devices_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListTrustedDevicesResponse) GetDevices() []*TrustedDevice {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+// Message for removing a trusted device of the user.
+type RemoveTrustedDeviceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Id of the device.
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *RemoveTrustedDeviceRequest) Reset() {
+	*x = RemoveTrustedDeviceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_trusted_devices_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveTrustedDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveTrustedDeviceRequest) ProtoMessage() {}
+
+func (x *RemoveTrustedDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_trusted_devices_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveTrustedDeviceRequest.ProtoReflect.Descriptor instead.
+func (*RemoveTrustedDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_trusted_devices_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RemoveTrustedDeviceRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// Response message for removing a trusted device.
+type RemoveTrustedDeviceResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RemoveTrustedDeviceResponse) Reset() {
+	*x = RemoveTrustedDeviceResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_trusted_devices_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveTrustedDeviceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveTrustedDeviceResponse) ProtoMessage() {}
+
+func (x *RemoveTrustedDeviceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_trusted_devices_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveTrustedDeviceResponse.ProtoReflect.Descriptor instead.
+func (*RemoveTrustedDeviceResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_trusted_devices_proto_rawDescGZIP(), []int{3}
+}
+
+var File_rpc_trusted_devices_proto protoreflect.FileDescriptor
+
+var file_rpc_trusted_devices_proto_rawDesc = []byte{
+	0x0a, 0x19, 0x72, 0x70, 0x63, 0x5f, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a,
+	0x14, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x1b, 0x0a, 0x19, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x75,
+	0x73, 0x74, 0x65, 0x64, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x22, 0x49, 0x0a, 0x1a, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x75, 0x73, 0x74, 0x65,
+	0x64, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x2b, 0x0a, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x44, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x52, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x22, 0x2c, 0x0a,
+	0x1a, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x54, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x44, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x22, 0x1d, 0x0a, 0x1b, 0x52,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x54, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x44, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x10, 0x5a, 0x0e, 0x53, 0x69,
+	0x6d, 0x70, 0x6c, 0x65, 0x2d, 0x42, 0x61, 0x6e, 0x6b, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_trusted_devices_proto_rawDescOnce sync.Once
+	file_rpc_trusted_devices_proto_rawDescData = file_rpc_trusted_devices_proto_rawDesc
+)
+
+func file_rpc_trusted_devices_proto_rawDescGZIP() []byte {
+	file_rpc_trusted_devices_proto_rawDescOnce.Do(func() {
+		file_rpc_trusted_devices_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_trusted_devices_proto_rawDescData)
+	})
+	return file_rpc_trusted_devices_proto_rawDescData
+}
+
+var file_rpc_trusted_devices_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_rpc_trusted_devices_proto_goTypes = []interface{}{
+	(*ListTrustedDevicesRequest)(nil),   // 0: pb.ListTrustedDevicesRequest
+	(*ListTrustedDevicesResponse)(nil),  // 1: pb.ListTrustedDevicesResponse
+	(*RemoveTrustedDeviceRequest)(nil),  // 2: pb.RemoveTrustedDeviceRequest
+	(*RemoveTrustedDeviceResponse)(nil), // 3: pb.RemoveTrustedDeviceResponse
+	(*TrustedDevice)(nil),               // 4: pb.TrustedDevice
+}
+var file_rpc_trusted_devices_proto_depIdxs = []int32{
+	4, // 0: pb.ListTrustedDevicesResponse.devices:type_name -> pb.TrustedDevice
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rpc_trusted_devices_proto_init() }
+func file_rpc_trusted_devices_proto_init() {
+	if File_rpc_trusted_devices_proto != nil {
+		return
+	}
+	file_trusted_device_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_trusted_devices_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListTrustedDevicesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_trusted_devices_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListTrustedDevicesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_trusted_devices_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveTrustedDeviceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_trusted_devices_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveTrustedDeviceResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_trusted_devices_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_trusted_devices_proto_goTypes,
+		DependencyIndexes: file_rpc_trusted_devices_proto_depIdxs,
+		MessageInfos:      file_rpc_trusted_devices_proto_msgTypes,
+	}.Build()
+	File_rpc_trusted_devices_proto = out.File
+	file_rpc_trusted_devices_proto_rawDesc = nil
+	file_rpc_trusted_devices_proto_goTypes = nil
+	file_rpc_trusted_devices_proto_depIdxs = nil
+}
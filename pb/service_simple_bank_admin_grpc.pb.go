@@ -0,0 +1,581 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v5.26.1
+// source: service_simple_bank_admin.proto
+
+// Package declaration for protocol buffer definitions.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SimpleBankAdmin_SearchUsers_FullMethodName        = "/pb.SimpleBankAdmin/SearchUsers"
+	SimpleBankAdmin_ListUserAccounts_FullMethodName   = "/pb.SimpleBankAdmin/ListUserAccounts"
+	SimpleBankAdmin_ListUserSessions_FullMethodName   = "/pb.SimpleBankAdmin/ListUserSessions"
+	SimpleBankAdmin_FreezeUser_FullMethodName         = "/pb.SimpleBankAdmin/FreezeUser"
+	SimpleBankAdmin_UnfreezeUser_FullMethodName       = "/pb.SimpleBankAdmin/UnfreezeUser"
+	SimpleBankAdmin_ForcePasswordReset_FullMethodName = "/pb.SimpleBankAdmin/ForcePasswordReset"
+	SimpleBankAdmin_UnlockUser_FullMethodName         = "/pb.SimpleBankAdmin/UnlockUser"
+	SimpleBankAdmin_ListAccountEntries_FullMethodName = "/pb.SimpleBankAdmin/ListAccountEntries"
+	SimpleBankAdmin_FreezeAccount_FullMethodName      = "/pb.SimpleBankAdmin/FreezeAccount"
+	SimpleBankAdmin_UnfreezeAccount_FullMethodName    = "/pb.SimpleBankAdmin/UnfreezeAccount"
+	SimpleBankAdmin_AdjustBalance_FullMethodName      = "/pb.SimpleBankAdmin/AdjustBalance"
+	SimpleBankAdmin_RevokeToken_FullMethodName        = "/pb.SimpleBankAdmin/RevokeToken"
+	SimpleBankAdmin_ListAuditEvents_FullMethodName    = "/pb.SimpleBankAdmin/ListAuditEvents"
+)
+
+// SimpleBankAdminClient is the client API for SimpleBankAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SimpleBankAdminClient interface {
+	// RPC method for searching users by username or email.
+	SearchUsers(ctx context.Context, in *SearchUsersRequest, opts ...grpc.CallOption) (*SearchUsersResponse, error)
+	// RPC method for listing every account of a user.
+	ListUserAccounts(ctx context.Context, in *ListUserAccountsRequest, opts ...grpc.CallOption) (*ListUserAccountsResponse, error)
+	// RPC method for listing every session of a user.
+	ListUserSessions(ctx context.Context, in *ListUserSessionsRequest, opts ...grpc.CallOption) (*ListUserSessionsResponse, error)
+	// RPC method for freezing a user.
+	FreezeUser(ctx context.Context, in *AdminUserActionRequest, opts ...grpc.CallOption) (*AdminUserActionResponse, error)
+	// RPC method for unfreezing a user.
+	UnfreezeUser(ctx context.Context, in *AdminUserActionRequest, opts ...grpc.CallOption) (*AdminUserActionResponse, error)
+	// RPC method for forcing a user to reset their password.
+	ForcePasswordReset(ctx context.Context, in *AdminUserActionRequest, opts ...grpc.CallOption) (*AdminUserActionResponse, error)
+	// RPC method for unlocking a user locked out by failed logins.
+	UnlockUser(ctx context.Context, in *AdminUserActionRequest, opts ...grpc.CallOption) (*AdminUserActionResponse, error)
+	// RPC method for listing the entries of any account.
+	ListAccountEntries(ctx context.Context, in *ListAccountEntriesRequest, opts ...grpc.CallOption) (*ListAccountEntriesResponse, error)
+	// RPC method for freezing a customer account.
+	FreezeAccount(ctx context.Context, in *AdminAccountActionRequest, opts ...grpc.CallOption) (*AdminAccountActionResponse, error)
+	// RPC method for unfreezing a customer account.
+	UnfreezeAccount(ctx context.Context, in *AdminAccountActionRequest, opts ...grpc.CallOption) (*AdminAccountActionResponse, error)
+	// RPC method for posting a manual adjustment to a customer account.
+	AdjustBalance(ctx context.Context, in *AdjustBalanceRequest, opts ...grpc.CallOption) (*AdjustBalanceResponse, error)
+	// RPC method for revoking any token of any user.
+	RevokeToken(ctx context.Context, in *AdminRevokeTokenRequest, opts ...grpc.CallOption) (*AdminRevokeTokenResponse, error)
+	// RPC method for listing the audit trail of the back office.
+	ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error)
+}
+
+type simpleBankAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSimpleBankAdminClient(cc grpc.ClientConnInterface) SimpleBankAdminClient {
+	return &simpleBankAdminClient{cc}
+}
+
+func (c *simpleBankAdminClient) SearchUsers(ctx context.Context, in *SearchUsersRequest, opts ...grpc.CallOption) (*SearchUsersResponse, error) {
+	out := new(SearchUsersResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_SearchUsers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) ListUserAccounts(ctx context.Context, in *ListUserAccountsRequest, opts ...grpc.CallOption) (*ListUserAccountsResponse, error) {
+	out := new(ListUserAccountsResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_ListUserAccounts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) ListUserSessions(ctx context.Context, in *ListUserSessionsRequest, opts ...grpc.CallOption) (*ListUserSessionsResponse, error) {
+	out := new(ListUserSessionsResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_ListUserSessions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) FreezeUser(ctx context.Context, in *AdminUserActionRequest, opts ...grpc.CallOption) (*AdminUserActionResponse, error) {
+	out := new(AdminUserActionResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_FreezeUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) UnfreezeUser(ctx context.Context, in *AdminUserActionRequest, opts ...grpc.CallOption) (*AdminUserActionResponse, error) {
+	out := new(AdminUserActionResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_UnfreezeUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) ForcePasswordReset(ctx context.Context, in *AdminUserActionRequest, opts ...grpc.CallOption) (*AdminUserActionResponse, error) {
+	out := new(AdminUserActionResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_ForcePasswordReset_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) UnlockUser(ctx context.Context, in *AdminUserActionRequest, opts ...grpc.CallOption) (*AdminUserActionResponse, error) {
+	out := new(AdminUserActionResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_UnlockUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) ListAccountEntries(ctx context.Context, in *ListAccountEntriesRequest, opts ...grpc.CallOption) (*ListAccountEntriesResponse, error) {
+	out := new(ListAccountEntriesResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_ListAccountEntries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) FreezeAccount(ctx context.Context, in *AdminAccountActionRequest, opts ...grpc.CallOption) (*AdminAccountActionResponse, error) {
+	out := new(AdminAccountActionResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_FreezeAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) UnfreezeAccount(ctx context.Context, in *AdminAccountActionRequest, opts ...grpc.CallOption) (*AdminAccountActionResponse, error) {
+	out := new(AdminAccountActionResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_UnfreezeAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) AdjustBalance(ctx context.Context, in *AdjustBalanceRequest, opts ...grpc.CallOption) (*AdjustBalanceResponse, error) {
+	out := new(AdjustBalanceResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_AdjustBalance_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) RevokeToken(ctx context.Context, in *AdminRevokeTokenRequest, opts ...grpc.CallOption) (*AdminRevokeTokenResponse, error) {
+	out := new(AdminRevokeTokenResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_RevokeToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleBankAdminClient) ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error) {
+	out := new(ListAuditEventsResponse)
+	err := c.cc.Invoke(ctx, SimpleBankAdmin_ListAuditEvents_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SimpleBankAdminServer is the server API for SimpleBankAdmin service.
+// All implementations must embed UnimplementedSimpleBankAdminServer
+// for forward compatibility
+type SimpleBankAdminServer interface {
+	// RPC method for searching users by username or email.
+	SearchUsers(context.Context, *SearchUsersRequest) (*SearchUsersResponse, error)
+	// RPC method for listing every account of a user.
+	ListUserAccounts(context.Context, *ListUserAccountsRequest) (*ListUserAccountsResponse, error)
+	// RPC method for listing every session of a user.
+	ListUserSessions(context.Context, *ListUserSessionsRequest) (*ListUserSessionsResponse, error)
+	// RPC method for freezing a user.
+	FreezeUser(context.Context, *AdminUserActionRequest) (*AdminUserActionResponse, error)
+	// RPC method for unfreezing a user.
+	UnfreezeUser(context.Context, *AdminUserActionRequest) (*AdminUserActionResponse, error)
+	// RPC method for forcing a user to reset their password.
+	ForcePasswordReset(context.Context, *AdminUserActionRequest) (*AdminUserActionResponse, error)
+	// RPC method for unlocking a user locked out by failed logins.
+	UnlockUser(context.Context, *AdminUserActionRequest) (*AdminUserActionResponse, error)
+	// RPC method for listing the entries of any account.
+	ListAccountEntries(context.Context, *ListAccountEntriesRequest) (*ListAccountEntriesResponse, error)
+	// RPC method for freezing a customer account.
+	FreezeAccount(context.Context, *AdminAccountActionRequest) (*AdminAccountActionResponse, error)
+	// RPC method for unfreezing a customer account.
+	UnfreezeAccount(context.Context, *AdminAccountActionRequest) (*AdminAccountActionResponse, error)
+	// RPC method for posting a manual adjustment to a customer account.
+	AdjustBalance(context.Context, *AdjustBalanceRequest) (*AdjustBalanceResponse, error)
+	// RPC method for revoking any token of any user.
+	RevokeToken(context.Context, *AdminRevokeTokenRequest) (*AdminRevokeTokenResponse, error)
+	// RPC method for listing the audit trail of the back office.
+	ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error)
+	mustEmbedUnimplementedSimpleBankAdminServer()
+}
+
+// UnimplementedSimpleBankAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedSimpleBankAdminServer struct {
+}
+
+func (UnimplementedSimpleBankAdminServer) SearchUsers(context.Context, *SearchUsersRequest) (*SearchUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchUsers not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) ListUserAccounts(context.Context, *ListUserAccountsRequest) (*ListUserAccountsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserAccounts not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) ListUserSessions(context.Context, *ListUserSessionsRequest) (*ListUserSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserSessions not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) FreezeUser(context.Context, *AdminUserActionRequest) (*AdminUserActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FreezeUser not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) UnfreezeUser(context.Context, *AdminUserActionRequest) (*AdminUserActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnfreezeUser not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) ForcePasswordReset(context.Context, *AdminUserActionRequest) (*AdminUserActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForcePasswordReset not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) UnlockUser(context.Context, *AdminUserActionRequest) (*AdminUserActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnlockUser not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) ListAccountEntries(context.Context, *ListAccountEntriesRequest) (*ListAccountEntriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAccountEntries not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) FreezeAccount(context.Context, *AdminAccountActionRequest) (*AdminAccountActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FreezeAccount not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) UnfreezeAccount(context.Context, *AdminAccountActionRequest) (*AdminAccountActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnfreezeAccount not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) AdjustBalance(context.Context, *AdjustBalanceRequest) (*AdjustBalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdjustBalance not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) RevokeToken(context.Context, *AdminRevokeTokenRequest) (*AdminRevokeTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeToken not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEvents not implemented")
+}
+func (UnimplementedSimpleBankAdminServer) mustEmbedUnimplementedSimpleBankAdminServer() {}
+
+// UnsafeSimpleBankAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SimpleBankAdminServer will
+// result in compilation errors.
+type UnsafeSimpleBankAdminServer interface {
+	mustEmbedUnimplementedSimpleBankAdminServer()
+}
+
+func RegisterSimpleBankAdminServer(s grpc.ServiceRegistrar, srv SimpleBankAdminServer) {
+	s.RegisterService(&SimpleBankAdmin_ServiceDesc, srv)
+}
+
+func _SimpleBankAdmin_SearchUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).SearchUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_SearchUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).SearchUsers(ctx, req.(*SearchUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_ListUserAccounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserAccountsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).ListUserAccounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_ListUserAccounts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).ListUserAccounts(ctx, req.(*ListUserAccountsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_ListUserSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).ListUserSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_ListUserSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).ListUserSessions(ctx, req.(*ListUserSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_FreezeUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminUserActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).FreezeUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_FreezeUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).FreezeUser(ctx, req.(*AdminUserActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_UnfreezeUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminUserActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).UnfreezeUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_UnfreezeUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).UnfreezeUser(ctx, req.(*AdminUserActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_ForcePasswordReset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminUserActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).ForcePasswordReset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_ForcePasswordReset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).ForcePasswordReset(ctx, req.(*AdminUserActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_UnlockUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminUserActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).UnlockUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_UnlockUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).UnlockUser(ctx, req.(*AdminUserActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_ListAccountEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAccountEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).ListAccountEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_ListAccountEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).ListAccountEntries(ctx, req.(*ListAccountEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_FreezeAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminAccountActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).FreezeAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_FreezeAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).FreezeAccount(ctx, req.(*AdminAccountActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_UnfreezeAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminAccountActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).UnfreezeAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_UnfreezeAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).UnfreezeAccount(ctx, req.(*AdminAccountActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_AdjustBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdjustBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).AdjustBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_AdjustBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).AdjustBalance(ctx, req.(*AdjustBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_RevokeToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminRevokeTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).RevokeToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_RevokeToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).RevokeToken(ctx, req.(*AdminRevokeTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleBankAdmin_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleBankAdminServer).ListAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleBankAdmin_ListAuditEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleBankAdminServer).ListAuditEvents(ctx, req.(*ListAuditEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SimpleBankAdmin_ServiceDesc is the grpc.ServiceDesc for SimpleBankAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SimpleBankAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.SimpleBankAdmin",
+	HandlerType: (*SimpleBankAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SearchUsers",
+			Handler:    _SimpleBankAdmin_SearchUsers_Handler,
+		},
+		{
+			MethodName: "ListUserAccounts",
+			Handler:    _SimpleBankAdmin_ListUserAccounts_Handler,
+		},
+		{
+			MethodName: "ListUserSessions",
+			Handler:    _SimpleBankAdmin_ListUserSessions_Handler,
+		},
+		{
+			MethodName: "FreezeUser",
+			Handler:    _SimpleBankAdmin_FreezeUser_Handler,
+		},
+		{
+			MethodName: "UnfreezeUser",
+			Handler:    _SimpleBankAdmin_UnfreezeUser_Handler,
+		},
+		{
+			MethodName: "ForcePasswordReset",
+			Handler:    _SimpleBankAdmin_ForcePasswordReset_Handler,
+		},
+		{
+			MethodName: "UnlockUser",
+			Handler:    _SimpleBankAdmin_UnlockUser_Handler,
+		},
+		{
+			MethodName: "ListAccountEntries",
+			Handler:    _SimpleBankAdmin_ListAccountEntries_Handler,
+		},
+		{
+			MethodName: "FreezeAccount",
+			Handler:    _SimpleBankAdmin_FreezeAccount_Handler,
+		},
+		{
+			MethodName: "UnfreezeAccount",
+			Handler:    _SimpleBankAdmin_UnfreezeAccount_Handler,
+		},
+		{
+			MethodName: "AdjustBalance",
+			Handler:    _SimpleBankAdmin_AdjustBalance_Handler,
+		},
+		{
+			MethodName: "RevokeToken",
+			Handler:    _SimpleBankAdmin_RevokeToken_Handler,
+		},
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _SimpleBankAdmin_ListAuditEvents_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "service_simple_bank_admin.proto",
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket: a subject can make Burst requests at once, and Requests more every Period.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	// Burst is the number of requests allowed at once, Requests if zero
+	Burst int
+}
+
+// Unlimited is the limit of the routes that are not rate limited.
+var Unlimited = Limit{Requests: -1}
+
+// limited reports whether the limit restricts requests at all.
+func (limit Limit) limited() bool {
+	return limit.Requests >= 0
+}
+
+// validate returns an error if the limit cannot be enforced.
+func (limit Limit) validate() error {
+	if !limit.limited() {
+		return nil
+	}
+	if limit.Requests == 0 || limit.Period <= 0 {
+		return fmt.Errorf("rate limits require a number of requests and a period")
+	}
+	if limit.Burst < 0 {
+		return fmt.Errorf("rate limits cannot have a negative burst")
+	}
+
+	return nil
+}
+
+// burst returns the capacity of the bucket.
+func (limit Limit) burst() float64 {
+	if limit.Burst == 0 {
+		return float64(limit.Requests)
+	}
+
+	return float64(limit.Burst)
+}
+
+// rate returns the tokens added to the bucket every second.
+func (limit Limit) rate() float64 {
+	return float64(limit.Requests) / limit.Period.Seconds()
+}
+
+// refillTime returns how long an empty bucket takes to refill, after which a bucket is as good as absent.
+func (limit Limit) refillTime() time.Duration {
+	return time.Duration(limit.burst() / limit.rate() * float64(time.Second))
+}
+
+// take takes a token from a bucket holding tokens updatedAt, created full if new. It returns the tokens left in
+// the bucket, and zero if a token was taken or how long until one can be taken otherwise.
+func (limit Limit) take(tokens float64, updatedAt time.Time, isNew bool, now time.Time) (float64, time.Duration) {
+	if isNew {
+		tokens = limit.burst()
+	} else if elapsed := now.Sub(updatedAt); elapsed > 0 {
+		tokens = math.Min(limit.burst(), tokens+elapsed.Seconds()*limit.rate())
+	}
+
+	if tokens < 1 {
+		return tokens, time.Duration(math.Ceil((1 - tokens) / limit.rate() * float64(time.Second)))
+	}
+
+	return tokens - 1, 0
+}
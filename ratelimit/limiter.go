@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRequests and DefaultPeriod are the limit of the routes without a rule when none is configured
+	DefaultRequests = 300
+	DefaultPeriod   = time.Minute
+)
+
+// ErrRateLimited is returned when a subject made too many requests to a route
+var ErrRateLimited = errors.New("too many requests")
+
+// LimitedError is the error of a request refused because of previous requests. It wraps ErrRateLimited.
+type LimitedError struct {
+	// RetryAfter is how long to wait before trying again
+	RetryAfter time.Duration
+}
+
+func (err *LimitedError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrRateLimited, err.RetryAfter.Round(time.Second))
+}
+
+func (err *LimitedError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// Rule is the limit of a route.
+type Rule struct {
+	Route string
+	Limit
+}
+
+// Limiter limits the requests subjects, users or client addresses, make to each route, with a token bucket per
+// subject and route kept in a Store. The requests of authenticated users take a token of the bucket of their
+// address and of the bucket of their user.
+type Limiter struct {
+	store        Store
+	defaultLimit Limit
+	limits       map[string]Limit
+}
+
+// NewLimiter creates a Limiter keeping its buckets in store, limiting the routes of rules to their limit and the
+// others to defaultLimit, DefaultRequests if zero. Limits without a period refill every DefaultPeriod.
+func NewLimiter(store Store, defaultLimit Limit, rules []Rule) (*Limiter, error) {
+	if defaultLimit.Requests == 0 {
+		defaultLimit.Requests = DefaultRequests
+	}
+	if defaultLimit.Period == 0 {
+		defaultLimit.Period = DefaultPeriod
+	}
+	if err := defaultLimit.validate(); err != nil {
+		return nil, err
+	}
+
+	limits := map[string]Limit{}
+	for _, rule := range rules {
+		if rule.Route == "" {
+			return nil, fmt.Errorf("rate limit rules require a route")
+		}
+		if _, ok := limits[rule.Route]; ok {
+			return nil, fmt.Errorf("route %q has several rate limit rules", rule.Route)
+		}
+		if rule.Period == 0 {
+			rule.Period = DefaultPeriod
+		}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("route %q: %w", rule.Route, err)
+		}
+		limits[rule.Route] = rule.Limit
+	}
+
+	return &Limiter{
+		store:        store,
+		defaultLimit: defaultLimit,
+		limits:       limits,
+	}, nil
+}
+
+// Allow takes a token of the bucket of subject on route. It returns a *LimitedError if the bucket is empty, and
+// the error of the store if it failed.
+func (limiter *Limiter) Allow(route, subject string) error {
+	limit := limiter.Limit(route)
+	if !limit.limited() {
+		return nil
+	}
+
+	retryAfter, err := limiter.store.Take(route+"|"+subject, limit, time.Now())
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return &LimitedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// Limit returns the limit of route.
+func (limiter *Limiter) Limit(route string) Limit {
+	if limit, ok := limiter.limits[route]; ok {
+		return limit
+	}
+
+	return limiter.defaultLimit
+}
+
+// UserSubject returns the subject of the requests of an authenticated user.
+func UserSubject(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+// IPSubject returns the subject of the requests of a client address, authenticated or not, removing the port of
+// addresses, e.g. the peer addresses of grpc requests.
+func IPSubject(clientIP string) string {
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		clientIP = host
+	}
+
+	return "ip:" + clientIP
+}
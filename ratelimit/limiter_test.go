@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// failingStore fails to take tokens.
+type failingStore struct{}
+
+func (failingStore) Take(string, Limit, time.Time) (time.Duration, error) {
+	return 0, errors.New("store unavailable")
+}
+
+func TestLimiter(t *testing.T) {
+	limiter, err := NewLimiter(NewMemoryStore(), Limit{Requests: 3, Period: time.Minute}, []Rule{
+		{Route: "POST /users/login", Limit: Limit{Requests: 1, Period: time.Minute}},
+		{Route: "GET /", Limit: Unlimited},
+	})
+	require.NoError(t, err)
+
+	subject := IPSubject("10.0.0.1:5000")
+	require.NoError(t, limiter.Allow("POST /users/login", subject))
+
+	err = limiter.Allow("POST /users/login", subject)
+	require.ErrorIs(t, err, ErrRateLimited)
+	var limitedErr *LimitedError
+	require.ErrorAs(t, err, &limitedErr)
+	require.Positive(t, limitedErr.RetryAfter)
+	require.LessOrEqual(t, limitedErr.RetryAfter, time.Minute)
+
+	// other subjects and routes have their own limit, the same address with another port is the same subject
+	require.NoError(t, limiter.Allow("POST /users/login", IPSubject("10.0.0.2")))
+	require.ErrorIs(t, limiter.Allow("POST /users/login", IPSubject("10.0.0.1:5001")), ErrRateLimited)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Allow("GET /accounts", subject))
+	}
+	require.ErrorIs(t, limiter.Allow("GET /accounts", subject), ErrRateLimited)
+	require.NoError(t, limiter.Allow("GET /accounts", UserSubject("alice")))
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, limiter.Allow("GET /", subject))
+	}
+}
+
+func TestLimiterDefaults(t *testing.T) {
+	limiter, err := NewLimiter(NewMemoryStore(), Limit{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, Limit{Requests: DefaultRequests, Period: DefaultPeriod}, limiter.Limit("GET /accounts"))
+
+	limiter, err = NewLimiter(NewMemoryStore(), Limit{Requests: 10}, []Rule{{Route: "POST /users/login", Limit: Limit{Requests: 1}}})
+	require.NoError(t, err)
+	require.Equal(t, Limit{Requests: 10, Period: DefaultPeriod}, limiter.Limit("GET /accounts"))
+	require.Equal(t, Limit{Requests: 1, Period: DefaultPeriod}, limiter.Limit("POST /users/login"))
+
+	// a negative number of requests disables limiting
+	limiter, err = NewLimiter(failingStore{}, Unlimited, nil)
+	require.NoError(t, err)
+	require.NoError(t, limiter.Allow("GET /accounts", IPSubject("10.0.0.1")))
+}
+
+func TestLimiterStoreError(t *testing.T) {
+	limiter, err := NewLimiter(failingStore{}, Limit{}, nil)
+	require.NoError(t, err)
+
+	err = limiter.Allow("GET /accounts", IPSubject("10.0.0.1"))
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrRateLimited)
+}
+
+func TestNewLimiterInvalidRules(t *testing.T) {
+	testCases := []struct {
+		name         string
+		defaultLimit Limit
+		rules        []Rule
+	}{
+		{
+			name:         "NegativePeriod",
+			defaultLimit: Limit{Requests: 10, Period: -time.Second},
+		},
+		{
+			name:  "RuleWithoutRoute",
+			rules: []Rule{{Limit: Limit{Requests: 1, Period: time.Second}}},
+		},
+		{
+			name:  "RuleWithoutRequests",
+			rules: []Rule{{Route: "GET /accounts"}},
+		},
+		{
+			name:  "NegativeBurst",
+			rules: []Rule{{Route: "GET /accounts", Limit: Limit{Requests: 1, Period: time.Second, Burst: -1}}},
+		},
+		{
+			name: "DuplicateRoute",
+			rules: []Rule{
+				{Route: "GET /accounts", Limit: Limit{Requests: 1, Period: time.Second}},
+				{Route: "GET /accounts", Limit: Limit{Requests: 2, Period: time.Second}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewLimiter(NewMemoryStore(), testCase.defaultLimit, testCase.rules)
+			require.Error(t, err)
+		})
+	}
+}
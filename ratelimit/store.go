@@ -0,0 +1,137 @@
+package ratelimit
+
+import (
+	"Simple-Bank/db/services"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// store backends
+const (
+	// BackendMemory keeps the buckets in memory, so every instance limits the requests it receives
+	BackendMemory = "memory"
+	// BackendPostgres keeps the buckets in postgres, so the instances of a deployment share their limits
+	BackendPostgres = "postgres"
+)
+
+// pruneInterval is how often stores forget the buckets that refilled
+const pruneInterval = 10 * time.Minute
+
+// Store keeps the token buckets of the subjects.
+type Store interface {
+	// Take takes a token from the bucket with key under limit, and returns zero if a token was taken or how long
+	// until one can be taken otherwise.
+	Take(key string, limit Limit, now time.Time) (time.Duration, error)
+}
+
+// NewStore creates the Store of backend, BackendMemory if empty, keeping postgres buckets with services.
+func NewStore(backend string, services services.Services) (Store, error) {
+	switch backend {
+	case "", BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendPostgres:
+		return NewPostgresStore(services), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", backend)
+	}
+}
+
+// MemoryStore keeps the buckets in memory.
+type MemoryStore struct {
+	mutex   sync.Mutex
+	buckets map[string]*memoryBucket
+	// prunedAt is when the buckets that refilled were last forgotten, maxRefillTime the longest refill of the
+	// limits buckets were taken under
+	prunedAt      time.Time
+	maxRefillTime time.Duration
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  map[string]*memoryBucket{},
+		prunedAt: time.Now(),
+	}
+}
+
+func (store *MemoryStore) Take(key string, limit Limit, now time.Time) (time.Duration, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.maxRefillTime = max(store.maxRefillTime, limit.refillTime())
+	if now.Sub(store.prunedAt) > pruneInterval {
+		for bucketKey, bucket := range store.buckets {
+			if now.Sub(bucket.updatedAt) > store.maxRefillTime {
+				delete(store.buckets, bucketKey)
+			}
+		}
+		store.prunedAt = now
+	}
+
+	bucket, ok := store.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{}
+		store.buckets[key] = bucket
+	}
+
+	var retryAfter time.Duration
+	bucket.tokens, retryAfter = limit.take(bucket.tokens, bucket.updatedAt, !ok, now)
+	bucket.updatedAt = now
+
+	return retryAfter, nil
+}
+
+// PostgresStore keeps the buckets in postgres, every token is taken in a transaction locking the bucket.
+type PostgresStore struct {
+	services services.Services
+
+	mutex         sync.Mutex
+	prunedAt      time.Time
+	maxRefillTime time.Duration
+}
+
+// NewPostgresStore creates a PostgresStore keeping the buckets with services.
+func NewPostgresStore(services services.Services) *PostgresStore {
+	return &PostgresStore{
+		services: services,
+		prunedAt: time.Now(),
+	}
+}
+
+func (store *PostgresStore) Take(key string, limit Limit, now time.Time) (time.Duration, error) {
+	if err := store.prune(limit, now); err != nil {
+		return 0, err
+	}
+
+	var retryAfter time.Duration
+	err := store.services.UpdateRateLimitBucket(key, func(tokens float64, updatedAt time.Time, isNew bool) float64 {
+		tokens, retryAfter = limit.take(tokens, updatedAt, isNew, now)
+		return tokens
+	}, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return retryAfter, nil
+}
+
+// prune deletes the buckets that refilled, at most every pruneInterval.
+func (store *PostgresStore) prune(limit Limit, now time.Time) error {
+	store.mutex.Lock()
+	store.maxRefillTime = max(store.maxRefillTime, limit.refillTime())
+	if now.Sub(store.prunedAt) <= pruneInterval {
+		store.mutex.Unlock()
+		return nil
+	}
+	store.prunedAt = now
+	refilledBefore := now.Add(-store.maxRefillTime)
+	store.mutex.Unlock()
+
+	return store.services.DeleteRateLimitBuckets(refilledBefore)
+}
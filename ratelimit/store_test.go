@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 2, Period: time.Second, Burst: 3}
+	now := time.Now()
+
+	// a new bucket holds the burst
+	for i := 0; i < 3; i++ {
+		retryAfter, err := store.Take("key", limit, now)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+	}
+	retryAfter, err := store.Take("key", limit, now)
+	require.NoError(t, err)
+	require.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// other keys have their own bucket
+	retryAfter, err = store.Take("other", limit, now)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+
+	// tokens refill at the rate of the limit
+	retryAfter, err = store.Take("key", limit, now.Add(500*time.Millisecond))
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+	retryAfter, err = store.Take("key", limit, now.Add(750*time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, 250*time.Millisecond, retryAfter)
+
+	// buckets refill up to the burst
+	later := now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		retryAfter, err = store.Take("key", limit, later)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+	}
+	retryAfter, err = store.Take("key", limit, later)
+	require.NoError(t, err)
+	require.Positive(t, retryAfter)
+}
+
+func TestMemoryStorePrune(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 1, Period: time.Minute}
+	now := time.Now()
+
+	_, err := store.Take("stale", limit, now)
+	require.NoError(t, err)
+	_, err = store.Take("fresh", limit, now.Add(pruneInterval))
+	require.NoError(t, err)
+	_, err = store.Take("fresh", limit, now.Add(pruneInterval+time.Second))
+	require.NoError(t, err)
+
+	require.NotContains(t, store.buckets, "stale")
+	require.Contains(t, store.buckets, "fresh")
+}
+
+func TestPostgresStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the mock keeps a single bucket like the database would
+	var (
+		tokens    float64
+		updatedAt time.Time
+		exists    bool
+	)
+	services := mockdb.NewMockServices(ctrl)
+	services.EXPECT().
+		UpdateRateLimitBucket("key", gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(key string, update func(float64, time.Time, bool) float64, now time.Time) error {
+			tokens = update(tokens, updatedAt, !exists)
+			updatedAt, exists = now, true
+			return nil
+		})
+
+	store := NewPostgresStore(services)
+	limit := Limit{Requests: 1, Period: time.Minute}
+	now := time.Now()
+
+	retryAfter, err := store.Take("key", limit, now)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+
+	retryAfter, err = store.Take("key", limit, now.Add(30*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, retryAfter)
+
+	// buckets that refilled are deleted every prune interval
+	later := now.Add(pruneInterval + time.Second)
+	services.EXPECT().DeleteRateLimitBuckets(later.Add(-time.Minute)).Times(1).Return(nil)
+	retryAfter, err = store.Take("key", limit, later)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+
+	services.EXPECT().UpdateRateLimitBucket("failing", gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+	_, err = store.Take("failing", limit, later)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestNewStore(t *testing.T) {
+	store, err := NewStore("", nil)
+	require.NoError(t, err)
+	require.IsType(t, &MemoryStore{}, store)
+
+	store, err = NewStore(BackendPostgres, nil)
+	require.NoError(t, err)
+	require.IsType(t, &PostgresStore{}, store)
+
+	_, err = NewStore("redis", nil)
+	require.Error(t, err)
+}
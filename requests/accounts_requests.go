@@ -1,5 +1,7 @@
 package requests
 
+import "time"
+
 type GetAccountRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
@@ -18,3 +20,11 @@ type WithdrawRequest struct {
 	AccountID int64 `json:"account_id" binding:"required"`
 	Amount    int32 `json:"amount" binding:"required,gt=0"`
 }
+
+type GetAccountBalanceRequest struct {
+	At time.Time `form:"at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type GetTrialBalanceRequest struct {
+	At time.Time `form:"at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
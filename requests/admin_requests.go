@@ -0,0 +1,49 @@
+package requests
+
+// every request of the back office has a mandatory reason written to the audit trail
+
+type AdminReasonQuery struct {
+	Reason string `form:"reason" binding:"required"`
+}
+
+type AdminReasonRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type AdminUserRequest struct {
+	Username string `uri:"username" binding:"required,validUsername"`
+}
+
+type AdminSearchUsersRequest struct {
+	Query    string `form:"query"`
+	PageID   int64  `form:"page_id" binding:"required,min=1"`
+	PageSize int8   `form:"page_size" binding:"required,min=5,max=50"`
+	Reason   string `form:"reason" binding:"required"`
+}
+
+type AdminListEntriesRequest struct {
+	PageID   int64  `form:"page_id" binding:"required,min=1"`
+	PageSize int8   `form:"page_size" binding:"required,min=5,max=50"`
+	Reason   string `form:"reason" binding:"required"`
+}
+
+type AdminAdjustBalanceRequest struct {
+	// Amount is credited to the account, or debited if negative
+	Amount int32  `json:"amount" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+type AdminRevokeTokenRequest struct {
+	TokenID  string `json:"token_id" binding:"required,uuid"`
+	Username string `json:"username" binding:"required"`
+	Reason   string `json:"reason" binding:"required"`
+}
+
+type AdminListAuditEventsRequest struct {
+	Operator   string `form:"operator"`
+	TargetType string `form:"target_type"`
+	TargetID   string `form:"target_id"`
+	PageID     int64  `form:"page_id" binding:"required,min=1"`
+	PageSize   int8   `form:"page_size" binding:"required,min=5,max=50"`
+	Reason     string `form:"reason" binding:"required"`
+}
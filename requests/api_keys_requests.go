@@ -0,0 +1,16 @@
+package requests
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=64"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,validScope"`
+	// AllowedIPs restricts the addresses allowed to use the key, every address is allowed if empty
+	AllowedIPs []string `json:"allowed_ips" binding:"omitempty,dive,ip|cidr"`
+	// ExpiresAt is when the key expires, it never does if nil
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type RevokeAPIKeyRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
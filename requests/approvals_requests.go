@@ -0,0 +1,19 @@
+package requests
+
+type GetApprovalRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type ListApprovalsRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending approved executed failed rejected expired"`
+	PageID   int64  `form:"page_id" binding:"required,min=1"`
+	PageSize int8   `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+type DecideApprovalRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type CloseAccountRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,5 @@
+package requests
+
+type RemoveTrustedDeviceRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
@@ -0,0 +1,12 @@
+package requests
+
+type TOTPCodeRequest struct {
+	// Code is a code of the authenticator app, or a recovery code where accepted
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+type VerifyLoginMFARequest struct {
+	ChallengeToken string `json:"mfa_challenge_token" binding:"required"`
+	// Code is a code of the authenticator app or a recovery code
+	Code string `json:"code" binding:"required,max=32"`
+}
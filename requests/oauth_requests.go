@@ -0,0 +1,40 @@
+package requests
+
+type RegisterOAuthClientRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+	// RedirectURIs are the uris authorization codes can be sent to
+	RedirectURIs []string `json:"redirect_uris" binding:"omitempty,dive,url"`
+	Scopes       []string `json:"scopes" binding:"required,min=1,dive,validScope"`
+	// Confidential clients get a secret and can use the client credentials grant
+	Confidential bool `json:"confidential"`
+}
+
+type AuthorizeOAuthClientRequest struct {
+	ResponseType string `json:"response_type" binding:"required"`
+	ClientID     string `json:"client_id" binding:"required"`
+	RedirectURI  string `json:"redirect_uri" binding:"required"`
+	// Scope is the space separated list of the granted scopes, every scope of the client if empty
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge" binding:"required"`
+	CodeChallengeMethod string `json:"code_challenge_method" binding:"required"`
+}
+
+// OAuthTokenRequest is the form of the token endpoint. Clients may authenticate with basic authentication instead
+// of ClientID and ClientSecret.
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// OAuthTokenActionRequest is the form of the introspection and revocation endpoints.
+type OAuthTokenActionRequest struct {
+	Token        string `form:"token" binding:"required"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
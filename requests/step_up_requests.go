@@ -0,0 +1,10 @@
+package requests
+
+type StepUpRequest struct {
+	// Operation is the operation the elevated token is requested for
+	Operation string `json:"operation" binding:"required,oneof=transfer close_account change_email"`
+	// Password re-authenticates users without two-factor authentication
+	Password string `json:"password" binding:"max=64"`
+	// Code re-authenticates users with two-factor authentication, a code of their authenticator app or a recovery code
+	Code string `json:"code" binding:"max=32"`
+}
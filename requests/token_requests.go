@@ -3,3 +3,7 @@ package requests
 type RenewAccessTokenRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
+
+type RevokeSessionRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
@@ -5,3 +5,7 @@ type TransferRequest struct {
 	ToAccountID   int64 `json:"to_account_id" binding:"required,min=1"`
 	Amount        int32 `json:"amount" binding:"required,gt=0"`
 }
+
+type GetTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
@@ -2,7 +2,8 @@ package requests
 
 type CreateUserRequest struct {
 	Username string `json:"username" binding:"required,validUsername"`
-	Password string `json:"password" binding:"required,validPassword"`
+	// Password must satisfy the password policy, checked by the handler since the policy is configured
+	Password string `json:"password" binding:"required,max=256"`
 	FullName string `json:"fullName" binding:"required,validFullname"`
 	Email    string `json:"email" binding:"required,email"`
 }
@@ -13,5 +14,38 @@ type GetUserRequest struct {
 
 type LoginRequest struct {
 	Username string `json:"username" binding:"required,validUsername"`
-	Password string `json:"password" binding:"required,validPassword"`
+	Password string `json:"password" binding:"required,max=256"`
+}
+
+type VerifyEmailRequest struct {
+	// Token is the token emailed to the user
+	Token string `json:"token" binding:"required,max=128"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	// Token is the token emailed to the user
+	Token       string `json:"token" binding:"required,max=128"`
+	NewPassword string `json:"new_password" binding:"required,max=256"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required,max=256"`
+	NewPassword     string `json:"new_password" binding:"required,max=256"`
+}
+
+type PasswordlessLoginRequest struct {
+	// Identifier is the username or the email of the user
+	Identifier string `json:"identifier" binding:"required,max=254"`
+}
+
+// VerifyPasswordlessLoginRequest carries either the identifier of the user and the code delivered to them, or the
+// token of the magic link delivered to them.
+type VerifyPasswordlessLoginRequest struct {
+	Identifier string `json:"identifier" binding:"required_without=Token,max=254"`
+	Code       string `json:"code" binding:"required_with=Identifier,max=32"`
+	Token      string `json:"token" binding:"required_without=Identifier,max=128"`
 }
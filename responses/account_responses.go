@@ -30,3 +30,9 @@ type EntryResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 	Amount    int32     `json:"amount"`
 }
+
+type AccountBalanceResponse struct {
+	AccountID int64     `json:"account_id"`
+	Balance   int64     `json:"balance"`
+	At        time.Time `json:"at"`
+}
@@ -0,0 +1,60 @@
+package responses
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+type AdminUserResponse struct {
+	UserInformationResponse
+	FrozenAt              *time.Time `json:"frozen_at"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+}
+
+type AdminListUsersResponse struct {
+	Users []AdminUserResponse `json:"users"`
+}
+
+type AdminAccountResponse struct {
+	GetAccountResponse
+	FrozenAt *time.Time `json:"frozen_at"`
+}
+
+type AdminListAccountsResponse struct {
+	Accounts []AdminAccountResponse `json:"accounts"`
+}
+
+type AdminSessionResponse struct {
+	SessionID  uuid.UUID  `json:"session_id"`
+	FamilyID   uuid.UUID  `json:"family_id"`
+	UserAgent  string     `json:"user_agent"`
+	ClientIP   string     `json:"client_ip"`
+	IsBlocked  bool       `json:"is_blocked"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ConsumedAt *time.Time `json:"consumed_at"`
+}
+
+type AdminListSessionsResponse struct {
+	Sessions []AdminSessionResponse `json:"sessions"`
+}
+
+type ListEntriesResponse struct {
+	Entries []EntryResponse `json:"entries"`
+}
+
+type AuditEventResponse struct {
+	ID         int64           `json:"id"`
+	Operator   string          `json:"operator"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Reason     string          `json:"reason"`
+	Details    json.RawMessage `json:"details"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type ListAuditEventsResponse struct {
+	Events []AuditEventResponse `json:"events"`
+}
@@ -0,0 +1,27 @@
+package responses
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ApprovalResponse struct {
+	ID             int64           `json:"id"`
+	Operation      string          `json:"operation"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	Status         string          `json:"status"`
+	RequestedBy    string          `json:"requested_by"`
+	Reason         string          `json:"reason"`
+	DecidedBy      string          `json:"decided_by,omitempty"`
+	DecisionReason string          `json:"decision_reason,omitempty"`
+	Result         json.RawMessage `json:"result,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	DecidedAt      *time.Time      `json:"decided_at,omitempty"`
+	ExecutedAt     *time.Time      `json:"executed_at,omitempty"`
+}
+
+type ListApprovalsResponse struct {
+	Approvals []ApprovalResponse `json:"approvals"`
+}
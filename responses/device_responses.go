@@ -0,0 +1,17 @@
+package responses
+
+import "time"
+
+type TrustedDeviceResponse struct {
+	ID        int64  `json:"id"`
+	UserAgent string `json:"user_agent"`
+	// IPRange is the network the device last logged in from, LastIP its address
+	IPRange    string    `json:"ip_range"`
+	LastIP     string    `json:"last_ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type ListTrustedDevicesResponse struct {
+	Devices []TrustedDeviceResponse `json:"devices"`
+}
@@ -0,0 +1,13 @@
+package responses
+
+type EnrollTOTPResponse struct {
+	// Secret is the base32 encoded secret, for users typing it in their authenticator app
+	Secret string `json:"secret"`
+	// OTPAuthURI is the uri of the secret, for authenticator apps scanning it as a QR code
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type ConfirmTOTPResponse struct {
+	// RecoveryCodes replace a code of the authenticator once each, they are only returned once
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,27 @@
+package responses
+
+import "time"
+
+type OAuthClientResponse struct {
+	ClientID     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	Confidential bool      `json:"confidential"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type RegisterOAuthClientResponse struct {
+	OAuthClientResponse
+	// ClientSecret is the secret of a confidential client, it is only returned once since it is stored hashed
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+type ListOAuthClientsResponse struct {
+	Clients []OAuthClientResponse `json:"clients"`
+}
+
+type AuthorizeOAuthClientResponse struct {
+	// RedirectURI is the redirect uri of the client carrying the authorization code
+	RedirectURI string `json:"redirect_uri"`
+}
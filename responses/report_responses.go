@@ -0,0 +1,19 @@
+package responses
+
+import "time"
+
+type TrialBalanceLineResponse struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Balance  int64  `json:"balance"`
+}
+
+type TrialBalanceResponse struct {
+	At                    time.Time                  `json:"at"`
+	GLAccounts            []TrialBalanceLineResponse `json:"gl_accounts"`
+	CustomerAccounts      int64                      `json:"customer_accounts"`
+	CustomerAccountsTotal int64                      `json:"customer_accounts_total"`
+	Total                 int64                      `json:"total"`
+	Balanced              bool                       `json:"balanced"`
+}
@@ -0,0 +1,16 @@
+package responses
+
+import "time"
+
+type StepUpResponse struct {
+	// StepUpToken is sent in the X-Step-Up-Token header of the request performing the operation
+	StepUpToken          string    `json:"step_up_token"`
+	StepUpTokenExpiresAt time.Time `json:"step_up_token_expires_at"`
+	Operation            string    `json:"operation"`
+}
+
+type StepUpRequiredResponse struct {
+	Error string `json:"error"`
+	// Operation is the operation to re-authenticate for with POST /users/step_up
+	Operation string `json:"step_up_operation"`
+}
@@ -1,8 +1,38 @@
 package responses
 
-import "time"
+import (
+	"Simple-Bank/token"
+	"github.com/google/uuid"
+	"time"
+)
 
 type RenewAccessTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+	SessionID             uuid.UUID `json:"session_id"`
+}
+
+type SessionResponse struct {
+	SessionID uuid.UUID `json:"session_id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIP  string    `json:"client_ip"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Current reports whether the session is the one of the request
+	Current bool `json:"current"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+type LogoutOtherSessionsResponse struct {
+	RevokedSessions int64 `json:"revoked_sessions"`
+}
+
+// PublicKeysResponse is the JSON web key set of the keys verifying tokens, as described by RFC 7517
+type PublicKeysResponse struct {
+	Keys []token.JSONWebKey `json:"keys"`
 }
@@ -8,6 +8,22 @@ type TransferResponse struct {
 	DstAccountID    int64     `json:"dst_account_id"`
 	IncomingEntryID int64     `json:"incoming_entry_id"`
 	OutgoingEntryID int64     `json:"out_going_entry_id"`
+	Status          string    `json:"status"`
+	StatusReason    string    `json:"status_reason"`
 	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 	Amount          int32     `json:"amount"`
 }
+
+type TransferStatusChangeResponse struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	Actor      string    `json:"actor"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type GetTransferResponse struct {
+	TransferResponse
+	History []TransferStatusChangeResponse `json:"history"`
+}
@@ -9,9 +9,12 @@ type UserInformationResponse struct {
 	Username  string    `json:"username"`
 	FullName  string    `json:"fullname"`
 	Email     string    `json:"email"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	DeletedAt time.Time `json:"deleted_at"`
+	// EmailVerified is true once the user verified they own Email
+	EmailVerified bool `json:"email_verified"`
 }
 
 type LoginResponse struct {
@@ -21,4 +24,34 @@ type LoginResponse struct {
 	RefreshTokenExpiresAt time.Time               `json:"refresh_token_expires_at"`
 	UserInformation       UserInformationResponse `json:"user_information"`
 	SessionID             uuid.UUID               `json:"session_id"`
+	// PasswordResetRequired is true when the back office requires the user to change their password
+	PasswordResetRequired bool `json:"password_reset_required"`
+	// MFARequired is true when the user must complete the login with a second factor, and the response only
+	// carries the challenge to complete instead of tokens
+	MFARequired bool `json:"mfa_required"`
+	// MFAChallengeToken is exchanged with a code at the second step of the login
+	MFAChallengeToken     string     `json:"mfa_challenge_token,omitempty"`
+	MFAChallengeExpiresAt *time.Time `json:"mfa_challenge_expires_at,omitempty"`
+	// DeviceVerificationRequired is true when the user logs in from a new device, and must complete the login with
+	// the login code delivered to them instead of getting tokens
+	DeviceVerificationRequired bool `json:"device_verification_required,omitempty"`
+}
+
+type ChangePasswordResponse struct {
+	// RevokedSessions is the number of other sessions of the user that were logged out
+	RevokedSessions int64 `json:"revoked_sessions"`
+}
+
+type PasswordPolicyResponse struct {
+	// MinLength and MaxLength bound the length of new passwords in characters
+	MinLength int `json:"min_length"`
+	MaxLength int `json:"max_length"`
+	// Rules are every rule new passwords must satisfy, the lengths included
+	Rules []PasswordRule `json:"rules"`
+}
+
+type PasswordRule struct {
+	Name string `json:"name"`
+	// Description completes a sentence starting with "password", e.g. "must contain a digit"
+	Description string `json:"description"`
 }
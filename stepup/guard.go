@@ -0,0 +1,193 @@
+package stepup
+
+import (
+	"Simple-Bank/auth"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/mfa"
+	"Simple-Bank/token"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Operation is a sensitive operation that can require a recent re-authentication.
+type Operation string
+
+// operations that can require a recent re-authentication
+const (
+	OperationTransfer     Operation = "transfer"
+	OperationCloseAccount Operation = "close_account"
+	OperationChangeEmail  Operation = "change_email"
+)
+
+// operations lists every operation a user can re-authenticate for.
+var operations = []Operation{
+	OperationTransfer,
+	OperationCloseAccount,
+	OperationChangeEmail,
+}
+
+const (
+	// DefaultTokenDuration is how long elevated tokens last when no duration is configured
+	DefaultTokenDuration = 5 * time.Minute
+	// Audience is the audience of elevated tokens, which sets them apart from access tokens
+	Audience = "step_up"
+	// scopePrefix prefixes the operation an elevated token is accepted for in its scopes
+	scopePrefix = "step_up:"
+)
+
+var (
+	// ErrStepUpRequired is returned when an operation is performed without a valid elevated token for it
+	ErrStepUpRequired = errors.New("recent re-authentication is required")
+	// ErrInvalidCredentials is returned when a user re-authenticates with a wrong password or code
+	ErrInvalidCredentials = errors.New("password or code is incorrect")
+	// ErrUnknownOperation is returned when a user re-authenticates for an operation that does not exist
+	ErrUnknownOperation = errors.New("unknown operation")
+)
+
+// IsValidOperation reports whether operation is an operation a user can re-authenticate for.
+func IsValidOperation(operation string) bool {
+	return slices.Contains(operations, Operation(operation))
+}
+
+// Guard decides which operations require a recent re-authentication, and issues and checks the short-lived
+// elevated tokens proving it. An elevated token is only accepted for the operation and the user it was issued for,
+// and never as an access token, since its scope grants no endpoint.
+type Guard struct {
+	services services.Services
+	mfa      *mfa.Manager
+	// logins checks the passwords, so wrong passwords count towards the same lockout as failed logins
+	logins     *auth.LoginAuthenticator
+	tokenMaker token.Maker
+	// tokenDuration is how long elevated tokens last, i.e. how recent a re-authentication must be
+	tokenDuration time.Duration
+	// transferThreshold is the amount from which transfers require a re-authentication, zero if they never do
+	transferThreshold int32
+	// operations are the operations other than transfers that always require a re-authentication
+	operations []Operation
+}
+
+// NewGuard creates a Guard issuing elevated tokens lasting tokenDuration, DefaultTokenDuration if zero.
+// Transfers of at least transferThreshold and the given operations require a re-authentication.
+// Users with two-factor authentication re-authenticate with a code, other users with their password, checked by logins.
+func NewGuard(
+	services services.Services,
+	mfa *mfa.Manager,
+	logins *auth.LoginAuthenticator,
+	tokenMaker token.Maker,
+	tokenDuration time.Duration,
+	transferThreshold int32,
+	operations []string,
+) *Guard {
+	if tokenDuration <= 0 {
+		tokenDuration = DefaultTokenDuration
+	}
+
+	guard := &Guard{
+		services:          services,
+		mfa:               mfa,
+		logins:            logins,
+		tokenMaker:        tokenMaker,
+		tokenDuration:     tokenDuration,
+		transferThreshold: transferThreshold,
+	}
+	for _, operation := range operations {
+		guard.operations = append(guard.operations, Operation(operation))
+	}
+
+	return guard
+}
+
+// TransferRequiresStepUp reports whether a transfer of amount requires a recent re-authentication.
+func (guard *Guard) TransferRequiresStepUp(amount int32) bool {
+	return guard.transferThreshold > 0 && amount >= guard.transferThreshold
+}
+
+// RequiresStepUp reports whether an operation other than a transfer requires a recent re-authentication.
+func (guard *Guard) RequiresStepUp(operation Operation) bool {
+	return slices.Contains(guard.operations, operation)
+}
+
+// Elevate re-authenticates a user with their password, or with a code of their authenticator app if they enabled
+// two-factor authentication, and returns an elevated token accepted for operation until it expires.
+// Wrong passwords are counted like failed logins from clientIP, and return a *auth.LockedError once too many failed.
+func (guard *Guard) Elevate(username string, operation Operation, password, code, clientIP string) (string, *token.Payload, error) {
+	if !IsValidOperation(string(operation)) {
+		return "", nil, ErrUnknownOperation
+	}
+
+	enabled, err := guard.mfa.Enabled(username)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var user models.User
+	if enabled {
+		user, err = guard.services.GetUser(username)
+		if err != nil {
+			return "", nil, err
+		}
+		if err := guard.mfa.Verify(username, code); err != nil {
+			if errors.Is(err, mfa.ErrInvalidCode) {
+				return "", nil, ErrInvalidCredentials
+			}
+			return "", nil, err
+		}
+	} else {
+		user, err = guard.logins.Authenticate(username, password, clientIP)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				return "", nil, ErrInvalidCredentials
+			}
+			return "", nil, err
+		}
+	}
+
+	return guard.tokenMaker.CreateScopedToken(
+		username,
+		user.Role,
+		[]string{scopePrefix + string(operation)},
+		Audience,
+		guard.tokenDuration,
+	)
+}
+
+// RequiredError is the error of an operation performed without a valid elevated token for it.
+// It wraps ErrStepUpRequired and names the operation, so clients know what to re-authenticate for.
+type RequiredError struct {
+	Operation Operation
+	// Reason is why the elevated token was refused, empty if none was given
+	Reason string
+}
+
+func (err *RequiredError) Error() string {
+	if err.Reason == "" {
+		return fmt.Sprintf("%s for %s", ErrStepUpRequired, err.Operation)
+	}
+	return fmt.Sprintf("%s for %s: %s", ErrStepUpRequired, err.Operation, err.Reason)
+}
+
+func (err *RequiredError) Unwrap() error {
+	return ErrStepUpRequired
+}
+
+// Check returns a *RequiredError unless elevatedToken is a valid elevated token issued to the user of payload
+// for operation.
+func (guard *Guard) Check(payload *token.Payload, operation Operation, elevatedToken string) error {
+	if elevatedToken == "" {
+		return &RequiredError{Operation: operation}
+	}
+
+	elevated, err := guard.tokenMaker.VerifyToken(elevatedToken)
+	if err != nil {
+		return &RequiredError{Operation: operation, Reason: err.Error()}
+	}
+	if elevated.Audience != Audience || elevated.Username != payload.Username ||
+		!slices.Contains(elevated.Scopes, scopePrefix+string(operation)) {
+		return &RequiredError{Operation: operation, Reason: "token was not issued for this operation"}
+	}
+
+	return nil
+}
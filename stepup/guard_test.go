@@ -0,0 +1,138 @@
+package stepup
+
+import (
+	"Simple-Bank/auth"
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/mfa"
+	"Simple-Bank/token"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func newTestGuard(t *testing.T, mockServices *mockdb.MockServices, tokenDuration time.Duration) (*Guard, token.Maker) {
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32, util.ALL))
+	require.NoError(t, err)
+
+	manager := mfa.NewManager(mockServices, util.RandomString(32, util.ALL), 0)
+	logins := auth.NewLoginAuthenticator(mockServices, 0, 0, 0)
+	guard := NewGuard(mockServices, manager, logins, tokenMaker, tokenDuration, 1000, []string{string(OperationCloseAccount)})
+
+	return guard, tokenMaker
+}
+
+func TestRequiresStepUp(t *testing.T) {
+	guard, _ := newTestGuard(t, nil, 0)
+	require.Equal(t, DefaultTokenDuration, guard.tokenDuration)
+
+	require.False(t, guard.TransferRequiresStepUp(999))
+	require.True(t, guard.TransferRequiresStepUp(1000))
+	require.True(t, guard.RequiresStepUp(OperationCloseAccount))
+	require.False(t, guard.RequiresStepUp(OperationChangeEmail))
+
+	// a zero threshold disables the re-authentication of transfers
+	require.False(t, NewGuard(nil, nil, nil, nil, 0, 0, nil).TransferRequiresStepUp(1<<30))
+}
+
+func TestElevateAndCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	guard, tokenMaker := newTestGuard(t, mockServices, time.Minute)
+
+	password := util.RandomPassword()
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+	user := models.User{Username: util.RandomUsername(), HashedPassword: hashedPassword, Role: models.RoleCustomer}
+
+	mockServices.EXPECT().GetUser(user.Username).AnyTimes().Return(user, nil)
+	mockServices.EXPECT().GetTOTPCredential(user.Username).AnyTimes().Return(models.TOTPCredential{}, gorm.ErrRecordNotFound)
+	mockServices.EXPECT().GetLoginFailure(gomock.Any(), gomock.Any()).AnyTimes().Return(models.LoginFailure{}, gorm.ErrRecordNotFound)
+	mockServices.EXPECT().ClearLoginFailures(models.LoginFailureKindUsername, user.Username).AnyTimes().Return(nil)
+
+	// wrong passwords count as failed logins
+	mockServices.EXPECT().
+		RecordLoginFailure(models.LoginFailureKindUsername, user.Username, gomock.Any()).
+		Times(1).
+		Return(models.LoginFailure{}, nil)
+	_, _, err = guard.Elevate(user.Username, OperationTransfer, "wrong password", "", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	_, _, err = guard.Elevate(user.Username, "withdraw", password, "", "")
+	require.ErrorIs(t, err, ErrUnknownOperation)
+
+	elevatedToken, payload, err := guard.Elevate(user.Username, OperationTransfer, password, "", "")
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Minute), payload.ExpiredAt, time.Second)
+
+	accessPayload := &token.Payload{Username: user.Username}
+	require.NoError(t, guard.Check(accessPayload, OperationTransfer, elevatedToken))
+
+	// the token is only accepted for its operation and its user
+	var required *RequiredError
+	err = guard.Check(accessPayload, OperationCloseAccount, elevatedToken)
+	require.ErrorIs(t, err, ErrStepUpRequired)
+	require.ErrorAs(t, err, &required)
+	require.Equal(t, OperationCloseAccount, required.Operation)
+	require.ErrorIs(t, guard.Check(&token.Payload{Username: util.RandomUsername()}, OperationTransfer, elevatedToken), ErrStepUpRequired)
+	require.ErrorIs(t, guard.Check(accessPayload, OperationTransfer, ""), ErrStepUpRequired)
+
+	// access tokens are not elevated tokens
+	accessToken, _, err := tokenMaker.CreateToken(user.Username, time.Minute)
+	require.NoError(t, err)
+	require.ErrorIs(t, guard.Check(accessPayload, OperationTransfer, accessToken), ErrStepUpRequired)
+
+	expiredToken, _, err := tokenMaker.CreateScopedToken(
+		user.Username, user.Role, []string{scopePrefix + string(OperationTransfer)}, Audience, -time.Minute)
+	require.NoError(t, err)
+	require.ErrorIs(t, guard.Check(accessPayload, OperationTransfer, expiredToken), ErrStepUpRequired)
+}
+
+func TestElevateLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	guard, _ := newTestGuard(t, mockServices, time.Minute)
+
+	password := util.RandomPassword()
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+	user := models.User{Username: util.RandomUsername(), HashedPassword: hashedPassword}
+
+	mockServices.EXPECT().GetTOTPCredential(user.Username).AnyTimes().Return(models.TOTPCredential{}, gorm.ErrRecordNotFound)
+	mockServices.EXPECT().
+		GetLoginFailure(models.LoginFailureKindUsername, user.Username).
+		Times(1).
+		Return(models.LoginFailure{Failures: auth.DefaultMaxLoginFailures, LastFailedAt: time.Now()}, nil)
+	mockServices.EXPECT().GetUser(gomock.Any()).Times(0)
+
+	// once the user failed too many times, not even the right password elevates
+	var locked *auth.LockedError
+	_, _, err = guard.Elevate(user.Username, OperationTransfer, password, "", "")
+	require.ErrorAs(t, err, &locked)
+	require.ErrorIs(t, err, auth.ErrLoginLocked)
+}
+
+func TestElevateWithTwoFactorAuthentication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	guard, _ := newTestGuard(t, mockServices, time.Minute)
+
+	password := util.RandomPassword()
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+	user := models.User{Username: util.RandomUsername(), HashedPassword: hashedPassword}
+	confirmedAt := time.Now()
+
+	mockServices.EXPECT().GetUser(user.Username).AnyTimes().Return(user, nil)
+	mockServices.EXPECT().
+		GetTOTPCredential(user.Username).
+		AnyTimes().
+		Return(models.TOTPCredential{Username: user.Username, ConfirmedAt: &confirmedAt}, nil)
+	mockServices.EXPECT().UseRecoveryCode(user.Username, gomock.Any()).Times(1).Return(gorm.ErrRecordNotFound)
+
+	// the password is not enough for users with two-factor authentication
+	_, _, err = guard.Elevate(user.Username, OperationTransfer, password, "abcde-12345", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+}
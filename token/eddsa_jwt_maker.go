@@ -0,0 +1,144 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"time"
+)
+
+// signingMethodEdDSA signs JWTs with Ed25519, as described by RFC 8037
+type signingMethodEdDSA struct{}
+
+var edDSASigningMethod = &signingMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(edDSASigningMethod.Alg(), func() jwt.SigningMethod {
+		return edDSASigningMethod
+	})
+}
+
+func (method *signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+func (method *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
+
+func (method *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	decoded, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, []byte(signingString), decoded) {
+		return jwt.ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// EdDSAJWTMaker makes JWTs signed with the current key of a KeyRing and verified with any of its keys,
+// named by the kid header, so services verifying tokens only need the public keys.
+type EdDSAJWTMaker struct {
+	keyRing *KeyRing
+}
+
+func NewEdDSAJWTMaker(keyRing *KeyRing) (Maker, error) {
+	return &EdDSAJWTMaker{keyRing: keyRing}, nil
+}
+
+func (maker *EdDSAJWTMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.CreateSessionToken(username, "", uuid.Nil, duration)
+}
+
+func (maker *EdDSAJWTMaker) CreateSessionToken(username, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+	payload.Role = role
+	payload.SessionID = sessionID
+
+	return maker.sign(payload)
+}
+
+func (maker *EdDSAJWTMaker) CreateRefreshToken(username, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+	payload.Type = TokenTypeRefresh
+	payload.Role = role
+	payload.SessionID = sessionID
+
+	return maker.sign(payload)
+}
+
+func (maker *EdDSAJWTMaker) CreateScopedToken(username, role string, scopes []string, audience string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+	payload.Role = role
+	payload.Scopes = scopes
+	payload.Audience = audience
+
+	return maker.sign(payload)
+}
+
+func (maker *EdDSAJWTMaker) sign(payload *Payload) (string, *Payload, error) {
+	key := maker.keyRing.signingKey()
+
+	jwtToken := jwt.NewWithClaims(edDSASigningMethod, payload)
+	jwtToken.Header["kid"] = key.ID
+	signedToken, err := jwtToken.SignedString(key.PrivateKey)
+	return signedToken, payload, err
+}
+
+func (maker *EdDSAJWTMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		if token.Method != edDSASigningMethod {
+			return nil, ErrInvalidToken
+		}
+		keyID, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		return maker.keyRing.verificationKey(keyID)
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+	if err != nil {
+		// unlike signature errors, the expiry is only checked once the signature is verified
+		var verr *jwt.ValidationError
+		if errors.As(err, &verr) && errors.Is(verr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+func (maker *EdDSAJWTMaker) PublicKeys() []JSONWebKey {
+	return maker.keyRing.publicKeys(edDSASigningMethod.Alg())
+}
@@ -0,0 +1,80 @@
+package token
+
+import (
+	"Simple-Bank/util"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestEdDSAJWTMaker(t *testing.T) {
+	keyRing, key := newKeyRing(t, "2024-01")
+	maker, err := NewEdDSAJWTMaker(keyRing)
+	require.NoError(t, err)
+
+	username := util.RandomUsername()
+	token, payload, err := maker.CreateSessionToken(username, "teller", uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	returnedPayload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, returnedPayload.ID)
+	require.Equal(t, username, returnedPayload.Username)
+	require.Equal(t, "teller", returnedPayload.Role)
+	require.Equal(t, payload.SessionID, returnedPayload.SessionID)
+
+	// the key id is embedded in the header of the token
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, &Payload{})
+	require.NoError(t, err)
+	require.Equal(t, key.ID, parsed.Header["kid"])
+	require.Equal(t, "EdDSA", parsed.Header["alg"])
+
+	publicKeys := maker.PublicKeys()
+	require.Len(t, publicKeys, 1)
+	require.Equal(t, "EdDSA", publicKeys[0].Algorithm)
+}
+
+func TestExpiredEdDSAJWTToken(t *testing.T) {
+	keyRing, _ := newKeyRing(t, "2024-01")
+	maker, err := NewEdDSAJWTMaker(keyRing)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomUsername(), -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.Equal(t, ErrExpiredToken, err)
+	require.Nil(t, payload)
+}
+
+func TestInvalidEdDSAJWTToken(t *testing.T) {
+	keyRing, _ := newKeyRing(t, "2024-01")
+	maker, err := NewEdDSAJWTMaker(keyRing)
+	require.NoError(t, err)
+
+	// tokens signed with a secret key are refused, so the public key cannot be used as an HMAC secret
+	symmetricMaker, err := NewJWTMaker(util.RandomString(32, util.ALL))
+	require.NoError(t, err)
+	symmetricToken, _, err := symmetricMaker.CreateToken(util.RandomUsername(), time.Minute)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(symmetricToken)
+	require.Equal(t, ErrInvalidToken, err)
+
+	// tokens of unknown keys are refused
+	otherRing, _ := newKeyRing(t, "2024-02")
+	otherMaker, err := NewEdDSAJWTMaker(otherRing)
+	require.NoError(t, err)
+	otherToken, _, err := otherMaker.CreateToken(util.RandomUsername(), time.Minute)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(otherToken)
+	require.Equal(t, ErrInvalidToken, err)
+
+	// tokens of the none algorithm are refused
+	noneToken, err := jwt.NewWithClaims(jwt.SigningMethodNone, &Payload{Username: util.RandomUsername(), ExpiredAt: time.Now().Add(time.Minute)}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(noneToken)
+	require.Equal(t, ErrInvalidToken, err)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 	"time"
 )
 
@@ -24,11 +25,45 @@ func NewJWTMaker(secretKey string) (Maker, error) {
 }
 
 func (maker *JWTMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.CreateSessionToken(username, "", uuid.Nil, duration)
+}
+
+func (maker *JWTMaker) CreateSessionToken(username, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+	payload.Role = role
+	payload.SessionID = sessionID
+
+	return maker.sign(payload)
+}
+
+func (maker *JWTMaker) CreateRefreshToken(username, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+	payload.Type = TokenTypeRefresh
+	payload.Role = role
+	payload.SessionID = sessionID
+
+	return maker.sign(payload)
+}
+
+func (maker *JWTMaker) CreateScopedToken(username, role string, scopes []string, audience string, duration time.Duration) (string, *Payload, error) {
 	payload, err := NewPayload(username, duration)
 	if err != nil {
 		return "", nil, err
 	}
+	payload.Role = role
+	payload.Scopes = scopes
+	payload.Audience = audience
+
+	return maker.sign(payload)
+}
 
+func (maker *JWTMaker) sign(payload *Payload) (string, *Payload, error) {
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
 	signedToken, err := jwtToken.SignedString([]byte(maker.secretKey))
 	return signedToken, payload, err
@@ -62,3 +97,8 @@ func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
 
 	return payload, nil
 }
+
+// PublicKeys returns no key, since tokens are signed with the symmetric key.
+func (maker *JWTMaker) PublicKeys() []JSONWebKey {
+	return nil
+}
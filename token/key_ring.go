@@ -0,0 +1,154 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnknownKey is returned when a token names a key missing from the key ring, or a key that has retired
+var ErrUnknownKey = errors.New("signing key is unknown")
+
+// SigningKey is an Ed25519 key of a KeyRing, identified by the id embedded in the tokens it signs.
+type SigningKey struct {
+	ID string
+	// PrivateKey signs tokens, it is only required for the key signing new tokens
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+	// RetiresAt is when the key stops verifying tokens, zero if it never does
+	RetiresAt time.Time
+}
+
+// GenerateSigningKey generates a new key with the given id.
+func GenerateSigningKey(id string) (SigningKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return SigningKey{ID: id, PrivateKey: privateKey, PublicKey: publicKey}, nil
+}
+
+// ParseSigningKey creates a key from its base64 encoded seed or public key. The public key is derived from the
+// seed when only the seed is given, and keys only verifying tokens can be created from their public key alone.
+func ParseSigningKey(id, seed, publicKey string, retiresAt time.Time) (SigningKey, error) {
+	key := SigningKey{ID: id, RetiresAt: retiresAt}
+
+	if seed != "" {
+		decoded, err := base64.StdEncoding.DecodeString(seed)
+		if err != nil || len(decoded) != ed25519.SeedSize {
+			return SigningKey{}, fmt.Errorf("private key of %q must be a base64 encoded seed of %d bytes", id, ed25519.SeedSize)
+		}
+		key.PrivateKey = ed25519.NewKeyFromSeed(decoded)
+		key.PublicKey = key.PrivateKey.Public().(ed25519.PublicKey)
+	}
+
+	if publicKey != "" {
+		decoded, err := base64.StdEncoding.DecodeString(publicKey)
+		if err != nil || len(decoded) != ed25519.PublicKeySize {
+			return SigningKey{}, fmt.Errorf("public key of %q must be base64 encoded and of %d bytes", id, ed25519.PublicKeySize)
+		}
+		if key.PublicKey != nil && !key.PublicKey.Equal(ed25519.PublicKey(decoded)) {
+			return SigningKey{}, fmt.Errorf("public key of %q does not match its private key", id)
+		}
+		key.PublicKey = decoded
+	}
+
+	if key.PublicKey == nil {
+		return SigningKey{}, fmt.Errorf("key %q has neither a private nor a public key", id)
+	}
+
+	return key, nil
+}
+
+// KeyRing holds the keys of the makers signing tokens with Ed25519: the current key, signing new tokens,
+// and the previous keys, still verifying the tokens they signed until they retire.
+//
+// To rotate keys without logging anyone out, a new key is put first and the previous current key is kept
+// with a retirement date later than the expiry of the last token it signed, e.g. a refresh token duration away.
+type KeyRing struct {
+	current SigningKey
+	// keys are the current and the previous keys, in order
+	keys []SigningKey
+}
+
+// NewKeyRing creates a KeyRing signing with current and verifying with current and previous.
+func NewKeyRing(current SigningKey, previous ...SigningKey) (*KeyRing, error) {
+	if current.PrivateKey == nil {
+		return nil, fmt.Errorf("current key %q must have a private key", current.ID)
+	}
+	if !current.RetiresAt.IsZero() {
+		return nil, fmt.Errorf("current key %q cannot retire", current.ID)
+	}
+
+	keys := append([]SigningKey{current}, previous...)
+	ids := map[string]bool{}
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("keys must have an id")
+		}
+		if ids[key.ID] {
+			return nil, fmt.Errorf("key id %q is used more than once", key.ID)
+		}
+		ids[key.ID] = true
+	}
+
+	return &KeyRing{current: current, keys: keys}, nil
+}
+
+// signingKey returns the key signing new tokens.
+func (keyRing *KeyRing) signingKey() SigningKey {
+	return keyRing.current
+}
+
+// verificationKey returns the public key with the given id, or ErrUnknownKey if it is unknown or retired.
+func (keyRing *KeyRing) verificationKey(id string) (ed25519.PublicKey, error) {
+	for _, key := range keyRing.keys {
+		if key.ID == id && !key.retired(time.Now()) {
+			return key.PublicKey, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// publicKeys returns the keys verifying tokens as JSON web keys, the current key first.
+func (keyRing *KeyRing) publicKeys(algorithm string) []JSONWebKey {
+	now := time.Now()
+	var keys []JSONWebKey
+	for _, key := range keyRing.keys {
+		if !key.retired(now) {
+			keys = append(keys, newJSONWebKey(key, algorithm))
+		}
+	}
+
+	return keys
+}
+
+func (key SigningKey) retired(now time.Time) bool {
+	return !key.RetiresAt.IsZero() && now.After(key.RetiresAt)
+}
+
+// JSONWebKey is a public key as described by RFC 7517 and RFC 8037, published so other services can verify tokens.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg,omitempty"`
+}
+
+func newJSONWebKey(key SigningKey, algorithm string) JSONWebKey {
+	return JSONWebKey{
+		KeyType:   "OKP",
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(key.PublicKey),
+		KeyID:     key.ID,
+		Use:       "sig",
+		Algorithm: algorithm,
+	}
+}
@@ -0,0 +1,67 @@
+package token
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func mustGenerateSigningKey(t *testing.T, id string) SigningKey {
+	key, err := GenerateSigningKey(id)
+	require.NoError(t, err)
+
+	return key
+}
+
+func TestParseSigningKey(t *testing.T) {
+	generated := mustGenerateSigningKey(t, "2024-01")
+	seed := base64.StdEncoding.EncodeToString(generated.PrivateKey.Seed())
+	publicKey := base64.StdEncoding.EncodeToString(generated.PublicKey)
+
+	key, err := ParseSigningKey("2024-01", seed, "", time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, generated.PrivateKey, key.PrivateKey)
+	require.Equal(t, generated.PublicKey, key.PublicKey)
+
+	key, err = ParseSigningKey("2024-01", "", publicKey, time.Time{})
+	require.NoError(t, err)
+	require.Nil(t, key.PrivateKey)
+	require.Equal(t, generated.PublicKey, key.PublicKey)
+
+	other := mustGenerateSigningKey(t, "other")
+	_, err = ParseSigningKey("2024-01", seed, base64.StdEncoding.EncodeToString(other.PublicKey), time.Time{})
+	require.Error(t, err)
+
+	_, err = ParseSigningKey("2024-01", "c2hvcnQ=", "", time.Time{})
+	require.Error(t, err)
+
+	_, err = ParseSigningKey("2024-01", "", "", time.Time{})
+	require.Error(t, err)
+}
+
+func TestNewKeyRing(t *testing.T) {
+	current := mustGenerateSigningKey(t, "2024-02")
+
+	_, err := NewKeyRing(SigningKey{ID: "2024-02", PublicKey: current.PublicKey})
+	require.Error(t, err, "the current key must be able to sign")
+
+	_, err = NewKeyRing(current, mustGenerateSigningKey(t, "2024-02"))
+	require.Error(t, err, "key ids must be unique")
+
+	_, err = NewKeyRing(mustGenerateSigningKey(t, ""))
+	require.Error(t, err, "keys must have an id")
+
+	retiring := current
+	retiring.RetiresAt = time.Now().Add(time.Hour)
+	_, err = NewKeyRing(retiring)
+	require.Error(t, err, "the current key cannot retire")
+
+	keyRing, err := NewKeyRing(current, mustGenerateSigningKey(t, "2024-01"))
+	require.NoError(t, err)
+
+	_, err = keyRing.verificationKey("2024-01")
+	require.NoError(t, err)
+	_, err = keyRing.verificationKey("2023-12")
+	require.ErrorIs(t, err, ErrUnknownKey)
+}
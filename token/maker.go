@@ -1,8 +1,22 @@
 package token
 
-import "time"
+import (
+	"github.com/google/uuid"
+	"time"
+)
 
 type Maker interface {
 	CreateToken(username string, duration time.Duration) (string, *Payload, error)
+	// CreateSessionToken creates a token for a user with the given role, bound to the session with the given id
+	CreateSessionToken(username, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error)
+	// CreateRefreshToken creates a refresh token for a user with the given role, bound to the session with the given id.
+	// Refresh tokens are not access tokens: they can only be exchanged for new tokens of the session
+	CreateRefreshToken(username, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error)
+	// CreateScopedToken creates a token for a user with the given role, restricted to scopes and issued to audience,
+	// e.g. the OAuth client that requested it
+	CreateScopedToken(username, role string, scopes []string, audience string, duration time.Duration) (string, *Payload, error)
 	VerifyToken(token string) (*Payload, error)
+	// PublicKeys returns the keys other services can verify the tokens with, empty if the tokens are signed
+	// with a secret key
+	PublicKeys() []JSONWebKey
 }
@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 	time "time"
 
+	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +41,54 @@ func (m *MockMaker) EXPECT() *MockMakerMockRecorder {
 	return m.recorder
 }
 
+// CreateRefreshToken mocks base method.
+func (m *MockMaker) CreateRefreshToken(arg0 string, arg1 string, arg2 uuid.UUID, arg3 time.Duration) (string, *token.Payload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*token.Payload)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockMakerMockRecorder) CreateRefreshToken(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockMaker)(nil).CreateRefreshToken), arg0, arg1, arg2, arg3)
+}
+
+// CreateScopedToken mocks base method.
+func (m *MockMaker) CreateScopedToken(arg0 string, arg1 string, arg2 []string, arg3 string, arg4 time.Duration) (string, *token.Payload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScopedToken", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*token.Payload)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateScopedToken indicates an expected call of CreateScopedToken.
+func (mr *MockMakerMockRecorder) CreateScopedToken(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScopedToken", reflect.TypeOf((*MockMaker)(nil).CreateScopedToken), arg0, arg1, arg2, arg3, arg4)
+}
+
+// CreateSessionToken mocks base method.
+func (m *MockMaker) CreateSessionToken(arg0 string, arg1 string, arg2 uuid.UUID, arg3 time.Duration) (string, *token.Payload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSessionToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*token.Payload)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateSessionToken indicates an expected call of CreateSessionToken.
+func (mr *MockMakerMockRecorder) CreateSessionToken(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSessionToken", reflect.TypeOf((*MockMaker)(nil).CreateSessionToken), arg0, arg1, arg2, arg3)
+}
+
 // CreateToken mocks base method.
 func (m *MockMaker) CreateToken(arg0 string, arg1 time.Duration) (string, *token.Payload, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +105,20 @@ func (mr *MockMakerMockRecorder) CreateToken(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockMaker)(nil).CreateToken), arg0, arg1)
 }
 
+// PublicKeys mocks base method.
+func (m *MockMaker) PublicKeys() []token.JSONWebKey {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublicKeys")
+	ret0, _ := ret[0].([]token.JSONWebKey)
+	return ret0
+}
+
+// PublicKeys indicates an expected call of PublicKeys.
+func (mr *MockMakerMockRecorder) PublicKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicKeys", reflect.TypeOf((*MockMaker)(nil).PublicKeys))
+}
+
 // VerifyToken mocks base method.
 func (m *MockMaker) VerifyToken(arg0 string) (*token.Payload, error) {
 	m.ctrl.T.Helper()
@@ -2,6 +2,7 @@ package token
 
 import (
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/o1egl/paseto"
 	"golang.org/x/crypto/chacha20poly1305"
 	"time"
@@ -24,11 +25,45 @@ func NewPasetoMaker(symmetricKey string) (Maker, error) {
 }
 
 func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.CreateSessionToken(username, "", uuid.Nil, duration)
+}
+
+func (maker *PasetoMaker) CreateSessionToken(username, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+	payload.Role = role
+	payload.SessionID = sessionID
+
+	return maker.sign(payload)
+}
+
+func (maker *PasetoMaker) CreateRefreshToken(username, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+	payload.Type = TokenTypeRefresh
+	payload.Role = role
+	payload.SessionID = sessionID
+
+	return maker.sign(payload)
+}
+
+func (maker *PasetoMaker) CreateScopedToken(username, role string, scopes []string, audience string, duration time.Duration) (string, *Payload, error) {
 	payload, err := NewPayload(username, duration)
 	if err != nil {
 		return "", nil, err
 	}
+	payload.Role = role
+	payload.Scopes = scopes
+	payload.Audience = audience
+
+	return maker.sign(payload)
+}
 
+func (maker *PasetoMaker) sign(payload *Payload) (string, *Payload, error) {
 	pasetoToken, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
 	return pasetoToken, payload, err
 }
@@ -48,3 +83,8 @@ func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
 
 	return payload, nil
 }
+
+// PublicKeys returns no key, since tokens are encrypted with the symmetric key.
+func (maker *PasetoMaker) PublicKeys() []JSONWebKey {
+	return nil
+}
@@ -3,6 +3,7 @@ package token
 import (
 	"Simple-Bank/util"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
@@ -78,3 +79,53 @@ func TestInvalidPasetoToken(t *testing.T) {
 	require.Equal(t, ErrInvalidToken, err)
 	require.Nil(t, payload)
 }
+
+func TestPasetoMakerSessionToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32, util.ALL))
+	require.NoError(t, err)
+
+	sessionID := uuid.New()
+	token, payload, err := maker.CreateSessionToken(util.RandomUsername(), "teller", sessionID, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, sessionID, payload.SessionID)
+
+	returnedPayload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, sessionID, returnedPayload.SessionID)
+	require.Equal(t, "teller", returnedPayload.Role)
+	require.Equal(t, payload.ID, returnedPayload.ID)
+	require.Equal(t, TokenTypeAccess, returnedPayload.Type)
+
+	// refresh tokens are bound to the session too, but are not access tokens
+	token, _, err = maker.CreateRefreshToken(util.RandomUsername(), "teller", sessionID, time.Minute)
+	require.NoError(t, err)
+
+	returnedPayload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, sessionID, returnedPayload.SessionID)
+	require.Equal(t, TokenTypeRefresh, returnedPayload.Type)
+	require.ErrorIs(t, returnedPayload.CheckType(TokenTypeAccess), ErrInvalidTokenType)
+
+	// tokens not bound to a session have no session id
+	token, _, err = maker.CreateToken(util.RandomUsername(), time.Minute)
+	require.NoError(t, err)
+
+	returnedPayload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Nil, returnedPayload.SessionID)
+}
+
+func TestPasetoMakerScopedToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32, util.ALL))
+	require.NoError(t, err)
+
+	scopes := []string{"accounts:read", "transfers:read"}
+	token, _, err := maker.CreateScopedToken(util.RandomUsername(), "customer", scopes, "client", time.Minute)
+	require.NoError(t, err)
+
+	returnedPayload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, scopes, returnedPayload.Scopes)
+	require.Equal(t, "client", returnedPayload.Audience)
+	require.Equal(t, uuid.Nil, returnedPayload.SessionID)
+}
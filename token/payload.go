@@ -7,13 +7,33 @@ import (
 )
 
 var (
-	ErrExpiredToken = errors.New("token has expired")
-	ErrInvalidToken = errors.New("token is invalid")
+	ErrExpiredToken     = errors.New("token has expired")
+	ErrInvalidToken     = errors.New("token is invalid")
+	ErrInvalidTokenType = errors.New("token has the wrong type")
+)
+
+const (
+	// TokenTypeAccess is the type of tokens authenticating requests
+	TokenTypeAccess = "access"
+	// TokenTypeRefresh is the type of tokens that can only be exchanged for new tokens of their session
+	TokenTypeRefresh = "refresh"
 )
 
 type Payload struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	// Type is what the token can be used for, TokenTypeAccess or TokenTypeRefresh
+	Type string `json:"type"`
+	// Role is the role of the user when the token was issued, empty for tokens issued before roles existed
+	Role string `json:"role"`
+	// SessionID is the id of the session the token was issued for, if any
+	SessionID uuid.UUID `json:"session_id"`
+	// Scopes restricts the payload to the endpoints of the given scopes, empty if the payload is not restricted
+	Scopes []string `json:"scopes,omitempty"`
+	// Audience is who the token was issued to, e.g. the id of an OAuth client, empty for the clients of the bank
+	Audience string `json:"audience,omitempty"`
+	// APIKeyID is the id of the api key the payload was authenticated with, if any
+	APIKeyID  uuid.UUID `json:"api_key_id"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
@@ -27,6 +47,7 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	return &Payload{
 		ID:        tokenID,
 		Username:  username,
+		Type:      TokenTypeAccess,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}, nil
@@ -38,3 +59,11 @@ func (p *Payload) Valid() error {
 	}
 	return nil
 }
+
+// CheckType returns ErrInvalidTokenType unless the token is of the given type.
+func (p *Payload) CheckType(tokenType string) error {
+	if p.Type != tokenType {
+		return ErrInvalidTokenType
+	}
+	return nil
+}
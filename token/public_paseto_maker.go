@@ -0,0 +1,194 @@
+package token
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// publicPasetoHeader is the header of PASETO v4.public tokens
+const publicPasetoHeader = "v4.public."
+
+// PublicPasetoMaker makes PASETO v4.public tokens, signed with the current key of a KeyRing and verified
+// with any of its keys, so services verifying tokens only need the public keys.
+type PublicPasetoMaker struct {
+	keyRing *KeyRing
+}
+
+// pasetoFooter is the footer of the tokens, naming the key that signed them
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+func NewPublicPasetoMaker(keyRing *KeyRing) (Maker, error) {
+	return &PublicPasetoMaker{keyRing: keyRing}, nil
+}
+
+func (maker *PublicPasetoMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.CreateSessionToken(username, "", uuid.Nil, duration)
+}
+
+func (maker *PublicPasetoMaker) CreateSessionToken(username, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+	payload.Role = role
+	payload.SessionID = sessionID
+
+	return maker.sign(payload)
+}
+
+func (maker *PublicPasetoMaker) CreateRefreshToken(username, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+	payload.Type = TokenTypeRefresh
+	payload.Role = role
+	payload.SessionID = sessionID
+
+	return maker.sign(payload)
+}
+
+func (maker *PublicPasetoMaker) CreateScopedToken(username, role string, scopes []string, audience string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+	payload.Role = role
+	payload.Scopes = scopes
+	payload.Audience = audience
+
+	return maker.sign(payload)
+}
+
+func (maker *PublicPasetoMaker) sign(payload *Payload) (string, *Payload, error) {
+	key := maker.keyRing.signingKey()
+
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, err
+	}
+	footer, err := json.Marshal(pasetoFooter{KeyID: key.ID})
+	if err != nil {
+		return "", nil, err
+	}
+
+	pasetoToken := signPublicPaseto(key.PrivateKey, message, footer, nil)
+
+	return pasetoToken, payload, nil
+}
+
+func (maker *PublicPasetoMaker) VerifyToken(token string) (*Payload, error) {
+	decoded, err := decodePublicPaseto(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var decodedFooter pasetoFooter
+	if err := json.Unmarshal(decoded.footer, &decodedFooter); err != nil {
+		return nil, ErrInvalidToken
+	}
+	publicKey, err := maker.keyRing.verificationKey(decodedFooter.KeyID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	message, err := decoded.verify(publicKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &Payload{}
+	if err := json.Unmarshal(message, payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	err = payload.Valid()
+	if err != nil {
+		return nil, ErrExpiredToken
+	}
+
+	return payload, nil
+}
+
+func (maker *PublicPasetoMaker) PublicKeys() []JSONWebKey {
+	// JSON web keys have no algorithm for PASETO, the version of the tokens already names it
+	return maker.keyRing.publicKeys("")
+}
+
+// publicPasetoToken is a decoded PASETO v4.public token, whose signature is not verified yet
+type publicPasetoToken struct {
+	message   []byte
+	signature []byte
+	footer    []byte
+}
+
+// signPublicPaseto signs message, footer and the implicit assertion into a PASETO v4.public token, as specified by
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#sign
+func signPublicPaseto(privateKey ed25519.PrivateKey, message, footer, implicit []byte) string {
+	signature := ed25519.Sign(privateKey, preAuthEncode([]byte(publicPasetoHeader), message, footer, implicit))
+
+	pasetoToken := publicPasetoHeader + base64.RawURLEncoding.EncodeToString(append(message[:len(message):len(message)], signature...))
+	if len(footer) > 0 {
+		pasetoToken += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+
+	return pasetoToken
+}
+
+// decodePublicPaseto decodes a PASETO v4.public token, with or without a footer.
+func decodePublicPaseto(token string) (publicPasetoToken, error) {
+	var decoded publicPasetoToken
+
+	if !strings.HasPrefix(token, publicPasetoHeader) {
+		return decoded, ErrInvalidToken
+	}
+	parts := strings.Split(strings.TrimPrefix(token, publicPasetoHeader), ".")
+	if len(parts) > 2 {
+		return decoded, ErrInvalidToken
+	}
+
+	body, err := base64.RawURLEncoding.Strict().DecodeString(parts[0])
+	if err != nil || len(body) < ed25519.SignatureSize {
+		return decoded, ErrInvalidToken
+	}
+	decoded.message, decoded.signature = body[:len(body)-ed25519.SignatureSize], body[len(body)-ed25519.SignatureSize:]
+
+	if len(parts) == 2 {
+		decoded.footer, err = base64.RawURLEncoding.Strict().DecodeString(parts[1])
+		if err != nil {
+			return decoded, ErrInvalidToken
+		}
+	}
+
+	return decoded, nil
+}
+
+// verify returns the message of the token if it was signed by the private key of publicKey with the implicit assertion,
+// as specified by https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#verify
+func (decoded publicPasetoToken) verify(publicKey ed25519.PublicKey, implicit []byte) ([]byte, error) {
+	if !ed25519.Verify(publicKey, preAuthEncode([]byte(publicPasetoHeader), decoded.message, decoded.footer, implicit), decoded.signature) {
+		return nil, ErrInvalidToken
+	}
+
+	return decoded.message, nil
+}
+
+// preAuthEncode is the pre-authentication encoding of PASETO, making the signed pieces unambiguous.
+func preAuthEncode(pieces ...[]byte) []byte {
+	var buffer bytes.Buffer
+	_ = binary.Write(&buffer, binary.LittleEndian, uint64(len(pieces)))
+	for _, piece := range pieces {
+		_ = binary.Write(&buffer, binary.LittleEndian, uint64(len(piece)))
+		buffer.Write(piece)
+	}
+
+	return buffer.Bytes()
+}
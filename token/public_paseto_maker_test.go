@@ -0,0 +1,210 @@
+package token
+
+import (
+	"Simple-Bank/util"
+	"crypto/ed25519"
+	"encoding/hex"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newKeyRing(t *testing.T, id string, previous ...SigningKey) (*KeyRing, SigningKey) {
+	key, err := GenerateSigningKey(id)
+	require.NoError(t, err)
+
+	keyRing, err := NewKeyRing(key, previous...)
+	require.NoError(t, err)
+
+	return keyRing, key
+}
+
+func TestPublicPasetoMaker(t *testing.T) {
+	keyRing, key := newKeyRing(t, "2024-01")
+	maker, err := NewPublicPasetoMaker(keyRing)
+	require.NoError(t, err)
+
+	username := util.RandomUsername()
+	scopes := []string{"accounts:read"}
+	token, payload, err := maker.CreateScopedToken(username, "customer", scopes, "client", time.Minute)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+
+	returnedPayload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, returnedPayload.ID)
+	require.Equal(t, username, returnedPayload.Username)
+	require.Equal(t, scopes, returnedPayload.Scopes)
+	require.WithinDuration(t, payload.ExpiredAt, returnedPayload.ExpiredAt, time.Second)
+
+	// the public key is enough to verify tokens
+	verifyingRing, err := NewKeyRing(mustGenerateSigningKey(t, "other"), SigningKey{ID: key.ID, PublicKey: key.PublicKey})
+	require.NoError(t, err)
+	verifier, err := NewPublicPasetoMaker(verifyingRing)
+	require.NoError(t, err)
+	_, err = verifier.VerifyToken(token)
+	require.NoError(t, err)
+
+	publicKeys := maker.PublicKeys()
+	require.Len(t, publicKeys, 1)
+	require.Equal(t, key.ID, publicKeys[0].KeyID)
+	require.Equal(t, "Ed25519", publicKeys[0].Curve)
+}
+
+func TestExpiredPublicPasetoToken(t *testing.T) {
+	keyRing, _ := newKeyRing(t, "2024-01")
+	maker, err := NewPublicPasetoMaker(keyRing)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomUsername(), -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.Equal(t, ErrExpiredToken, err)
+	require.Nil(t, payload)
+}
+
+func TestInvalidPublicPasetoToken(t *testing.T) {
+	keyRing, _ := newKeyRing(t, "2024-01")
+	maker, err := NewPublicPasetoMaker(keyRing)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomUsername(), time.Minute)
+	require.NoError(t, err)
+
+	// a token signed by another key with the same id is refused
+	otherRing, _ := newKeyRing(t, "2024-01")
+	otherMaker, err := NewPublicPasetoMaker(otherRing)
+	require.NoError(t, err)
+	_, err = otherMaker.VerifyToken(token)
+	require.Equal(t, ErrInvalidToken, err)
+
+	// tampering with the payload breaks the signature
+	tampered := []byte(token)
+	tampered[len(publicPasetoHeader)+5] ^= 1
+	_, err = maker.VerifyToken(string(tampered))
+	require.Equal(t, ErrInvalidToken, err)
+
+	// symmetric tokens are refused
+	symmetricMaker, err := NewPasetoMaker(util.RandomString(32, util.ALL))
+	require.NoError(t, err)
+	symmetricToken, _, err := symmetricMaker.CreateToken(util.RandomUsername(), time.Minute)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(symmetricToken)
+	require.Equal(t, ErrInvalidToken, err)
+}
+
+func TestPublicPasetoMakerKeyRotation(t *testing.T) {
+	oldRing, oldKey := newKeyRing(t, "2024-01")
+	oldMaker, err := NewPublicPasetoMaker(oldRing)
+	require.NoError(t, err)
+
+	token, _, err := oldMaker.CreateToken(util.RandomUsername(), time.Minute)
+	require.NoError(t, err)
+
+	// tokens of the previous key keep verifying until it retires
+	oldKey.RetiresAt = time.Now().Add(time.Hour)
+	newRing, newKey := newKeyRing(t, "2024-02", oldKey)
+	newMaker, err := NewPublicPasetoMaker(newRing)
+	require.NoError(t, err)
+
+	_, err = newMaker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Len(t, newMaker.PublicKeys(), 2)
+	require.Equal(t, newKey.ID, newMaker.PublicKeys()[0].KeyID)
+
+	oldKey.RetiresAt = time.Now().Add(-time.Second)
+	retiredRing, _ := newKeyRing(t, "2024-02", oldKey)
+	retiredMaker, err := NewPublicPasetoMaker(retiredRing)
+	require.NoError(t, err)
+
+	_, err = retiredMaker.VerifyToken(token)
+	require.Equal(t, ErrInvalidToken, err)
+	require.Len(t, retiredMaker.PublicKeys(), 1)
+}
+
+// TestPublicPasetoVectors checks the signing and the verification of v4.public tokens against the official test
+// vectors of https://github.com/paseto-standard/test-vectors/blob/master/v4.json
+func TestPublicPasetoVectors(t *testing.T) {
+	privateKey, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774" +
+		"1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+	publicKey, err := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+	require.Equal(t, ed25519.PublicKey(publicKey), ed25519.PrivateKey(privateKey).Public())
+
+	payload := `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`
+
+	testCases := []struct {
+		name     string
+		token    string
+		footer   string
+		implicit string
+	}{
+		{
+			name: "4-S-1",
+			token: "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+				"bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA",
+		},
+		{
+			name: "4-S-2",
+			token: "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+				"v3Jt8mx_TdM2ceTGoqwrh4yDFn0XsHvvV_D0DtwQxVrJEBMl0F2caAdgnpKlt4p7xBnx1HcO-SPo8FPp214HDw" +
+				".eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+			footer: `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`,
+		},
+		{
+			name: "4-S-3",
+			token: "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+				"NPWciuD3d0o5eXJXG5pJy-DiVEoyPYWs1YSTwWHNJq6DZD3je5gf-0M4JR9ipdUSJbIovzmBECeaWmaqcaP0DQ" +
+				".eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+			footer:   `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`,
+			implicit: `{"test-vector":"4-S-3"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Ed25519 signatures are deterministic, so signing the vector gives the exact token
+			pasetoToken := signPublicPaseto(privateKey, []byte(payload), []byte(testCase.footer), []byte(testCase.implicit))
+			require.Equal(t, testCase.token, pasetoToken)
+
+			decoded, err := decodePublicPaseto(testCase.token)
+			require.NoError(t, err)
+			require.Equal(t, testCase.footer, string(decoded.footer))
+
+			message, err := decoded.verify(publicKey, []byte(testCase.implicit))
+			require.NoError(t, err)
+			require.Equal(t, payload, string(message))
+
+			// the implicit assertion is part of the signature
+			_, err = decoded.verify(publicKey, []byte(`{"test-vector":"other"}`))
+			require.ErrorIs(t, err, ErrInvalidToken)
+
+			// so is the footer
+			decoded.footer = append(decoded.footer, ' ')
+			_, err = decoded.verify(publicKey, []byte(testCase.implicit))
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+// TestPublicPasetoVectorsFailure checks that tokens of other versions and purposes, and malformed tokens,
+// are rejected before their signature is verified.
+func TestPublicPasetoVectorsFailure(t *testing.T) {
+	// the body of 4-S-1
+	body := "eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+		"bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA"
+
+	for _, pasetoToken := range []string{
+		"v4.local." + body,
+		"v3.public." + body,
+		"v4.public." + body + ".e30.e30",
+		"v4.public." + body[:40],
+		"v4.public." + body + "=",
+	} {
+		_, err := decodePublicPaseto(pasetoToken)
+		require.ErrorIs(t, err, ErrInvalidToken, pasetoToken)
+	}
+}
@@ -1,18 +1,223 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
+	"strings"
 )
 
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+var (
+	// ErrPasswordMismatch is returned when a password does not match a hash
+	ErrPasswordMismatch = errors.New("password does not match")
+	// ErrUnsupportedHash is returned when a hash was not encoded by the hasher checking it
+	ErrUnsupportedHash = errors.New("unsupported password hash")
+)
+
+// PasswordHasher hashes passwords into encoded hashes carrying their algorithm and cost parameters,
+// so hashes of older algorithms and parameters can still be checked.
+type PasswordHasher interface {
+	// Hash hashes password with the current algorithm and parameters of the hasher.
+	Hash(password string) (string, error)
+	// Check returns ErrPasswordMismatch if password does not match encodedHash,
+	// and ErrUnsupportedHash if the hasher cannot check encodedHash.
+	Check(password, encodedHash string) error
+	// NeedsRehash reports whether encodedHash was not hashed with the current algorithm and parameters of the hasher.
+	NeedsRehash(encodedHash string) bool
+}
+
+// Argon2idParams are the cost parameters of argon2id.
+type Argon2idParams struct {
+	// Memory is the memory used in KiB
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams are the parameters recommended by OWASP for argon2id.
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Argon2idHasher hashes passwords with argon2id, encoding hashes in the PHC string format,
+// e.g. $argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>.
+type Argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher creates an Argon2idHasher hashing with params.
+func NewArgon2idHasher(params Argon2idParams) *Argon2idHasher {
+	return &Argon2idHasher{params: params}
+}
+
+func (hasher *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, hasher.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt,
+		hasher.params.Iterations, hasher.params.Memory, hasher.params.Parallelism, hasher.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		hasher.params.Memory,
+		hasher.params.Iterations,
+		hasher.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (hasher *Argon2idHasher) Check(password, encodedHash string) error {
+	params, salt, key, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return err
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return ErrPasswordMismatch
+	}
+
+	return nil
+}
+
+func (hasher *Argon2idHasher) NeedsRehash(encodedHash string) bool {
+	params, _, _, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return params != hasher.params
+}
+
+// decodeArgon2id decodes a hash encoded by Argon2idHasher.Hash.
+func decodeArgon2id(encodedHash string) (params Argon2idParams, salt, key []byte, err error) {
+	// the hash starts with a $, so the first part is empty
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2idParams{}, nil, nil, ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: argon2id version %q", ErrUnsupportedHash, parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil ||
+		params.Iterations == 0 || params.Parallelism == 0 {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: argon2id parameters %q", ErrUnsupportedHash, parts[3])
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: argon2id salt: %s", ErrUnsupportedHash, err)
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: argon2id key", ErrUnsupportedHash)
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
+
+// BcryptHasher hashes passwords with bcrypt, whose hashes carry their cost, e.g. $2a$10$<salt and key>.
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher creates a BcryptHasher hashing with cost.
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+func (hasher *BcryptHasher) Hash(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), hasher.cost)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
 	return string(hashedPassword), nil
 }
 
+func (hasher *BcryptHasher) Check(password, encodedHash string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return ErrPasswordMismatch
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedHash, err)
+	}
+}
+
+func (hasher *BcryptHasher) NeedsRehash(encodedHash string) bool {
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	return err != nil || cost != hasher.cost
+}
+
+// migratingHasher hashes passwords with a current hasher, and checks hashes of the current hasher
+// and of the hashers it replaced. Hashes of the replaced hashers need a rehash.
+type migratingHasher struct {
+	current  PasswordHasher
+	replaced []PasswordHasher
+}
+
+// NewMigratingHasher creates a PasswordHasher hashing with current, and still checking the hashes of replaced.
+func NewMigratingHasher(current PasswordHasher, replaced ...PasswordHasher) PasswordHasher {
+	return &migratingHasher{current: current, replaced: replaced}
+}
+
+func (hasher *migratingHasher) Hash(password string) (string, error) {
+	return hasher.current.Hash(password)
+}
+
+func (hasher *migratingHasher) Check(password, encodedHash string) error {
+	err := hasher.current.Check(password, encodedHash)
+	for _, replaced := range hasher.replaced {
+		if !errors.Is(err, ErrUnsupportedHash) {
+			break
+		}
+		err = replaced.Check(password, encodedHash)
+	}
+
+	return err
+}
+
+func (hasher *migratingHasher) NeedsRehash(encodedHash string) bool {
+	return hasher.current.NeedsRehash(encodedHash)
+}
+
+// DefaultPasswordHasher hashes passwords with argon2id, and still checks the bcrypt hashes of the passwords
+// set before argon2id.
+var DefaultPasswordHasher = NewMigratingHasher(
+	NewArgon2idHasher(DefaultArgon2idParams),
+	NewBcryptHasher(bcrypt.DefaultCost),
+)
+
+// HashPassword hashes password with DefaultPasswordHasher.
+func HashPassword(password string) (string, error) {
+	return DefaultPasswordHasher.Hash(password)
+}
+
+// CheckPassword returns ErrPasswordMismatch if password does not match hashPassword,
+// a hash of DefaultPasswordHasher or of one of the hashers it replaced.
 func CheckPassword(password, hashPassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashPassword), []byte(password))
+	return DefaultPasswordHasher.Check(password, hashPassword)
+}
+
+// PasswordNeedsRehash reports whether hashPassword must be hashed again with DefaultPasswordHasher,
+// because it was hashed with another algorithm or other parameters.
+func PasswordNeedsRehash(hashPassword string) bool {
+	return DefaultPasswordHasher.NeedsRehash(hashPassword)
 }
@@ -3,6 +3,7 @@ package util
 import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
+	"strings"
 	"testing"
 )
 
@@ -12,6 +13,8 @@ func TestPassword(t *testing.T) {
 	hashedPassword, err := HashPassword(password)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword)
+	require.True(t, strings.HasPrefix(hashedPassword, "$argon2id$v=19$m=19456,t=2,p=1$"))
+	require.False(t, PasswordNeedsRehash(hashedPassword))
 
 	err = CheckPassword(password, hashedPassword)
 	require.NoError(t, err)
@@ -19,7 +22,7 @@ func TestPassword(t *testing.T) {
 	wrongPassword := RandomPassword()
 	err = CheckPassword(wrongPassword, hashedPassword)
 	require.Error(t, err)
-	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+	require.ErrorIs(t, err, ErrPasswordMismatch)
 
 	hashedPassword2, err := HashPassword(password)
 	require.NoError(t, err)
@@ -27,3 +30,52 @@ func TestPassword(t *testing.T) {
 
 	require.NotEqual(t, hashedPassword, hashedPassword2)
 }
+
+func TestBcryptPassword(t *testing.T) {
+	password := RandomPassword()
+
+	// passwords hashed before argon2id are still checked, and need a rehash
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	require.NoError(t, CheckPassword(password, string(hashedPassword)))
+	require.ErrorIs(t, CheckPassword(RandomPassword(), string(hashedPassword)), ErrPasswordMismatch)
+	require.True(t, PasswordNeedsRehash(string(hashedPassword)))
+
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+	hashed, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.NoError(t, hasher.Check(password, hashed))
+	require.False(t, hasher.NeedsRehash(hashed))
+	require.True(t, hasher.NeedsRehash(string(hashedPassword)))
+}
+
+func TestArgon2idParameters(t *testing.T) {
+	password := RandomPassword()
+	weakParams := Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 8, KeyLength: 16}
+
+	// hashes carry their parameters, so hashes of older parameters are still checked, and need a rehash
+	hashedPassword, err := NewArgon2idHasher(weakParams).Hash(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hashedPassword, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	require.NoError(t, CheckPassword(password, hashedPassword))
+	require.ErrorIs(t, CheckPassword(RandomPassword(), hashedPassword), ErrPasswordMismatch)
+	require.True(t, PasswordNeedsRehash(hashedPassword))
+	require.False(t, NewArgon2idHasher(weakParams).NeedsRehash(hashedPassword))
+}
+
+func TestUnsupportedPasswordHash(t *testing.T) {
+	hasher := NewArgon2idHasher(DefaultArgon2idParams)
+
+	for _, encodedHash := range []string{
+		"",
+		"plain text",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=1024,t=0,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$not base64!$a2V5a2V5",
+	} {
+		require.ErrorIs(t, hasher.Check("password", encodedHash), ErrUnsupportedHash, encodedHash)
+		require.True(t, hasher.NeedsRehash(encodedHash), encodedHash)
+		require.Error(t, CheckPassword("password", encodedHash), encodedHash)
+	}
+}
@@ -0,0 +1,175 @@
+package verification
+
+import (
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/mail"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTokenTTL is how long verification tokens last when no ttl is configured
+	DefaultTokenTTL = 24 * time.Hour
+	// tokenBytes is the number of random bytes of a verification token
+	tokenBytes = 32
+)
+
+var (
+	// ErrInvalidToken is returned when a verification token is unknown, expired, already used,
+	// or was sent to an email the user changed since
+	ErrInvalidToken = errors.New("verification token is invalid or has expired")
+	// ErrAlreadyVerified is returned when a verification is requested for an email that is already verified
+	ErrAlreadyVerified = errors.New("email is already verified")
+	// ErrEmailNotVerified is returned when a user who must verify their email moves money before they do
+	ErrEmailNotVerified = errors.New("email must be verified first")
+)
+
+// Verifier emails users a token proving they own their email, and verifies it when they send it back.
+type Verifier struct {
+	services services.Services
+	mailer   mail.Mailer
+	tokenTTL time.Duration
+	// linkURL is the page of the frontend verifying tokens, the emails only carry the token if it is empty
+	linkURL string
+	// required is true when users cannot move money until they verify their email
+	required bool
+}
+
+// NewVerifier creates a Verifier emailing tokens lasting tokenTTL, DefaultTokenTTL if zero, in links to linkURL.
+// If required, users cannot move money until they verify their email.
+func NewVerifier(services services.Services, mailer mail.Mailer, tokenTTL time.Duration, linkURL string, required bool) *Verifier {
+	if tokenTTL <= 0 {
+		tokenTTL = DefaultTokenTTL
+	}
+
+	return &Verifier{
+		services: services,
+		mailer:   mailer,
+		tokenTTL: tokenTTL,
+		linkURL:  linkURL,
+		required: required,
+	}
+}
+
+// Send emails a new verification token to the current email of user.
+func (verifier *Verifier) Send(user models.User) error {
+	verificationToken, err := randomHex(tokenBytes)
+	if err != nil {
+		return err
+	}
+
+	verification, err := verifier.services.CreateEmailVerification(models.EmailVerification{
+		HashedToken: hashToken(verificationToken),
+		Username:    user.Username,
+		Email:       user.Email,
+		ExpiresAt:   time.Now().Add(verifier.tokenTTL).UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return verifier.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body:    verifier.body(user, verificationToken, verification.ExpiresAt),
+	})
+}
+
+// Resend emails a new verification token to a user whose email is not verified yet.
+// It returns ErrAlreadyVerified if it is.
+func (verifier *Verifier) Resend(username string) error {
+	user, err := verifier.services.GetUser(username)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return ErrAlreadyVerified
+	}
+
+	return verifier.Send(user)
+}
+
+// Verify verifies the email a token was sent to, and returns its user. It returns ErrInvalidToken if the token
+// cannot verify the current email of the user.
+func (verifier *Verifier) Verify(verificationToken string) (models.User, error) {
+	verification, err := verifier.services.GetEmailVerification(hashToken(verificationToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, ErrInvalidToken
+		}
+		return models.User{}, err
+	}
+	if time.Now().After(verification.ExpiresAt) {
+		return models.User{}, ErrInvalidToken
+	}
+
+	user, err := verifier.services.VerifyEmail(verification)
+	if err != nil {
+		if errors.Is(err, services.ErrEmailChanged) {
+			return models.User{}, ErrInvalidToken
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// CheckVerified returns ErrEmailNotVerified if users must verify their email before they move money,
+// and the user with username did not.
+func (verifier *Verifier) CheckVerified(username string) error {
+	if !verifier.required {
+		return nil
+	}
+
+	user, err := verifier.services.GetUser(username)
+	if err != nil {
+		return err
+	}
+	if !user.EmailVerified {
+		return ErrEmailNotVerified
+	}
+
+	return nil
+}
+
+// body returns the text of the email carrying verificationToken.
+func (verifier *Verifier) body(user models.User, verificationToken string, expiresAt time.Time) string {
+	var body strings.Builder
+
+	fmt.Fprintf(&body, "Hello %s,\n\n", user.FullName)
+	if link, err := url.Parse(verifier.linkURL); err == nil && verifier.linkURL != "" {
+		query := link.Query()
+		query.Set("token", verificationToken)
+		link.RawQuery = query.Encode()
+		fmt.Fprintf(&body, "Open this link to verify your email:\n\n%s\n\n", link)
+	} else {
+		fmt.Fprintf(&body, "Use this token to verify your email:\n\n%s\n\n", verificationToken)
+	}
+	fmt.Fprintf(&body, "It expires on %s. If you did not sign up to Simple Bank, ignore this email.\n",
+		expiresAt.Format(time.RFC1123))
+
+	return body.String()
+}
+
+// hashToken hashes verification tokens, which are long random strings, so a fast hash is enough.
+func hashToken(verificationToken string) string {
+	sum := sha256.Sum256([]byte(verificationToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
@@ -0,0 +1,143 @@
+package verification
+
+import (
+	mockdb "Simple-Bank/db/mock"
+	"Simple-Bank/db/models"
+	"Simple-Bank/db/services"
+	"Simple-Bank/mail"
+	"Simple-Bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// recordingMailer records the emails it sends.
+type recordingMailer struct {
+	messages []mail.Message
+}
+
+func (mailer *recordingMailer) Send(message mail.Message) error {
+	mailer.messages = append(mailer.messages, message)
+	return nil
+}
+
+func randomUser() models.User {
+	return models.User{
+		Username: util.RandomUsername(),
+		FullName: util.RandomFullname(),
+		Email:    util.RandomEmail(),
+	}
+}
+
+func TestSendAndVerify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	mailer := &recordingMailer{}
+	verifier := NewVerifier(mockServices, mailer, time.Hour, "https://bank.example.com/verify_email", false)
+	user := randomUser()
+
+	var stored models.EmailVerification
+	mockServices.EXPECT().
+		CreateEmailVerification(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(verification models.EmailVerification) (models.EmailVerification, error) {
+			stored = verification
+			return verification, nil
+		})
+
+	require.NoError(t, verifier.Send(user))
+	require.Equal(t, user.Username, stored.Username)
+	require.Equal(t, user.Email, stored.Email)
+	require.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Second)
+
+	require.Len(t, mailer.messages, 1)
+	require.Equal(t, user.Email, mailer.messages[0].To)
+	link, err := url.Parse(regexp.MustCompile(`https://\S+`).FindString(mailer.messages[0].Body))
+	require.NoError(t, err)
+	verificationToken := link.Query().Get("token")
+	require.Equal(t, hashToken(verificationToken), stored.HashedToken)
+
+	mockServices.EXPECT().GetEmailVerification(stored.HashedToken).Times(1).Return(stored, nil)
+	mockServices.EXPECT().VerifyEmail(stored).Times(1).Return(models.User{Username: user.Username, EmailVerified: true}, nil)
+
+	verified, err := verifier.Verify(verificationToken)
+	require.NoError(t, err)
+	require.True(t, verified.EmailVerified)
+}
+
+func TestVerifyInvalidToken(t *testing.T) {
+	user := randomUser()
+	verificationToken := util.RandomString(64, util.LOWERCASE)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(mockServices *mockdb.MockServices)
+	}{
+		{
+			name: "Unknown",
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().GetEmailVerification(gomock.Any()).Times(1).Return(models.EmailVerification{}, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "Expired",
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().
+					GetEmailVerification(hashToken(verificationToken)).
+					Times(1).
+					Return(models.EmailVerification{Username: user.Username, ExpiresAt: time.Now().Add(-time.Second)}, nil)
+				mockServices.EXPECT().VerifyEmail(gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "EmailChanged",
+			buildStubs: func(mockServices *mockdb.MockServices) {
+				mockServices.EXPECT().
+					GetEmailVerification(hashToken(verificationToken)).
+					Times(1).
+					Return(models.EmailVerification{Username: user.Username, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				mockServices.EXPECT().VerifyEmail(gomock.Any()).Times(1).Return(models.User{}, services.ErrEmailChanged)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockServices := mockdb.NewMockServices(ctrl)
+			testCase.buildStubs(mockServices)
+
+			_, err := NewVerifier(mockServices, &recordingMailer{}, 0, "", false).Verify(verificationToken)
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestResendAndCheckVerified(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockServices := mockdb.NewMockServices(ctrl)
+	mailer := &recordingMailer{}
+	user := randomUser()
+	verifiedUser := randomUser()
+	verifiedUser.EmailVerified = true
+
+	mockServices.EXPECT().GetUser(user.Username).AnyTimes().Return(user, nil)
+	mockServices.EXPECT().GetUser(verifiedUser.Username).AnyTimes().Return(verifiedUser, nil)
+	mockServices.EXPECT().CreateEmailVerification(gomock.Any()).Times(1).Return(models.EmailVerification{}, nil)
+
+	// without a link, the email carries the token alone
+	verifier := NewVerifier(mockServices, mailer, 0, "", true)
+	require.NoError(t, verifier.Resend(user.Username))
+	require.Len(t, mailer.messages, 1)
+	require.Regexp(t, `\n[0-9a-f]{64}\n`, mailer.messages[0].Body)
+	require.ErrorIs(t, verifier.Resend(verifiedUser.Username), ErrAlreadyVerified)
+
+	require.ErrorIs(t, verifier.CheckVerified(user.Username), ErrEmailNotVerified)
+	require.NoError(t, verifier.CheckVerified(verifiedUser.Username))
+	// unless it is required, users move money before they verify their email
+	require.NoError(t, NewVerifier(mockServices, mailer, 0, "", false).CheckVerified(user.Username))
+}